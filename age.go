@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// formatAge describes how much older from is than to in calendar terms, e.g.
+// "4 months 12 days". Differences under a day are "less than a day".
+func formatAge(from, to time.Time) string {
+	from, to = from.UTC(), to.UTC()
+	if to.Sub(from) < 24*time.Hour {
+		return "less than a day"
+	}
+
+	// Count whole months, then the whole days remaining after them.
+	months := (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+	anchor := addMonths(from, months)
+	if anchor.After(to) {
+		months--
+		anchor = addMonths(from, months)
+	}
+	years := months / 12
+	months %= 12
+	days := int(to.Sub(anchor) / (24 * time.Hour))
+
+	var parts []string
+	for _, p := range []struct {
+		n    int
+		unit string
+	}{
+		{years, "year"},
+		{months, "month"},
+		{days, "day"},
+	} {
+		if p.n != 0 {
+			parts = append(parts, plural(p.n, p.unit))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// addMonths adds n months to t. Unlike time.Time.AddDate, the day is clamped
+// to the end of the month rather than overflowing, so January 31 plus one
+// month is February 28 (or 29).
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return time.Date(
+		first.Year(),
+		first.Month(),
+		min(t.Day(), lastDay),
+		t.Hour(),
+		t.Minute(),
+		t.Second(),
+		t.Nanosecond(),
+		time.UTC,
+	)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatAge(t *testing.T) {
+	date := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		from time.Time
+		to   time.Time
+		want string
+	}{
+		{
+			name: "months and days",
+			from: date(2023, 7, 19, 12),
+			to:   date(2023, 12, 1, 12),
+			want: "4 months 12 days",
+		},
+		{name: "one day", from: date(2023, 11, 1, 0), to: date(2023, 11, 2, 0), want: "1 day"},
+		{name: "one month", from: date(2023, 11, 1, 0), to: date(2023, 12, 1, 0), want: "1 month"},
+		{
+			name: "years",
+			from: date(2021, 3, 1, 0),
+			to:   date(2023, 4, 3, 0),
+			want: "2 years 1 month 2 days",
+		},
+		{
+			name: "partial day does not count",
+			from: date(2023, 11, 1, 18),
+			to:   date(2023, 11, 3, 6),
+			want: "1 day",
+		},
+		{
+			name: "borrows from the previous month",
+			from: date(2023, 1, 31, 0),
+			to:   date(2023, 3, 1, 0),
+			want: "1 month 1 day",
+		},
+		{
+			name: "less than a day",
+			from: date(2023, 11, 1, 0),
+			to:   date(2023, 11, 1, 23),
+			want: "less than a day",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatAge(tt.from, tt.to); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// badgeLabel is the label of the -badge badge.
+const badgeLabel = "untagged deps"
+
+// badge is a shields.io endpoint badge
+// (https://shields.io/badges/endpoint-badge).
+type badge struct { //nolint:tagliatelle // matches the Shields.io endpoint
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// Ages of the stalest update at which the badge turns orange and then red.
+const (
+	badgeOrangeAge = 30 * 24 * time.Hour
+	badgeRedAge    = 180 * 24 * time.Hour
+)
+
+// newBadge returns the badge for a report: how many dependencies are behind,
+// colored by how much older than the latest the stalest current commit is.
+func newBadge(rep check.Report) badge {
+	b := badge{SchemaVersion: 1, Label: badgeLabel}
+	switch {
+	case len(rep.Updates) > 0:
+		b.Message = fmt.Sprintf("%d behind", len(rep.Updates))
+		var stalest time.Duration
+		for _, u := range rep.Updates {
+			stalest = max(stalest, u.Age())
+		}
+		switch {
+		case stalest >= badgeRedAge:
+			b.Color = "red"
+		case stalest >= badgeOrangeAge:
+			b.Color = "orange"
+		default:
+			b.Color = "yellow"
+		}
+	case len(rep.Failures) > 0:
+		b.Message = "check failed"
+		b.Color = "lightgrey"
+	default:
+		b.Message = "up to date"
+		b.Color = "brightgreen"
+	}
+	return b
+}
+
+// writeBadge writes the report's badge to the file at path.
+func writeBadge(path string, rep check.Report) error {
+	data, err := json.MarshalIndent(newBadge(rep), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding badge: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing badge: %w", err)
+	}
+	return nil
+}
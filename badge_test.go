@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestNewBadge(t *testing.T) {
+	pinned := time.Date(2023, 7, 19, 0, 0, 0, 0, time.UTC)
+	update := func(age time.Duration) check.Update {
+		return check.Update{
+			Module:      "go4.org/netipx",
+			CurrentTime: pinned,
+			LatestTime:  pinned.Add(age),
+		}
+	}
+	failures := []check.Failure{{Module: "example.com/a", Err: errors.New("x")}}
+
+	tests := []struct {
+		name        string
+		rep         check.Report
+		wantMessage string
+		wantColor   string
+	}{
+		{name: "clean", wantMessage: "up to date", wantColor: "brightgreen"},
+		{
+			name:        "failures",
+			rep:         check.Report{Failures: failures},
+			wantMessage: "check failed",
+			wantColor:   "lightgrey",
+		},
+		{
+			name:        "recent update",
+			rep:         check.Report{Updates: []check.Update{update(48 * time.Hour)}},
+			wantMessage: "1 behind",
+			wantColor:   "yellow",
+		},
+		{
+			name: "stale update",
+			rep: check.Report{
+				Updates: []check.Update{
+					update(48 * time.Hour),
+					update(60 * 24 * time.Hour),
+				},
+				Failures: failures,
+			},
+			wantMessage: "2 behind",
+			wantColor:   "orange",
+		},
+		{
+			name:        "very stale update",
+			rep:         check.Report{Updates: []check.Update{update(365 * 24 * time.Hour)}},
+			wantMessage: "1 behind",
+			wantColor:   "red",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBadge(tt.rep)
+			if b.SchemaVersion != 1 || b.Label != badgeLabel {
+				t.Errorf("got schema version %d and label %q", b.SchemaVersion, b.Label)
+			}
+			if b.Message != tt.wantMessage || b.Color != tt.wantColor {
+				t.Errorf(
+					"got %q in %s, want %q in %s",
+					b.Message,
+					b.Color,
+					tt.wantMessage,
+					tt.wantColor,
+				)
+			}
+		})
+	}
+}
+
+func TestWriteBadge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "badge.json")
+	if err := writeBadge(path, check.Report{}); err != nil {
+		t.Fatalf("writeBadge: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "schemaVersion": 1,
+  "label": "untagged deps",
+  "message": "up to date",
+  "color": "brightgreen"
+}
+`
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
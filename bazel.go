@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// bazelCommand is the subcommand checking the Go dependencies pinned in a
+// Bazel file.
+const bazelCommand = "bazel"
+
+// bazelConcurrency is how many pins the bazel subcommand checks at a time,
+// the go.mod check's default.
+const bazelConcurrency = 4
+
+// parseBazelFlags parses the arguments of the bazel subcommand: flags
+// followed by an optional Bazel file, which defaults to MODULE.bazel.
+func parseBazelFlags(args []string) (probeOptions, string, error) {
+	opts, fs, err := parseProbeFlagSet(
+		bazelCommand,
+		"Usage: check-untagged-go-deps bazel [flags] [MODULE.bazel|WORKSPACE|deps.bzl]\n\n"+
+			"Report whether newer commits exist for Go dependencies pinned to commits or "+
+			"pseudo-versions by go_repository rules or go_deps.module tags.\n\n",
+		args,
+	)
+	if err != nil {
+		return probeOptions{}, "", err
+	}
+
+	switch fs.NArg() {
+	case 0:
+		return opts, "MODULE.bazel", nil
+	case 1:
+		return opts, fs.Arg(0), nil
+	default:
+		return probeOptions{}, "", &usageError{msg: "bazel takes at most one file"}
+	}
+}
+
+// runBazel runs the bazel subcommand with args and writes its report to w.
+// It returns the exit code.
+func runBazel(args []string, w io.Writer) (int, error) {
+	opts, path, err := parseBazelFlags(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, nil
+		}
+		return exitUsage, err
+	}
+
+	// Stop in-flight queries on interrupt rather than waiting for them.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c, err := newProbeChecker(opts, bazelConcurrency)
+	if err != nil {
+		return exitError, err
+	}
+	rep, err := c.CheckBazel(ctx, path)
+	if err != nil {
+		return exitError, err
+	}
+	return writeProbeReport(w, rep, opts, path)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseBazelFlags(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantPath string
+		wantErr  bool
+	}{
+		{wantPath: "MODULE.bazel"},
+		{args: []string{"-branches", "trunk", "WORKSPACE"}, wantPath: "WORKSPACE"},
+		{args: []string{"WORKSPACE", "deps.bzl"}, wantErr: true},
+		{args: []string{"-format", "markdown"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		_, path, err := parseBazelFlags(tt.args)
+		if tt.wantErr {
+			var usageErr *usageError
+			if !errors.As(err, &usageErr) {
+				t.Errorf("parseBazelFlags(%q): got error %v, want a usage error", tt.args, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseBazelFlags(%q): %v", tt.args, err)
+			continue
+		}
+		if path != tt.wantPath {
+			t.Errorf("parseBazelFlags(%q): got path %q, want %q", tt.args, path, tt.wantPath)
+		}
+	}
+}
+
+func TestRunBazelMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "MODULE.bazel")
+	code, err := runBazel([]string{path}, &bytes.Buffer{})
+	if code != exitError || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got exit code %d and error %v, want %d and a missing file", code, err, exitError)
+	}
+}
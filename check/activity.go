@@ -0,0 +1,74 @@
+package check
+
+import (
+	"context"
+	"time"
+)
+
+// ActivitySource reports how actively a module's repository is maintained.
+type ActivitySource interface {
+	// Activity returns the state of the module's repository. If the module
+	// is not hosted where the source can look, the error must wrap
+	// ErrUnsupportedHost.
+	Activity(ctx context.Context, modulePath string) (Activity, error)
+}
+
+// Activity is the state of a module's repository.
+type Activity struct {
+	// Archived is set if the repository is archived (read-only).
+	Archived bool
+	// LastCommit is the time of the newest commit on the repository's
+	// default branch, if known.
+	LastCommit time.Time
+}
+
+// Abandoned is a dependency whose repository is archived or has had no
+// recent commits (see WithAbandonedCheck). Such a dependency is better
+// replaced, or forked, than updated.
+type Abandoned struct {
+	// Module is the module path.
+	Module string `json:"module"`
+	// Archived is set if the repository is archived.
+	Archived bool `json:"archived"`
+	// LastCommit is the time of the newest commit on the repository's
+	// default branch, if known.
+	LastCommit time.Time `json:"last_commit,omitzero"`
+}
+
+// WithAbandonedCheck looks up the activity of every dependency's repository,
+// whether or not it has an update, and reports it as abandoned if the
+// repository is archived or, if staleAfter is positive, its default branch
+// has had no commits for staleAfter. A failed lookup is logged and the
+// dependency is not reported. By default repositories are not checked.
+func WithAbandonedCheck(source ActivitySource, staleAfter time.Duration) Option {
+	return func(c *Checker) {
+		c.activitySource = source
+		c.staleAfter = staleAfter
+	}
+}
+
+// checkAbandoned returns how the module's repository is abandoned, or nil if
+// it is not.
+func (c *Checker) checkAbandoned(ctx context.Context, modulePath string) (*Abandoned, error) {
+	release, err := c.limiter.acquire(ctx, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	activity, err := c.activitySource.Activity(ctx, modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	stale := c.staleAfter > 0 && !activity.LastCommit.IsZero() &&
+		time.Since(activity.LastCommit) > c.staleAfter
+	if !activity.Archived && !stale {
+		return nil, nil
+	}
+	return &Abandoned{
+		Module:     modulePath,
+		Archived:   activity.Archived,
+		LastCommit: activity.LastCommit,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type activitySourceFunc func(modulePath string) (Activity, error)
+
+func (f activitySourceFunc) Activity(_ context.Context, modulePath string) (Activity, error) {
+	return f(modulePath)
+}
+
+func TestWithAbandonedCheck(t *testing.T) {
+	old := time.Now().AddDate(-2, 0, 0).UTC().Truncate(time.Second)
+	recent := time.Now().AddDate(0, -1, 0).UTC().Truncate(time.Second)
+	activity := map[string]Activity{
+		"github.com/example/archived": {Archived: true, LastCommit: recent},
+		"github.com/example/stale":    {LastCommit: old},
+		"github.com/example/active":   {LastCommit: recent},
+	}
+
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"github.com/example/archived@main": "v0.0.0-20231201000000-bbbbbbbbbbbb",
+			"github.com/example/stale@main":    "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			"github.com/example/active@main":   "v0.0.0-20231201000000-bbbbbbbbbbbb",
+			"go4.org/netipx@main":              "v0.0.0-20231101000000-aaaaaaaaaaaa",
+		}),
+		WithBranches(branchMain),
+		WithAbandonedCheck(activitySourceFunc(func(modulePath string) (Activity, error) {
+			a, ok := activity[modulePath]
+			if !ok {
+				return Activity{}, fmt.Errorf("%s: %w", modulePath, ErrUnsupportedHost)
+			}
+			return a, nil
+		}), 365*24*time.Hour),
+	)
+
+	// The stale module has no update, but is still reported.
+	rep := c.Check(t.Context(), []Dependency{
+		{Module: "github.com/example/archived", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "github.com/example/stale", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "github.com/example/active", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "go4.org/netipx", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+	})
+
+	if len(rep.Failures) != 0 {
+		t.Fatalf("got failures %v", rep.Failures)
+	}
+	want := []Abandoned{
+		{Module: "github.com/example/archived", Archived: true, LastCommit: recent},
+		{Module: "github.com/example/stale", LastCommit: old},
+	}
+	if !reflect.DeepEqual(rep.Abandoned, want) {
+		t.Errorf("got %+v, want %+v", rep.Abandoned, want)
+	}
+}
@@ -0,0 +1,344 @@
+package check
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Compatibility assesses whether an update changes the module's exported API
+// in ways that could break importers, similar to what gorelease reports for
+// a tagged release. It is based on declarations only: it does not type
+// check, so changes made through type aliases or embedded types from other
+// packages are not detected.
+type Compatibility struct {
+	// Compatible is set if no incompatible changes were found.
+	Compatible bool `json:"compatible"`
+	// Incompatible describes incompatible changes, e.g.
+	// "example.com/m/pkg.Func: removed", sorted.
+	Incompatible []string `json:"incompatible,omitempty"`
+	// Added lists new exported declarations, sorted.
+	Added []string `json:"added,omitempty"`
+}
+
+// WithCompatibility downloads the current and latest versions of each updated
+// dependency from source and compares their exported APIs (see
+// Compatibility). A failed assessment is logged and leaves
+// Update.Compatibility unset. By default APIs are not compared.
+func WithCompatibility(source ModuleSource) Option {
+	return func(c *Checker) {
+		c.moduleSource = source
+		c.compatibility = true
+	}
+}
+
+// maxSourceFileSize limits the size of Go files parsed from module zips.
+const maxSourceFileSize = 10 << 20
+
+// assessCompatibility compares the exported APIs of two versions of a module.
+func assessCompatibility(
+	ctx context.Context,
+	zips *moduleZips,
+	current,
+	latest string,
+) (*Compatibility, error) {
+	oldAPI, err := moduleAPI(ctx, zips, current)
+	if err != nil {
+		return nil, err
+	}
+	newAPI, err := moduleAPI(ctx, zips, latest)
+	if err != nil {
+		return nil, err
+	}
+	return compareAPIs(oldAPI, newAPI), nil
+}
+
+func moduleAPI(ctx context.Context, zips *moduleZips, version string) (map[string]string, error) {
+	zr, err := zips.get(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	api, err := exportedAPI(zr, zips.modulePath, version)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s@%s: %w", zips.modulePath, version, err)
+	}
+	return api, nil
+}
+
+// compareAPIs compares exported APIs as returned by exportedAPI.
+func compareAPIs(oldAPI, newAPI map[string]string) *Compatibility {
+	c := &Compatibility{}
+	for name, oldDecl := range oldAPI {
+		newDecl, ok := newAPI[name]
+		switch {
+		case !ok:
+			c.Incompatible = append(c.Incompatible, name+": removed")
+		case newDecl != oldDecl:
+			c.Incompatible = append(
+				c.Incompatible,
+				fmt.Sprintf("%s: changed from %s to %s", name, oldDecl, newDecl),
+			)
+		}
+	}
+	for name := range newAPI {
+		if _, ok := oldAPI[name]; !ok {
+			c.Added = append(c.Added, name)
+		}
+	}
+	slices.Sort(c.Incompatible)
+	slices.Sort(c.Added)
+	c.Compatible = len(c.Incompatible) == 0
+	return c
+}
+
+// exportedAPI returns the exported declarations of the packages in a module
+// zip, keyed by qualified name (e.g. "example.com/m/pkg.T.Method"), with
+// each declaration's type as the value. Test files and internal, testdata,
+// and vendor directories are skipped, as are main packages and nested
+// modules.
+func exportedAPI(zr *zip.Reader, modulePath, version string) (map[string]string, error) {
+	prefix := modulePath + "@" + version + "/"
+
+	// Directories containing a go.mod file, other than the root, are other
+	// modules.
+	var nested []string
+	for _, f := range zr.File {
+		rel := strings.TrimPrefix(f.Name, prefix)
+		if path.Base(rel) == "go.mod" && path.Dir(rel) != "." {
+			nested = append(nested, path.Dir(rel)+"/")
+		}
+	}
+
+	files := slices.Clone(zr.File)
+	slices.SortFunc(files, func(a, b *zip.File) int { return strings.Compare(a.Name, b.Name) })
+
+	api := map[string]string{}
+	fset := token.NewFileSet()
+	for _, f := range files {
+		rel, ok := strings.CutPrefix(f.Name, prefix)
+		if !ok || !isAPISourceFile(rel, nested) || f.UncompressedSize64 > maxSourceFileSize {
+			continue
+		}
+
+		src, err := readZipFile(f)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parser.ParseFile(fset, rel, src, parser.SkipObjectResolution)
+		if err != nil {
+			// Files that do not parse would not build either. Skip them
+			// rather than failing the whole assessment.
+			continue
+		}
+		if file.Name.Name == "main" {
+			continue
+		}
+
+		importPath := modulePath
+		if dir := path.Dir(rel); dir != "." {
+			importPath += "/" + dir
+		}
+		addFileAPI(api, fset, importPath, file)
+	}
+	return api, nil
+}
+
+// isAPISourceFile reports whether the file at rel, relative to the module
+// root, may declare exported API.
+func isAPISourceFile(rel string, nested []string) bool {
+	if !strings.HasSuffix(rel, ".go") || strings.HasSuffix(rel, "_test.go") {
+		return false
+	}
+	for _, n := range nested {
+		if strings.HasPrefix(rel, n) {
+			return false
+		}
+	}
+	dir := path.Dir(rel)
+	if dir == "." {
+		return true
+	}
+	for elem := range strings.SplitSeq(dir, "/") {
+		if elem == "internal" || elem == "testdata" || elem == "vendor" ||
+			strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") {
+			return false
+		}
+	}
+	return true
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", f.Name, err)
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+	data, err := io.ReadAll(io.LimitReader(rc, maxSourceFileSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", f.Name, err)
+	}
+	return data, nil
+}
+
+// addFileAPI adds the exported declarations in file to api. If a name is
+// declared more than once (e.g. in files for different platforms), the first
+// declaration is kept.
+func addFileAPI(api map[string]string, fset *token.FileSet, importPath string, file *ast.File) {
+	add := func(name, decl string) {
+		if _, ok := api[name]; !ok {
+			api[name] = decl
+		}
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			if d.Recv == nil {
+				add(importPath+"."+d.Name.Name, nodeString(fset, d.Type))
+				continue
+			}
+			if recv := receiverName(d.Recv); ast.IsExported(recv) {
+				add(importPath+"."+recv+"."+d.Name.Name, nodeString(fset, d.Type))
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.IsExported() {
+						addTypeAPI(add, fset, importPath+"."+s.Name.Name, s)
+					}
+				case *ast.ValueSpec:
+					kind := d.Tok.String()
+					if s.Type != nil {
+						kind += " " + nodeString(fset, s.Type)
+					}
+					for _, name := range s.Names {
+						if name.IsExported() {
+							add(importPath+"."+name.Name, kind)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// addTypeAPI adds an exported type. Struct fields are added individually, so
+// adding a field is compatible but removing or changing one is not. Other
+// types, including interfaces (to which adding a method is incompatible),
+// are compared as a whole.
+func addTypeAPI(add func(name, decl string), fset *token.FileSet, name string, s *ast.TypeSpec) {
+	prefix := "type"
+	if s.Assign.IsValid() {
+		prefix = "type ="
+	}
+	if s.TypeParams != nil {
+		prefix += " " + nodeString(fset, s.TypeParams)
+	}
+
+	st, ok := s.Type.(*ast.StructType)
+	if !ok || s.Assign.IsValid() {
+		add(name, prefix+" "+nodeString(fset, s.Type))
+		return
+	}
+
+	add(name, prefix+" struct")
+	for _, field := range st.Fields.List {
+		fieldType := nodeString(fset, field.Type)
+		if len(field.Names) == 0 {
+			// An embedded field is named after its type.
+			embedded, _, _ := strings.Cut(strings.TrimPrefix(fieldType, "*"), "[")
+			if i := strings.LastIndex(embedded, "."); i >= 0 {
+				embedded = embedded[i+1:]
+			}
+			if ast.IsExported(embedded) {
+				add(name+"."+embedded, "embedded "+fieldType)
+			}
+			continue
+		}
+		for _, fieldName := range field.Names {
+			if fieldName.IsExported() {
+				add(name+"."+fieldName.Name, fieldType)
+			}
+		}
+	}
+}
+
+// receiverName returns the name of a method receiver's base type.
+func receiverName(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+	expr := recv.List[0].Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// nodeString prints node on one line, without comments, so that
+// declarations that differ only in formatting or comments compare equal.
+func nodeString(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, stripComments(node)); err != nil {
+		return ""
+	}
+
+	// Join multi-line types (structs and interfaces) as they would be
+	// written on one line.
+	var b strings.Builder
+	for line := range strings.Lines(buf.String()) {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			if strings.HasSuffix(b.String(), "{") || strings.HasPrefix(line, "}") {
+				b.WriteString(" ")
+			} else {
+				b.WriteString("; ")
+			}
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// stripComments removes field comments, which the printer would otherwise
+// include.
+func stripComments(node ast.Node) ast.Node {
+	ast.Inspect(node, func(n ast.Node) bool {
+		if f, ok := n.(*ast.Field); ok {
+			f.Doc = nil
+			f.Comment = nil
+		}
+		return true
+	})
+	return node
+}
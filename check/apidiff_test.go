@@ -0,0 +1,158 @@
+package check
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// moduleZip returns a module zip containing files, whose names are relative
+// to the module root.
+func moduleZip(t *testing.T, modulePath, version string, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(modulePath + "@" + version + "/" + name)
+		if err != nil {
+			t.Fatalf("creating zip entry: %v", err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("writing zip entry: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("closing zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompatibility(t *testing.T) {
+	const (
+		modulePath = "example.com/m"
+		oldVersion = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+		newVersion = "v0.0.0-20231201000000-bbbbbbbbbbbb"
+	)
+
+	oldFiles := map[string]string{
+		"go.mod": "module example.com/m\n",
+		"m.go": `package m
+
+// Func does things.
+func Func(a int) error { return nil }
+
+func Changed(a int) {}
+
+func unexported() {}
+
+type T struct {
+	Name string // The name.
+	Size int
+	hidden bool
+}
+
+func (t *T) Method() {}
+
+type I interface {
+	M()
+}
+
+const C = 1
+
+var V, W int
+`,
+		"m_test.go":          "package m\n\nfunc TestOnly() {}\n",
+		"internal/x/x.go":    "package x\n\nfunc Internal() {}\n",
+		"cmd/tool/main.go":   "package main\n\nfunc Main() {}\n",
+		"sub/go.mod":         "module example.com/m/sub\n",
+		"sub/sub.go":         "package sub\n\nfunc Nested() {}\n",
+		"pkg/pkg.go":         "package pkg\n\nfunc Removed() {}\n",
+		"testdata/broken.go": "not go",
+	}
+	newFiles := map[string]string{
+		"go.mod": "module example.com/m\n",
+		"m.go": `package m
+
+// Func does things, differently.
+func Func(a   int) error { return nil }
+
+func Changed(a int, b string) {}
+
+func Added() {}
+
+type T struct {
+	Name  string
+	Size  int64
+	Extra []byte
+}
+
+func (t *T) Method() {}
+
+type I interface {
+	M()
+	N()
+}
+
+const C = 1
+
+var V int
+`,
+		"pkg/pkg.go": "package pkg\n",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/m/@v/" + oldVersion + ".zip":
+			_, _ = w.Write(moduleZip(t, modulePath, oldVersion, oldFiles))
+		case "/example.com/m/@v/" + newVersion + ".zip":
+			_, _ = w.Write(moduleZip(t, modulePath, newVersion, newFiles))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := NewChecker(
+		WithLogger(discardLogger()),
+		WithCompatibility(NewProxyResolverWithClient(server.URL, server.Client(), nil)),
+	)
+
+	zips := c.newModuleZips(modulePath)
+	got, err := assessCompatibility(t.Context(), zips, oldVersion, newVersion)
+	if err != nil {
+		t.Fatalf("assessing compatibility: %v", err)
+	}
+
+	want := &Compatibility{
+		Incompatible: []string{
+			"example.com/m.Changed: changed from func(a int) to func(a int, b string)",
+			"example.com/m.I: changed from type interface { M() } to type interface { M(); N() }",
+			"example.com/m.T.Size: changed from int to int64",
+			"example.com/m.W: removed",
+			"example.com/m/pkg.Removed: removed",
+		},
+		Added: []string{
+			"example.com/m.Added",
+			"example.com/m.T.Extra",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+
+	missing := "v0.0.0-20240101000000-cccccccccccc"
+	if _, err := assessCompatibility(t.Context(), zips, oldVersion, missing); err == nil {
+		t.Error("expected error for missing version, got nil")
+	}
+}
+
+func TestCompareAPIsCompatible(t *testing.T) {
+	api := map[string]string{"example.com/m.F": "func()"}
+	got := compareAPIs(api, api)
+	if !got.Compatible || len(got.Incompatible) != 0 || len(got.Added) != 0 {
+		t.Errorf("got %#v, want compatible with no changes", got)
+	}
+}
@@ -0,0 +1,286 @@
+package check
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+)
+
+// BazelPin is a Go dependency pinned to a commit in a Bazel file: a
+// go_repository rule in WORKSPACE or a .bzl macro, or a go_deps.module tag
+// in MODULE.bazel.
+type BazelPin struct {
+	// Module is the module path (importpath or path).
+	Module string
+	// Version is the pinned pseudo-version, if the pin has one.
+	Version string
+	// Commit is the pinned commit hash, if go_repository pins a commit
+	// rather than a version.
+	Commit string
+	// Line is the line the rule starts on.
+	Line int
+}
+
+// FindBazelPins returns the Go dependencies in the Bazel file at path that
+// are pinned to pseudo-versions or commits. Dependencies at tagged versions
+// are skipped, as are rules whose attributes are not string literals.
+func FindBazelPins(path string) ([]BazelPin, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return parseBazelPins(data)
+}
+
+// CheckBazel checks the pins in the Bazel file at path the way CheckGoMod
+// checks a go.mod file's requirements. Commit pins are first resolved to
+// pseudo-versions with the resolver; a commit that resolves to a tagged
+// version is skipped, and one that cannot be resolved is a failure whose
+// dependency has the commit as its version.
+func (c *Checker) CheckBazel(ctx context.Context, path string) (Report, error) {
+	pins, err := FindBazelPins(path)
+	if err != nil {
+		return Report{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	resolved := c.resolvePins(ctx, len(pins), func(ctx context.Context, i int) (Dependency, error) {
+		pin := pins[i]
+		if pin.Commit == "" {
+			return Dependency{Module: pin.Module, Version: pin.Version}, nil
+		}
+		version, err := c.resolveRev(ctx, pin.Module, pin.Commit)
+		if err != nil {
+			return Dependency{Module: pin.Module, Version: pin.Commit}, err
+		}
+		return Dependency{Module: pin.Module, Version: version}, nil
+	})
+
+	rep := c.Check(ctx, resolved.deps)
+	resolved.addFailures(&rep)
+	return rep, nil
+}
+
+// resolvedPins are pins found outside go.mod, resolved to the modules and
+// versions to check.
+type resolvedPins struct {
+	// deps are the pins that resolved to pseudo-versions.
+	deps []Dependency
+	// failed are the pins that could not be resolved, and failures why.
+	failed   []Dependency
+	failures []Failure
+}
+
+// addFailures adds the pins that could not be resolved to rep.
+func (r resolvedPins) addFailures(rep *Report) {
+	rep.Dependencies = append(rep.Dependencies, r.failed...)
+	rep.Failures = append(rep.Failures, r.failures...)
+}
+
+// resolvePins resolves n pins concurrently (see WithConcurrency) with
+// resolve, which returns the dependency at index i. A pin that resolves to a
+// tagged version is dropped. If resolving fails, the returned dependency is
+// recorded as failed.
+func (c *Checker) resolvePins(
+	ctx context.Context,
+	n int,
+	resolve func(ctx context.Context, i int) (Dependency, error),
+) resolvedPins {
+	deps := make([]Dependency, n)
+	errs := make([]error, n)
+	sem := make(chan struct{}, max(c.concurrency, 1))
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			deps[i], errs[i] = resolve(ctx, i)
+		})
+	}
+	wg.Wait()
+
+	var r resolvedPins
+	for i, dep := range deps {
+		switch {
+		case errs[i] != nil:
+			r.failed = append(r.failed, dep)
+			r.failures = append(r.failures, Failure{Module: dep.Module, Err: errs[i]})
+		case module.IsPseudoVersion(dep.Version):
+			r.deps = append(r.deps, dep)
+		default:
+			c.log().Debug("pin is a tagged version", "module", dep.Module, "version", dep.Version)
+		}
+	}
+	return r
+}
+
+// resolveRev returns the version of the module at rev, a commit hash or
+// pseudo-version.
+func (c *Checker) resolveRev(ctx context.Context, modulePath, rev string) (string, error) {
+	release, err := c.limiter.acquire(ctx, modulePath)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	version, err := c.resolve(ctx, modulePath, rev)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", rev, err)
+	}
+	return version, nil
+}
+
+// parseBazelPins returns the pins in a Starlark file. See FindBazelPins.
+func parseBazelPins(data []byte) ([]BazelPin, error) {
+	toks, err := scanStarlark(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var pins []BazelPin
+	for i := 0; i+1 < len(toks); i++ {
+		t := toks[i]
+		if t.kind != tokIdent || toks[i+1].kind != '(' {
+			continue
+		}
+		var pathAttr string
+		switch {
+		case t.text == "go_repository":
+			pathAttr = "importpath"
+		case strings.HasSuffix(t.text, ".module"):
+			// The go_deps extension, whatever its variable is named.
+			pathAttr = "path"
+		default:
+			continue
+		}
+
+		attrs, end := callAttrs(toks, i+2)
+		i = end
+		pin := BazelPin{Module: attrs[pathAttr], Line: t.line}
+		if v := attrs["version"]; module.IsPseudoVersion(v) {
+			pin.Version = v
+		} else if pathAttr == "importpath" {
+			pin.Commit = attrs["commit"]
+		}
+		if pin.Module != "" && (pin.Version != "" || pin.Commit != "") {
+			pins = append(pins, pin)
+		}
+	}
+	return pins, nil
+}
+
+// callAttrs returns the keyword arguments with string literal values of the
+// call whose arguments start at toks[start], and the index of the call's
+// closing parenthesis.
+func callAttrs(toks []starlarkToken, start int) (map[string]string, int) {
+	attrs := map[string]string{}
+	depth := 0
+	for i := start; i < len(toks); i++ {
+		switch toks[i].kind {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				return attrs, i
+			}
+			depth--
+		case tokIdent:
+			if depth == 0 && i+2 < len(toks) && toks[i+1].kind == '=' &&
+				toks[i+2].kind == tokString {
+				attrs[toks[i].text] = toks[i+2].text
+				i += 2
+			}
+		}
+	}
+	return attrs, len(toks)
+}
+
+// Kinds of starlarkToken other than punctuation, which is its own kind.
+const (
+	tokIdent  = 'i'
+	tokString = 's'
+)
+
+// starlarkToken is a token of a Starlark file: an identifier, which may be
+// dotted (go_deps.module), a string literal's contents, or a punctuation
+// character.
+type starlarkToken struct {
+	kind byte
+	text string
+	line int
+}
+
+// scanStarlark splits a Starlark file into tokens, dropping comments. It is
+// only as thorough as finding rule attributes needs: escape sequences in
+// strings are kept as written, and numbers scan as identifiers.
+func scanStarlark(data []byte) ([]starlarkToken, error) {
+	var toks []starlarkToken
+	line := 1
+	for i := 0; i < len(data); {
+		ch := data[i]
+		switch {
+		case ch == '\n':
+			line++
+			i++
+		case ch == '#':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+		case ch == '"' || ch == '\'':
+			s, n, err := scanStarlarkString(data[i:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			toks = append(toks, starlarkToken{kind: tokString, text: s, line: line})
+			line += bytes.Count(data[i:i+n], []byte("\n"))
+			i += n
+		case isIdentByte(ch):
+			j := i
+			for j < len(data) && (isIdentByte(data[j]) || data[j] == '.') {
+				j++
+			}
+			toks = append(
+				toks,
+				starlarkToken{kind: tokIdent, text: string(data[i:j]), line: line},
+			)
+			i = j
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\\':
+			i++
+		default:
+			toks = append(toks, starlarkToken{kind: ch, line: line})
+			i++
+		}
+	}
+	return toks, nil
+}
+
+// scanStarlarkString returns the contents of the string literal that data
+// starts with, and the literal's length.
+func scanStarlarkString(data []byte) (string, int, error) {
+	quote := data[:1]
+	if len(data) >= 3 && data[1] == data[0] && data[2] == data[0] {
+		quote = data[:3]
+	}
+	for i := len(quote); i < len(data); i++ {
+		switch {
+		case data[i] == '\\':
+			i++
+		case data[i] == '\n' && len(quote) == 1:
+			return "", 0, errors.New("unterminated string")
+		case bytes.HasPrefix(data[i:], quote):
+			return string(data[len(quote):i]), i + len(quote), nil
+		}
+	}
+	return "", 0, errors.New("unterminated string")
+}
+
+func isIdentByte(ch byte) bool {
+	return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' ||
+		ch >= '0' && ch <= '9'
+}
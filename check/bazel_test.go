@@ -0,0 +1,135 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const bazelWorkspace = `load("@bazel_gazelle//:deps.bzl", "go_repository")
+
+def go_dependencies():
+    go_repository(
+        name = "org_go4_netipx",
+        importpath = "go4.org/netipx",
+        commit = "aaaaaaaaaaaa",  # pinned for the IPv6 fix
+    )
+    go_repository(
+        name = "com_example_pseudo",
+        importpath = "example.com/pseudo",
+        sum = "h1:xyz=",
+        version = "v0.0.0-20231101000000-bbbbbbbbbbbb",
+    )
+    go_repository(
+        name = "com_example_tagged",
+        importpath = "example.com/tagged",
+        version = "v1.2.0",
+    )
+    go_repository(
+        name = "com_example_release",
+        build_directives = ["gazelle:proto disable"],
+        importpath = "example.com/release",
+        commit = "cccccccccccc",
+    )
+    # go_repository(importpath = "example.com/commented", commit = "dddddddddddd")
+`
+
+const bazelModule = `bazel_dep(name = "gazelle", version = "0.35.0")
+
+go_deps = use_extension("@gazelle//:extensions.bzl", "go_deps")
+go_deps.module(
+    path = "example.com/bzlmod",
+    sum = "h1:xyz=",
+    version = "v0.0.0-20231101000000-eeeeeeeeeeee",
+)
+go_deps.module(path = "example.com/bzlmod-tagged", version = "v1.0.0")
+`
+
+func TestFindBazelPins(t *testing.T) {
+	dir := t.TempDir()
+	workspace := filepath.Join(dir, "deps.bzl")
+	if err := os.WriteFile(workspace, []byte(bazelWorkspace), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	moduleBazel := filepath.Join(dir, "MODULE.bazel")
+	if err := os.WriteFile(moduleBazel, []byte(bazelModule), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	pins, err := FindBazelPins(workspace)
+	if err != nil {
+		t.Fatalf("FindBazelPins: %v", err)
+	}
+	want := []BazelPin{
+		{Module: "go4.org/netipx", Commit: "aaaaaaaaaaaa", Line: 4},
+		{Module: "example.com/pseudo", Version: "v0.0.0-20231101000000-bbbbbbbbbbbb", Line: 9},
+		{Module: "example.com/release", Commit: "cccccccccccc", Line: 20},
+	}
+	if !reflect.DeepEqual(pins, want) {
+		t.Errorf("got %+v, want %+v", pins, want)
+	}
+
+	pins, err = FindBazelPins(moduleBazel)
+	if err != nil {
+		t.Fatalf("FindBazelPins: %v", err)
+	}
+	want = []BazelPin{
+		{Module: "example.com/bzlmod", Version: "v0.0.0-20231101000000-eeeeeeeeeeee", Line: 4},
+	}
+	if !reflect.DeepEqual(pins, want) {
+		t.Errorf("got %+v, want %+v", pins, want)
+	}
+
+	if _, err := parseBazelPins([]byte("go_repository(importpath = \"x\n")); err == nil {
+		t.Error("parsing an unterminated string succeeded")
+	}
+}
+
+func TestCheckBazel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deps.bzl")
+	if err := os.WriteFile(path, []byte(bazelWorkspace), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"go4.org/netipx@aaaaaaaaaaaa":      "v0.0.0-20230719000000-aaaaaaaaaaaa",
+			"go4.org/netipx@main":              "v0.0.0-20231201000000-ffffffffffff",
+			"example.com/pseudo@main":          "v0.0.0-20231101000000-bbbbbbbbbbbb",
+			"example.com/release@cccccccccccc": "v1.3.0",
+		}),
+		WithBranches(branchMain),
+	)
+
+	rep, err := c.CheckBazel(t.Context(), path)
+	if err != nil {
+		t.Fatalf("CheckBazel: %v", err)
+	}
+	wantDeps := []Dependency{
+		{Module: "go4.org/netipx", Version: "v0.0.0-20230719000000-aaaaaaaaaaaa"},
+		{Module: "example.com/pseudo", Version: "v0.0.0-20231101000000-bbbbbbbbbbbb"},
+	}
+	if !reflect.DeepEqual(rep.Dependencies, wantDeps) {
+		t.Errorf("got dependencies %+v, want %+v", rep.Dependencies, wantDeps)
+	}
+	if len(rep.Updates) != 1 || rep.Updates[0].Latest != "v0.0.0-20231201000000-ffffffffffff" {
+		t.Errorf("got updates %+v, want one for go4.org/netipx", rep.Updates)
+	}
+	if len(rep.Failures) != 0 {
+		t.Errorf("got failures %+v", rep.Failures)
+	}
+
+	// A commit that cannot be resolved is a failure.
+	c = NewChecker(WithResolver(fakeResolver{}), WithBranches(branchMain))
+	rep, err = c.CheckBazel(t.Context(), path)
+	if err != nil {
+		t.Fatalf("CheckBazel: %v", err)
+	}
+	if len(rep.Failures) != 3 {
+		t.Fatalf("got failures %+v, want 3", rep.Failures)
+	}
+	last := rep.Dependencies[len(rep.Dependencies)-1]
+	if want := (Dependency{Module: "example.com/release", Version: "cccccccccccc"}); last != want {
+		t.Errorf("got dependency %+v for the failed commit, want %+v", last, want)
+	}
+}
@@ -0,0 +1,132 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// WithModuleBranches sets the branch queried for particular modules, keyed by
+// module path, instead of the branches set by WithBranches, e.g. to follow a
+// long-lived release branch or a fork's development branch. A key may also be
+// a glob pattern, such as k8s.io/*, giving a whole family of modules a
+// branch: like a GOPRIVATE pattern, it matches module paths whose leading
+// elements match it, so k8s.io/* matches k8s.io/client-go and
+// k8s.io/client-go/v2. A module's own key takes precedence over patterns,
+// and the longest matching pattern over shorter ones. This takes precedence
+// over WithReleaseBranches. A module whose branch does not exist
+// fails with ErrBranchNotFound. Updates found on such a branch have it in
+// Update.Branch.
+func WithModuleBranches(branches map[string]string) Option {
+	return func(c *Checker) { c.moduleBranches = branches }
+}
+
+// WithReleaseBranches checks a dependency whose pseudo-version is based on a
+// release, such as v1.29.3-0.20240101000000-abcdefabcdef (based on v1.29.2),
+// against the head of its release line's branch rather than the default
+// branch, if the module's repository has one. The branches tried, in order,
+// are release-1.29, release-v1.29, release/1.29, and release/v1.29, and one
+// is only used if its head is still on the v1.29 line. Updates found on a
+// release branch have it in Update.Branch. Dependencies whose pseudo-versions
+// are not based on a release, or whose release line has no branch, are
+// checked on the branches set by WithBranches. By default release lines are
+// not detected.
+func WithReleaseBranches(enabled bool) Option {
+	return func(c *Checker) { c.releaseBranches = enabled }
+}
+
+// latestVersion returns the latest version of the dependency and the branch
+// it was found on, if that is particular to the dependency: a branch set by
+// WithModuleBranches or a release branch. Otherwise the branch is "".
+func (c *Checker) latestVersion(ctx context.Context, dep Dependency) (string, string, error) {
+	if branch, ok := c.moduleBranch(dep.Module); ok {
+		version, found, err := c.resolveBranch(ctx, dep.Module, branch)
+		if err != nil {
+			return "", "", err
+		}
+		if !found {
+			return "", "", classify(ErrBranchNotFound, fmt.Errorf("branch %s not found", branch))
+		}
+		return version, branch, nil
+	}
+
+	if c.releaseBranches {
+		version, branch, err := c.releaseBranchVersion(ctx, dep)
+		if err != nil || branch != "" {
+			return version, branch, err
+		}
+	}
+
+	version, err := c.getLatestVersion(ctx, dep.Module)
+	return version, "", err
+}
+
+// moduleBranch returns the branch set for the module by WithModuleBranches,
+// if there is one.
+func (c *Checker) moduleBranch(modulePath string) (string, bool) {
+	return matchModule(c.moduleBranches, modulePath)
+}
+
+// matchModule returns the value for the module in m, keyed by module path or
+// glob pattern (see WithModuleBranches), if there is one.
+func matchModule[V any](m map[string]V, modulePath string) (V, bool) {
+	if v, ok := m[modulePath]; ok {
+		return v, true
+	}
+	var (
+		pattern string
+		value   V
+	)
+	for p, v := range m {
+		if !strings.ContainsAny(p, "*?[") || !module.MatchPrefixPatterns(p, modulePath) {
+			continue
+		}
+		// Prefer the longest pattern, and of equally long ones, the first
+		// in lexical order, so the choice does not depend on map iteration.
+		if len(p) > len(pattern) || len(p) == len(pattern) && p < pattern {
+			pattern, value = p, v
+		}
+	}
+	return value, pattern != ""
+}
+
+// releaseBranchVersion returns the version at the head of the branch of the
+// release line the dependency's pseudo-version is based on, and the branch,
+// or "" for both if there is none.
+func (c *Checker) releaseBranchVersion(
+	ctx context.Context,
+	dep Dependency,
+) (string, string, error) {
+	base, err := module.PseudoVersionBase(dep.Version)
+	if err != nil || base == "" {
+		return "", "", nil
+	}
+	line := semver.MajorMinor(base)
+	for _, branch := range releaseBranchNames(line) {
+		version, found, err := c.resolveBranch(ctx, dep.Module, branch)
+		if err != nil {
+			return "", "", err
+		}
+		// A branch that has moved on to a later release line, or that
+		// merely shares the name, is not the release line's.
+		if found && semver.MajorMinor(version) == line {
+			return version, branch, nil
+		}
+	}
+	return "", "", nil
+}
+
+// releaseBranchNames returns the conventional names of the branch of a
+// release line such as v1.29.
+func releaseBranchNames(line string) []string {
+	number := strings.TrimPrefix(line, "v")
+	return []string{
+		"release-" + number,
+		"release-" + line,
+		"release/" + number,
+		"release/" + line,
+	}
+}
@@ -0,0 +1,128 @@
+package check
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithReleaseBranches(t *testing.T) {
+	const (
+		onRelease = "v1.29.3-0.20240101000000-aaaaaaaaaaaa"
+		onMain    = "v0.0.0-20240301000000-bbbbbbbbbbbb"
+	)
+	res := fakeResolver{
+		"k8s.io/client-go@main":           onMain,
+		"k8s.io/client-go@release-1.29":   "v1.29.5-0.20240201000000-cccccccccccc",
+		"example.com/slash@main":          onMain,
+		"example.com/slash@release/v1.29": "v1.29.4",
+		// The branch has moved on to a later release line.
+		"example.com/moved@main":         onMain,
+		"example.com/moved@release-1.29": "v1.30.1-0.20240201000000-dddddddddddd",
+		"example.com/none@main":          onMain,
+		"example.com/unreleased@main":    onMain,
+	}
+
+	tests := []struct {
+		module, version        string
+		wantLatest, wantBranch string
+	}{
+		{
+			module:     "k8s.io/client-go",
+			version:    onRelease,
+			wantLatest: "v1.29.5-0.20240201000000-cccccccccccc",
+			wantBranch: "release-1.29",
+		},
+		{
+			module:     "example.com/slash",
+			version:    onRelease,
+			wantLatest: "v1.29.4",
+			wantBranch: "release/v1.29",
+		},
+		{module: "example.com/moved", version: onRelease, wantLatest: onMain},
+		{module: "example.com/none", version: onRelease, wantLatest: onMain},
+		{
+			module:     "example.com/unreleased",
+			version:    "v0.0.0-20240101000000-aaaaaaaaaaaa",
+			wantLatest: onMain,
+		},
+	}
+
+	c := NewChecker(WithResolver(res), WithBranches(branchMain), WithReleaseBranches(true))
+	for _, tt := range tests {
+		latest, branch, err := c.latestVersion(
+			t.Context(),
+			Dependency{Module: tt.module, Version: tt.version},
+		)
+		if err != nil {
+			t.Errorf("%s: %v", tt.module, err)
+			continue
+		}
+		if latest != tt.wantLatest || branch != tt.wantBranch {
+			t.Errorf(
+				"%s: got %s on %q, want %s on %q",
+				tt.module, latest, branch, tt.wantLatest, tt.wantBranch,
+			)
+		}
+	}
+}
+
+func TestModuleBranch(t *testing.T) {
+	c := NewChecker(WithModuleBranches(map[string]string{
+		"k8s.io/*":          "master",
+		"k8s.io/client-go":  "release-1.29",
+		"k8s.io/api/*":      "develop",
+		"example.com/?":     "a",
+		"example.com/*":     "b",
+		"example.org/plain": "main",
+	}))
+
+	tests := []struct {
+		module, want string
+	}{
+		{module: "k8s.io/client-go", want: "release-1.29"},
+		{module: "k8s.io/apimachinery", want: "master"},
+		{module: "k8s.io/apimachinery/v2", want: "master"},
+		{module: "k8s.io/api/core", want: "develop"},
+		// Of equally long patterns, the lexically first wins.
+		{module: "example.com/x", want: "b"},
+		{module: "example.org/plain", want: "main"},
+		// A plain module path only matches that module.
+		{module: "example.org/plain/v2"},
+		{module: "sigs.k8s.io/yaml"},
+	}
+	for _, tt := range tests {
+		got, ok := c.moduleBranch(tt.module)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("moduleBranch(%q) = %q, %t, want %q", tt.module, got, ok, tt.want)
+		}
+	}
+}
+
+func TestWithModuleBranches(t *testing.T) {
+	const version = "v1.29.3-0.20240101000000-aaaaaaaaaaaa"
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"k8s.io/client-go@main":         "v0.0.0-20240301000000-bbbbbbbbbbbb",
+			"k8s.io/client-go@release-1.29": "v1.29.5-0.20240201000000-cccccccccccc",
+			"k8s.io/client-go@master":       "v0.0.0-20240201000000-dddddddddddd",
+		}),
+		WithBranches(branchMain),
+		WithReleaseBranches(true),
+		WithModuleBranches(map[string]string{
+			"k8s.io/client-go": "master",
+			"example.com/gone": "develop",
+		}),
+	)
+
+	rep := c.Check(t.Context(), []Dependency{
+		{Module: "k8s.io/client-go", Version: version},
+		{Module: "example.com/gone", Version: version},
+	})
+	if len(rep.Updates) != 1 || rep.Updates[0].Latest != "v0.0.0-20240201000000-dddddddddddd" ||
+		rep.Updates[0].Branch != "master" {
+		t.Errorf("got updates %+v, want one on master", rep.Updates)
+	}
+	if len(rep.Failures) != 1 || !errors.Is(rep.Failures[0].Err, ErrBranchNotFound) {
+		t.Errorf("got failures %+v, want one for a missing branch", rep.Failures)
+	}
+}
@@ -0,0 +1,158 @@
+package check
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheDirName is the name of the directory under os.UserCacheDir where
+// resolved versions are cached.
+const cacheDirName = "check-untagged-go-deps"
+
+// Cache is an on-disk cache of module@branch -> version resolutions.
+// A nil *Cache is valid and caches nothing.
+type Cache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+
+	// modCacheDir is the go command's module cache. If set, resolutions the go
+	// command recorded there are used when we have no entry of our own.
+	modCacheDir string
+}
+
+// cacheEntry is the on-disk representation of a cached resolution.
+type cacheEntry struct {
+	Module   string    `json:"module"`
+	Branch   string    `json:"branch"`
+	Version  string    `json:"version,omitempty"`
+	NotFound bool      `json:"not_found,omitempty"`
+	Resolved time.Time `json:"resolved"`
+}
+
+// NewCache returns a cache storing entries in dir that are valid for ttl. If
+// dir is empty, a directory under os.UserCacheDir is used. If ttl is not
+// positive, caching is disabled and nil is returned.
+func NewCache(dir string, ttl time.Duration) (*Cache, error) {
+	if ttl <= 0 {
+		return nil, nil //nolint:nilnil // a nil cache is valid and disables caching
+	}
+
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("finding user cache directory: %w", err)
+		}
+		dir = filepath.Join(userCacheDir, cacheDirName)
+	}
+
+	return &Cache{
+		dir: dir,
+		ttl: ttl,
+		now: time.Now,
+	}, nil
+}
+
+// get returns the cached entry for module@branch, falling back to the go
+// command's module cache. ok is false if there is no entry or it has expired.
+func (c *Cache) get(modulePath, branch string) (cacheEntry, bool) {
+	if c == nil {
+		return cacheEntry{}, false
+	}
+
+	if entry, ok := c.getOwn(modulePath, branch); ok {
+		return entry, true
+	}
+
+	version, ok := lookupModCache(c.modCacheDir, modulePath, branch, c.ttl, c.now())
+	if !ok {
+		return cacheEntry{}, false
+	}
+	return cacheEntry{Module: modulePath, Branch: branch, Version: version}, true
+}
+
+func (c *Cache) getOwn(modulePath, branch string) (cacheEntry, bool) {
+	data, err := os.ReadFile(c.path(modulePath, branch))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return cacheEntry{}, false
+	}
+
+	// Guard against hash collisions and hand-edited files.
+	if entry.Module != modulePath || entry.Branch != branch {
+		return cacheEntry{}, false
+	}
+
+	if c.now().Sub(entry.Resolved) > c.ttl {
+		return cacheEntry{}, false
+	}
+
+	return entry, true
+}
+
+// put stores an entry for module@branch. Failing to write to the cache is not
+// fatal to a run, so callers may ignore the error.
+func (c *Cache) put(entry cacheEntry) error {
+	if c == nil {
+		return nil
+	}
+
+	entry.Resolved = c.now()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding cache entry: %w", err)
+	}
+
+	if err := os.MkdirAll(c.dir, 0o750); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	// Write to a temporary file and rename so concurrent runs never observe a
+	// partially written entry.
+	tmp, err := os.CreateTemp(c.dir, "entry-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating cache file: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writing cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing cache file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), c.path(entry.Module, entry.Branch)); err != nil {
+		return fmt.Errorf("renaming cache file: %w", err)
+	}
+
+	return nil
+}
+
+// SetModCacheDir sets the go command's module cache directory (see
+// FindModCacheDir). On a miss, resolutions the go command recorded there are
+// used if they are within the cache's TTL.
+func (c *Cache) SetModCacheDir(dir string) {
+	if c == nil {
+		return
+	}
+	c.modCacheDir = dir
+}
+
+func (c *Cache) path(modulePath, branch string) string {
+	sum := sha256.Sum256([]byte(modulePath + "@" + branch))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,86 @@
+package check
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResultCache(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cache, err := NewCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewCache: %v", err)
+	}
+	cache.now = func() time.Time { return now }
+
+	if _, ok := cache.get("go4.org/netipx", branchMain); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+
+	entry := cacheEntry{
+		Module:  "go4.org/netipx",
+		Branch:  branchMain,
+		Version: "v0.0.0-20231129151722-fdeea329fbba",
+	}
+	if err := cache.put(entry); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	got, ok := cache.get("go4.org/netipx", branchMain)
+	if !ok {
+		t.Fatal("expected hit after put")
+	}
+	if got.Version != entry.Version {
+		t.Errorf("got version %q, want %q", got.Version, entry.Version)
+	}
+
+	if _, ok := cache.get("go4.org/netipx", branchMaster); ok {
+		t.Error("expected miss for a different branch")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, ok := cache.get("go4.org/netipx", branchMain); ok {
+		t.Error("expected miss after TTL expired")
+	}
+}
+
+func TestResultCacheNotFound(t *testing.T) {
+	cache, err := NewCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewCache: %v", err)
+	}
+
+	if err := cache.put(cacheEntry{
+		Module:   "go4.org/netipx",
+		Branch:   branchMaster,
+		NotFound: true,
+	}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	got, ok := cache.get("go4.org/netipx", branchMaster)
+	if !ok {
+		t.Fatal("expected hit after put")
+	}
+	if !got.NotFound {
+		t.Error("expected NotFound to be preserved")
+	}
+}
+
+func TestResultCacheDisabled(t *testing.T) {
+	cache, err := NewCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("NewCache: %v", err)
+	}
+	if cache != nil {
+		t.Fatal("expected nil cache when TTL is zero")
+	}
+
+	if err := cache.put(cacheEntry{Module: "go4.org/netipx", Branch: branchMain}); err != nil {
+		t.Errorf("put on nil cache: %v", err)
+	}
+	if _, ok := cache.get("go4.org/netipx", branchMain); ok {
+		t.Error("expected miss on nil cache")
+	}
+}
@@ -0,0 +1,1116 @@
+// Package check finds pseudo-versioned (commit-pinned) Go dependencies and
+// checks whether newer commits are available on their default branch.
+//
+// It is the library behind the check-untagged-go-deps command and can be
+// embedded by other tools that want the results as data rather than text.
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Checker checks pseudo-versioned dependencies for updates. Create one with
+// NewChecker. The zero value is also usable and checks dependencies one at a
+// time on the main and master branches using go list, with no caching or rate
+// limiting.
+type Checker struct {
+	resolver          Resolver
+	logger            *slog.Logger
+	cache             *Cache
+	limiter           *HostLimiter
+	concurrency       int
+	branches          []string
+	includeIndirect   bool
+	moduleTimeout     time.Duration
+	timings           bool
+	comparer          Comparer
+	maxCommits        int
+	minCommitsBehind  int
+	changeSummary     bool
+	authors           bool
+	classifyRisk      bool
+	repoFinder        *RepoFinder
+	moduleSource      ModuleSource
+	compatibility     bool
+	licenseCheck      bool
+	releaseNotes      ReleaseNotesSource
+	vulnSource        VulnSource
+	sumDBVerifier     *SumDBVerifier
+	activitySource    ActivitySource
+	staleAfter        time.Duration
+	goModSource       GoModSource
+	retractionSource  GoModSource
+	retractionLister  TagLister
+	moveDetector      MoveDetector
+	signatureVerifier SignatureVerifier
+	requireSigned     string
+	commitFinder      CommitFinder
+	tagLister         TagLister
+	vendorCheck       bool
+	toolPins          bool
+	taggedLister      TagLister
+	goReleases        GoReleaseSource
+	dirHistory        DirHistory
+	trackDirs         bool
+	moduleTracking    map[string]Tracking
+	moduleBranches    map[string]string
+	releaseBranches   bool
+	forkComparer      Comparer
+	eventHandler      EventHandler
+	importChains      bool
+	testOnlyCheck     bool
+	skipTestOnly      bool
+	requirers         bool
+	warningAge        time.Duration
+	criticalAge       time.Duration
+	goRunner          CommandRunner
+
+	eventMu sync.Mutex
+}
+
+// Option configures a Checker.
+type Option func(*Checker)
+
+// NewChecker returns a Checker configured with the given options.
+func NewChecker(opts ...Option) *Checker {
+	c := &Checker{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithResolver sets how branch queries are resolved. The default is a
+// GoListResolver.
+func WithResolver(r Resolver) Option {
+	return func(c *Checker) { c.resolver = r }
+}
+
+// WithLogger sets the logger for diagnostic logs. By default nothing is
+// logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Checker) { c.logger = logger }
+}
+
+// WithCache caches resolutions in cache, which may be nil to disable caching
+// (the default).
+func WithCache(cache *Cache) Option {
+	return func(c *Checker) { c.cache = cache }
+}
+
+// WithHostLimiter limits queries per host. By default queries are not
+// limited.
+func WithHostLimiter(limiter *HostLimiter) Option {
+	return func(c *Checker) { c.limiter = limiter }
+}
+
+// WithConcurrency sets how many dependencies are checked at once. Values less
+// than 1 mean 1, the default.
+func WithConcurrency(n int) Option {
+	return func(c *Checker) { c.concurrency = n }
+}
+
+// WithBranches sets the branches queried for the latest commit. If more than
+// one exists, the one with the most recent commit is used. The default is
+// DefaultBranches. For a module at major version 2 or higher, such as
+// example.com/mod/v3, the major branch v3 is also queried if none of these
+// has that major version (see DefaultBranches).
+func WithBranches(branches ...string) Option {
+	return func(c *Checker) { c.branches = branches }
+}
+
+// WithIncludeIndirect includes dependencies marked // indirect in go.mod.
+// They are excluded by default.
+func WithIncludeIndirect(include bool) Option {
+	return func(c *Checker) { c.includeIndirect = include }
+}
+
+// WithPerModuleTimeout limits how long checking a single dependency may take,
+// across all of its branch queries. The limit starts once the dependency's
+// turn comes (see WithConcurrency), not when checking starts. A dependency
+// that runs out of time fails with an error matching ErrTimeout. The default
+// is no limit beyond the context's.
+func WithPerModuleTimeout(d time.Duration) Option {
+	return func(c *Checker) { c.moduleTimeout = d }
+}
+
+// Dependency is a pseudo-versioned requirement found in go.mod.
+type Dependency struct {
+	// Module is the module path.
+	Module string `json:"module"`
+	// Version is the pseudo-version required in go.mod.
+	Version string `json:"version"`
+	// Tags is whether the module has tagged versions. It is empty unless
+	// WithTagCheck was given and the lookup succeeded.
+	Tags TagStatus `json:"tags,omitempty"`
+	// LatestTag is the module's latest tagged version, if Tags is
+	// TagStatusTagged.
+	LatestTag string `json:"latest_tag,omitempty"`
+	// BaseTag is the tag Version is based on, such as v1.1.0 for
+	// v1.1.1-0.20231101000000-aaaaaaaaaaaa. It is empty unless WithTagCheck
+	// was given and Version is based on a tag.
+	BaseTag string `json:"base_tag,omitempty"`
+	// Source is where the dependency is pinned, such as "Dockerfile:12", if
+	// it was found outside go.mod (see WithToolPins).
+	Source string `json:"source,omitempty"`
+	// Replaces is the module path required in go.mod if a replace directive
+	// replaces it with Module, typically a fork pinned to a commit.
+	Replaces string `json:"replaces,omitempty"`
+	// Fork is how far Module has diverged from the module it replaces. It
+	// is nil unless WithForkDivergence was given and the comparison
+	// succeeded.
+	Fork *ForkStatus `json:"fork,omitempty"`
+	// Indirect is whether the requirement is marked // indirect in go.mod.
+	Indirect bool `json:"-"`
+	// Span is where the dependency is pinned in go.mod: its require
+	// directive, or its replace directive if it is a fork, which ends with
+	// Version. It is nil if the dependency was not read from go.mod, such as
+	// a tool pin.
+	Span *Span `json:"-"`
+}
+
+// Span is where a directive is in a go.mod file.
+type Span struct {
+	Start, End modfile.Position
+}
+
+// Update is an available update for a dependency.
+type Update struct {
+	// Module is the module path.
+	Module string `json:"module"`
+	// Current is the pseudo-version required in go.mod.
+	Current string `json:"current"`
+	// Latest is the pseudo-version of the newest commit on the checked
+	// branches.
+	Latest string `json:"latest"`
+	// CurrentTime is the commit time encoded in Current.
+	CurrentTime time.Time `json:"current_time,omitzero"`
+	// LatestTime is the commit time encoded in Latest.
+	LatestTime time.Time `json:"latest_time,omitzero"`
+	// CommitsBehind is how many commits Current is behind Latest. It is 0
+	// if unknown (see WithComparer).
+	CommitsBehind int `json:"commits_behind,omitzero"`
+	// Commits are the newest commits between Current and Latest, newest
+	// first (see WithMaxCommits).
+	Commits []Commit `json:"commits,omitempty"`
+	// Risk labels how disruptive the update is likely to be (see
+	// WithRiskClassification).
+	Risk Risk `json:"risk,omitempty"`
+	// Authors are the distinct authors of the commits between Current and
+	// Latest, sorted (see WithAuthors).
+	Authors []string `json:"authors,omitempty"`
+	// Changes summarizes the files changed between Current and Latest (see
+	// WithChangeSummary).
+	Changes *ChangeSummary `json:"changes,omitempty"`
+	// CompareURL is a web page comparing Current and Latest, if one is known
+	// (see WithRepoFinder).
+	CompareURL string `json:"compare_url,omitempty"`
+	// Compatibility assesses whether Latest changes the exported API
+	// incompatibly (see WithCompatibility).
+	Compatibility *Compatibility `json:"compatibility,omitempty"`
+	// ReleaseNotes is Markdown describing the changes between Current and
+	// Latest, such as the new sections of the module's changelog (see
+	// WithReleaseNotes).
+	ReleaseNotes string `json:"release_notes,omitempty"`
+	// Vulnerabilities are the known vulnerabilities affecting Current,
+	// sorted by ID (see WithVulnerabilities).
+	Vulnerabilities []Vulnerability `json:"vulnerabilities,omitempty"`
+	// SumDB is whether Latest was verified against the checksum database
+	// (see WithSumDBVerifier).
+	SumDB SumDBStatus `json:"sumdb,omitempty"`
+	// LicenseChange describes how the module's license changed between
+	// Current and Latest, if it did (see WithLicenseCheck).
+	LicenseChange *LicenseChange `json:"license_change,omitempty"`
+	// DeclaredPath is the module path declared by Latest's go.mod file, if
+	// it differs from Module: the module has been renamed, and importers
+	// should switch to the new path rather than update (see
+	// WithPathChangeCheck).
+	DeclaredPath string `json:"declared_path,omitempty"`
+	// MovedTo is the module path at the new location of the module's
+	// repository, if it was renamed or transferred (see
+	// WithPathChangeCheck).
+	MovedTo string `json:"moved_to,omitempty"`
+	// Signature is the signature status of Latest's commit (see
+	// WithSignatureCheck).
+	Signature *Signature `json:"signature,omitempty"`
+	// PinVanished is set if Current's commit no longer exists upstream,
+	// because the repository's history was rewritten: Current should be
+	// re-pinned to Latest (see WithPinCheck).
+	PinVanished bool `json:"pin_vanished,omitempty"`
+	// Severity labels how urgently the update needs attention, from the
+	// information about it that was asked for, such as its age (see
+	// WithAgeSeverity), Vulnerabilities, and Risk.
+	Severity Severity `json:"severity,omitempty"`
+	// ImportChain is the shortest chain of package imports from the main
+	// module to the dependency, starting with a main module package (see
+	// WithImportChains).
+	ImportChain []string `json:"import_chain,omitempty"`
+	// TestOnly is set if only the main module's tests need the dependency
+	// (see WithTestOnlyCheck).
+	TestOnly bool `json:"test_only,omitempty"`
+	// RequiredBy are the other modules in the build list whose go.mod files
+	// require the dependency, sorted by module path (see WithRequirers).
+	RequiredBy []Requirer `json:"required_by,omitempty"`
+	// Source is where the dependency is pinned, if outside go.mod (see
+	// Dependency.Source).
+	Source string `json:"source,omitempty"`
+	// Replaces is the module path Module replaces, if it is a fork (see
+	// Dependency.Replaces).
+	Replaces string `json:"replaces,omitempty"`
+	// Branch is the branch Latest is on if it is particular to the
+	// dependency: a branch set for the module (see WithModuleBranches) or
+	// its release line's branch (see WithReleaseBranches). It is empty if
+	// Latest is on one of the branches queried for every dependency.
+	Branch string `json:"branch,omitempty"`
+	// Indirect and Span are the dependency's (see Dependency.Indirect and
+	// Dependency.Span).
+	Indirect bool  `json:"-"`
+	Span     *Span `json:"-"`
+}
+
+// Age returns how much older the current commit is than the latest one, or 0
+// if either time is unknown.
+func (u Update) Age() time.Duration {
+	if u.CurrentTime.IsZero() || u.LatestTime.IsZero() {
+		return 0
+	}
+	return u.LatestTime.Sub(u.CurrentTime)
+}
+
+// Failure records a dependency that could not be checked. In JSON, Err is
+// represented by its message ("error") and its ErrorCode ("code").
+type Failure struct {
+	Module string `json:"module"`
+	Err    error  `json:"-"`
+}
+
+// Report is the result of checking a set of dependencies.
+//
+// The JSON encoding of a Report is described by Schema. Fields are only ever
+// added to it; existing fields are not renamed, removed, or changed in
+// meaning. In JSON, empty lists are encoded as [] rather than null.
+type Report struct {
+	// Dependencies are the dependencies that were checked, in go.mod order.
+	Dependencies []Dependency `json:"dependencies"`
+	// Updates are the dependencies with newer versions available.
+	Updates []Update `json:"updates"`
+	// Failures are the dependencies that could not be checked, including
+	// those whose pinned commits vanished (see WithPinCheck), or whose
+	// updates were refused (see WithSignatureCheck).
+	Failures []Failure `json:"failures"`
+	// Abandoned are the dependencies whose repositories are archived or
+	// inactive, in go.mod order (see WithAbandonedCheck). Unlike the other
+	// lists, it is omitted from JSON if it is empty.
+	Abandoned []Abandoned `json:"abandoned,omitempty"`
+	// VendorMismatches are the dependencies whose vendored copies are not
+	// the versions go.mod requires, in go.mod order (see WithVendorCheck).
+	// It is omitted from JSON if it is empty.
+	VendorMismatches []VendorMismatch `json:"vendor_mismatches,omitempty"`
+	// TaggedUpdates are the newer releases of requirements at tagged
+	// versions, in go.mod order (see WithTaggedUpdates). It is omitted from
+	// JSON if it is empty.
+	TaggedUpdates []TaggedUpdate `json:"tagged_updates,omitempty"`
+	// Toolchain is how go.mod's Go version compares with the latest Go
+	// release, if it was checked (see WithToolchainCheck). It is omitted from
+	// JSON if it is nil.
+	Toolchain *ToolchainStatus `json:"toolchain,omitempty"`
+	// Summary is the report's freshness statistics, if they were asked for
+	// (see Summarize). It is omitted from JSON if it is nil.
+	Summary *Summary `json:"summary,omitempty"`
+	// Timings are how long each dependency took to check, in go.mod order,
+	// if they were asked for (see WithTimings). It is omitted from JSON if
+	// it is empty.
+	Timings []Timing `json:"timings,omitempty"`
+}
+
+// UnknownModuleError is returned when a module requested for checking is not
+// a pseudo-versioned requirement in go.mod.
+type UnknownModuleError struct {
+	Module string
+}
+
+func (e *UnknownModuleError) Error() string {
+	return e.Module + " is not a pseudo-versioned requirement in go.mod"
+}
+
+// CheckGoMod finds pseudo-versioned dependencies in the given go.mod file and
+// checks if updates are available for them. A dependency that cannot be
+// checked is recorded as a failure rather than aborting the run.
+//
+// If modules are given, just those are checked. Each must be a
+// pseudo-versioned requirement in go.mod, otherwise an *UnknownModuleError is
+// returned. Naming an indirect dependency checks it even if indirect
+// dependencies are not otherwise included.
+func (c *Checker) CheckGoMod(
+	ctx context.Context,
+	gomodPath string,
+	modules ...string,
+) (Report, error) {
+	deps, err := c.findDeps(gomodPath, modules)
+	if err != nil {
+		return Report{}, err
+	}
+
+	var tools resolvedPins
+	if c.toolPins && len(modules) == 0 {
+		tools, err = c.findToolDeps(ctx, filepath.Dir(gomodPath))
+		if err != nil {
+			return Report{}, err
+		}
+		deps = append(deps, tools.deps...)
+	}
+
+	checkTagged := c.taggedLister != nil && len(modules) == 0
+	checkToolchain := c.goReleases != nil && len(modules) == 0
+	if len(deps) == 0 && len(tools.failed) == 0 && !checkTagged && !checkToolchain {
+		return Report{}, nil
+	}
+
+	rep := c.Check(ctx, deps)
+	tools.addFailures(&rep)
+	if checkTagged {
+		if err := c.checkTaggedUpdates(ctx, gomodPath, &rep); err != nil {
+			return Report{}, err
+		}
+	}
+	c.explainUpdates(ctx, gomodPath, &rep)
+	if c.vendorCheck {
+		c.checkVendor(gomodPath, &rep)
+	}
+	if checkToolchain {
+		c.checkToolchain(ctx, gomodPath, &rep)
+	}
+	return rep, nil
+}
+
+// findDeps returns the dependencies in go.mod to check. See CheckGoMod.
+func (c *Checker) findDeps(gomodPath string, modules []string) ([]Dependency, error) {
+	deps, err := FindPseudoVersionedDeps(gomodPath, c.includeIndirect || len(modules) > 0)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", gomodPath, err)
+	}
+
+	if len(modules) > 0 {
+		return filterDeps(deps, modules)
+	}
+	return deps, nil
+}
+
+// filterDeps returns the dependencies for the given modules, in go.mod order.
+// A fork is selected by its own path or the path of the module it replaces.
+func filterDeps(deps []Dependency, modules []string) ([]Dependency, error) {
+	wanted := map[string]bool{}
+	for _, m := range modules {
+		wanted[m] = true
+	}
+
+	var filtered []Dependency
+	for _, dep := range deps {
+		if wanted[dep.Module] || dep.Replaces != "" && wanted[dep.Replaces] {
+			filtered = append(filtered, dep)
+			delete(wanted, dep.Module)
+			delete(wanted, dep.Replaces)
+		}
+	}
+
+	for _, m := range modules {
+		if wanted[m] {
+			return nil, &UnknownModuleError{Module: m}
+		}
+	}
+
+	return filtered, nil
+}
+
+// FindPseudoVersionedDeps returns the requirements in the go.mod file at
+// gomodPath that use pseudo-versions. A requirement that a replace directive
+// replaces with another module, such as a fork, is checked as the
+// replacement instead, with Dependency.Replaces set, if the replacement is
+// at a pseudo-version, and skipped otherwise, since its required version is
+// not built. Requirements replaced by directories are unaffected.
+func FindPseudoVersionedDeps(gomodPath string, includeIndirect bool) ([]Dependency, error) {
+	f, err := parseGoMod(gomodPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var deps []Dependency
+	for _, s := range selectRequirements(f, includeIndirect, false) {
+		if s.dep != nil {
+			deps = append(deps, *s.dep)
+		}
+	}
+
+	return deps, nil
+}
+
+// parseGoMod reads and parses the go.mod file at gomodPath.
+func parseGoMod(gomodPath string) (*modfile.File, error) {
+	data, err := os.ReadFile(filepath.Clean(gomodPath))
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+
+	f, err := modfile.Parse(gomodPath, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parsing go.mod: %w", err)
+	}
+	return f, nil
+}
+
+// Check checks deps concurrently (see WithConcurrency). The updates,
+// failures, and abandoned dependencies in the report are in the same order
+// as deps.
+func (c *Checker) Check(ctx context.Context, deps []Dependency) Report {
+	results := make([]Result, len(deps))
+	for res := range c.Stream(ctx, deps) {
+		results[res.index] = res
+	}
+
+	rep := Report{Dependencies: slices.Clone(deps)}
+	for i, res := range results {
+		rep.Dependencies[i] = res.Dependency
+		if c.timings {
+			rep.Timings = append(rep.Timings, res.Timing)
+		}
+		if res.Err != nil {
+			rep.Failures = append(
+				rep.Failures,
+				Failure{Module: res.Dependency.Module, Err: res.Err},
+			)
+			continue
+		}
+		if res.HasUpdate() {
+			rep.Updates = append(rep.Updates, c.newUpdate(res))
+		}
+		if res.Abandoned != nil {
+			rep.Abandoned = append(rep.Abandoned, *res.Abandoned)
+		}
+	}
+
+	return rep
+}
+
+// newUpdate returns the update described by res, with the commit times parsed
+// from both pseudo-versions and the parts of its comparison that were asked
+// for.
+func (c *Checker) newUpdate(res Result) Update {
+	u := Update{
+		Module:   res.Dependency.Module,
+		Current:  res.Dependency.Version,
+		Latest:   res.Latest,
+		Source:   res.Dependency.Source,
+		Replaces: res.Dependency.Replaces,
+		Branch:   res.Branch,
+		Indirect: res.Dependency.Indirect,
+		Span:     res.Dependency.Span,
+	}
+	// The versions are pseudo-versions, so errors are not expected. If one
+	// occurs, the time is left unknown.
+	u.CurrentTime, _ = module.PseudoVersionTime(u.Current)
+	u.LatestTime, _ = module.PseudoVersionTime(u.Latest)
+	if cmp := res.Comparison; cmp != nil {
+		u.CommitsBehind = cmp.Ahead
+		if c.maxCommits > 0 && len(cmp.Commits) > 0 {
+			u.Commits = cmp.Commits[:min(c.maxCommits, len(cmp.Commits))]
+		}
+		if c.authors {
+			u.Authors = commitAuthors(cmp.Commits)
+		}
+		if c.classifyRisk {
+			u.Risk = classifyRisk(cmp.Commits)
+		}
+		if c.changeSummary && cmp.Files != nil {
+			u.Changes = summarizeChanges(cmp.Files)
+		}
+	}
+	u.CompareURL = res.CompareURL
+	u.Compatibility = res.Compatibility
+	u.ReleaseNotes = res.ReleaseNotes
+	u.Vulnerabilities = res.Vulnerabilities
+	u.SumDB = res.SumDB
+	u.LicenseChange = res.LicenseChange
+	u.DeclaredPath = res.DeclaredPath
+	u.MovedTo = res.MovedTo
+	u.Signature = res.Signature
+	u.PinVanished = res.PinVanished
+	u.Severity = c.assessSeverity(u)
+	return u
+}
+
+// Result is the outcome of checking one dependency.
+type Result struct {
+	// Dependency is the dependency that was checked, with its tag status
+	// if WithTagCheck was given.
+	Dependency Dependency
+	// Latest is the newest version on the checked branches, or the current
+	// version if WithMinCommitsBehind dropped the update. It is empty if Err
+	// is set.
+	Latest string
+	// Branch is the branch Latest is on if it is particular to the
+	// dependency (see Update.Branch).
+	Branch string
+	// Err is why the dependency could not be checked, if it could not be,
+	// including because its pinned commit vanished (see WithPinCheck), or
+	// why its update was refused (see WithSignatureCheck).
+	Err error
+	// Comparison compares the current version with Latest. It is nil unless
+	// there is an update and a Comparer (see WithComparer) could compare
+	// them.
+	Comparison *Comparison
+	// CompareURL is a web page comparing the current version with Latest. It
+	// is empty unless there is an update and a RepoFinder (see
+	// WithRepoFinder) found the repository on a known host.
+	CompareURL string
+	// Compatibility compares the exported APIs of the current version and
+	// Latest. It is nil unless there is an update and WithCompatibility was
+	// given and the assessment succeeded.
+	Compatibility *Compatibility
+	// ReleaseNotes describes the changes between the current version and
+	// Latest. It is empty unless there is an update and a
+	// ReleaseNotesSource (see WithReleaseNotes) found notes.
+	ReleaseNotes string
+	// Vulnerabilities are the known vulnerabilities affecting the current
+	// version. They are only looked up if there is an update and a
+	// VulnSource was given (see WithVulnerabilities).
+	Vulnerabilities []Vulnerability
+	// SumDB is whether Latest was verified against the checksum database.
+	// It is empty unless there is an update and a SumDBVerifier was given
+	// (see WithSumDBVerifier).
+	SumDB SumDBStatus
+	// LicenseChange describes how the module's license changed, if it did.
+	// It is nil unless there is an update and WithLicenseCheck was given.
+	LicenseChange *LicenseChange
+	// DeclaredPath is the module path declared by Latest's go.mod file if
+	// it differs from the dependency's. It is empty unless there is an
+	// update and a GoModSource was given (see WithPathChangeCheck).
+	DeclaredPath string
+	// MovedTo is the module path at the new location of the module's
+	// repository, if it moved. It is empty unless there is an update and a
+	// MoveDetector was given (see WithPathChangeCheck).
+	MovedTo string
+	// Signature is the signature status of Latest's commit. It is nil
+	// unless there is an update and a SignatureVerifier (see
+	// WithSignatureCheck) could check it.
+	Signature *Signature
+	// PinVanished is set if the dependency's pinned commit no longer exists
+	// upstream although the repository does. It is false unless there is an
+	// update and a CommitFinder was given (see WithPinCheck).
+	PinVanished bool
+	// Abandoned describes how the module's repository is abandoned, if it
+	// is. It is nil unless WithAbandonedCheck was given. Unlike the other
+	// fields, it is set whether or not there is an update.
+	Abandoned *Abandoned
+	// Timing is how long checking the dependency took. It is always set.
+	Timing Timing
+
+	// index is the position of Dependency in the slice given to Stream.
+	index int
+}
+
+// HasUpdate reports whether a newer version than the one in go.mod is
+// available. A pseudo-version of the commit go.mod already requires is not
+// one, even if it is based on a different tag, as happens when a tag is
+// pushed after the commit was required.
+func (r Result) HasUpdate() bool {
+	return r.Err == nil && r.Latest != r.Dependency.Version &&
+		!sameCommit(r.Latest, r.Dependency.Version)
+}
+
+// StreamGoMod is like CheckGoMod, but sends each dependency's result as soon
+// as it is known (see Stream). Errors reading go.mod or finding the requested
+// modules are returned before any checking starts.
+func (c *Checker) StreamGoMod(
+	ctx context.Context,
+	gomodPath string,
+	modules ...string,
+) (<-chan Result, error) {
+	deps, err := c.findDeps(gomodPath, modules)
+	if err != nil {
+		return nil, err
+	}
+	return c.Stream(ctx, deps), nil
+}
+
+// Stream checks deps concurrently (see WithConcurrency) and sends each result
+// on the returned channel as soon as it is known, so results arrive in
+// completion order rather than in the order of deps. The channel is closed
+// once every dependency has a result. If ctx is canceled, dependencies not yet
+// checked have results with ctx's error.
+//
+// The channel is buffered to hold every result, so callers may stop receiving
+// early without leaking goroutines.
+func (c *Checker) Stream(ctx context.Context, deps []Dependency) <-chan Result {
+	results := make(chan Result, len(deps))
+	sem := make(chan struct{}, max(c.concurrency, 1))
+
+	var wg sync.WaitGroup
+	for i, dep := range deps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- c.checkDependency(ctx, sem, i, dep)
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// checkDependency resolves the latest version of dep once a slot in sem is
+// free.
+func (c *Checker) checkDependency(
+	ctx context.Context,
+	sem chan struct{},
+	index int,
+	dep Dependency,
+) (res Result) {
+	res = Result{Dependency: dep, index: index, Timing: Timing{Module: dep.Module}}
+
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		res.Err = ctx.Err()
+		return res
+	}
+	defer func() { <-sem }()
+
+	c.emit(Event{Kind: EventModuleStarted, Module: dep.Module})
+	start := time.Now()
+
+	moduleCtx, t := withTiming(ctx)
+	defer func() {
+		duration := time.Since(start)
+		res.Timing.DurationMs = duration.Milliseconds()
+		res.Timing.ResolveMs = t.resolve.Milliseconds()
+		res.Timing.Queries = t.queries
+		res.Timing.CacheHits = t.cacheHits
+		c.log().Info(
+			"checked",
+			"module", dep.Module,
+			"duration", duration,
+			"resolve_duration", t.resolve,
+			"queries", t.queries,
+			"cache_hits", t.cacheHits,
+		)
+	}()
+	if c.moduleTimeout > 0 {
+		var cancel context.CancelFunc
+		moduleCtx, cancel = context.WithTimeout(moduleCtx, c.moduleTimeout)
+		defer cancel()
+	}
+
+	res.Latest, res.Branch, res.Err = c.latestVersion(moduleCtx, dep)
+	if res.Err != nil && ctx.Err() == nil && moduleCtx.Err() != nil {
+		res.Err = classify(
+			ErrTimeout,
+			fmt.Errorf("timed out after %s: %w", c.moduleTimeout, res.Err),
+		)
+	}
+
+	if res.HasUpdate() && c.tracksDir(dep.Module) {
+		c.resolveDirChange(moduleCtx, &res)
+	}
+
+	if c.retractionSource != nil && res.HasUpdate() {
+		c.checkRetraction(moduleCtx, &res)
+	}
+
+	if c.commitFinder != nil {
+		c.checkPin(moduleCtx, &res)
+	}
+
+	// An update refused by the signature policy is a failure.
+	if c.signatureVerifier != nil && res.HasUpdate() {
+		res.Signature, res.Err = c.checkSignature(moduleCtx, dep.Module, res.Latest)
+		if res.Err != nil {
+			res.Latest = ""
+		}
+	}
+
+	if res.Err != nil {
+		c.emit(Event{
+			Kind:     EventModuleFailed,
+			Module:   dep.Module,
+			Duration: time.Since(start),
+			Err:      res.Err,
+		})
+		return res
+	}
+	if c.activitySource != nil {
+		abandoned, err := c.checkAbandoned(moduleCtx, dep.Module)
+		switch {
+		case err == nil:
+			res.Abandoned = abandoned
+		case errors.Is(err, ErrUnsupportedHost):
+			c.log().Debug("cannot check repository activity", "module", dep.Module, "error", err)
+		default:
+			c.log().Warn("checking repository activity failed", "module", dep.Module, "error", err)
+		}
+	}
+
+	if c.tagLister != nil {
+		if err := c.checkTags(moduleCtx, &res.Dependency); err != nil {
+			c.log().Warn("listing tagged versions failed", "module", dep.Module, "error", err)
+		}
+	}
+
+	if c.forkComparer != nil && dep.Replaces != "" {
+		err := c.checkFork(moduleCtx, &res)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrUnsupportedHost):
+			c.log().Debug("cannot compare fork with upstream", "module", dep.Module, "error", err)
+		default:
+			c.log().Warn("comparing fork with upstream failed", "module", dep.Module, "error", err)
+		}
+	}
+
+	if c.comparer != nil && res.HasUpdate() {
+		cmp, err := c.compare(moduleCtx, dep.Module, dep.Version, res.Latest)
+		switch {
+		case err == nil && cmp.Ahead < c.minCommitsBehind:
+			c.log().Debug(
+				"skipping update with too few new commits",
+				"module", dep.Module,
+				"commits", cmp.Ahead,
+			)
+			res.Latest = dep.Version
+		case err == nil:
+			res.Comparison = &cmp
+		case errors.Is(err, ErrUnsupportedHost):
+			c.log().Debug("cannot compare versions", "module", dep.Module, "error", err)
+		default:
+			c.log().Warn("comparing versions failed", "module", dep.Module, "error", err)
+		}
+	}
+
+	if c.goModSource != nil && res.HasUpdate() {
+		path, err := c.declaredPath(moduleCtx, dep.Module, res.Latest)
+		if err != nil {
+			c.log().Warn("reading declared module path failed", "module", dep.Module, "error", err)
+		} else {
+			res.DeclaredPath = path
+		}
+	}
+
+	if c.moveDetector != nil && res.HasUpdate() {
+		path, err := c.movedPath(moduleCtx, dep.Module)
+		switch {
+		case err == nil:
+			res.MovedTo = path
+		case errors.Is(err, ErrUnsupportedHost):
+			c.log().Debug(
+				"cannot check whether repository moved",
+				"module", dep.Module,
+				"error", err,
+			)
+		default:
+			c.log().Warn(
+				"checking whether repository moved failed",
+				"module", dep.Module,
+				"error", err,
+			)
+		}
+	}
+
+	if c.repoFinder != nil && res.HasUpdate() {
+		res.CompareURL = c.compareURL(moduleCtx, dep.Module, dep.Version, res.Latest)
+	}
+
+	if c.releaseNotes != nil && res.HasUpdate() {
+		notes, err := c.findReleaseNotes(moduleCtx, dep.Module, dep.Version, res.Latest)
+		switch {
+		case err == nil:
+			res.ReleaseNotes = notes
+		case errors.Is(err, ErrUnsupportedHost):
+			c.log().Debug("cannot find release notes", "module", dep.Module, "error", err)
+		default:
+			c.log().Warn("finding release notes failed", "module", dep.Module, "error", err)
+		}
+	}
+
+	if c.sumDBVerifier != nil && res.HasUpdate() {
+		status, err := c.sumDBVerifier.Verify(moduleCtx, dep.Module, res.Latest)
+		if err != nil {
+			c.log().Warn(
+				"verifying version against checksum database failed",
+				"module", dep.Module,
+				"version", res.Latest,
+				"error", err,
+			)
+		}
+		res.SumDB = status
+	}
+
+	if c.vulnSource != nil && res.HasUpdate() {
+		vulns, err := c.findVulnerabilities(moduleCtx, dep.Module, dep.Version, res.Latest)
+		if err != nil {
+			c.log().Warn("looking up vulnerabilities failed", "module", dep.Module, "error", err)
+		} else {
+			res.Vulnerabilities = vulns
+		}
+	}
+
+	if c.moduleSource != nil && res.HasUpdate() {
+		zips := c.newModuleZips(dep.Module)
+		if c.compatibility {
+			compat, err := assessCompatibility(moduleCtx, zips, dep.Version, res.Latest)
+			if err != nil {
+				c.log().Warn(
+					"assessing API compatibility failed",
+					"module", dep.Module,
+					"error", err,
+				)
+			} else {
+				res.Compatibility = compat
+			}
+		}
+		if c.licenseCheck {
+			change, err := compareLicenses(moduleCtx, zips, dep.Version, res.Latest)
+			if err != nil {
+				c.log().Warn("comparing licenses failed", "module", dep.Module, "error", err)
+			} else {
+				res.LicenseChange = change
+			}
+		}
+	}
+
+	c.emit(Event{
+		Kind:     EventModuleResolved,
+		Module:   dep.Module,
+		Version:  res.Latest,
+		Duration: time.Since(start),
+	})
+	return res
+}
+
+const (
+	branchMain   = "main"
+	branchMaster = "master"
+)
+
+// DefaultBranches are the branches queried if WithBranches is not used.
+//
+// A branch whose go.mod file declares another major version of the module
+// path than the dependency's, e.g. because main has moved on to v4 while the
+// dependency requires example.com/mod/v3, does not count as found. If no
+// branch is found for a module at major version 2 or higher, its major
+// branch, such as v3, is queried, as that is where the go command's "major
+// branch" convention maintains older major versions.
+var DefaultBranches = []string{branchMain, branchMaster}
+
+// getLatestVersion queries the Go module proxy for the latest version on the
+// default branch. It queries each configured branch (@main and @master by
+// default) and returns the version with the most recent timestamp (in case
+// more than one exists). If none is found, it falls back to the module's
+// major branch, if it has one.
+func (c *Checker) getLatestVersion(ctx context.Context, modulePath string) (string, error) {
+	branches := c.branches
+	if len(branches) == 0 {
+		branches = DefaultBranches
+	}
+
+	latest, err := c.latestOnBranches(ctx, modulePath, branches)
+	if err != nil {
+		return "", err
+	}
+	if major := majorBranch(modulePath); latest == "" && major != "" &&
+		!slices.Contains(branches, major) {
+		latest, err = c.latestOnBranches(ctx, modulePath, []string{major})
+		if err != nil {
+			return "", err
+		}
+		branches = append(slices.Clip(branches), major)
+	}
+
+	if latest == "" {
+		return "", classify(
+			ErrBranchNotFound,
+			fmt.Errorf("none of the branches %s found", strings.Join(branches, ", ")),
+		)
+	}
+
+	return latest, nil
+}
+
+// latestOnBranches returns the version with the most recent timestamp at the
+// heads of the branches that exist, or "" if none does.
+func (c *Checker) latestOnBranches(
+	ctx context.Context,
+	modulePath string,
+	branches []string,
+) (string, error) {
+	var latest string
+	for _, branch := range branches {
+		version, found, err := c.resolveBranch(ctx, modulePath, branch)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			continue
+		}
+		if latest == "" {
+			latest = version
+			continue
+		}
+		// If we have more than one, keep the one with the newer timestamp
+		latest, err = newerVersion(latest, version)
+		if err != nil {
+			return "", err
+		}
+	}
+	return latest, nil
+}
+
+// majorBranch returns the conventional branch of a module's major version,
+// such as v3 for example.com/mod/v3, or "" if its path has no major version
+// suffix. gopkg.in paths, whose versions are selected by the gopkg.in
+// service, have none.
+func majorBranch(modulePath string) string {
+	_, pathMajor, ok := module.SplitPathVersion(modulePath)
+	if !ok || !strings.HasPrefix(pathMajor, "/") {
+		return ""
+	}
+	return pathMajor[1:]
+}
+
+// resolveBranch returns the version at the head of the given branch,
+// consulting the cache before querying. found is false if the branch does not
+// exist.
+func (c *Checker) resolveBranch(
+	ctx context.Context,
+	modulePath,
+	branch string,
+) (string, bool, error) {
+	logger := c.log().With("module", modulePath, "branch", branch)
+
+	if entry, ok := c.cache.get(modulePath, branch); ok {
+		timingFrom(ctx).recordCacheHit()
+		logger.Debug(
+			"cache hit",
+			"version", entry.Version,
+			"not_found", entry.NotFound,
+		)
+		c.emit(Event{
+			Kind:     EventCacheHit,
+			Module:   modulePath,
+			Branch:   branch,
+			Version:  entry.Version,
+			NotFound: entry.NotFound,
+		})
+		return entry.Version, !entry.NotFound, nil
+	}
+
+	release, err := c.limiter.acquire(ctx, modulePath)
+	if err != nil {
+		return "", false, err
+	}
+	start := time.Now()
+	version, err := c.resolve(ctx, modulePath, branch)
+	duration := time.Since(start)
+	release()
+	timingFrom(ctx).recordQuery(duration)
+	if err != nil {
+		if !errors.Is(err, ErrBranchNotFound) {
+			logger.Info("resolution failed", "duration", duration, "error", err)
+			return "", false, err
+		}
+		logger.Info("branch not found", "duration", duration)
+		c.emit(Event{
+			Kind:     EventBranchResolved,
+			Module:   modulePath,
+			Branch:   branch,
+			NotFound: true,
+			Duration: duration,
+		})
+		_ = c.cache.put(cacheEntry{Module: modulePath, Branch: branch, NotFound: true})
+		return "", false, nil
+	}
+
+	logger.Info("resolved", "version", version, "duration", duration)
+	c.emit(Event{
+		Kind:     EventBranchResolved,
+		Module:   modulePath,
+		Branch:   branch,
+		Version:  version,
+		Duration: duration,
+	})
+	_ = c.cache.put(cacheEntry{Module: modulePath, Branch: branch, Version: version})
+	return version, true, nil
+}
+
+func (c *Checker) resolve(ctx context.Context, modulePath, branch string) (string, error) {
+	if c.resolver == nil {
+		return GoListResolver{Logger: c.log()}.Resolve(ctx, modulePath, branch)
+	}
+	return c.resolver.Resolve(ctx, modulePath, branch)
+}
+
+func (c *Checker) log() *slog.Logger {
+	if c.logger == nil {
+		return discardLogger()
+	}
+	return c.logger
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+// newerVersion compares two versions and returns the newer one. Two
+// pseudo-versions, whatever their base (v0.0.0-, a release, a prerelease such
+// as v1.2.4-pre.0., or +incompatible), are compared by commit time. A tagged
+// version, which a branch head resolves to if it is tagged, is compared by
+// semantic version precedence, under which a pseudo-version sorts just after
+// the version it is based on.
+func newerVersion(a, b string) (string, error) {
+	for _, v := range []string{a, b} {
+		if !semver.IsValid(v) {
+			return "", fmt.Errorf("parsing version %q: not a semantic version", v)
+		}
+	}
+	if !module.IsPseudoVersion(a) || !module.IsPseudoVersion(b) {
+		if semver.Compare(a, b) >= 0 {
+			return a, nil
+		}
+		return b, nil
+	}
+
+	tsA, err := module.PseudoVersionTime(a)
+	if err != nil {
+		return "", fmt.Errorf("parsing version %q: %w", a, err)
+	}
+	tsB, err := module.PseudoVersionTime(b)
+	if err != nil {
+		return "", fmt.Errorf("parsing version %q: %w", b, err)
+	}
+	if !tsA.Before(tsB) {
+		return a, nil
+	}
+	return b, nil
+}
+
+// sameCommit reports whether a and b are pseudo-versions of the same commit.
+func sameCommit(a, b string) bool {
+	revA, errA := module.PseudoVersionRev(a)
+	revB, errB := module.PseudoVersionRev(b)
+	return errA == nil && errB == nil && revA == revB
+}
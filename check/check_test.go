@@ -0,0 +1,973 @@
+package check
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+func TestCheckGoMod(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	// Create a go.mod with intentionally old pseudo-versions.
+	// These are real old commits that should have newer versions available.
+	content := `module test
+
+go 1.25
+
+require (
+	github.com/maxmind/mmdbwriter v1.1.1-0.20240104181157-4f07c5502982
+	go4.org/netipx v0.0.0-20220925034521-797b0c90d8ab
+)
+`
+	dir := t.TempDir()
+	gomodPath := filepath.Join(dir, "go.mod")
+	if err := os.WriteFile(gomodPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("writing go.mod: %v", err)
+	}
+
+	ctx := t.Context()
+	rep, err := NewChecker().CheckGoMod(ctx, gomodPath)
+	if err != nil {
+		t.Fatalf("checkGoMod: %v", err)
+	}
+
+	// Should find 2 pseudo-versioned deps
+	if len(rep.Dependencies) != 2 {
+		t.Errorf("got %d deps, want 2", len(rep.Dependencies))
+	}
+
+	// Should find updates for both (since we used old versions)
+	if len(rep.Updates) != 2 {
+		t.Errorf("got %d updates, want 2", len(rep.Updates))
+	}
+
+	for _, f := range rep.Failures {
+		t.Errorf("unexpected failure for %s: %v", f.Module, f.Err)
+	}
+
+	// Verify updates are for the expected modules
+	foundModules := map[string]bool{}
+	for _, u := range rep.Updates {
+		foundModules[u.Module] = true
+
+		// The latest version should be newer (different) than current
+		if u.Current == u.Latest {
+			t.Errorf("expected update for %s, but current == latest (%s)", u.Module, u.Current)
+		}
+
+		// Latest should also be a pseudo-version
+		if !module.IsPseudoVersion(u.Latest) {
+			t.Errorf("expected latest to be pseudo-version, got %q", u.Latest)
+		}
+	}
+
+	if !foundModules["github.com/maxmind/mmdbwriter"] {
+		t.Error("expected update for github.com/maxmind/mmdbwriter")
+	}
+	if !foundModules["go4.org/netipx"] {
+		t.Error("expected update for go4.org/netipx")
+	}
+}
+
+// fakeResolver resolves queries from a map keyed by module@branch. Missing
+// keys resolve to an ErrBranchNotFound error.
+type fakeResolver map[string]string
+
+func (r fakeResolver) Resolve(_ context.Context, modulePath, branch string) (string, error) {
+	key := modulePath + "@" + branch
+	if v, ok := r[key]; ok {
+		if v == "error" {
+			return "", errors.New("server error")
+		}
+		return v, nil
+	}
+	return "", fmt.Errorf("%s@%s: %w", modulePath, branch, ErrBranchNotFound)
+}
+
+func TestCheck(t *testing.T) {
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"example.com/current@main": "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			"example.com/stale@master": "v0.0.0-20231201000000-bbbbbbbbbbbb",
+			"example.com/broken@main":  "error",
+			"example.com/both@main":    "v0.0.0-20231201000000-cccccccccccc",
+			"example.com/both@master":  "v0.0.0-20231101000000-dddddddddddd",
+			"example.com/nobranch@dev": "v0.0.0-20231101000000-eeeeeeeeeeee",
+		}),
+		WithConcurrency(2),
+	)
+
+	deps := []Dependency{
+		{Module: "example.com/current", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "example.com/stale", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "example.com/broken", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "example.com/both", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "example.com/nobranch", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+	}
+
+	rep := c.Check(t.Context(), deps)
+
+	nov1 := time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)
+	dec1 := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+	wantUpdates := []Update{
+		{
+			Module:      "example.com/stale",
+			Current:     "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			Latest:      "v0.0.0-20231201000000-bbbbbbbbbbbb",
+			CurrentTime: nov1,
+			LatestTime:  dec1,
+			Severity:    SeverityLow,
+		},
+		{
+			Module:      "example.com/both",
+			Current:     "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			Latest:      "v0.0.0-20231201000000-cccccccccccc",
+			CurrentTime: nov1,
+			LatestTime:  dec1,
+			Severity:    SeverityLow,
+		},
+	}
+	if !reflect.DeepEqual(rep.Updates, wantUpdates) {
+		t.Errorf("got updates %+v, want %+v", rep.Updates, wantUpdates)
+	}
+	if age := rep.Updates[0].Age(); age != 30*24*time.Hour {
+		t.Errorf("got age %s, want 720h", age)
+	}
+
+	var failed []string
+	for _, f := range rep.Failures {
+		failed = append(failed, f.Module)
+	}
+	wantFailed := []string{"example.com/broken", "example.com/nobranch"}
+	if !slices.Equal(failed, wantFailed) {
+		t.Errorf("got failures for %v, want %v", failed, wantFailed)
+	}
+}
+
+// blockingResolver wraps a fakeResolver, blocking queries for module (or for
+// every module, if module is empty) until release is closed.
+type blockingResolver struct {
+	fakeResolver
+	module  string
+	release chan struct{}
+}
+
+func (r blockingResolver) Resolve(ctx context.Context, modulePath, branch string) (string, error) {
+	if r.module == "" || modulePath == r.module {
+		select {
+		case <-r.release:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	return r.fakeResolver.Resolve(ctx, modulePath, branch)
+}
+
+func TestStream(t *testing.T) {
+	res := blockingResolver{
+		fakeResolver: fakeResolver{
+			"example.com/slow@main": "v0.0.0-20231201000000-bbbbbbbbbbbb",
+			"example.com/fast@main": "v0.0.0-20231101000000-aaaaaaaaaaaa",
+		},
+		module:  "example.com/slow",
+		release: make(chan struct{}),
+	}
+	c := NewChecker(WithResolver(res), WithConcurrency(2), WithBranches(branchMain))
+
+	deps := []Dependency{
+		{Module: "example.com/slow", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "example.com/fast", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+	}
+
+	results := c.Stream(t.Context(), deps)
+
+	// The fast dependency's result arrives while the slow one is still
+	// being checked.
+	first := <-results
+	if first.Dependency.Module != "example.com/fast" {
+		t.Fatalf("got first result for %s, want example.com/fast", first.Dependency.Module)
+	}
+	if first.Err != nil || first.HasUpdate() {
+		t.Errorf("got first result %+v, want no update", first)
+	}
+
+	close(res.release)
+
+	second := <-results
+	if second.Dependency.Module != "example.com/slow" {
+		t.Fatalf("got second result for %s, want example.com/slow", second.Dependency.Module)
+	}
+	if !second.HasUpdate() || second.Latest != "v0.0.0-20231201000000-bbbbbbbbbbbb" {
+		t.Errorf("got second result %+v, want update", second)
+	}
+
+	if _, ok := <-results; ok {
+		t.Error("expected results channel to be closed")
+	}
+}
+
+func TestWithPerModuleTimeout(t *testing.T) {
+	res := blockingResolver{
+		fakeResolver: fakeResolver{
+			"example.com/fast@main": "v0.0.0-20231101000000-aaaaaaaaaaaa",
+		},
+		module:  "example.com/slow",
+		release: make(chan struct{}),
+	}
+	c := NewChecker(
+		WithResolver(res),
+		WithConcurrency(2),
+		WithBranches(branchMain),
+		WithPerModuleTimeout(50*time.Millisecond),
+	)
+
+	deps := []Dependency{
+		{Module: "example.com/slow", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "example.com/fast", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+	}
+
+	start := time.Now()
+	rep := c.Check(t.Context(), deps)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("checking took %s, want it to stop soon after the timeout", elapsed)
+	}
+
+	if len(rep.Failures) != 1 {
+		t.Fatalf("got failures %v, want one for example.com/slow", rep.Failures)
+	}
+	if f := rep.Failures[0]; f.Module != "example.com/slow" || !errors.Is(f.Err, ErrTimeout) {
+		t.Errorf("got failure %s: %v, want example.com/slow timing out", f.Module, f.Err)
+	}
+}
+
+func TestStreamCanceled(t *testing.T) {
+	res := blockingResolver{release: make(chan struct{})}
+	c := NewChecker(WithResolver(res), WithBranches(branchMain))
+
+	// With a concurrency of 1, one dependency is being checked and the other
+	// is waiting for its turn when ctx is canceled.
+	deps := []Dependency{
+		{Module: "example.com/a", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "example.com/b", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	results := c.Stream(ctx, deps)
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	timeout := time.After(5 * time.Second)
+	for range deps {
+		select {
+		case r := <-results:
+			if !errors.Is(r.Err, context.Canceled) {
+				t.Errorf("got %s: %v, want context.Canceled", r.Dependency.Module, r.Err)
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for results after cancellation")
+		}
+	}
+}
+
+func TestExecRunnerCanceled(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	// The background sleep keeps the shell's output open after the shell is
+	// killed, like git run by the go command.
+	start := time.Now()
+	_, err := ExecRunner{}.Run(ctx, "sh", "-c", "sleep 30 & sleep 30")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if elapsed := time.Since(start); elapsed > commandWaitDelay+5*time.Second {
+		t.Errorf("Run took %s after cancellation", elapsed)
+	}
+}
+
+// fakeComparer compares revisions from a map keyed by base...head.
+type fakeComparer map[string]Comparison
+
+func (f fakeComparer) Compare(
+	_ context.Context,
+	modulePath,
+	base,
+	head string,
+) (Comparison, error) {
+	cmp, ok := f[base+"..."+head]
+	if !ok {
+		return Comparison{}, fmt.Errorf("%s: %w", modulePath, ErrUnsupportedHost)
+	}
+	return cmp, nil
+}
+
+func TestWithComparer(t *testing.T) {
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"example.com/a@main": "v0.0.0-20231201000000-bbbbbbbbbbbb",
+			"example.com/b@main": "v0.0.0-20231201000000-dddddddddddd",
+		}),
+		WithBranches(branchMain),
+		WithComparer(fakeComparer{
+			"aaaaaaaaaaaa...bbbbbbbbbbbb": {
+				Ahead: 37,
+				Commits: []Commit{
+					{SHA: "3333", Subject: "Third", Author: "bob"},
+					{SHA: "2222", Subject: "Second", Author: "alice"},
+					{SHA: "1111", Subject: "First", Author: "renovate[bot]"},
+				},
+				Files: []ChangedFile{{Path: "go.mod", Additions: 1, Deletions: 1}},
+			},
+		}),
+		WithMaxCommits(2),
+		WithChangeSummary(true),
+		WithAuthors(true),
+	)
+
+	rep := c.Check(t.Context(), []Dependency{
+		{Module: "example.com/a", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "example.com/b", Version: "v0.0.0-20231101000000-cccccccccccc"},
+	})
+
+	if len(rep.Failures) != 0 {
+		t.Fatalf("got failures %v, want none", rep.Failures)
+	}
+	if len(rep.Updates) != 2 {
+		t.Fatalf("got updates %v, want 2", rep.Updates)
+	}
+	if got := rep.Updates[0].CommitsBehind; got != 37 {
+		t.Errorf("got %d commits behind, want 37", got)
+	}
+	wantCommits := []Commit{
+		{SHA: "3333", Subject: "Third", Author: "bob"},
+		{SHA: "2222", Subject: "Second", Author: "alice"},
+	}
+	if got := rep.Updates[0].Commits; !slices.Equal(got, wantCommits) {
+		t.Errorf("got commits %v, want %v", got, wantCommits)
+	}
+	// Authors cover all commits, not just those included.
+	wantAuthors := []string{"alice", "bob", "renovate[bot]"}
+	if got := rep.Updates[0].Authors; !slices.Equal(got, wantAuthors) {
+		t.Errorf("got authors %q, want %q", got, wantAuthors)
+	}
+	if got := rep.Updates[0].Changes; got == nil || got.Files != 1 || !got.GoModChanged {
+		t.Errorf("got changes %+v, want one file changing go.mod", got)
+	}
+	// A module the comparer cannot handle is still reported.
+	if got := rep.Updates[1].CommitsBehind; got != 0 {
+		t.Errorf("got %d commits behind, want 0 (unknown)", got)
+	}
+}
+
+func TestWithMinCommitsBehind(t *testing.T) {
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"example.com/few@main":     "v0.0.0-20231201000000-bbbbbbbbbbbb",
+			"example.com/many@main":    "v0.0.0-20231201000000-dddddddddddd",
+			"example.com/unknown@main": "v0.0.0-20231201000000-ffffffffffff",
+		}),
+		WithBranches(branchMain),
+		WithComparer(fakeComparer{
+			"aaaaaaaaaaaa...bbbbbbbbbbbb": {Ahead: 2},
+			"cccccccccccc...dddddddddddd": {Ahead: 3},
+		}),
+		WithMinCommitsBehind(3),
+	)
+
+	rep := c.Check(t.Context(), []Dependency{
+		{Module: "example.com/few", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "example.com/many", Version: "v0.0.0-20231101000000-cccccccccccc"},
+		{Module: "example.com/unknown", Version: "v0.0.0-20231101000000-eeeeeeeeeeee"},
+	})
+
+	var got []string
+	for _, u := range rep.Updates {
+		got = append(got, u.Module)
+	}
+	// Updates that could not be compared are kept.
+	if want := []string{"example.com/many", "example.com/unknown"}; !slices.Equal(got, want) {
+		t.Errorf("got updates of %q, want %q", got, want)
+	}
+	if len(rep.Failures) != 0 {
+		t.Errorf("got failures %v, want none", rep.Failures)
+	}
+}
+
+func TestWithRepoFinder(t *testing.T) {
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"github.com/owner/repo@main": "v0.0.0-20231201000000-bbbbbbbbbbbb",
+		}),
+		WithBranches(branchMain),
+		WithRepoFinder(NewRepoFinder(nil)),
+	)
+
+	rep := c.Check(t.Context(), []Dependency{
+		{Module: "github.com/owner/repo", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+	})
+
+	if len(rep.Updates) != 1 {
+		t.Fatalf("got updates %v, want 1", rep.Updates)
+	}
+	want := "https://github.com/owner/repo/compare/aaaaaaaaaaaa...bbbbbbbbbbbb"
+	if got := rep.Updates[0].CompareURL; got != want {
+		t.Errorf("got compare URL %q, want %q", got, want)
+	}
+}
+
+func TestStreamGoModUnknownModule(t *testing.T) {
+	gomod := filepath.Join(t.TempDir(), "go.mod")
+	content := "module example.com/test\n\ngo 1.21\n\n" +
+		"require go4.org/netipx v0.0.0-20231129151722-fdeea329fbba\n"
+	if err := os.WriteFile(gomod, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewChecker(WithResolver(fakeResolver{}))
+	_, err := c.StreamGoMod(t.Context(), gomod, "example.com/nope")
+	var unknownErr *UnknownModuleError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("got error %v, want *UnknownModuleError", err)
+	}
+}
+
+func TestFindPseudoVersionedDeps(t *testing.T) {
+	gomodContent := `module test
+
+go 1.25
+
+require (
+	github.com/maxmind/mmdbwriter v1.1.1-0.20251215205057-2f3252140e00
+	github.com/oschwald/maxminddb-golang/v2 v2.1.1
+	go4.org/netipx v0.0.0-20231129151722-fdeea329fbba
+)
+
+require (
+	github.com/example/indirect v0.0.0-20231129151722-abcdef123456 // indirect
+)
+`
+
+	tests := []struct {
+		name            string
+		includeIndirect bool
+		want            map[string]string
+	}{
+		{
+			name:            "exclude indirect",
+			includeIndirect: false,
+			want: map[string]string{
+				"github.com/maxmind/mmdbwriter": "v1.1.1-0.20251215205057-2f3252140e00",
+				"go4.org/netipx":                "v0.0.0-20231129151722-fdeea329fbba",
+			},
+		},
+		{
+			name:            "include indirect",
+			includeIndirect: true,
+			want: map[string]string{
+				"github.com/maxmind/mmdbwriter": "v1.1.1-0.20251215205057-2f3252140e00",
+				"go4.org/netipx":                "v0.0.0-20231129151722-fdeea329fbba",
+				"github.com/example/indirect":   "v0.0.0-20231129151722-abcdef123456",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			gomodPath := filepath.Join(dir, "go.mod")
+			if err := os.WriteFile(gomodPath, []byte(gomodContent), 0o644); err != nil {
+				t.Fatalf("writing go.mod: %v", err)
+			}
+
+			deps, err := FindPseudoVersionedDeps(gomodPath, tt.includeIndirect)
+			if err != nil {
+				t.Fatalf("FindPseudoVersionedDeps: %v", err)
+			}
+
+			if len(deps) != len(tt.want) {
+				t.Errorf("got %d deps, want %d", len(deps), len(tt.want))
+			}
+
+			for _, dep := range deps {
+				expectedVersion, ok := tt.want[dep.Module]
+				if !ok {
+					t.Errorf("unexpected module: %s", dep.Module)
+					continue
+				}
+				if dep.Version != expectedVersion {
+					t.Errorf(
+						"module %s: got version %s, want %s",
+						dep.Module,
+						dep.Version,
+						expectedVersion,
+					)
+				}
+			}
+		})
+	}
+}
+
+func TestWithBranches(t *testing.T) {
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"example.com/a@main":    "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			"example.com/a@develop": "v0.0.0-20231201000000-bbbbbbbbbbbb",
+			"example.com/a@next":    "v0.0.0-20231115000000-cccccccccccc",
+		}),
+		WithBranches("main", "develop", "next", "missing"),
+	)
+
+	got, err := c.getLatestVersion(t.Context(), "example.com/a")
+	if err != nil {
+		t.Fatalf("getLatestVersion: %v", err)
+	}
+	if want := "v0.0.0-20231201000000-bbbbbbbbbbbb"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	c = NewChecker(WithResolver(fakeResolver{}), WithBranches("trunk"))
+	if _, err := c.getLatestVersion(t.Context(), "example.com/a"); err == nil {
+		t.Error("expected error when no branch exists")
+	}
+}
+
+func TestMajorBranch(t *testing.T) {
+	const v3 = "v3.1.1-0.20231101000000-aaaaaaaaaaaa"
+	c := NewChecker(WithResolver(fakeResolver{
+		// main is at v4, so it is not found for the v3 module path.
+		"example.com/moved/v3@v3":    v3,
+		"example.com/subdir/v3@main": "v3.2.1-0.20231201000000-bbbbbbbbbbbb",
+		"example.com/subdir/v3@v3":   v3,
+	}))
+
+	tests := []struct {
+		module  string
+		want    string
+		wantErr string
+	}{
+		{module: "example.com/moved/v3", want: v3},
+		// The major branch is only a fallback.
+		{module: "example.com/subdir/v3", want: "v3.2.1-0.20231201000000-bbbbbbbbbbbb"},
+		{module: "example.com/gone/v2", wantErr: "none of the branches main, master, v2 found"},
+		{module: "example.com/gone", wantErr: "none of the branches main, master found"},
+		{module: "gopkg.in/yaml.v3", wantErr: "none of the branches main, master found"},
+	}
+	for _, tt := range tests {
+		got, err := c.getLatestVersion(t.Context(), tt.module)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%s: got error %v, want %q", tt.module, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.module, got, err, tt.want)
+		}
+	}
+}
+
+func TestFilterDeps(t *testing.T) {
+	deps := []Dependency{
+		{Module: "example.com/a"},
+		{Module: "example.com/b"},
+		{Module: "example.com/c"},
+	}
+
+	got, err := filterDeps(deps, []string{"example.com/c", "example.com/a"})
+	if err != nil {
+		t.Fatalf("filterDeps: %v", err)
+	}
+	want := []Dependency{{Module: "example.com/a"}, {Module: "example.com/c"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	_, err = filterDeps(deps, []string{"example.com/missing"})
+	var unknownErr *UnknownModuleError
+	if !errors.As(err, &unknownErr) {
+		t.Errorf("got error %v, want *UnknownModuleError", err)
+	}
+}
+
+func TestIsPseudoVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		matches bool
+	}{
+		{
+			name:    "pseudo-version without base tag",
+			input:   "v0.0.0-20231129151722-fdeea329fbba",
+			matches: true,
+		},
+		{
+			name:    "pseudo-version with base tag",
+			input:   "v1.1.1-0.20251215205057-2f3252140e00",
+			matches: true,
+		},
+		{
+			name:    "tagged version",
+			input:   "v1.2.3",
+			matches: false,
+		},
+		{
+			name:    "tagged version with prerelease",
+			input:   "v1.2.3-beta.1",
+			matches: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := module.IsPseudoVersion(tt.input)
+			if got != tt.matches {
+				t.Errorf("module.IsPseudoVersion(%q) = %v, want %v", tt.input, got, tt.matches)
+			}
+		})
+	}
+}
+
+func TestGetLatestVersion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tests := []struct {
+		name   string
+		module string
+	}{
+		{
+			name:   "netipx has no tagged versions",
+			module: "go4.org/netipx",
+		},
+		{
+			name:   "mmdbwriter has tagged versions",
+			module: "github.com/maxmind/mmdbwriter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			version, err := NewChecker().getLatestVersion(ctx, tt.module)
+			if err != nil {
+				t.Fatalf("getLatestVersion: %v", err)
+			}
+
+			if !module.IsPseudoVersion(version) {
+				t.Errorf("expected pseudo-version, got %q", version)
+			}
+		})
+	}
+}
+
+func TestQueryModuleVersion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tests := []struct {
+		name        string
+		module      string
+		branch      string
+		wantPseudo  bool
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:       "netipx has no tagged versions",
+			module:     "go4.org/netipx",
+			branch:     branchMain,
+			wantPseudo: true,
+		},
+		{
+			name:       "mmdbwriter has tagged versions but main returns pseudo",
+			module:     "github.com/maxmind/mmdbwriter",
+			branch:     branchMain,
+			wantPseudo: true,
+		},
+		{
+			name:        "nonexistent branch returns error",
+			module:      "go4.org/netipx",
+			branch:      "nonexistent-branch",
+			wantErr:     true,
+			errContains: "unknown revision",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			version, err := queryModuleVersion(ctx, ExecRunner{}, t.TempDir(), tt.module, tt.branch)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if tt.errContains != "" && !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("expected error to contain %q, got %q", tt.errContains, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("queryModuleVersion: %v", err)
+			}
+
+			if tt.wantPseudo && !module.IsPseudoVersion(version) {
+				t.Errorf("expected pseudo-version, got %q", version)
+			}
+		})
+	}
+}
+
+func TestNewerVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       string
+		b       string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "a is newer",
+			a:    "v0.0.0-20231201000000-aaaaaaaaaaaa",
+			b:    "v0.0.0-20231101000000-bbbbbbbbbbbb",
+			want: "v0.0.0-20231201000000-aaaaaaaaaaaa",
+		},
+		{
+			name: "b is newer",
+			a:    "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			b:    "v0.0.0-20231201000000-bbbbbbbbbbbb",
+			want: "v0.0.0-20231201000000-bbbbbbbbbbbb",
+		},
+		{
+			name: "same timestamp returns a",
+			a:    "v0.0.0-20231201000000-aaaaaaaaaaaa",
+			b:    "v0.0.0-20231201000000-bbbbbbbbbbbb",
+			want: "v0.0.0-20231201000000-aaaaaaaaaaaa",
+		},
+		{
+			name: "different bases compare by timestamp",
+			a:    "v1.2.4-pre.0.20231101000000-aaaaaaaaaaaa",
+			b:    "v0.0.0-20231201000000-bbbbbbbbbbbb+incompatible",
+			want: "v0.0.0-20231201000000-bbbbbbbbbbbb+incompatible",
+		},
+		{
+			name: "tag newer than the pseudo-version's base",
+			a:    "v1.2.3-0.20231201000000-aaaaaaaaaaaa",
+			b:    "v1.2.3",
+			want: "v1.2.3",
+		},
+		{
+			name: "pseudo-version after the tag",
+			a:    "v1.2.3",
+			b:    "v1.2.4-0.20231201000000-bbbbbbbbbbbb",
+			want: "v1.2.4-0.20231201000000-bbbbbbbbbbbb",
+		},
+		{
+			name:    "invalid version a returns error",
+			a:       "main",
+			b:       "v0.0.0-20231201000000-bbbbbbbbbbbb",
+			wantErr: true,
+		},
+		{
+			name:    "invalid version b returns error",
+			a:       "v0.0.0-20231201000000-aaaaaaaaaaaa",
+			b:       "latest",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newerVersion(tt.a, tt.b)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("newerVersion(%q, %q) expected error, got nil", tt.a, tt.b)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("newerVersion(%q, %q) unexpected error: %v", tt.a, tt.b, err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("newerVersion(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// runnerFunc adapts a function to CommandRunner.
+type runnerFunc func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+func (f runnerFunc) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return f(ctx, name, args...)
+}
+
+func TestGoListResolverRunner(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		stderr  string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "resolves version",
+			output: `{"Path":"go4.org/netipx","Version":"v0.0.0-20231129151722-fdeea329fbba"}`,
+			want:   "v0.0.0-20231129151722-fdeea329fbba",
+		},
+		{
+			name:    "unknown revision",
+			stderr:  "go: go4.org/netipx@main: invalid version: unknown revision main",
+			wantErr: ErrBranchNotFound,
+		},
+		{
+			name: "rate limited",
+			stderr: "go: reading https://proxy.golang.org/go4.org/netipx/@v/main.info: " +
+				"429 Too Many Requests",
+			wantErr: ErrRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotArgs []string
+			var logs bytes.Buffer
+			r := GoListResolver{
+				Dir: "/work",
+				Runner: runnerFunc(
+					func(_ context.Context, name string, args ...string) ([]byte, error) {
+						gotArgs = append([]string{name}, args...)
+						if tt.stderr != "" {
+							return nil, &exec.ExitError{Stderr: []byte(tt.stderr)}
+						}
+						return []byte(tt.output), nil
+					},
+				),
+				Logger: slog.New(slog.NewTextHandler(
+					&logs,
+					&slog.HandlerOptions{Level: slog.LevelDebug},
+				)),
+			}
+
+			got, err := r.Resolve(t.Context(), "go4.org/netipx", branchMain)
+
+			wantArgs := []string{
+				"go", "-C", "/work", "list", "-mod=mod", "-m", "-json", "go4.org/netipx@main",
+			}
+			if !slices.Equal(gotArgs, wantArgs) {
+				t.Errorf("ran %q, want %q", gotArgs, wantArgs)
+			}
+			// The command logged is the one run.
+			wantLog := `command="` + strings.Join(wantArgs, " ") + `"`
+			if strings.Count(logs.String(), wantLog) != 2 {
+				t.Errorf("got logs %q, want two containing %s", logs.String(), wantLog)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), tt.stderr) {
+					t.Errorf("got error %q, want it to contain stderr %q", err, tt.stderr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoListResolverTempDir(t *testing.T) {
+	var dir string
+	r := GoListResolver{Runner: runnerFunc(
+		func(_ context.Context, _ string, args ...string) ([]byte, error) {
+			dir = args[1]
+			gomod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+			if err != nil || !strings.HasPrefix(string(gomod), "module ") {
+				t.Errorf("go command ran in %s: %v, go.mod %q, want an empty module",
+					dir, err, gomod)
+			}
+			return []byte(`{"Version":"v0.0.0-20231129151722-fdeea329fbba"}`), nil
+		},
+	)}
+	if _, err := r.Resolve(t.Context(), "go4.org/netipx", branchMain); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if _, err := os.Stat(dir); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("directory %s left behind: %v", dir, err)
+	}
+}
+
+func TestHasUpdate(t *testing.T) {
+	const current = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+	tests := []struct {
+		latest string
+		want   bool
+	}{
+		{latest: current},
+		{latest: "v0.0.0-20231201000000-bbbbbbbbbbbb", want: true},
+		// A tag was pushed before the commit after it was required.
+		{latest: "v1.2.1-0.20231101000000-aaaaaaaaaaaa"},
+		{latest: "v1.2.1-0.20231101000000-aaaaaaaaaaaa+incompatible"},
+		{latest: "v1.3.0", want: true},
+	}
+	for _, tt := range tests {
+		res := Result{Dependency: Dependency{Version: current}, Latest: tt.latest}
+		if got := res.HasUpdate(); got != tt.want {
+			t.Errorf("HasUpdate with latest %s = %t, want %t", tt.latest, got, tt.want)
+		}
+	}
+}
+
+func TestIsolatedGoEnv(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin",
+		"GOWORK=/src/go.work",
+		"GOFLAGS=-mod=vendor -modcacherw --modfile=alt.mod -tags=integration",
+		"GOPRIVATE=corp.example.com",
+		"GONOSUMDB=corp.example.com",
+		"GO111MODULE=auto",
+	}
+	got := isolatedGoEnv(environ)
+	want := []string{
+		"PATH=/usr/bin",
+		"GOPRIVATE=corp.example.com",
+		"GONOSUMDB=corp.example.com",
+		"GOWORK=off",
+		"GO111MODULE=on",
+		"GOFLAGS=-modcacherw -tags=integration -mod=mod",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := goEnvOverrides(environ); !slices.Equal(got, want[3:]) {
+		t.Errorf("got overrides %q, want %q", got, want[3:])
+	}
+}
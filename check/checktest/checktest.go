@@ -0,0 +1,105 @@
+// Package checktest provides fakes for testing code that uses the check
+// package without network access or a Go toolchain.
+package checktest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// Resolver is a check.Resolver that resolves queries from maps keyed by
+// module@branch, e.g. "go4.org/netipx@main". Queries that are in neither map
+// fail with an error wrapping check.ErrBranchNotFound. It is safe for
+// concurrent use.
+type Resolver struct {
+	// Versions maps queries to the versions they resolve to.
+	Versions map[string]string
+	// Errors maps queries to the errors they fail with.
+	Errors map[string]error
+
+	mu      sync.Mutex
+	queries []string
+}
+
+var _ check.Resolver = (*Resolver)(nil)
+
+// Resolve implements check.Resolver.
+func (r *Resolver) Resolve(_ context.Context, modulePath, branch string) (string, error) {
+	key := modulePath + "@" + branch
+
+	r.mu.Lock()
+	r.queries = append(r.queries, key)
+	r.mu.Unlock()
+
+	if err, ok := r.Errors[key]; ok {
+		return "", err
+	}
+	if v, ok := r.Versions[key]; ok {
+		return v, nil
+	}
+	return "", fmt.Errorf("%s: %w", key, check.ErrBranchNotFound)
+}
+
+// Queries returns the queries resolved so far, sorted.
+func (r *Resolver) Queries() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	queries := slices.Clone(r.queries)
+	slices.Sort(queries)
+	return queries
+}
+
+// GoRunner is a check.CommandRunner that answers 'go -C dir list -mod=mod -m
+// -json module@branch' the way the go command would, from maps keyed by
+// module@branch. Use it with check.GoListResolver to exercise go list
+// handling, including error classification, without a Go toolchain.
+type GoRunner struct {
+	// Versions maps queries to the versions they resolve to.
+	Versions map[string]string
+	// Stderr maps queries to what the go command writes to standard error
+	// when it fails, e.g. "go: example.com/x@main: 410 Gone". Queries that
+	// are in neither map fail with an "unknown revision" error.
+	Stderr map[string]string
+}
+
+var _ check.CommandRunner = GoRunner{}
+
+// Run implements check.CommandRunner.
+func (r GoRunner) Run(_ context.Context, name string, args ...string) ([]byte, error) {
+	if len(args) > 2 && args[0] == "-C" {
+		args = args[2:]
+	}
+	if name != "go" || len(args) != 5 ||
+		!slices.Equal(args[:4], []string{"list", "-mod=mod", "-m", "-json"}) {
+		return nil, fmt.Errorf(
+			"checktest: unexpected command: %s %s",
+			name,
+			strings.Join(args, " "),
+		)
+	}
+	query := args[4]
+
+	if msg, ok := r.Stderr[query]; ok {
+		return nil, &exec.ExitError{Stderr: []byte(msg)}
+	}
+	version, ok := r.Versions[query]
+	if !ok {
+		_, branch, _ := strings.Cut(query, "@")
+		return nil, &exec.ExitError{
+			Stderr: fmt.Appendf(nil, "go: %s: invalid version: unknown revision %s", query, branch),
+		}
+	}
+
+	modulePath, _, _ := strings.Cut(query, "@")
+	return json.Marshal(struct {
+		Path    string
+		Version string
+	}{Path: modulePath, Version: version})
+}
@@ -0,0 +1,80 @@
+package checktest_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+	"github.com/horgh/check-untagged-go-deps/check/checktest"
+)
+
+func TestResolver(t *testing.T) {
+	r := &checktest.Resolver{
+		Versions: map[string]string{
+			"example.com/stale@main": "v0.0.0-20231201000000-bbbbbbbbbbbb",
+		},
+		Errors: map[string]error{
+			"example.com/private@main": check.ErrAuth,
+		},
+	}
+	c := check.NewChecker(check.WithResolver(r), check.WithBranches("main"))
+
+	rep := c.Check(t.Context(), []check.Dependency{
+		{Module: "example.com/stale", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "example.com/private", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+	})
+
+	if len(rep.Updates) != 1 || rep.Updates[0].Module != "example.com/stale" {
+		t.Errorf("got updates %v, want example.com/stale", rep.Updates)
+	}
+	if len(rep.Failures) != 1 || !errors.Is(rep.Failures[0].Err, check.ErrAuth) {
+		t.Errorf("got failures %v, want example.com/private with ErrAuth", rep.Failures)
+	}
+
+	want := []string{"example.com/private@main", "example.com/stale@main"}
+	if got := r.Queries(); !slices.Equal(got, want) {
+		t.Errorf("got queries %q, want %q", got, want)
+	}
+}
+
+func TestGoRunner(t *testing.T) {
+	r := check.GoListResolver{Runner: checktest.GoRunner{
+		Versions: map[string]string{
+			"go4.org/netipx@main": "v0.0.0-20231129151722-fdeea329fbba",
+		},
+		Stderr: map[string]string{
+			"example.com/gone@main": "go: example.com/gone@main: reading " +
+				"https://proxy.golang.org/example.com/gone/@v/main.info: 410 Gone",
+		},
+	}}
+
+	tests := []struct {
+		module  string
+		branch  string
+		want    string
+		wantErr error
+	}{
+		{module: "go4.org/netipx", branch: "main", want: "v0.0.0-20231129151722-fdeea329fbba"},
+		{module: "go4.org/netipx", branch: "master", wantErr: check.ErrBranchNotFound},
+		{module: "example.com/gone", branch: "main", wantErr: check.ErrModuleNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.module+"@"+tt.branch, func(t *testing.T) {
+			got, err := r.Resolve(t.Context(), tt.module, tt.branch)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// ErrUnsupportedHost means a Comparer cannot compare revisions of a module,
+// e.g. because it is not hosted on the comparer's forge.
+var ErrUnsupportedHost = errors.New("module host not supported")
+
+// Comparer compares two revisions of a module's repository.
+type Comparer interface {
+	// Compare compares base to head, which are commit hashes (or prefixes
+	// of them) from the module's repository. If the module is not hosted
+	// where the Comparer can look, the error must wrap ErrUnsupportedHost.
+	Compare(ctx context.Context, modulePath, base, head string) (Comparison, error)
+}
+
+// Comparison describes the difference between two revisions.
+type Comparison struct {
+	// Ahead is the number of commits in head that are not in base, i.e. how
+	// many commits base is behind head.
+	Ahead int
+	// Behind is the number of commits in base that are not in head. It is 0
+	// if head descends from base, as it does for an update, or if the
+	// Comparer does not know.
+	Behind int
+	// Commits are the commits in head that are not in base, newest first.
+	// There may be fewer than Ahead, since forges limit how many commits
+	// they return.
+	Commits []Commit
+	// Files are the files changed between base and head. Forges may limit
+	// how many they return.
+	Files []ChangedFile
+}
+
+// ChangedFile is a file changed between two revisions.
+type ChangedFile struct {
+	// Path is the file's path relative to the repository root.
+	Path string
+	// Additions and Deletions are the number of lines added and removed.
+	Additions int
+	Deletions int
+}
+
+// ChangeSummary summarizes the files changed between two revisions.
+type ChangeSummary struct {
+	// Files is the number of files changed.
+	Files int `json:"files"`
+	// Additions and Deletions are the number of lines added and removed.
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+	// Directories are the directories containing changed files, relative to
+	// the repository root ("." for the root itself), sorted.
+	Directories []string `json:"directories"`
+	// GoModChanged is set if any go.mod or go.sum file changed, i.e. the
+	// update may change the module's own requirements.
+	GoModChanged bool `json:"go_mod_changed"`
+	// APIChanged is set if any Go file that could affect the exported API
+	// changed: a non-test Go file outside internal and testdata directories.
+	APIChanged bool `json:"api_changed"`
+}
+
+// summarizeChanges summarizes files.
+func summarizeChanges(files []ChangedFile) *ChangeSummary {
+	s := &ChangeSummary{Files: len(files), Directories: []string{}}
+	for _, f := range files {
+		s.Additions += f.Additions
+		s.Deletions += f.Deletions
+
+		dir := path.Dir(f.Path)
+		if !slices.Contains(s.Directories, dir) {
+			s.Directories = append(s.Directories, dir)
+		}
+
+		switch base := path.Base(f.Path); {
+		case base == "go.mod" || base == "go.sum":
+			s.GoModChanged = true
+		case isAPIFile(f.Path):
+			s.APIChanged = true
+		}
+	}
+	slices.Sort(s.Directories)
+	return s
+}
+
+// isAPIFile reports whether the file at p could be part of a package's
+// exported API.
+func isAPIFile(p string) bool {
+	if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+		return false
+	}
+	for elem := range strings.SplitSeq(path.Dir(p), "/") {
+		if elem == "internal" || elem == "testdata" {
+			return false
+		}
+	}
+	return true
+}
+
+// WithChangeSummary includes a summary of the files changed between the
+// current and latest versions in each Update, if the Comparer provides them
+// (see WithComparer). By default no summary is included.
+func WithChangeSummary(include bool) Option {
+	return func(c *Checker) { c.changeSummary = include }
+}
+
+// Commit is a commit in a module's repository.
+type Commit struct {
+	// SHA is the commit hash.
+	SHA string `json:"sha"`
+	// Subject is the first line of the commit message.
+	Subject string `json:"subject"`
+	// Author identifies the commit's author: their forge account name if
+	// known, otherwise the name recorded in the commit. Bot accounts on
+	// GitHub have names ending in "[bot]".
+	Author string `json:"author,omitempty"`
+	// Message is the full commit message, if known. It is not included in
+	// JSON reports.
+	Message string `json:"-"`
+}
+
+// WithAuthors includes the distinct authors of the commits between the
+// current and latest versions in each Update, if the Comparer provides them
+// (see WithComparer). By default authors are not included.
+func WithAuthors(include bool) Option {
+	return func(c *Checker) { c.authors = include }
+}
+
+// commitAuthors returns the distinct authors of commits, sorted.
+func commitAuthors(commits []Commit) []string {
+	var authors []string
+	for _, commit := range commits {
+		if commit.Author != "" && !slices.Contains(authors, commit.Author) {
+			authors = append(authors, commit.Author)
+		}
+	}
+	slices.Sort(authors)
+	return authors
+}
+
+// IsBot reports whether author (see Commit.Author) is a bot account.
+func IsBot(author string) bool {
+	return strings.HasSuffix(author, "[bot]")
+}
+
+// WithMaxCommits includes up to n of the newest commits between the current
+// and latest versions in each Update, if the Comparer provides them (see
+// WithComparer). By default no commits are included.
+func WithMaxCommits(n int) Option {
+	return func(c *Checker) { c.maxCommits = n }
+}
+
+// WithMinCommitsBehind drops updates whose current version is fewer than n
+// commits behind the latest, as counted by the Comparer (see WithComparer),
+// so that a trivial upstream commit does not cause churn. The dependency is
+// then treated as up to date. Updates that could not be compared are kept.
+// By default, and if n is 0, updates are kept however few commits behind
+// they are.
+func WithMinCommitsBehind(n int) Option {
+	return func(c *Checker) { c.minCommitsBehind = n }
+}
+
+// WithComparer sets how updates are compared with the current version, e.g.
+// to count how many commits behind the current version is. Comparisons are
+// only made for dependencies with updates. A failed comparison is logged and
+// leaves the comparison fields of the Update unset rather than failing the
+// dependency. By default no comparisons are made.
+func WithComparer(comparer Comparer) Option {
+	return func(c *Checker) { c.comparer = comparer }
+}
+
+// compare compares the commits of current and latest, which are
+// pseudo-versions of the module.
+func (c *Checker) compare(
+	ctx context.Context,
+	modulePath,
+	current,
+	latest string,
+) (Comparison, error) {
+	base, err := module.PseudoVersionRev(current)
+	if err != nil {
+		return Comparison{}, fmt.Errorf("parsing version %q: %w", current, err)
+	}
+	head, err := module.PseudoVersionRev(latest)
+	if err != nil {
+		return Comparison{}, fmt.Errorf("parsing version %q: %w", latest, err)
+	}
+
+	release, err := c.limiter.acquire(ctx, modulePath)
+	if err != nil {
+		return Comparison{}, err
+	}
+	defer release()
+
+	return c.comparer.Compare(ctx, modulePath, base, head)
+}
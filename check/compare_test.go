@@ -0,0 +1,59 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarizeChanges(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []ChangedFile
+		want  ChangeSummary
+	}{
+		{
+			name: "exported API",
+			files: []ChangedFile{
+				{Path: "netipx.go", Additions: 10, Deletions: 2},
+				{Path: "netipx_test.go", Additions: 30},
+				{Path: "sub/pkg/pkg.go", Additions: 1, Deletions: 1},
+			},
+			want: ChangeSummary{
+				Files:       3,
+				Additions:   41,
+				Deletions:   3,
+				Directories: []string{".", "sub/pkg"},
+				APIChanged:  true,
+			},
+		},
+		{
+			name: "go.mod and internal packages",
+			files: []ChangedFile{
+				{Path: "go.mod", Additions: 1, Deletions: 1},
+				{Path: "go.sum", Additions: 2, Deletions: 2},
+				{Path: "internal/x/x.go", Additions: 5},
+				{Path: "cmd/tool/testdata/in.go", Additions: 5},
+				{Path: "README.md", Deletions: 4},
+			},
+			want: ChangeSummary{
+				Files:        5,
+				Additions:    13,
+				Deletions:    7,
+				Directories:  []string{".", "cmd/tool/testdata", "internal/x"},
+				GoModChanged: true,
+			},
+		},
+		{
+			name: "no files",
+			want: ChangeSummary{Directories: []string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeChanges(tt.files); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
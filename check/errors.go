@@ -0,0 +1,174 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors classifying why a dependency could not be checked. Errors
+// returned by resolvers and recorded in Failure.Err wrap at most one of these;
+// test for them with errors.Is.
+var (
+	// ErrBranchNotFound means the queried branch does not exist. Resolvers
+	// must wrap it for missing branches so the Checker can try the next one.
+	ErrBranchNotFound = errors.New("branch not found")
+	// ErrModuleNotFound means the module or its repository does not exist.
+	ErrModuleNotFound = errors.New("module not found")
+	// ErrAuth means access to the module was denied or needs credentials.
+	// Note that some hosts (such as GitHub) respond to requests for
+	// repositories that do not exist as if authentication were required.
+	ErrAuth = errors.New("authentication required or denied")
+	// ErrRateLimited means a proxy or host rejected the query due to rate
+	// limiting.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrTimeout means the query timed out.
+	ErrTimeout = errors.New("timed out")
+	// ErrUnsigned means an update was refused because its latest commit
+	// does not have a verified signature (see WithSignatureCheck).
+	ErrUnsigned = errors.New("commit signature not verified")
+	// ErrPinVanished means the commit a dependency is pinned to no longer
+	// exists upstream (see WithPinCheck).
+	ErrPinVanished = errors.New("pinned commit vanished")
+	// ErrRetracted means the version a dependency would be updated to is
+	// retracted, as are its version and every other candidate, leaving no
+	// eligible target (see WithRetractionCheck).
+	ErrRetracted = errors.New("latest version retracted")
+)
+
+// Error codes returned by ErrorCode.
+const (
+	CodeBranchNotFound = "branch_not_found"
+	CodeModuleNotFound = "module_not_found"
+	CodeAuth           = "auth"
+	CodeRateLimited    = "rate_limited"
+	CodeTimeout        = "timeout"
+	CodeUnsigned       = "unsigned"
+	CodePinVanished    = "pin_vanished"
+	CodeRetracted      = "retracted"
+	CodeUnknown        = "unknown"
+)
+
+// ErrorCode returns a stable, machine-readable code for err, such as
+// "branch_not_found". It returns "" for a nil error and "unknown" for errors
+// that are not classified.
+func ErrorCode(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrBranchNotFound):
+		return CodeBranchNotFound
+	case errors.Is(err, ErrModuleNotFound):
+		return CodeModuleNotFound
+	case errors.Is(err, ErrAuth):
+		return CodeAuth
+	case errors.Is(err, ErrRateLimited):
+		return CodeRateLimited
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.Is(err, ErrUnsigned):
+		return CodeUnsigned
+	case errors.Is(err, ErrPinVanished):
+		return CodePinVanished
+	case errors.Is(err, ErrRetracted):
+		return CodeRetracted
+	default:
+		return CodeUnknown
+	}
+}
+
+// classifiedError keeps an error's message while making it match a sentinel
+// error with errors.Is.
+type classifiedError struct {
+	kind error
+	err  error
+}
+
+func (e *classifiedError) Error() string   { return e.err.Error() }
+func (e *classifiedError) Unwrap() []error { return []error{e.kind, e.err} }
+
+// classify wraps err so it matches kind. If kind is nil, err is returned
+// unchanged.
+func classify(kind, err error) error {
+	if kind == nil || err == nil {
+		return err
+	}
+	return &classifiedError{kind: kind, err: err}
+}
+
+// classifyMessage guesses the kind of error from an error message produced by
+// the go command or a module proxy.
+func classifyMessage(msg string) error {
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.Contains(lower, "unknown revision"),
+		strings.Contains(lower, "no matching versions"):
+		return ErrBranchNotFound
+	// The branch exists, but its go.mod file is for another major version,
+	// e.g. main has moved on to example.com/mod/v4 and the module path is
+	// example.com/mod/v3.
+	case isMajorMismatch(lower):
+		return ErrBranchNotFound
+	case strings.Contains(lower, "429 too many requests"),
+		strings.Contains(lower, "rate limit"):
+		return ErrRateLimited
+	case strings.Contains(lower, "terminal prompts disabled"),
+		strings.Contains(lower, "could not read username"),
+		strings.Contains(lower, "authentication"),
+		strings.Contains(lower, "401 unauthorized"),
+		strings.Contains(lower, "403 forbidden"):
+		return ErrAuth
+	case strings.Contains(lower, "404 not found"),
+		strings.Contains(lower, "410 gone"),
+		strings.Contains(lower, "repository not found"),
+		strings.Contains(lower, "unrecognized import path"),
+		strings.Contains(lower, "not found"):
+		return ErrModuleNotFound
+	case strings.Contains(lower, "timeout"),
+		strings.Contains(lower, "timed out"),
+		strings.Contains(lower, "deadline exceeded"):
+		return ErrTimeout
+	default:
+		return nil
+	}
+}
+
+// isMajorMismatch reports whether a lowercase go command or proxy error
+// message says a revision's go.mod file declares another major version of
+// the module path.
+func isMajorMismatch(lower string) bool {
+	return strings.Contains(lower, "go.mod has post-v") ||
+		strings.Contains(lower, "go.mod has non-") ||
+		strings.Contains(lower, "module path must match major version")
+}
+
+// classifyStatus returns the kind of error for an HTTP response from a module
+// proxy. body is used to tell a missing branch from a missing module.
+func classifyStatus(status int, body string) error {
+	switch status {
+	case http.StatusNotFound, http.StatusGone:
+		if kind := classifyMessage(body); kind == ErrBranchNotFound {
+			return kind
+		}
+		return ErrModuleNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrAuth
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ErrTimeout
+	default:
+		return classifyMessage(body)
+	}
+}
+
+// isTimeout reports whether err is a timeout from a context or the network.
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
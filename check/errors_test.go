@@ -0,0 +1,125 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestClassifyMessage(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want error
+	}{
+		{
+			msg:  "go: go4.org/netipx@nope: invalid version: unknown revision nope",
+			want: ErrBranchNotFound,
+		},
+		{
+			msg: "go: example.com/x@main: " +
+				"reading https://proxy.golang.org/example.com/x/@v/main.info: " +
+				"404 Not Found\n\tserver response: not found",
+			want: ErrModuleNotFound,
+		},
+		{
+			msg: "go: github.com/private/repo@main: invalid version: git ls-remote -q origin: " +
+				"exit status 128:\n\tfatal: could not read Username for 'https://github.com': " +
+				"terminal prompts disabled",
+			want: ErrAuth,
+		},
+		{
+			msg:  "reading https://proxy.example.com/x/@v/main.info: 429 Too Many Requests",
+			want: ErrRateLimited,
+		},
+		{
+			msg:  "dial tcp: i/o timeout",
+			want: ErrTimeout,
+		},
+		{
+			msg: "go: github.com/foo/bar/v3@main: invalid version: " +
+				`go.mod has post-v3 module path "github.com/foo/bar/v4" at revision 0123456789ab`,
+			want: ErrBranchNotFound,
+		},
+		{
+			msg: "not found: github.com/foo/bar/v3@master: invalid version: " +
+				`go.mod has non-.../v3 module path "github.com/foo/bar" (and .../v3/go.mod ` +
+				"does not exist) at revision 0123456789ab",
+			want: ErrBranchNotFound,
+		},
+		{
+			msg:  "something else went wrong",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			if got := classifyMessage(tt.msg); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   error
+	}{
+		{
+			status: http.StatusNotFound,
+			body:   "not found: unknown revision main",
+			want:   ErrBranchNotFound,
+		},
+		{status: http.StatusGone, body: "not found: module example.com/x", want: ErrModuleNotFound},
+		{status: http.StatusForbidden, want: ErrAuth},
+		{status: http.StatusTooManyRequests, want: ErrRateLimited},
+		{status: http.StatusGatewayTimeout, want: ErrTimeout},
+		{status: http.StatusInternalServerError, body: "oops", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			if got := classifyStatus(tt.status, tt.body); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: nil, want: ""},
+		{err: classify(ErrBranchNotFound, errors.New("x")), want: CodeBranchNotFound},
+		{err: fmt.Errorf("checking: %w", ErrModuleNotFound), want: CodeModuleNotFound},
+		{err: classify(ErrAuth, errors.New("x")), want: CodeAuth},
+		{err: classify(ErrRateLimited, errors.New("x")), want: CodeRateLimited},
+		{err: fmt.Errorf("running go list: %w", context.DeadlineExceeded), want: CodeTimeout},
+		{err: classify(ErrUnsigned, errors.New("x")), want: CodeUnsigned},
+		{err: classify(ErrPinVanished, errors.New("x")), want: CodePinVanished},
+		{err: classify(ErrRetracted, errors.New("x")), want: CodeRetracted},
+		{err: errors.New("x"), want: CodeUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := ErrorCode(tt.err); got != tt.want {
+			t.Errorf("ErrorCode(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyKeepsMessage(t *testing.T) {
+	err := classify(ErrAuth, errors.New("fatal: could not read Username"))
+	if got := err.Error(); got != "fatal: could not read Username" {
+		t.Errorf("got message %q", got)
+	}
+	if !errors.Is(err, ErrAuth) {
+		t.Error("expected error to match ErrAuth")
+	}
+}
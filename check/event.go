@@ -0,0 +1,80 @@
+package check
+
+import "time"
+
+// EventKind identifies the kind of an Event.
+type EventKind int
+
+const (
+	// EventModuleStarted is sent when checking a dependency starts.
+	EventModuleStarted EventKind = iota + 1
+	// EventCacheHit is sent when a branch resolution is served from the cache.
+	EventCacheHit
+	// EventBranchResolved is sent when a branch query completes, whether or
+	// not the branch exists (see Event.NotFound).
+	EventBranchResolved
+	// EventModuleResolved is sent when the latest version of a dependency has
+	// been determined.
+	EventModuleResolved
+	// EventModuleFailed is sent when a dependency could not be checked.
+	EventModuleFailed
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventModuleStarted:
+		return "module started"
+	case EventCacheHit:
+		return "cache hit"
+	case EventBranchResolved:
+		return "branch resolved"
+	case EventModuleResolved:
+		return "module resolved"
+	case EventModuleFailed:
+		return "module failed"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes progress while checking dependencies. Fields that do not
+// apply to an event's kind are left empty.
+type Event struct {
+	Kind   EventKind
+	Module string
+	// Branch is set for EventCacheHit and EventBranchResolved.
+	Branch string
+	// Version is the resolved version, if any.
+	Version string
+	// NotFound is set for EventCacheHit and EventBranchResolved if the branch
+	// does not exist.
+	NotFound bool
+	// Duration is how long the query took, for EventBranchResolved, or how
+	// long checking the dependency took, for EventModuleResolved and
+	// EventModuleFailed.
+	Duration time.Duration
+	// Err is set for EventModuleFailed.
+	Err error
+}
+
+// EventHandler receives events. Calls are serialized, so a handler does not
+// need to be safe for concurrent use, but it should return quickly as it
+// blocks checking.
+type EventHandler func(Event)
+
+// WithEventHandler delivers progress events to h, for example to drive a
+// progress display or record metrics.
+func WithEventHandler(h EventHandler) Option {
+	return func(c *Checker) { c.eventHandler = h }
+}
+
+func (c *Checker) emit(e Event) {
+	if c.eventHandler == nil {
+		return
+	}
+
+	c.eventMu.Lock()
+	defer c.eventMu.Unlock()
+
+	c.eventHandler(e)
+}
@@ -0,0 +1,73 @@
+package check
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWithEventHandler(t *testing.T) {
+	cache, err := NewCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewCache: %v", err)
+	}
+	if err := cache.put(cacheEntry{
+		Module:   "example.com/stale",
+		Branch:   branchMaster,
+		NotFound: true,
+	}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	var events []Event
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"example.com/stale@main": "v0.0.0-20231201000000-bbbbbbbbbbbb",
+		}),
+		WithCache(cache),
+		WithEventHandler(func(e Event) { events = append(events, e) }),
+	)
+
+	c.Check(t.Context(), []Dependency{
+		{Module: "example.com/stale", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "example.com/broken", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+	})
+
+	kinds := map[string][]EventKind{}
+	for _, e := range events {
+		kinds[e.Module] = append(kinds[e.Module], e.Kind)
+	}
+
+	wantStale := []EventKind{
+		EventModuleStarted,
+		EventBranchResolved,
+		EventCacheHit,
+		EventModuleResolved,
+	}
+	if !slices.Equal(kinds["example.com/stale"], wantStale) {
+		t.Errorf("got events %v for stale module, want %v", kinds["example.com/stale"], wantStale)
+	}
+
+	wantBroken := []EventKind{
+		EventModuleStarted,
+		EventBranchResolved,
+		EventBranchResolved,
+		EventModuleFailed,
+	}
+	if !slices.Equal(kinds["example.com/broken"], wantBroken) {
+		t.Errorf(
+			"got events %v for broken module, want %v",
+			kinds["example.com/broken"],
+			wantBroken,
+		)
+	}
+
+	for _, e := range events {
+		if e.Kind == EventModuleResolved && e.Version != "v0.0.0-20231201000000-bbbbbbbbbbbb" {
+			t.Errorf("got resolved version %q", e.Version)
+		}
+		if e.Kind == EventModuleFailed && e.Err == nil {
+			t.Error("expected error on failed event")
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package check
+
+import (
+	"fmt"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// Selection records whether CheckGoMod checks a requirement in go.mod, and
+// why (see ExplainGoMod).
+type Selection struct {
+	// Module and Version are the requirement's, as written in go.mod.
+	Module  string
+	Version string
+	// Checked is whether the requirement is checked.
+	Checked bool
+	// Reason says why it is checked or not, e.g. "not a pseudo-version".
+	Reason string
+
+	// dep is the dependency checked for the requirement, if it is checked
+	// as a pseudo-versioned dependency.
+	dep *Dependency
+}
+
+// ExplainGoMod returns a Selection for each requirement in the go.mod file
+// at gomodPath, in go.mod order, saying whether CheckGoMod, given the same
+// modules, would check it and why. Unlike CheckGoMod, it does not fail if
+// a module is not a pseudo-versioned requirement. It reads only go.mod, so
+// the dependencies found by WithToolPins are not included.
+func (c *Checker) ExplainGoMod(gomodPath string, modules ...string) ([]Selection, error) {
+	f, err := parseGoMod(gomodPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", gomodPath, err)
+	}
+
+	tagged := c.taggedLister != nil && len(modules) == 0
+	sels := selectRequirements(f, c.includeIndirect || len(modules) > 0, tagged)
+	if len(modules) > 0 {
+		wanted := map[string]bool{}
+		for _, m := range modules {
+			wanted[m] = true
+		}
+		for i := range sels {
+			s := &sels[i]
+			if !s.Checked || wanted[s.Module] || s.dep != nil && wanted[s.dep.Module] {
+				continue
+			}
+			s.Checked = false
+			s.Reason = "not one of the modules to check"
+		}
+	}
+	return sels, nil
+}
+
+// selectRequirements returns a Selection for each requirement in f.
+// Indirect requirements are skipped unless includeIndirect is set, and
+// requirements at tagged versions are checked for newer releases if tagged
+// is set (see WithTaggedUpdates).
+func selectRequirements(f *modfile.File, includeIndirect, tagged bool) []Selection {
+	sels := make([]Selection, 0, len(f.Require))
+	for _, req := range f.Require {
+		s := Selection{Module: req.Mod.Path, Version: req.Mod.Version}
+		dep := Dependency{
+			Module:   req.Mod.Path,
+			Version:  req.Mod.Version,
+			Indirect: req.Indirect,
+			Span:     &Span{Start: req.Syntax.Start, End: req.Syntax.End},
+		}
+		r, forked := replacement(f.Replace, req.Mod)
+		var fork module.Version
+		if forked {
+			fork = r.New
+			dep.Module = fork.Path
+			dep.Version = fork.Version
+			dep.Replaces = req.Mod.Path
+			dep.Span = &Span{Start: r.Syntax.Start, End: r.Syntax.End}
+		}
+		switch {
+		case req.Indirect && !includeIndirect:
+			s.Reason = "indirect, and indirect dependencies are not included"
+		case module.IsPseudoVersion(dep.Version):
+			s.Checked = true
+			s.dep = &dep
+			s.Reason = "pseudo-version"
+			if forked {
+				s.Reason = fmt.Sprintf("replaced by pseudo-version %s@%s", fork.Path, fork.Version)
+			} else if dir, ok := dirReplacement(f.Replace, req.Mod); ok {
+				s.Reason += fmt.Sprintf(
+					", though replaced by directory %s, whose version is not checked",
+					dir,
+				)
+			}
+		case tagged && !module.IsPseudoVersion(req.Mod.Version):
+			s.Checked = true
+			s.Reason = "tagged version, checked for newer releases"
+		case forked:
+			s.Reason = fmt.Sprintf(
+				"replaced by %s@%s, which is not a pseudo-version",
+				fork.Path,
+				fork.Version,
+			)
+		default:
+			s.Reason = "not a pseudo-version"
+		}
+		sels = append(sels, s)
+	}
+	return sels
+}
+
+// dirReplacement returns the directory that a replace directive replaces mod
+// with, if any.
+func dirReplacement(replaces []*modfile.Replace, mod module.Version) (string, bool) {
+	for _, r := range replaces {
+		if r.Old.Path == mod.Path && (r.Old.Version == "" || r.Old.Version == mod.Version) &&
+			r.New.Version == "" {
+			return r.New.Path, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,106 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExplainGoMod(t *testing.T) {
+	gomod := filepath.Join(t.TempDir(), "go.mod")
+	content := "module example.com/app\n\ngo 1.21\n\nrequire (\n" +
+		"\tgithub.com/example/pseudo v0.0.0-20240101000000-aaaaaaaaaaaa\n" +
+		"\tgithub.com/example/tagged v1.2.0\n" +
+		"\tgithub.com/example/indirect v0.0.0-20240101000000-bbbbbbbbbbbb // indirect\n" +
+		"\tgithub.com/example/forked v1.0.0\n" +
+		"\tgithub.com/example/released v1.0.0\n" +
+		"\tgithub.com/example/local v0.0.0-20240101000000-cccccccccccc\n" +
+		")\n\n" +
+		"replace github.com/example/forked => github.com/fork/forked " +
+		"v0.0.0-20240102000000-dddddddddddd\n\n" +
+		"replace github.com/example/released => github.com/fork/released v1.1.0\n\n" +
+		"replace github.com/example/local => ../local\n"
+	if err := os.WriteFile(gomod, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// selection is whether a requirement is checked and a fragment of the
+	// reason.
+	type selection struct {
+		checked bool
+		reason  string
+	}
+	tests := []struct {
+		name    string
+		opts    []Option
+		modules []string
+		want    map[string]selection
+	}{
+		{
+			name: "default",
+			want: map[string]selection{
+				"github.com/example/pseudo":   {true, "pseudo-version"},
+				"github.com/example/tagged":   {false, "not a pseudo-version"},
+				"github.com/example/indirect": {false, "indirect"},
+				"github.com/example/forked":   {true, "replaced by pseudo-version github.com/fork"},
+				"github.com/example/released": {false, "which is not a pseudo-version"},
+				"github.com/example/local":    {true, "replaced by directory ../local"},
+			},
+		},
+		{
+			name: "indirect and tagged",
+			opts: []Option{
+				WithIncludeIndirect(true),
+				WithTaggedUpdates(tagListerFunc(func(string) ([]string, error) {
+					return nil, nil
+				})),
+			},
+			want: map[string]selection{
+				"github.com/example/pseudo":   {true, "pseudo-version"},
+				"github.com/example/tagged":   {true, "tagged version"},
+				"github.com/example/indirect": {true, "pseudo-version"},
+				"github.com/example/forked":   {true, "replaced by pseudo-version"},
+				"github.com/example/released": {true, "tagged version"},
+				"github.com/example/local":    {true, "pseudo-version"},
+			},
+		},
+		{
+			name:    "modules",
+			modules: []string{"github.com/example/indirect", "github.com/fork/forked"},
+			want: map[string]selection{
+				"github.com/example/pseudo":   {false, "not one of the modules"},
+				"github.com/example/tagged":   {false, "not a pseudo-version"},
+				"github.com/example/indirect": {true, "pseudo-version"},
+				"github.com/example/forked":   {true, "replaced by pseudo-version"},
+				"github.com/example/released": {false, "which is not a pseudo-version"},
+				"github.com/example/local":    {false, "not one of the modules"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sels, err := NewChecker(tt.opts...).ExplainGoMod(gomod, tt.modules...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sels) != len(tt.want) {
+				t.Fatalf("got %d selections, want %d", len(sels), len(tt.want))
+			}
+			for _, s := range sels {
+				want := tt.want[s.Module]
+				if s.Checked != want.checked || !strings.Contains(s.Reason, want.reason) {
+					t.Errorf(
+						"%s: checked %v, reason %q; want checked %v, reason containing %q",
+						s.Module,
+						s.Checked,
+						s.Reason,
+						want.checked,
+						want.reason,
+					)
+				}
+			}
+		})
+	}
+}
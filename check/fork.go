@@ -0,0 +1,98 @@
+package check
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// ForkStatus is how far a fork that replaces a module in go.mod has diverged
+// from the upstream module (see WithForkDivergence).
+type ForkStatus struct {
+	// UpstreamLatest is the latest version on the upstream module's
+	// branches.
+	UpstreamLatest string `json:"upstream_latest"`
+	// Ahead is how many commits the fork's latest version has that
+	// UpstreamLatest does not.
+	Ahead int `json:"ahead"`
+	// Behind is how many commits UpstreamLatest has that the fork's latest
+	// version does not.
+	Behind int `json:"behind"`
+}
+
+// WithForkDivergence compares each fork that replaces a module in go.mod (see
+// Dependency.Replaces) with its upstream module, using comparer, and records
+// how far the fork's latest version has diverged from the latest version on
+// the upstream's branches in Dependency.Fork. The comparison is made in the
+// fork's repository, so the comparer must be able to find upstream commits
+// there, as GitHub does for forks in the same network. A failed comparison
+// is logged. By default forks are not compared with their upstreams.
+func WithForkDivergence(comparer Comparer) Option {
+	return func(c *Checker) { c.forkComparer = comparer }
+}
+
+// replacement returns the replace directive replacing mod in go.mod, if a
+// module rather than a directory replaces it. A replacement of the specific
+// version takes precedence over one of all versions, as for the go command.
+func replacement(replaces []*modfile.Replace, mod module.Version) (*modfile.Replace, bool) {
+	var found *modfile.Replace
+	for _, r := range replaces {
+		if r.Old.Path != mod.Path {
+			continue
+		}
+		if r.Old.Version == mod.Version {
+			found = r
+			break
+		}
+		if r.Old.Version == "" {
+			found = r
+		}
+	}
+	if found == nil || found.New.Version == "" {
+		return nil, false
+	}
+	return found, true
+}
+
+// checkFork records in res how far the fork has diverged from its upstream.
+func (c *Checker) checkFork(ctx context.Context, res *Result) error {
+	dep := &res.Dependency
+	upstream, err := c.getLatestVersion(ctx, dep.Replaces)
+	if err != nil {
+		return fmt.Errorf("resolving upstream %s: %w", dep.Replaces, err)
+	}
+
+	release, err := c.limiter.acquire(ctx, dep.Module)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	base, head := versionRev(upstream), versionRev(res.Latest)
+	cmp, err := c.forkComparer.Compare(ctx, dep.Module, base, head)
+	if err != nil {
+		return err
+	}
+	dep.Fork = &ForkStatus{UpstreamLatest: upstream, Ahead: cmp.Ahead, Behind: cmp.Behind}
+	return nil
+}
+
+// requiredPath returns the module path the update's dependency has in the
+// build list: the path go.mod requires, which a fork replaces.
+func (u Update) requiredPath() string {
+	if u.Replaces != "" {
+		return u.Replaces
+	}
+	return u.Module
+}
+
+// versionRev returns the revision of a version to compare: the commit hash
+// of a pseudo-version, or the version itself, which is then a tag.
+func versionRev(version string) string {
+	if rev, err := module.PseudoVersionRev(version); err == nil {
+		return rev
+	}
+	return version
+}
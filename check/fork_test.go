@@ -0,0 +1,114 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindPseudoVersionedDepsReplace(t *testing.T) {
+	gomod := filepath.Join(t.TempDir(), "go.mod")
+	content := `module test
+
+go 1.25
+
+require (
+	github.com/upstream/fork v1.2.0
+	github.com/upstream/exact v1.0.0
+	github.com/upstream/tagged v0.0.0-20231101000000-bbbbbbbbbbbb
+	github.com/upstream/local v0.0.0-20231101000000-cccccccccccc
+)
+
+replace github.com/upstream/fork => github.com/ourorg/fork v0.0.0-20231201000000-dddddddddddd
+
+replace (
+	github.com/upstream/exact v1.0.0 => github.com/ourorg/exact v0.0.0-20231201000000-eeeeeeeeeeee
+	github.com/upstream/exact => github.com/ourorg/other v0.0.0-20231201000000-ffffffffffff
+	github.com/upstream/tagged => github.com/ourorg/tagged v1.3.0
+	github.com/upstream/local => ../local
+)
+`
+	if err := os.WriteFile(gomod, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	deps, err := FindPseudoVersionedDeps(gomod, false)
+	if err != nil {
+		t.Fatalf("FindPseudoVersionedDeps: %v", err)
+	}
+	want := []Dependency{
+		{
+			Module:   "github.com/ourorg/fork",
+			Version:  "v0.0.0-20231201000000-dddddddddddd",
+			Replaces: "github.com/upstream/fork",
+		},
+		{
+			Module:   "github.com/ourorg/exact",
+			Version:  "v0.0.0-20231201000000-eeeeeeeeeeee",
+			Replaces: "github.com/upstream/exact",
+		},
+		{Module: "github.com/upstream/local", Version: "v0.0.0-20231101000000-cccccccccccc"},
+	}
+	// Forks are located at their replace directives, and other dependencies
+	// at their require directives, even if a directory replaces them.
+	for i, line := range []int{12, 15, 9} {
+		if i >= len(deps) || deps[i].Span == nil {
+			t.Fatalf("got %+v, want a span for each dependency", deps)
+		}
+		if got := deps[i].Span.Start.Line; got != line {
+			t.Errorf("%s: got span on line %d, want %d", deps[i].Module, got, line)
+		}
+		deps[i].Span = nil
+	}
+	if !reflect.DeepEqual(deps, want) {
+		t.Errorf("got %+v, want %+v", deps, want)
+	}
+
+	// A fork can be selected by the path it replaces.
+	got, err := filterDeps(deps, []string{"github.com/upstream/fork"})
+	if err != nil || len(got) != 1 || got[0].Module != "github.com/ourorg/fork" {
+		t.Errorf("filterDeps by upstream path = %+v, %v", got, err)
+	}
+}
+
+func TestWithForkDivergence(t *testing.T) {
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"github.com/ourorg/fork@main":     "v0.0.0-20231201000000-bbbbbbbbbbbb",
+			"github.com/upstream/fork@main":   "v1.3.1-0.20231215000000-cccccccccccc",
+			"github.com/ourorg/tagged@main":   "v0.0.0-20231201000000-dddddddddddd",
+			"github.com/upstream/tagged@main": "v1.4.0",
+		}),
+		WithBranches(branchMain),
+		WithForkDivergence(fakeComparer{
+			"cccccccccccc...bbbbbbbbbbbb": {Ahead: 3, Behind: 12},
+			"v1.4.0...dddddddddddd":       {Ahead: 1},
+		}),
+	)
+
+	rep := c.Check(t.Context(), []Dependency{
+		{
+			Module:   "github.com/ourorg/fork",
+			Version:  "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			Replaces: "github.com/upstream/fork",
+		},
+		{
+			Module:   "github.com/ourorg/tagged",
+			Version:  "v0.0.0-20231201000000-dddddddddddd",
+			Replaces: "github.com/upstream/tagged",
+		},
+	})
+	want := []*ForkStatus{
+		{UpstreamLatest: "v1.3.1-0.20231215000000-cccccccccccc", Ahead: 3, Behind: 12},
+		{UpstreamLatest: "v1.4.0", Ahead: 1},
+	}
+	for i, dep := range rep.Dependencies {
+		if !reflect.DeepEqual(dep.Fork, want[i]) {
+			t.Errorf("%s: got fork %+v, want %+v", dep.Module, dep.Fork, want[i])
+		}
+	}
+	if len(rep.Updates) != 1 || rep.Updates[0].Replaces != "github.com/upstream/fork" {
+		t.Errorf("got updates %+v, want one replacing github.com/upstream/fork", rep.Updates)
+	}
+}
@@ -0,0 +1,427 @@
+package check
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultGitHubAPIURL = "https://api.github.com"
+
+// errUnprocessable means GitHub rejected a request as 422 Unprocessable
+// Entity, which it does when asked for a commit that does not exist.
+var errUnprocessable = errors.New("unprocessable request")
+
+// GitHubClient is a Comparer, ReleaseNotesSource, ActivitySource,
+// MoveDetector, SignatureVerifier, CommitFinder, and DirHistory using the
+// GitHub REST API. It supports modules whose paths start with github.com/<owner>/<repo>.
+type GitHubClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewGitHubClient returns a client for the GitHub API at baseURL, e.g.
+// https://api.github.com (used if baseURL is empty) or a GitHub Enterprise
+// Server's https://<host>/api/v3. token is sent as a bearer token if it is
+// not empty; without one, GitHub allows only a low rate of requests. If
+// client is nil, a client with a one minute timeout is used.
+func NewGitHubClient(baseURL, token string, client *http.Client) *GitHubClient {
+	if baseURL == "" {
+		baseURL = defaultGitHubAPIURL
+	}
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	return &GitHubClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  client,
+	}
+}
+
+// githubComparison is the subset of the compare API's response that is used.
+type githubComparison struct {
+	AheadBy  int `json:"ahead_by"`
+	BehindBy int `json:"behind_by"`
+	// Commits are oldest first, and limited to 250.
+	Commits []struct {
+		SHA    string `json:"sha"`
+		Commit struct {
+			Message string `json:"message"`
+			Author  struct {
+				Name string `json:"name"`
+			} `json:"author"`
+		} `json:"commit"`
+		// Author is the GitHub account matching the commit's author, or
+		// null if there is none.
+		Author *struct {
+			Login string `json:"login"`
+		} `json:"author"`
+	} `json:"commits"`
+	// Files are limited to 300.
+	Files []struct {
+		Filename  string `json:"filename"`
+		Additions int    `json:"additions"`
+		Deletions int    `json:"deletions"`
+	} `json:"files"`
+}
+
+// Compare implements Comparer.
+func (g *GitHubClient) Compare(
+	ctx context.Context,
+	modulePath,
+	base,
+	head string,
+) (Comparison, error) {
+	owner, repo, ok := githubRepo(modulePath)
+	if !ok {
+		return Comparison{}, fmt.Errorf("%s: %w", modulePath, ErrUnsupportedHost)
+	}
+
+	path := "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) +
+		"/compare/" + url.PathEscape(base) + "..." + url.PathEscape(head)
+
+	var cmp githubComparison
+	if err := g.get(ctx, path, &cmp); err != nil {
+		return Comparison{}, err
+	}
+
+	out := Comparison{Ahead: cmp.AheadBy, Behind: cmp.BehindBy}
+	for _, commit := range slices.Backward(cmp.Commits) {
+		subject, _, _ := strings.Cut(commit.Commit.Message, "\n")
+		author := commit.Commit.Author.Name
+		if commit.Author != nil && commit.Author.Login != "" {
+			author = commit.Author.Login
+		}
+		out.Commits = append(out.Commits, Commit{
+			SHA:     commit.SHA,
+			Subject: strings.TrimSpace(subject),
+			Author:  author,
+			Message: commit.Commit.Message,
+		})
+	}
+	out.Files = make([]ChangedFile, 0, len(cmp.Files))
+	for _, file := range cmp.Files {
+		out.Files = append(out.Files, ChangedFile{
+			Path:      file.Filename,
+			Additions: file.Additions,
+			Deletions: file.Deletions,
+		})
+	}
+	return out, nil
+}
+
+// get requests path from the API and decodes the JSON response into v.
+func (g *GitHubClient) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("querying GitHub: %w", err)
+		if isTimeout(err) {
+			return classify(ErrTimeout, err)
+		}
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		msg := resp.Status
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+			msg = apiErr.Message
+		}
+		err := fmt.Errorf("querying GitHub: %s", msg)
+		// GitHub reports exhausted rate limits as 403 Forbidden.
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			return classify(ErrRateLimited, err)
+		}
+		if resp.StatusCode == http.StatusUnprocessableEntity {
+			return classify(errUnprocessable, err)
+		}
+		return classify(classifyStatus(resp.StatusCode, ""), err)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("parsing GitHub response: %w", err)
+	}
+	return nil
+}
+
+// githubRepo returns the owner and repository of a module hosted on GitHub.
+func githubRepo(modulePath string) (owner, repo string, ok bool) {
+	parts := strings.SplitN(modulePath, "/", 4)
+	if len(parts) < 3 || parts[0] != "github.com" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// changelogNames are the files searched for a changelog, in order.
+var changelogNames = []string{"CHANGELOG.md", "CHANGES.md", "HISTORY.md"}
+
+// maxReleases is how many of the newest releases are searched for release
+// notes.
+const maxReleases = 30
+
+// ReleaseNotes implements ReleaseNotesSource. It returns the sections added
+// to the repository's changelog (CHANGELOG.md or similar) between base and
+// head. If the repository has no changelog or no sections were added, it
+// returns the notes of GitHub releases published between baseTime and
+// headTime instead.
+func (g *GitHubClient) ReleaseNotes(
+	ctx context.Context,
+	modulePath,
+	base,
+	head string,
+	baseTime,
+	headTime time.Time,
+) (string, error) {
+	owner, repo, ok := githubRepo(modulePath)
+	if !ok {
+		return "", fmt.Errorf("%s: %w", modulePath, ErrUnsupportedHost)
+	}
+	repoPath := "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+
+	for _, name := range changelogNames {
+		newText, found, err := g.fileContents(ctx, repoPath, name, head)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			continue
+		}
+		oldText, _, err := g.fileContents(ctx, repoPath, name, base)
+		if err != nil {
+			return "", err
+		}
+		if notes := newChangelogSections(oldText, newText); notes != "" {
+			return notes, nil
+		}
+		break
+	}
+
+	var releases []struct {
+		TagName     string    `json:"tag_name"`
+		Name        string    `json:"name"`
+		Body        string    `json:"body"`
+		Draft       bool      `json:"draft"`
+		PublishedAt time.Time `json:"published_at"`
+	}
+	releasesPath := repoPath + "/releases?per_page=" + strconv.Itoa(maxReleases)
+	if err := g.get(ctx, releasesPath, &releases); err != nil {
+		return "", err
+	}
+	var notes []string
+	for _, r := range releases {
+		if r.Draft || !r.PublishedAt.After(baseTime) || r.PublishedAt.After(headTime) {
+			continue
+		}
+		title := r.Name
+		if title == "" {
+			title = r.TagName
+		}
+		notes = append(notes, "## "+title+"\n\n"+strings.TrimSpace(r.Body))
+	}
+	return strings.Join(notes, "\n\n"), nil
+}
+
+// fileContents returns the contents of the file at path in the repository at
+// ref. found is false if there is no such file.
+func (g *GitHubClient) fileContents(
+	ctx context.Context,
+	repoPath,
+	path,
+	ref string,
+) (content string, found bool, err error) {
+	var file struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	err = g.get(ctx, repoPath+"/contents/"+path+"?ref="+url.QueryEscape(ref), &file)
+	if errors.Is(err, ErrModuleNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	// Files over 1 MB have no content and the encoding "none".
+	if file.Encoding != "base64" {
+		return "", false, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+	if err != nil {
+		return "", false, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return string(data), true, nil
+}
+
+// githubRepository is the subset of the repository API's response that is
+// used.
+type githubRepository struct {
+	// FullName is "<owner>/<repo>" at the repository's current location.
+	// GitHub redirects requests for a renamed or transferred repository.
+	FullName      string `json:"full_name"`
+	Archived      bool   `json:"archived"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// repository returns the API path of the module's repository and the
+// repository's details.
+func (g *GitHubClient) repository(
+	ctx context.Context,
+	modulePath string,
+) (repoPath string, r githubRepository, err error) {
+	owner, repo, ok := githubRepo(modulePath)
+	if !ok {
+		return "", r, fmt.Errorf("%s: %w", modulePath, ErrUnsupportedHost)
+	}
+	repoPath = "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+	if err := g.get(ctx, repoPath, &r); err != nil {
+		return "", r, err
+	}
+	return repoPath, r, nil
+}
+
+// Activity implements ActivitySource.
+func (g *GitHubClient) Activity(ctx context.Context, modulePath string) (Activity, error) {
+	repoPath, r, err := g.repository(ctx, modulePath)
+	if err != nil {
+		return Activity{}, err
+	}
+
+	var commit struct {
+		Commit struct {
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	commitPath := repoPath + "/commits/" + url.PathEscape(r.DefaultBranch)
+	if err := g.get(ctx, commitPath, &commit); err != nil {
+		return Activity{}, err
+	}
+	return Activity{Archived: r.Archived, LastCommit: commit.Commit.Committer.Date}, nil
+}
+
+// MovedPath implements MoveDetector. Differences only in case are not
+// considered moves.
+func (g *GitHubClient) MovedPath(ctx context.Context, modulePath string) (string, error) {
+	_, r, err := g.repository(ctx, modulePath)
+	if err != nil {
+		return "", err
+	}
+
+	parts := strings.SplitN(modulePath, "/", 4)
+	if r.FullName == "" || strings.EqualFold(r.FullName, parts[1]+"/"+parts[2]) {
+		return "", nil
+	}
+	moved := "github.com/" + r.FullName
+	if len(parts) == 4 {
+		moved += "/" + parts[3]
+	}
+	return moved, nil
+}
+
+// CommitSignature implements SignatureVerifier.
+func (g *GitHubClient) CommitSignature(
+	ctx context.Context,
+	modulePath,
+	rev string,
+) (Signature, error) {
+	owner, repo, ok := githubRepo(modulePath)
+	if !ok {
+		return Signature{}, fmt.Errorf("%s: %w", modulePath, ErrUnsupportedHost)
+	}
+
+	path := "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) +
+		"/commits/" + url.PathEscape(rev)
+	var commit struct {
+		Commit struct {
+			Verification struct {
+				Verified bool   `json:"verified"`
+				Reason   string `json:"reason"`
+			} `json:"verification"`
+		} `json:"commit"`
+	}
+	if err := g.get(ctx, path, &commit); err != nil {
+		return Signature{}, err
+	}
+	return Signature{
+		Verified: commit.Commit.Verification.Verified,
+		Reason:   commit.Commit.Verification.Reason,
+	}, nil
+}
+
+// CommitExists implements CommitFinder.
+func (g *GitHubClient) CommitExists(ctx context.Context, modulePath, rev string) (bool, error) {
+	repoPath, _, err := g.repository(ctx, modulePath)
+	if err != nil {
+		return false, err
+	}
+
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+	err = g.get(ctx, repoPath+"/commits/"+url.PathEscape(rev), &commit)
+	// The repository exists, so a commit that is not found is gone.
+	if errors.Is(err, errUnprocessable) || errors.Is(err, ErrModuleNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LastChange implements DirHistory.
+func (g *GitHubClient) LastChange(ctx context.Context, modulePath, rev string) (string, error) {
+	owner, repo, ok := githubRepo(modulePath)
+	if !ok {
+		return "", fmt.Errorf("%s: %w", modulePath, ErrUnsupportedHost)
+	}
+	subdir := moduleSubdir("github.com/"+owner+"/"+repo, modulePath)
+	if subdir == "" {
+		return rev, nil
+	}
+
+	query := url.Values{"sha": {rev}, "path": {subdir}, "per_page": {"1"}}
+	var commits []struct {
+		SHA string `json:"sha"`
+	}
+	err := g.get(
+		ctx,
+		"/repos/"+url.PathEscape(owner)+"/"+url.PathEscape(repo)+"/commits?"+query.Encode(),
+		&commits,
+	)
+	if err != nil {
+		return "", err
+	}
+	if len(commits) == 0 {
+		return "", nil
+	}
+	return commits[0].SHA, nil
+}
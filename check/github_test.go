@@ -0,0 +1,367 @@
+package check
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGitHubClientCompare(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("got Authorization %q, want bearer token", got)
+		}
+		switch r.URL.Path {
+		case "/repos/example/repo/compare/aaaaaaaaaaaa...bbbbbbbbbbbb":
+			fmt.Fprint(w, `{"status":"diverged","ahead_by":37,"behind_by":2,"commits":[`+
+				`{"sha":"1111","commit":{"message":"Older commit","author":{"name":"Alice"}},`+
+				`"author":null},`+
+				`{"sha":"2222","commit":{"message":"Newer commit\n\nWith a body.",`+
+				`"author":{"name":"B"}},`+
+				`"author":{"login":"dependabot[bot]"}}],`+
+				`"files":[{"filename":"go.mod","status":"modified","additions":1,"deletions":1}]}`)
+		case "/repos/example/limited/compare/aaaaaaaaaaaa...bbbbbbbbbbbb":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"API rate limit exceeded"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+		}
+	}))
+	defer server.Close()
+
+	g := NewGitHubClient(server.URL+"/", "secret", server.Client())
+
+	tests := []struct {
+		name    string
+		module  string
+		want    int
+		wantErr error
+	}{
+		{name: "compares", module: "github.com/example/repo", want: 37},
+		{name: "subdirectory module", module: "github.com/example/repo/sub/v2", want: 37},
+		{name: "rate limited", module: "github.com/example/limited", wantErr: ErrRateLimited},
+		{name: "not found", module: "github.com/example/gone", wantErr: ErrModuleNotFound},
+		{name: "not on GitHub", module: "go4.org/netipx", wantErr: ErrUnsupportedHost},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.Compare(t.Context(), tt.module, "aaaaaaaaaaaa", "bbbbbbbbbbbb")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Compare: %v", err)
+			}
+			if got.Ahead != tt.want || got.Behind != 2 {
+				t.Errorf(
+					"got %d commits ahead, %d behind, want %d and 2",
+					got.Ahead, got.Behind, tt.want,
+				)
+			}
+			wantCommits := []Commit{
+				{
+					SHA:     "2222",
+					Subject: "Newer commit",
+					Author:  "dependabot[bot]",
+					Message: "Newer commit\n\nWith a body.",
+				},
+				{SHA: "1111", Subject: "Older commit", Author: "Alice", Message: "Older commit"},
+			}
+			if !slices.Equal(got.Commits, wantCommits) {
+				t.Errorf("got commits %v, want %v", got.Commits, wantCommits)
+			}
+			wantFiles := []ChangedFile{{Path: "go.mod", Additions: 1, Deletions: 1}}
+			if !slices.Equal(got.Files, wantFiles) {
+				t.Errorf("got files %v, want %v", got.Files, wantFiles)
+			}
+		})
+	}
+}
+
+func TestGitHubClientReleaseNotes(t *testing.T) {
+	contents := func(text string) string {
+		return fmt.Sprintf(
+			`{"encoding":"base64","content":%q}`,
+			base64.StdEncoding.EncodeToString([]byte(text)),
+		)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path + "?" + r.URL.RawQuery {
+		case "/repos/example/changelog/contents/CHANGELOG.md?ref=aaaaaaaaaaaa":
+			fmt.Fprint(w, contents("# Changelog\n\n## v1.0.0\n\n* Initial release.\n"))
+		case "/repos/example/changelog/contents/CHANGELOG.md?ref=bbbbbbbbbbbb":
+			fmt.Fprint(w, contents("# Changelog\n\n## Unreleased\n\n* Fix a bug.\n\n"+
+				"## v1.0.0\n\n* Initial release.\n"))
+		case "/repos/example/releases/releases?per_page=30":
+			fmt.Fprint(w, `[`+
+				`{"tag_name":"v1.2.0","name":"","body":"Too new.",`+
+				`"published_at":"2024-03-01T00:00:00Z"},`+
+				`{"tag_name":"v1.1.0","name":"Version 1.1","body":"New things.\r\n",`+
+				`"published_at":"2024-02-01T00:00:00Z"},`+
+				`{"tag_name":"v1.0.1","name":"","body":"Draft.","draft":true,`+
+				`"published_at":"2024-01-20T00:00:00Z"},`+
+				`{"tag_name":"v1.0.0","name":"","body":"Too old.",`+
+				`"published_at":"2024-01-01T00:00:00Z"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+		}
+	}))
+	defer server.Close()
+
+	g := NewGitHubClient(server.URL, "", server.Client())
+	baseTime := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	headTime := time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		module  string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "changelog",
+			module: "github.com/example/changelog",
+			want:   "## Unreleased\n\n* Fix a bug.",
+		},
+		{
+			name:   "releases",
+			module: "github.com/example/releases",
+			want:   "## Version 1.1\n\nNew things.",
+		},
+		{name: "not on GitHub", module: "go4.org/netipx", wantErr: ErrUnsupportedHost},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.ReleaseNotes(
+				t.Context(),
+				tt.module,
+				"aaaaaaaaaaaa",
+				"bbbbbbbbbbbb",
+				baseTime,
+				headTime,
+			)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("release notes: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubClientActivity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/example/repo":
+			fmt.Fprint(w, `{"archived":true,"default_branch":"trunk"}`)
+		case "/repos/example/repo/commits/trunk":
+			fmt.Fprint(w, `{"sha":"1111","commit":{"committer":{"date":"2021-03-04T05:06:07Z"}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+		}
+	}))
+	defer server.Close()
+
+	g := NewGitHubClient(server.URL, "", server.Client())
+
+	got, err := g.Activity(t.Context(), "github.com/example/repo/v2")
+	if err != nil {
+		t.Fatalf("Activity: %v", err)
+	}
+	want := Activity{Archived: true, LastCommit: time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)}
+	if !got.LastCommit.Equal(want.LastCommit) || got.Archived != want.Archived {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	_, err = g.Activity(t.Context(), "github.com/example/gone")
+	if !errors.Is(err, ErrModuleNotFound) {
+		t.Errorf("got error %v, want %v", err, ErrModuleNotFound)
+	}
+	if _, err := g.Activity(t.Context(), "go4.org/netipx"); !errors.Is(err, ErrUnsupportedHost) {
+		t.Errorf("got error %v, want %v", err, ErrUnsupportedHost)
+	}
+}
+
+func TestGitHubClientMovedPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/old/repo":
+			// GitHub redirects requests for renamed and transferred
+			// repositories to their ID.
+			http.Redirect(w, r, "/repositories/1", http.StatusMovedPermanently)
+		case "/repositories/1":
+			fmt.Fprint(w, `{"full_name":"new/repo"}`)
+		case "/repos/example/Same":
+			fmt.Fprint(w, `{"full_name":"Example/same"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+		}
+	}))
+	defer server.Close()
+
+	g := NewGitHubClient(server.URL, "", server.Client())
+
+	tests := []struct {
+		module  string
+		want    string
+		wantErr error
+	}{
+		{module: "github.com/old/repo", want: "github.com/new/repo"},
+		{module: "github.com/old/repo/sub/v2", want: "github.com/new/repo/sub/v2"},
+		{module: "github.com/example/Same"},
+		{module: "github.com/example/gone", wantErr: ErrModuleNotFound},
+		{module: "go4.org/netipx", wantErr: ErrUnsupportedHost},
+	}
+	for _, tt := range tests {
+		got, err := g.MovedPath(t.Context(), tt.module)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: got error %v, want %v", tt.module, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.module, got, tt.want)
+		}
+	}
+}
+
+func TestGitHubClientCommitSignature(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/example/repo/commits/bbbbbbbbbbbb":
+			fmt.Fprint(
+				w,
+				`{"sha":"bbbbbbbbbbbb1234","commit":{"verification":`+
+					`{"verified":false,"reason":"unknown_key","signature":"-----BEGIN"}}}`,
+			)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+		}
+	}))
+	defer server.Close()
+
+	g := NewGitHubClient(server.URL, "", server.Client())
+
+	got, err := g.CommitSignature(t.Context(), "github.com/example/repo", "bbbbbbbbbbbb")
+	if err != nil {
+		t.Fatalf("CommitSignature: %v", err)
+	}
+	if want := (Signature{Reason: "unknown_key"}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	_, err = g.CommitSignature(t.Context(), "go4.org/netipx", "bbbbbbbbbbbb")
+	if !errors.Is(err, ErrUnsupportedHost) {
+		t.Errorf("got error %v, want %v", err, ErrUnsupportedHost)
+	}
+}
+
+func TestGitHubClientCommitExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/example/repo":
+			fmt.Fprint(w, `{"full_name":"example/repo"}`)
+		case "/repos/example/repo/commits/aaaaaaaaaaaa":
+			fmt.Fprint(w, `{"sha":"aaaaaaaaaaaa1234"}`)
+		case "/repos/example/repo/commits/bbbbbbbbbbbb":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message":"No commit found for SHA: bbbbbbbbbbbb"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+		}
+	}))
+	defer server.Close()
+
+	g := NewGitHubClient(server.URL, "", server.Client())
+
+	tests := []struct {
+		module  string
+		rev     string
+		want    bool
+		wantErr error
+	}{
+		{module: "github.com/example/repo", rev: "aaaaaaaaaaaa", want: true},
+		{module: "github.com/example/repo/v2", rev: "aaaaaaaaaaaa", want: true},
+		{module: "github.com/example/repo", rev: "bbbbbbbbbbbb"},
+		{module: "github.com/example/repo", rev: "cccccccccccc"},
+		{module: "github.com/example/gone", rev: "aaaaaaaaaaaa", wantErr: ErrModuleNotFound},
+		{module: "go4.org/netipx", rev: "aaaaaaaaaaaa", wantErr: ErrUnsupportedHost},
+	}
+	for _, tt := range tests {
+		got, err := g.CommitExists(t.Context(), tt.module, tt.rev)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s@%s: got error %v, want %v", tt.module, tt.rev, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s@%s: got %t, want %t", tt.module, tt.rev, got, tt.want)
+		}
+	}
+}
+
+func TestGitHubClientLastChange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/repos/example/mono/commits" || q.Get("sha") != "bbbbbbbbbbbb" ||
+			q.Get("per_page") != "1" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+			return
+		}
+		switch q.Get("path") {
+		case "sub/mod":
+			fmt.Fprint(w, `[{"sha":"cccccccccccc1234"}]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer server.Close()
+
+	g := NewGitHubClient(server.URL, "", server.Client())
+
+	tests := []struct {
+		module  string
+		want    string
+		wantErr error
+	}{
+		{module: "github.com/example/mono/sub/mod", want: "cccccccccccc1234"},
+		{module: "github.com/example/mono/sub/mod/v2", want: "cccccccccccc1234"},
+		{module: "github.com/example/mono/gone"},
+		{module: "github.com/example/mono", want: "bbbbbbbbbbbb"},
+		{module: "go4.org/netipx", wantErr: ErrUnsupportedHost},
+	}
+	for _, tt := range tests {
+		got, err := g.LastChange(t.Context(), tt.module, "bbbbbbbbbbbb")
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: got error %v, want %v", tt.module, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.module, got, tt.want)
+		}
+	}
+}
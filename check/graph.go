@@ -0,0 +1,99 @@
+package check
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+)
+
+// Requirer is a module whose go.mod file requires a dependency.
+type Requirer struct {
+	// Module is the requiring module's path.
+	Module string `json:"module"`
+	// Version is the requiring module's version in the build list.
+	Version string `json:"version"`
+	// Requires is the version of the dependency it requires. If it is the
+	// dependency's current version, the requirement is why that version is
+	// selected, and the module's maintainers must update it for the pin to
+	// move without overriding it.
+	Requires string `json:"requires"`
+}
+
+// WithRequirers runs 'go mod graph' with runner in the directory of the
+// go.mod file given to CheckGoMod, and records in Update.RequiredBy which
+// other modules in the build list require each updated dependency. For an
+// indirect dependency, this attributes its pseudo-version to the modules that
+// pull it in, showing whether to bump the pin or ask their maintainers to. A
+// nil runner means ExecRunner.
+//
+// As with WithImportChains, the go command must be able to load the module
+// graph, a failed lookup is logged and leaves the requirers empty, and Check
+// and Stream ignore this option. By default requirers are not looked up.
+func WithRequirers(runner CommandRunner) Option {
+	return func(c *Checker) {
+		c.requirers = true
+		c.goRunner = runner
+	}
+}
+
+// addRequirers sets the requirers of the updates in rep (see WithRequirers).
+func (c *Checker) addRequirers(ctx context.Context, runner CommandRunner, dir string, rep *Report) {
+	output, err := runGo(ctx, runner, dir, "mod", "graph")
+	if err != nil {
+		c.log().Warn("reading module graph failed", "error", err)
+		return
+	}
+	requirers := parseModGraph(output)
+	for i := range rep.Updates {
+		rep.Updates[i].RequiredBy = requirers[rep.Updates[i].requiredPath()]
+	}
+}
+
+// parseModGraph parses the output of 'go mod graph', a line for each
+// requirement of the form "module@version module@version", where the main
+// module has no version. It returns, keyed by module path, the modules in the
+// build list other than the main module that require each module. Versions
+// of modules that are in the graph but were not selected are ignored.
+func parseModGraph(output []byte) map[string][]Requirer {
+	type edge struct{ from, to string }
+	var edges []edge
+	var main string
+	for line := range strings.Lines(string(output)) {
+		from, to, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		if !strings.Contains(from, "@") {
+			main = from
+		}
+		edges = append(edges, edge{from: from, to: to})
+	}
+
+	// Since Go 1.17, the main module requires every module in the build list
+	// at its selected version.
+	selected := map[string]string{}
+	for _, e := range edges {
+		if e.from == main {
+			path, version, _ := strings.Cut(e.to, "@")
+			selected[path] = version
+		}
+	}
+
+	requirers := map[string][]Requirer{}
+	for _, e := range edges {
+		fromPath, fromVersion, ok := strings.Cut(e.from, "@")
+		if !ok || selected[fromPath] != fromVersion {
+			continue
+		}
+		toPath, toVersion, _ := strings.Cut(e.to, "@")
+		r := Requirer{Module: fromPath, Version: fromVersion, Requires: toVersion}
+		if !slices.Contains(requirers[toPath], r) {
+			requirers[toPath] = append(requirers[toPath], r)
+		}
+	}
+	for _, rs := range requirers {
+		slices.SortFunc(rs, func(a, b Requirer) int { return cmp.Compare(a.Module, b.Module) })
+	}
+	return requirers
+}
@@ -0,0 +1,79 @@
+package check
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestWithRequirers(t *testing.T) {
+	dir := t.TempDir()
+	gomod := filepath.Join(dir, "go.mod")
+	content := "module example.com/app\n\ngo 1.21\n\nrequire (\n" +
+		"\tgolang.org/x/net v0.20.0\n" +
+		"\tgo4.org/netipx v0.0.0-20231101000000-aaaaaaaaaaaa // indirect\n" +
+		"\texample.com/ours v0.0.0-20231101000000-bbbbbbbbbbbb // indirect\n" +
+		")\n"
+	if err := os.WriteFile(gomod, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	const graph = `example.com/app go@1.21
+example.com/app golang.org/x/net@v0.20.0
+example.com/app go4.org/netipx@v0.0.0-20231101000000-aaaaaaaaaaaa
+example.com/app example.com/ours@v0.0.0-20231101000000-bbbbbbbbbbbb
+golang.org/x/net@v0.20.0 go4.org/netipx@v0.0.0-20231101000000-aaaaaaaaaaaa
+golang.org/x/net@v0.20.0 example.com/ours@v0.0.0-20230101000000-eeeeeeeeeeee
+golang.org/x/net@v0.19.0 go4.org/netipx@v0.0.0-20230101000000-ffffffffffff
+go@1.21 toolchain@go1.21
+`
+	var gotArgs []string
+	runner := runnerFunc(func(_ context.Context, name string, args ...string) ([]byte, error) {
+		gotArgs = append([]string{name}, args...)
+		return []byte(graph), nil
+	})
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"go4.org/netipx@main":   "v0.0.0-20231201000000-cccccccccccc",
+			"example.com/ours@main": "v0.0.0-20231201000000-dddddddddddd",
+		}),
+		WithBranches(branchMain),
+		WithIncludeIndirect(true),
+		WithRequirers(runner),
+	)
+
+	rep, err := c.CheckGoMod(t.Context(), gomod)
+	if err != nil {
+		t.Fatalf("CheckGoMod: %v", err)
+	}
+
+	if want := []string{"go", "-C", dir, "mod", "graph"}; !slices.Equal(gotArgs, want) {
+		t.Errorf("ran %q, want %q", gotArgs, want)
+	}
+	got := map[string][]Requirer{}
+	for _, u := range rep.Updates {
+		got[u.Module] = u.RequiredBy
+	}
+	want := map[string][]Requirer{
+		"go4.org/netipx": {
+			{
+				Module:   "golang.org/x/net",
+				Version:  "v0.20.0",
+				Requires: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			},
+		},
+		"example.com/ours": {
+			{
+				Module:   "golang.org/x/net",
+				Version:  "v0.20.0",
+				Requires: "v0.0.0-20230101000000-eeeeeeeeeeee",
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got requirers %+v, want %+v", got, want)
+	}
+}
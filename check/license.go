@@ -0,0 +1,246 @@
+package check
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// License identifiers for files that are missing or not recognized, as in
+// SPDX documents.
+const (
+	LicenseNone        = "NONE"
+	LicenseNoAssertion = "NOASSERTION"
+)
+
+// LicenseChange describes how a module's license changed between two
+// versions.
+type LicenseChange struct {
+	// Current and Latest are the SPDX identifiers of the licenses detected
+	// in each version, e.g. MIT, joined with " AND " if the module has more
+	// than one license file. LicenseNone means there is no license file and
+	// LicenseNoAssertion that the license was not recognized.
+	Current string `json:"current"`
+	Latest  string `json:"latest"`
+	// TextChanged is set if the text of the license files changed, other
+	// than in copyright lines or formatting. It may be set even if the
+	// identifiers did not change, e.g. if terms were added to a license.
+	TextChanged bool `json:"text_changed"`
+}
+
+// WithLicenseCheck downloads the current and latest versions of each updated
+// dependency from source and compares the license files at the module root
+// (LICENSE, COPYING, and similar), recording any change in
+// Update.LicenseChange. If WithCompatibility is also given, both use the
+// source given last. A failed comparison is logged. By default licenses are
+// not compared.
+func WithLicenseCheck(source ModuleSource) Option {
+	return func(c *Checker) {
+		c.moduleSource = source
+		c.licenseCheck = true
+	}
+}
+
+// compareLicenses compares the licenses of two versions of a module. It
+// returns nil if they are the same.
+func compareLicenses(
+	ctx context.Context,
+	zips *moduleZips,
+	current,
+	latest string,
+) (*LicenseChange, error) {
+	oldFiles, err := moduleLicenses(ctx, zips, current)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := moduleLicenses(ctx, zips, latest)
+	if err != nil {
+		return nil, err
+	}
+
+	change := &LicenseChange{
+		Current:     licenseIDs(oldFiles),
+		Latest:      licenseIDs(newFiles),
+		TextChanged: !slices.Equal(normalizedLicenses(oldFiles), normalizedLicenses(newFiles)),
+	}
+	if change.Current == change.Latest && !change.TextChanged {
+		return nil, nil
+	}
+	return change, nil
+}
+
+// moduleLicenses returns the contents of the license files at the root of the
+// module at version, sorted by file name.
+func moduleLicenses(ctx context.Context, zips *moduleZips, version string) ([]string, error) {
+	zr, err := zips.get(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := zips.modulePath + "@" + version + "/"
+	var files []*zip.File
+	for _, f := range zr.File {
+		rel, ok := strings.CutPrefix(f.Name, prefix)
+		if ok && !strings.Contains(rel, "/") && isLicenseFile(rel) {
+			files = append(files, f)
+		}
+	}
+	slices.SortFunc(files, func(a, b *zip.File) int { return strings.Compare(a.Name, b.Name) })
+
+	var licenses []string
+	for _, f := range files {
+		data, err := readZipFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s@%s: %w", zips.modulePath, version, err)
+		}
+		licenses = append(licenses, string(data))
+	}
+	return licenses, nil
+}
+
+// isLicenseFile reports whether name is the name of a license file, such as
+// LICENSE, LICENSE.md, LICENCE-MIT, or COPYING.
+func isLicenseFile(name string) bool {
+	name = strings.ToLower(name)
+	for _, prefix := range []string{"license", "licence", "copying"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// licenseIDs returns the SPDX identifiers of the licenses, joined with
+// " AND ", or LicenseNone if there are none.
+func licenseIDs(licenses []string) string {
+	if len(licenses) == 0 {
+		return LicenseNone
+	}
+	var ids []string
+	for _, text := range licenses {
+		if id := detectLicense(text); !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return strings.Join(ids, " AND ")
+}
+
+// normalizedLicenses returns the licenses with copyright lines removed, case
+// folded, and whitespace collapsed, so that only changes to their terms are
+// compared.
+func normalizedLicenses(licenses []string) []string {
+	var normalized []string
+	for _, text := range licenses {
+		var b strings.Builder
+		for line := range strings.Lines(text) {
+			if strings.HasPrefix(strings.ToLower(strings.TrimSpace(line)), "copyright") {
+				continue
+			}
+			b.WriteString(line)
+		}
+		normalized = append(normalized, normalizeLicenseText(b.String()))
+	}
+	return normalized
+}
+
+// normalizeLicenseText folds case and collapses whitespace.
+func normalizeLicenseText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// detectLicense returns the SPDX identifier of the license in text, or
+// LicenseNoAssertion if it is not recognized. It recognizes an
+// SPDX-License-Identifier line and the texts of common open source licenses
+// by their distinctive phrases; it does not match texts exactly.
+func detectLicense(text string) string {
+	for line := range strings.Lines(text) {
+		if _, id, ok := strings.Cut(line, "SPDX-License-Identifier:"); ok {
+			if id = strings.TrimSpace(id); id != "" {
+				return id
+			}
+		}
+	}
+
+	t := normalizeLicenseText(text)
+	if id, ok := detectGNULicense(t); ok {
+		return id
+	}
+	switch {
+	case strings.Contains(t, "apache license") && strings.Contains(t, "version 2.0"):
+		return "Apache-2.0"
+	case strings.Contains(t, "mozilla public license") && strings.Contains(t, "2.0"):
+		return "MPL-2.0"
+	case strings.Contains(t, "permission is hereby granted, free of charge"):
+		return "MIT"
+	case strings.Contains(
+		t,
+		"permission to use, copy, modify, and/or distribute this software for any purpose",
+	):
+		if strings.Contains(t, "provided that the above copyright notice") {
+			return "ISC"
+		}
+		return "0BSD"
+	case strings.Contains(t, "redistribution and use in source and binary forms"):
+		if strings.Contains(t, "neither the name") ||
+			strings.Contains(t, "names of its contributors") {
+			return "BSD-3-Clause"
+		}
+		return "BSD-2-Clause"
+	case strings.Contains(
+		t,
+		"this is free and unencumbered software released into the public domain",
+	):
+		return "Unlicense"
+	case strings.Contains(t, "cc0 1.0"):
+		return "CC0-1.0"
+	case strings.Contains(t, "boost software license - version 1.0"):
+		return "BSL-1.0"
+	default:
+		return LicenseNoAssertion
+	}
+}
+
+// maxGNUTitleOffset is how far into a license file the title of a GNU
+// license may be.
+const maxGNUTitleOffset = 200
+
+// gnuLicenseTitles maps the titles of GNU licenses to SPDX identifier
+// prefixes.
+var gnuLicenseTitles = []struct{ title, name string }{
+	{title: "gnu affero general public license", name: "AGPL"},
+	{title: "gnu lesser general public license", name: "LGPL"},
+	{title: "gnu library general public license", name: "LGPL"},
+	{title: "gnu general public license", name: "GPL"},
+}
+
+// detectGNULicense identifies a GNU license in normalized text. The GNU
+// licenses mention each other, so they are identified by the title that
+// appears first and the version that follows it, e.g. "version 2.1,
+// february 1999".
+func detectGNULicense(t string) (string, bool) {
+	first, name := -1, ""
+	for _, g := range gnuLicenseTitles {
+		if i := strings.Index(t, g.title); i >= 0 && (first < 0 || i < first) {
+			first, name = i, g.name
+		}
+	}
+	// Other licenses, such as the MPL, mention the GPL in their terms, but
+	// GNU licenses start with their title.
+	if first < 0 || first > maxGNUTitleOffset {
+		return "", false
+	}
+
+	title := t[first:min(len(t), first+100)]
+	for _, v := range []struct{ title, id string }{
+		{title: "version 2.1,", id: "2.1"},
+		{title: "version 2,", id: "2.0"},
+		{title: "version 3,", id: "3.0"},
+	} {
+		if strings.Contains(title, v.title) {
+			return name + "-" + v.id, true
+		}
+	}
+	return LicenseNoAssertion, true
+}
@@ -0,0 +1,184 @@
+package check
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDetectLicense(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "SPDX identifier",
+			text: "// SPDX-License-Identifier: MIT OR Apache-2.0\n",
+			want: "MIT OR Apache-2.0",
+		},
+		{
+			name: "MIT",
+			text: "MIT License\n\nCopyright (c) 2024 Someone\n\n" +
+				"Permission is hereby granted, free of\ncharge, to any person...",
+			want: "MIT",
+		},
+		{
+			name: "BSD-3-Clause",
+			text: "Redistribution and use in source and binary forms, with or without\n" +
+				"modification, are permitted...\n" +
+				"   * Neither the name of Google Inc. nor the names of its\n" +
+				"contributors may be used...",
+			want: "BSD-3-Clause",
+		},
+		{
+			name: "Apache-2.0",
+			text: "                                 Apache License\n" +
+				"                           Version 2.0, January 2004",
+			want: "Apache-2.0",
+		},
+		{
+			name: "GPL-3.0 mentioning AGPL",
+			text: "GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n\n...\n" +
+				"13. Use with the GNU Affero General Public License.",
+			want: "GPL-3.0",
+		},
+		{
+			name: "LGPL-2.1",
+			text: "GNU LESSER GENERAL PUBLIC LICENSE\nVersion 2.1, February 1999",
+			want: "LGPL-2.1",
+		},
+		{
+			name: "ISC",
+			text: "Permission to use, copy, modify, and/or distribute this software for any\n" +
+				"purpose with or without fee is hereby granted, provided that the above " +
+				"copyright notice...",
+			want: "ISC",
+		},
+		{
+			name: "MPL-2.0 mentioning GPL",
+			text: "Mozilla Public License Version 2.0\n" + strings.Repeat("==", 200) +
+				"\n1.12. \"Secondary License\" means either the GNU General Public License, " +
+				"Version 2.0, ...",
+			want: "MPL-2.0",
+		},
+		{name: "unknown", text: "All rights reserved.", want: LicenseNoAssertion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectLicense(tt.text); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeModuleSource serves module zips from memory, keyed by version, and
+// counts downloads.
+type fakeModuleSource struct {
+	t     *testing.T
+	files map[string]map[string]string
+
+	mu        sync.Mutex
+	downloads int
+}
+
+func (f *fakeModuleSource) Download(
+	_ context.Context,
+	modulePath,
+	version string,
+) (*zip.Reader, error) {
+	f.mu.Lock()
+	f.downloads++
+	f.mu.Unlock()
+
+	files, ok := f.files[version]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	data := moduleZip(f.t, modulePath, version, files)
+	return zip.NewReader(bytes.NewReader(data), int64(len(data)))
+}
+
+func TestWithLicenseCheck(t *testing.T) {
+	const (
+		mit = "Copyright 2023 A\n\n" +
+			"Permission is hereby granted, free of charge, to any person.\n"
+		mit2 = "Copyright 2024 A and B\n\n" +
+			"Permission is hereby granted,   free of charge, to any person.\n"
+		mitMod = "Permission is hereby granted, free of charge, to any person, except B.\n"
+		apache = "Apache License\nVersion 2.0, January 2004\n"
+		v1     = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+		v2     = "v0.0.0-20231201000000-bbbbbbbbbbbb"
+	)
+
+	tests := []struct {
+		name    string
+		current map[string]string
+		latest  map[string]string
+		want    *LicenseChange
+	}{
+		{
+			name:    "copyright and formatting only",
+			current: map[string]string{"LICENSE": mit},
+			latest:  map[string]string{"LICENSE": mit2, "sub/LICENSE": apache},
+		},
+		{
+			name:    "relicensed",
+			current: map[string]string{"LICENSE": mit},
+			latest:  map[string]string{"LICENSE.md": apache},
+			want:    &LicenseChange{Current: "MIT", Latest: "Apache-2.0", TextChanged: true},
+		},
+		{
+			name:    "terms changed",
+			current: map[string]string{"LICENSE": mit},
+			latest:  map[string]string{"LICENSE": mitMod},
+			want:    &LicenseChange{Current: "MIT", Latest: "MIT", TextChanged: true},
+		},
+		{
+			name:    "license removed",
+			current: map[string]string{"LICENSE": mit, "COPYING": apache},
+			latest:  map[string]string{"go.mod": "module example.com/a\n"},
+			want: &LicenseChange{
+				Current:     "Apache-2.0 AND MIT",
+				Latest:      LicenseNone,
+				TextChanged: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &fakeModuleSource{t: t, files: map[string]map[string]string{
+				v1: tt.current,
+				v2: tt.latest,
+			}}
+			c := NewChecker(
+				WithResolver(fakeResolver{"example.com/a@main": v2}),
+				WithBranches(branchMain),
+				WithLogger(discardLogger()),
+				WithCompatibility(source),
+				WithLicenseCheck(source),
+			)
+
+			rep := c.Check(t.Context(), []Dependency{{Module: "example.com/a", Version: v1}})
+
+			if len(rep.Updates) != 1 {
+				t.Fatalf("got updates %v, want 1", rep.Updates)
+			}
+			if got := rep.Updates[0].LicenseChange; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			// The API and license checks share downloads.
+			if source.downloads != 2 {
+				t.Errorf("got %d downloads, want 2", source.downloads)
+			}
+		})
+	}
+}
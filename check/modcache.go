@@ -0,0 +1,84 @@
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// FindModCacheDir returns the go command's module cache directory (GOMODCACHE),
+// or "" if it cannot be determined.
+func FindModCacheDir(ctx context.Context) string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+
+	output, err := ExecRunner{}.Run(ctx, "go", "env", "GOMODCACHE")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// lookupModCache looks for a resolution of module@branch previously recorded
+// by the go command in the module cache. When the go command resolves a query
+// like module@main, it writes the result to
+// cache/download/<escaped module>/@v/<branch>.info. The resolution is only
+// used if that file was modified within maxAge of now.
+func lookupModCache(
+	modCacheDir,
+	modulePath,
+	branch string,
+	maxAge time.Duration,
+	now time.Time,
+) (string, bool) {
+	if modCacheDir == "" {
+		return "", false
+	}
+
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return "", false
+	}
+	escapedBranch, err := module.EscapeVersion(branch)
+	if err != nil {
+		return "", false
+	}
+
+	file := filepath.Join(
+		modCacheDir,
+		"cache",
+		"download",
+		filepath.FromSlash(escapedPath),
+		"@v",
+		escapedBranch+".info",
+	)
+
+	fi, err := os.Stat(file)
+	if err != nil {
+		return "", false
+	}
+	if now.Sub(fi.ModTime()) > maxAge {
+		return "", false
+	}
+
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return "", false
+	}
+
+	var info moduleInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return "", false
+	}
+	if info.Version == "" {
+		return "", false
+	}
+
+	return info.Version, true
+}
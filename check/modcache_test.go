@@ -0,0 +1,80 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLookupModCache(t *testing.T) {
+	modCacheDir := t.TempDir()
+
+	// github.com/Azure/... exercises module path case-encoding.
+	infoDir := filepath.Join(
+		modCacheDir,
+		"cache",
+		"download",
+		"github.com",
+		"!azure",
+		"example",
+		"@v",
+	)
+	if err := os.MkdirAll(infoDir, 0o755); err != nil {
+		t.Fatalf("creating cache dir: %v", err)
+	}
+	info := `{"Version":"v0.0.0-20231129151722-fdeea329fbba","Time":"2023-11-29T15:17:22Z"}`
+	infoFile := filepath.Join(infoDir, "main.info")
+	if err := os.WriteFile(infoFile, []byte(info), 0o644); err != nil {
+		t.Fatalf("writing info file: %v", err)
+	}
+
+	modTime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(infoFile, modTime, modTime); err != nil {
+		t.Fatalf("setting info file time: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		branch      string
+		now         time.Time
+		wantVersion string
+		wantOK      bool
+	}{
+		{
+			name:        "fresh entry",
+			branch:      branchMain,
+			now:         modTime.Add(time.Minute),
+			wantVersion: "v0.0.0-20231129151722-fdeea329fbba",
+			wantOK:      true,
+		},
+		{
+			name:   "stale entry",
+			branch: branchMain,
+			now:    modTime.Add(2 * time.Hour),
+		},
+		{
+			name:   "missing branch",
+			branch: branchMaster,
+			now:    modTime.Add(time.Minute),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, ok := lookupModCache(
+				modCacheDir,
+				"github.com/Azure/example",
+				tt.branch,
+				time.Hour,
+				tt.now,
+			)
+			if ok != tt.wantOK {
+				t.Fatalf("got ok %v, want %v", ok, tt.wantOK)
+			}
+			if version != tt.wantVersion {
+				t.Errorf("got version %q, want %q", version, tt.wantVersion)
+			}
+		})
+	}
+}
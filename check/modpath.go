@@ -0,0 +1,80 @@
+package check
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/mod/modfile"
+)
+
+// GoModSource provides the go.mod files of module versions.
+type GoModSource interface {
+	// GoMod returns the go.mod file of module@version, as served by module
+	// proxies.
+	GoMod(ctx context.Context, modulePath, version string) ([]byte, error)
+}
+
+// MoveDetector finds modules whose repositories have been renamed or
+// transferred.
+type MoveDetector interface {
+	// MovedPath returns the module's path at its repository's current
+	// location, or "" if the repository has not moved. If the module is not
+	// hosted where the detector can look, the error must wrap
+	// ErrUnsupportedHost.
+	MovedPath(ctx context.Context, modulePath string) (string, error)
+}
+
+// WithPathChangeCheck detects updated dependencies whose module path has
+// changed, so they can be imported under the new path rather than updated:
+// gomods is used to read the module path declared by the latest version's
+// go.mod file, and moves to find repositories that have been renamed or
+// transferred. Either may be nil. A failed lookup is logged and leaves
+// Update.DeclaredPath or Update.MovedTo empty. By default module paths are
+// not checked.
+func WithPathChangeCheck(gomods GoModSource, moves MoveDetector) Option {
+	return func(c *Checker) {
+		c.goModSource = gomods
+		c.moveDetector = moves
+	}
+}
+
+// maxGoModSize limits the size of go.mod files read from a GoModSource.
+const maxGoModSize = 16 << 20
+
+// declaredPath returns the module path declared in the go.mod file of the
+// module at version if it differs from modulePath, or "" if it does not.
+func (c *Checker) declaredPath(ctx context.Context, modulePath, version string) (string, error) {
+	release, err := c.limiter.acquire(ctx, modulePath)
+	if err != nil {
+		return "", err
+	}
+	data, err := c.goModSource.GoMod(ctx, modulePath, version)
+	release()
+	if err != nil {
+		return "", fmt.Errorf("downloading go.mod of %s@%s: %w", modulePath, version, err)
+	}
+
+	// A module without a go.mod file, or one that does not declare a path,
+	// is served with a synthesized go.mod declaring modulePath.
+	path := modfile.ModulePath(data)
+	if path == "" || path == modulePath {
+		return "", nil
+	}
+	return path, nil
+}
+
+// movedPath returns the module's path at its repository's new location, or ""
+// if the repository has not moved.
+func (c *Checker) movedPath(ctx context.Context, modulePath string) (string, error) {
+	release, err := c.limiter.acquire(ctx, modulePath)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	path, err := c.moveDetector.MovedPath(ctx, modulePath)
+	if err != nil || path == modulePath {
+		return "", err
+	}
+	return path, nil
+}
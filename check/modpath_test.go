@@ -0,0 +1,86 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+type goModSourceFunc func(modulePath, version string) ([]byte, error)
+
+func (f goModSourceFunc) GoMod(_ context.Context, modulePath, version string) ([]byte, error) {
+	return f(modulePath, version)
+}
+
+type moveDetectorFunc func(modulePath string) (string, error)
+
+func (f moveDetectorFunc) MovedPath(_ context.Context, modulePath string) (string, error) {
+	return f(modulePath)
+}
+
+func TestWithPathChangeCheck(t *testing.T) {
+	const latest = "v0.0.0-20231201000000-bbbbbbbbbbbb"
+	gomods := map[string]string{
+		"github.com/old/renamed": "module github.com/new/renamed\n\ngo 1.21\n",
+		"github.com/old/moved":   "module github.com/old/moved\n",
+		"example.com/nogomod":    "module example.com/nogomod\n",
+		"example.com/empty":      "",
+	}
+
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"github.com/old/renamed@main": latest,
+			"github.com/old/moved@main":   latest,
+			"example.com/nogomod@main":    latest,
+			"example.com/empty@main":      latest,
+		}),
+		WithBranches(branchMain),
+		WithPathChangeCheck(
+			goModSourceFunc(func(modulePath, version string) ([]byte, error) {
+				if version != latest {
+					t.Errorf("got go.mod request for %s, want %s", version, latest)
+				}
+				return []byte(gomods[modulePath]), nil
+			}),
+			moveDetectorFunc(func(modulePath string) (string, error) {
+				switch modulePath {
+				case "github.com/old/moved":
+					return "github.com/new/moved", nil
+				case "github.com/old/renamed":
+					return modulePath, nil
+				default:
+					return "", fmt.Errorf("%s: %w", modulePath, ErrUnsupportedHost)
+				}
+			}),
+		),
+	)
+
+	var deps []Dependency
+	for _, m := range []string{
+		"github.com/old/renamed",
+		"github.com/old/moved",
+		"example.com/nogomod",
+		"example.com/empty",
+	} {
+		deps = append(deps, Dependency{Module: m, Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"})
+	}
+	rep := c.Check(t.Context(), deps)
+
+	if len(rep.Updates) != len(deps) {
+		t.Fatalf("got updates %+v, want %d", rep.Updates, len(deps))
+	}
+	want := []struct{ declared, moved string }{
+		{declared: "github.com/new/renamed"},
+		{moved: "github.com/new/moved"},
+		{},
+		{},
+	}
+	for i, u := range rep.Updates {
+		if u.DeclaredPath != want[i].declared || u.MovedTo != want[i].moved {
+			t.Errorf(
+				"%s: got declared path %q and moved to %q, want %q and %q",
+				u.Module, u.DeclaredPath, u.MovedTo, want[i].declared, want[i].moved,
+			)
+		}
+	}
+}
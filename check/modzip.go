@@ -0,0 +1,46 @@
+package check
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+)
+
+// ModuleSource provides the source code of module versions.
+type ModuleSource interface {
+	// Download returns the module zip for module@version, in the format
+	// served by module proxies.
+	Download(ctx context.Context, modulePath, version string) (*zip.Reader, error)
+}
+
+// moduleZips downloads versions of one module from the Checker's
+// ModuleSource, at most once each, so that checks inspecting module contents
+// (such as API compatibility and license changes) share downloads.
+type moduleZips struct {
+	c          *Checker
+	modulePath string
+	zips       map[string]*zip.Reader
+}
+
+func (c *Checker) newModuleZips(modulePath string) *moduleZips {
+	return &moduleZips{c: c, modulePath: modulePath, zips: map[string]*zip.Reader{}}
+}
+
+// get returns the zip of the module at version.
+func (z *moduleZips) get(ctx context.Context, version string) (*zip.Reader, error) {
+	if zr, ok := z.zips[version]; ok {
+		return zr, nil
+	}
+
+	release, err := z.c.limiter.acquire(ctx, z.modulePath)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := z.c.moduleSource.Download(ctx, z.modulePath, version)
+	release()
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s@%s: %w", z.modulePath, version, err)
+	}
+	z.zips[version] = zr
+	return zr, nil
+}
@@ -0,0 +1,118 @@
+package check
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultOSVURL is the OSV.dev API's URL.
+const DefaultOSVURL = "https://api.osv.dev"
+
+// OSVClient is a VulnSource using the OSV.dev API
+// (https://google.github.io/osv.dev/api/). It queries by module path and
+// version, so no Go toolchain or database index is needed, and includes
+// advisories from sources other than the Go vulnerability database, such as
+// GitHub's, which often rate severity.
+type OSVClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewOSVClient returns a client for the OSV API at baseURL (DefaultOSVURL if
+// empty). If client is nil, a client with a one minute timeout is used.
+func NewOSVClient(baseURL string, client *http.Client) *OSVClient {
+	if baseURL == "" {
+		baseURL = DefaultOSVURL
+	}
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	return &OSVClient{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+// osvQuery is a request to the query endpoint.
+type osvQuery struct {
+	Version string `json:"version"`
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	PageToken string `json:"page_token,omitempty"`
+}
+
+// Vulns implements VulnSource.
+func (o *OSVClient) Vulns(ctx context.Context, modulePath, version string) ([]OSVEntry, error) {
+	q := osvQuery{Version: version}
+	q.Package.Name = modulePath
+	q.Package.Ecosystem = "Go"
+
+	var entries []OSVEntry
+	for {
+		var resp struct {
+			Vulns         []OSVEntry `json:"vulns"`
+			NextPageToken string     `json:"next_page_token"`
+		}
+		if err := o.post(ctx, "/v1/query", q, &resp); err != nil {
+			return nil, err
+		}
+		entries = append(entries, resp.Vulns...)
+		if resp.NextPageToken == "" {
+			return entries, nil
+		}
+		q.PageToken = resp.NextPageToken
+	}
+}
+
+// post sends in as JSON to path and decodes the JSON response into out.
+func (o *OSVClient) post(ctx context.Context, path string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		o.baseURL+path,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("querying OSV: %w", err)
+		if isTimeout(err) {
+			return classify(ErrTimeout, err)
+		}
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		msg := resp.Status
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			msg = apiErr.Message
+		}
+		return classify(classifyStatus(resp.StatusCode, ""), errors.New("querying OSV: "+msg))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("parsing OSV response: %w", err)
+	}
+	return nil
+}
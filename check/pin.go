@@ -0,0 +1,95 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/mod/module"
+)
+
+// CommitFinder looks up commits in module repositories.
+type CommitFinder interface {
+	// CommitExists reports whether the commit rev (a hash, or a prefix of
+	// one) exists in the module's repository. It returns false only if the
+	// repository exists but the commit does not. If the repository does not
+	// exist, the error must wrap ErrModuleNotFound. If the module is not
+	// hosted where the finder can look, the error must wrap
+	// ErrUnsupportedHost.
+	CommitExists(ctx context.Context, modulePath, rev string) (bool, error)
+}
+
+// WithPinCheck checks whether the commit each dependency is pinned to still
+// exists upstream. A commit that has vanished, because the repository's
+// history was rewritten or the repository was deleted, is a supply chain red
+// flag: the module proxy may still serve it, but its source can no longer be
+// audited.
+//
+// If a dependency has an update, a vanished pin is recorded in
+// Update.PinVanished, and Latest is the version to re-pin to. If resolving
+// the dependency failed because the module or branch was not found, and its
+// pinned commit or repository is gone, the Failure wraps ErrPinVanished
+// instead. A failed lookup is logged. By default pins are not checked.
+func WithPinCheck(finder CommitFinder) Option {
+	return func(c *Checker) {
+		c.commitFinder = finder
+	}
+}
+
+// pinVanished reports whether the commit a dependency is pinned to no longer
+// exists upstream. If the repository itself is gone, the error wraps
+// ErrModuleNotFound.
+func (c *Checker) pinVanished(ctx context.Context, dep Dependency) (bool, error) {
+	rev, err := module.PseudoVersionRev(dep.Version)
+	if err != nil {
+		return false, fmt.Errorf("parsing version %q: %w", dep.Version, err)
+	}
+
+	release, err := c.limiter.acquire(ctx, dep.Module)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	exists, err := c.commitFinder.CommitExists(ctx, dep.Module, rev)
+	if err != nil {
+		return false, err
+	}
+	return !exists, nil
+}
+
+// checkPin records in res whether the dependency's pinned commit has
+// vanished.
+func (c *Checker) checkPin(ctx context.Context, res *Result) {
+	if !res.HasUpdate() &&
+		!errors.Is(res.Err, ErrModuleNotFound) &&
+		!errors.Is(res.Err, ErrBranchNotFound) {
+		return
+	}
+	dep := res.Dependency
+
+	vanished, err := c.pinVanished(ctx, dep)
+	switch {
+	case err == nil && !vanished:
+	case err == nil && res.Err == nil:
+		res.PinVanished = true
+	case err == nil:
+		// Failures wrap at most one sentinel error, so res.Err is not
+		// wrapped.
+		res.Err = classify(ErrPinVanished, fmt.Errorf(
+			"pinned commit of %s no longer exists upstream (history rewritten?): %v",
+			dep.Version,
+			res.Err,
+		))
+	case res.Err != nil && errors.Is(err, ErrModuleNotFound):
+		res.Err = classify(ErrPinVanished, fmt.Errorf(
+			"repository no longer exists upstream, so pinned commit of %s cannot be fetched: %v",
+			dep.Version,
+			res.Err,
+		))
+	case errors.Is(err, ErrUnsupportedHost):
+		c.log().Debug("cannot check pinned commit", "module", dep.Module, "error", err)
+	default:
+		c.log().Warn("checking pinned commit failed", "module", dep.Module, "error", err)
+	}
+}
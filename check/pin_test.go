@@ -0,0 +1,90 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type commitFinderFunc func(modulePath, rev string) (bool, error)
+
+func (f commitFinderFunc) CommitExists(_ context.Context, modulePath, rev string) (bool, error) {
+	return f(modulePath, rev)
+}
+
+func TestWithPinCheck(t *testing.T) {
+	const (
+		current = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+		latest  = "v0.0.0-20231201000000-bbbbbbbbbbbb"
+	)
+
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"github.com/example/intact@main":    latest,
+			"github.com/example/rewritten@main": latest,
+			"github.com/example/broken@main":    "error",
+			"example.com/elsewhere@main":        latest,
+		}),
+		WithBranches(branchMain),
+		WithPinCheck(commitFinderFunc(func(modulePath, rev string) (bool, error) {
+			if rev != "aaaaaaaaaaaa" {
+				t.Errorf("got revision %q, want the pinned one", rev)
+			}
+			switch modulePath {
+			case "github.com/example/intact", "github.com/example/broken":
+				return true, nil
+			case "github.com/example/rewritten", "github.com/example/renamed-branch":
+				return false, nil
+			case "github.com/example/deleted":
+				return false, fmt.Errorf("%s: %w", modulePath, ErrModuleNotFound)
+			default:
+				return false, fmt.Errorf("%s: %w", modulePath, ErrUnsupportedHost)
+			}
+		})),
+	)
+
+	var deps []Dependency
+	for _, m := range []string{
+		"github.com/example/intact",
+		"github.com/example/rewritten",
+		"github.com/example/broken",
+		"example.com/elsewhere",
+		"github.com/example/renamed-branch",
+		"github.com/example/deleted",
+	} {
+		deps = append(deps, Dependency{Module: m, Version: current})
+	}
+	rep := c.Check(t.Context(), deps)
+
+	vanished := map[string]bool{}
+	for _, u := range rep.Updates {
+		vanished[u.Module] = u.PinVanished
+	}
+	wantVanished := map[string]bool{
+		"github.com/example/intact":    false,
+		"github.com/example/rewritten": true,
+		"example.com/elsewhere":        false,
+	}
+	if fmt.Sprint(vanished) != fmt.Sprint(wantVanished) {
+		t.Errorf("got updates with vanished pins %v, want %v", vanished, wantVanished)
+	}
+
+	codes := map[string]string{}
+	for _, f := range rep.Failures {
+		codes[f.Module] = ErrorCode(f.Err)
+	}
+	wantCodes := map[string]string{
+		"github.com/example/broken":         CodeUnknown,
+		"github.com/example/renamed-branch": CodePinVanished,
+		"github.com/example/deleted":        CodePinVanished,
+	}
+	if fmt.Sprint(codes) != fmt.Sprint(wantCodes) {
+		t.Errorf("got failure codes %v, want %v", codes, wantCodes)
+	}
+	for _, f := range rep.Failures {
+		if errors.Is(f.Err, ErrPinVanished) && errors.Is(f.Err, ErrBranchNotFound) {
+			t.Errorf("%s: error %v wraps more than one sentinel error", f.Module, f.Err)
+		}
+	}
+}
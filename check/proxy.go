@@ -0,0 +1,397 @@
+package check
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+const defaultProxyURL = "https://proxy.golang.org"
+
+// maxErrorBodySize limits how much of an error response body from the proxy
+// is included in error messages.
+const maxErrorBodySize = 4096
+
+// Timeouts for proxy requests, including reading the response body. Module
+// zips may be up to maxModuleZipSize, so they have longer than the small
+// files, such as .info files, that the other requests fetch.
+const (
+	proxyRequestTimeout  = time.Minute
+	proxyDownloadTimeout = 10 * time.Minute
+)
+
+// ProxyResolver resolves queries by requesting
+// $GOPROXY/<module>/@v/<query>.info from a module proxy directly, without
+// needing a Go toolchain or git.
+//
+// Like the go command, it tries the proxies in a GOPROXY list in order. After
+// a proxy separated from the next by a comma, it only moves on if the proxy
+// responds 404 Not Found or 410 Gone; after one separated by a pipe, it moves
+// on after any error. "off" fails the lookup. The resolver cannot fetch from
+// version control, so "direct", and modules matching GONOPROXY (GOPRIVATE by
+// default), which the go command fetches directly, fail too. If an earlier
+// proxy did not have the module, that error is returned instead, so that
+// with the default GOPROXY of https://proxy.golang.org,direct a missing
+// branch is still reported as not found.
+type ProxyResolver struct {
+	proxies []proxySpec
+	noProxy string
+	client  *http.Client
+	logger  *slog.Logger
+
+	requestTimeout  time.Duration
+	downloadTimeout time.Duration
+}
+
+// proxySpec is an entry in a GOPROXY list.
+type proxySpec struct {
+	// url is the proxy's base URL, or "direct" or "off".
+	url string
+	// fallBackOnError is set if the entry is followed by a pipe rather than a
+	// comma, so any error moves on to the next entry.
+	fallBackOnError bool
+}
+
+// NewProxyResolver returns a resolver querying the proxies listed in GOPROXY
+// (or proxy.golang.org if GOPROXY is unset), except for modules matching
+// GONOPROXY or GOPRIVATE. maxConns is the expected number of concurrent
+// requests. logger may be nil.
+func NewProxyResolver(maxConns int, logger *slog.Logger) (*ProxyResolver, error) {
+	goproxy := os.Getenv("GOPROXY")
+	if goproxy == "" {
+		goproxy = defaultProxyURL + ",direct"
+	}
+	proxies, err := parseGOPROXY(goproxy)
+	if err != nil {
+		return nil, err
+	}
+	if url := proxies[0].url; url == "direct" || url == "off" {
+		return nil, fmt.Errorf("GOPROXY starts with %q; the proxy resolver needs a proxy URL", url)
+	}
+
+	r := NewProxyResolverWithClient("", newProxyClient(maxConns), logger)
+	r.proxies = proxies
+	r.noProxy = os.Getenv("GONOPROXY")
+	if r.noProxy == "" {
+		r.noProxy = os.Getenv("GOPRIVATE")
+	}
+	return r, nil
+}
+
+// NewProxyResolverWithClient returns a resolver querying the proxy at baseURL
+// with client. This allows using a custom transport, such as one serving
+// canned responses in tests. logger may be nil.
+func NewProxyResolverWithClient(
+	baseURL string,
+	client *http.Client,
+	logger *slog.Logger,
+) *ProxyResolver {
+	if logger == nil {
+		logger = discardLogger()
+	}
+	return &ProxyResolver{
+		proxies:         []proxySpec{{url: strings.TrimRight(baseURL, "/")}},
+		client:          client,
+		logger:          logger,
+		requestTimeout:  proxyRequestTimeout,
+		downloadTimeout: proxyDownloadTimeout,
+	}
+}
+
+// parseGOPROXY parses a GOPROXY list as the go command does. Entries after
+// "direct" or "off" are ignored, and a proxy URL without a scheme gets
+// https://.
+func parseGOPROXY(goproxy string) ([]proxySpec, error) {
+	var proxies []proxySpec
+	for goproxy != "" {
+		var p proxySpec
+		if i := strings.IndexAny(goproxy, ",|"); i >= 0 {
+			p.url, p.fallBackOnError, goproxy = goproxy[:i], goproxy[i] == '|', goproxy[i+1:]
+		} else {
+			p.url, goproxy = goproxy, ""
+		}
+		p.url = strings.TrimSpace(p.url)
+		switch {
+		case p.url == "":
+			continue
+		case p.url == "direct" || p.url == "off":
+			return append(proxies, p), nil
+		case !strings.Contains(p.url, ":/"):
+			p.url = "https://" + p.url
+		}
+		p.url = strings.TrimRight(p.url, "/")
+		proxies = append(proxies, p)
+	}
+	if len(proxies) == 0 {
+		return nil, errors.New("GOPROXY list is not the empty string, but contains no entries")
+	}
+	return proxies, nil
+}
+
+// newProxyClient returns an HTTP client shared by all proxy requests in a run
+// so connections are reused. Over HTTP/2 (which proxy.golang.org supports),
+// concurrent requests to the same proxy are multiplexed on one connection.
+// The client has no overall timeout, since each request has its own (see
+// proxyRequestTimeout), but a proxy must start responding within
+// proxyRequestTimeout.
+func newProxyClient(maxConns int) *http.Client {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return &http.Client{}
+	}
+	transport = transport.Clone()
+	transport.ForceAttemptHTTP2 = true
+	transport.MaxIdleConnsPerHost = max(maxConns, 2)
+	transport.ResponseHeaderTimeout = proxyRequestTimeout
+
+	return &http.Client{Transport: transport}
+}
+
+// Resolve implements Resolver.
+func (r *ProxyResolver) Resolve(ctx context.Context, modulePath, branch string) (string, error) {
+	resp, err := r.get(ctx, modulePath, branch, ".info")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	var info moduleInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", fmt.Errorf("parsing module info: %w", err)
+	}
+	if info.Version == "" {
+		return "", errors.New("proxy returned no version")
+	}
+
+	return info.Version, nil
+}
+
+// maxModuleZipSize is the largest module zip the proxy may serve (see
+// golang.org/x/mod/zip).
+const maxModuleZipSize = 500 << 20
+
+// Download implements ModuleSource by fetching the module's zip file from the
+// proxy. The zip is streamed to a temporary file rather than held in memory,
+// and the returned reader reads from that file. The file is removed once it
+// is open, where the operating system allows it, and closed when the reader
+// is garbage collected.
+func (r *ProxyResolver) Download(
+	ctx context.Context,
+	modulePath,
+	version string,
+) (*zip.Reader, error) {
+	resp, err := r.get(ctx, modulePath, version, ".zip")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	f, err := os.CreateTemp("", "check-untagged-go-deps-*.zip")
+	if err != nil {
+		return nil, fmt.Errorf("creating temporary file: %w", err)
+	}
+	_ = os.Remove(f.Name())
+
+	size, err := io.Copy(f, io.LimitReader(resp.Body, maxModuleZipSize+1))
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("downloading module zip: %w", err)
+	}
+	if size > maxModuleZipSize {
+		_ = f.Close()
+		return nil, fmt.Errorf("module zip for %s@%s is too large", modulePath, version)
+	}
+
+	zr, err := zip.NewReader(f, size)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("reading module zip: %w", err)
+	}
+	runtime.AddCleanup(zr, func(f *os.File) { _ = f.Close() }, f)
+	return zr, nil
+}
+
+// GoMod implements GoModSource by fetching the module's go.mod file from the
+// proxy.
+func (r *ProxyResolver) GoMod(ctx context.Context, modulePath, version string) ([]byte, error) {
+	resp, err := r.get(ctx, modulePath, version, ".mod")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxGoModSize))
+	if err != nil {
+		return nil, fmt.Errorf("downloading go.mod: %w", err)
+	}
+	return data, nil
+}
+
+// maxVersionListSize limits the size of version lists read from the proxy.
+const maxVersionListSize = 1 << 20
+
+// Versions implements TagLister by fetching the module's version list from
+// the proxy.
+func (r *ProxyResolver) Versions(ctx context.Context, modulePath string) ([]string, error) {
+	resp, err := r.get(ctx, modulePath, "list", "")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxVersionListSize))
+	if err != nil {
+		return nil, fmt.Errorf("downloading version list: %w", err)
+	}
+	return strings.Fields(string(data)), nil
+}
+
+// get requests $GOPROXY/<module>/@v/<query><suffix> from the proxies in turn
+// (see ProxyResolver) and returns the first response that succeeded. The
+// caller must close its body.
+func (r *ProxyResolver) get(
+	ctx context.Context,
+	modulePath,
+	query,
+	suffix string,
+) (*http.Response, error) {
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return nil, fmt.Errorf("escaping module path: %w", err)
+	}
+	// Upper-case letters in the path and query are case-encoded (Azure as
+	// !azure) for case-insensitive file systems, as the go command does.
+	// Queries such as branches containing a slash cannot be encoded at all,
+	// so like the go command, the resolver cannot look them up on a proxy.
+	escapedQuery, err := module.EscapeVersion(query)
+	if err != nil {
+		return nil, classify(
+			ErrBranchNotFound,
+			fmt.Errorf("%s cannot be requested from a module proxy: %w", query, err),
+		)
+	}
+
+	if module.MatchPrefixPatterns(r.noProxy, modulePath) {
+		return nil, fmt.Errorf(
+			"%s matches GONOPROXY or GOPRIVATE; the proxy resolver cannot fetch it directly",
+			modulePath,
+		)
+	}
+
+	timeout := r.requestTimeout
+	if suffix == ".zip" {
+		timeout = r.downloadTimeout
+	}
+
+	var (
+		bestErr      error
+		bestNotFound bool
+	)
+	for _, p := range r.proxies {
+		switch p.url {
+		case "direct":
+			if bestErr == nil {
+				bestErr = errors.New("GOPROXY reached direct; " +
+					"the proxy resolver cannot fetch from version control")
+			}
+			return nil, bestErr
+		case "off":
+			if bestErr == nil {
+				bestErr = errors.New("module lookup disabled by GOPROXY=off")
+			}
+			return nil, bestErr
+		}
+
+		u := p.url + "/" + escapedPath + "/@v/" + url.PathEscape(escapedQuery) + suffix
+		resp, notFound, err := r.getURL(ctx, u, timeout)
+		if err == nil {
+			return resp, nil
+		}
+		// As for the go command, an error other than not found takes
+		// precedence over not found, and otherwise the first error does.
+		if bestErr == nil || !notFound && bestNotFound {
+			bestErr, bestNotFound = err, notFound
+		}
+		if !notFound && !p.fallBackOnError {
+			break
+		}
+	}
+	return nil, bestErr
+}
+
+// getURL requests u and returns the response if it succeeded, and whether it
+// failed because the proxy does not have it. The request, including reading
+// the body, must finish within timeout. The caller must close the response's
+// body.
+func (r *ProxyResolver) getURL(
+	ctx context.Context,
+	u string,
+	timeout time.Duration,
+) (*http.Response, bool, error) {
+	r.logger.Debug("querying proxy", "url", u)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		cancel()
+		return nil, false, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		cancel()
+		err = fmt.Errorf("querying proxy: %w", err)
+		if isTimeout(err) {
+			return nil, false, classify(ErrTimeout, err)
+		}
+		return nil, false, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		_ = resp.Body.Close()
+		cancel()
+		msg := strings.TrimSpace(string(body))
+		if msg == "" {
+			msg = resp.Status
+		}
+		notFound := resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone
+		return nil, notFound, classify(classifyStatus(resp.StatusCode, msg), errors.New(msg))
+	}
+
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, false, nil
+}
+
+// cancelOnClose cancels a request's context when its response body is
+// closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,379 @@
+package check
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProxyResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/go4.org/netipx/@v/main.info":
+			fmt.Fprint(
+				w,
+				`{"Version":"v0.0.0-20231129151722-fdeea329fbba","Time":"2023-11-29T15:17:22Z"}`,
+			)
+		case "/github.com/!azure/example/@v/main.info",
+			"/github.com/!azure/example/@v/!feature-!x.info":
+			fmt.Fprint(
+				w,
+				`{"Version":"v0.0.0-20240101000000-aaaaaaaaaaaa","Time":"2024-01-01T00:00:00Z"}`,
+			)
+		default:
+			http.Error(w, "not found: unknown revision", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	r := NewProxyResolverWithClient(server.URL+"/", server.Client(), nil)
+
+	tests := []struct {
+		name        string
+		module      string
+		branch      string
+		want        string
+		errContains string
+		wantErr     error
+	}{
+		{
+			name:   "resolves branch",
+			module: "go4.org/netipx",
+			branch: branchMain,
+			want:   "v0.0.0-20231129151722-fdeea329fbba",
+		},
+		{
+			name:   "escapes uppercase module path",
+			module: "github.com/Azure/example",
+			branch: branchMain,
+			want:   "v0.0.0-20240101000000-aaaaaaaaaaaa",
+		},
+		{
+			name:   "escapes uppercase branch",
+			module: "github.com/Azure/example",
+			branch: "Feature-X",
+			want:   "v0.0.0-20240101000000-aaaaaaaaaaaa",
+		},
+		{
+			name:        "branch with a slash",
+			module:      "go4.org/netipx",
+			branch:      "release/1.29",
+			errContains: "cannot be requested from a module proxy",
+			wantErr:     ErrBranchNotFound,
+		},
+		{
+			name:        "missing branch",
+			module:      "go4.org/netipx",
+			branch:      branchMaster,
+			errContains: "unknown revision",
+			wantErr:     ErrBranchNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Resolve(t.Context(), tt.module, tt.branch)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("got error %v, want error containing %q", err, tt.errContains)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("got error %v, want it to match %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolve: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxyResolverVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github.com/!azure/example/@v/list":
+			fmt.Fprint(w, "v1.0.0\nv1.1.0\n")
+		case "/go4.org/netipx/@v/list":
+			// Modules without tags have an empty list.
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	r := NewProxyResolverWithClient(server.URL, server.Client(), nil)
+
+	got, err := r.Versions(t.Context(), "github.com/Azure/example")
+	if err != nil {
+		t.Fatalf("Versions: %v", err)
+	}
+	if want := []string{"v1.0.0", "v1.1.0"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	got, err = r.Versions(t.Context(), "go4.org/netipx")
+	if err != nil || len(got) != 0 {
+		t.Errorf("got %q, %v, want no versions", got, err)
+	}
+
+	if _, err := r.Versions(t.Context(), "example.com/gone"); !errors.Is(err, ErrModuleNotFound) {
+		t.Errorf("got error %v, want it to match ErrModuleNotFound", err)
+	}
+}
+
+func TestProxyResolverDownload(t *testing.T) {
+	var zipData bytes.Buffer
+	zw := zip.NewWriter(&zipData)
+	fw, err := zw.Create("example.com/mod@v1.0.0/LICENSE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write([]byte("MIT License")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/mod/@v/v1.0.0.zip":
+			// Slower than the request timeout, but within the download
+			// timeout.
+			time.Sleep(100 * time.Millisecond)
+			_, _ = w.Write(zipData.Bytes())
+		case "/example.com/mod/@v/main.info":
+			time.Sleep(100 * time.Millisecond)
+			fmt.Fprint(w, `{"Version":"v0.0.0-20240101000000-aaaaaaaaaaaa"}`)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	r := NewProxyResolverWithClient(server.URL, server.Client(), nil)
+	r.requestTimeout = 20 * time.Millisecond
+	r.downloadTimeout = 5 * time.Second
+
+	zr, err := r.Download(t.Context(), "example.com/mod", "v1.0.0")
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if len(zr.File) != 1 || zr.File[0].Name != "example.com/mod@v1.0.0/LICENSE" {
+		t.Fatalf("got files %v, want the LICENSE file", zr.File)
+	}
+	rc, err := zr.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(rc)
+	_ = rc.Close()
+	if err != nil || string(data) != "MIT License" {
+		t.Errorf("got %q, %v, want the license text", data, err)
+	}
+
+	_, err = r.Resolve(t.Context(), "example.com/mod", branchMain)
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("got error %v, want it to match ErrTimeout", err)
+	}
+}
+
+func TestNewProxyResolver(t *testing.T) {
+	tests := []struct {
+		goproxy string
+		want    string
+		wantErr bool
+	}{
+		{goproxy: "", want: defaultProxyURL},
+		{goproxy: "https://proxy.example.com/,direct", want: "https://proxy.example.com"},
+		{goproxy: "https://a.example.com|https://b.example.com", want: "https://a.example.com"},
+		{goproxy: "direct", wantErr: true},
+		{goproxy: "off", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goproxy, func(t *testing.T) {
+			t.Setenv("GOPROXY", tt.goproxy)
+
+			r, err := NewProxyResolver(1, discardLogger())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newProxyResolver: %v", err)
+			}
+			if got := r.proxies[0].url; got != tt.want {
+				t.Errorf("got base URL %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGOPROXY(t *testing.T) {
+	tests := []struct {
+		goproxy string
+		want    []proxySpec
+		wantErr bool
+	}{
+		{
+			goproxy: "https://a.example.com/,https://b.example.com|direct,https://ignored",
+			want: []proxySpec{
+				{url: "https://a.example.com"},
+				{url: "https://b.example.com", fallBackOnError: true},
+				{url: "direct"},
+			},
+		},
+		{
+			goproxy: "proxy.example.com/go| off",
+			want: []proxySpec{
+				{url: "https://proxy.example.com/go", fallBackOnError: true},
+				{url: "off"},
+			},
+		},
+		{goproxy: " , |", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseGOPROXY(tt.goproxy)
+		if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+			t.Errorf("parseGOPROXY(%q) = %+v, %v, want %+v", tt.goproxy, got, err, tt.want)
+		}
+	}
+}
+
+func TestProxyResolverFallback(t *testing.T) {
+	const version = `{"Version":"v0.0.0-20240101000000-aaaaaaaaaaaa"}`
+	// The corporate proxy only has internal modules, and errors on others.
+	corporate := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/corp.example.com/lib/@v/main.info":
+			fmt.Fprint(w, version)
+		case "/github.com/gone/repo/@v/main.info":
+			http.Error(w, "not found: repository gone", http.StatusGone)
+		default:
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+		}
+	}))
+	defer corporate.Close()
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/github.com/example/repo/@v/main.info" {
+			fmt.Fprint(w, version)
+			return
+		}
+		http.Error(w, "not found: unknown revision main", http.StatusNotFound)
+	}))
+	defer public.Close()
+
+	tests := []struct {
+		name    string
+		goproxy string
+		module  string
+		// errContains is empty if the module resolves.
+		errContains string
+		wantErr     error
+	}{
+		{
+			name:    "first proxy has it",
+			goproxy: corporate.URL + "," + public.URL,
+			module:  "corp.example.com/lib",
+		},
+		{
+			name:        "comma does not fall back on other errors",
+			goproxy:     corporate.URL + "," + public.URL,
+			module:      "github.com/example/repo",
+			errContains: "bad gateway",
+		},
+		{
+			name:    "pipe falls back on any error",
+			goproxy: corporate.URL + "|" + public.URL,
+			module:  "github.com/example/repo",
+		},
+		{
+			name:    "comma falls back on 410",
+			goproxy: corporate.URL + "," + public.URL,
+			module:  "github.com/gone/repo",
+			// Both are not found, so the first proxy's error is returned.
+			errContains: "repository gone",
+			wantErr:     ErrModuleNotFound,
+		},
+		{
+			name:        "an error takes precedence over not found",
+			goproxy:     public.URL + "|" + corporate.URL,
+			module:      "github.com/other/repo",
+			errContains: "bad gateway",
+		},
+		{
+			name:        "not found before direct",
+			goproxy:     public.URL + ",direct",
+			module:      "github.com/other/repo",
+			errContains: "unknown revision",
+			wantErr:     ErrBranchNotFound,
+		},
+		{
+			name:        "off",
+			goproxy:     "off," + public.URL,
+			module:      "github.com/example/repo",
+			errContains: "disabled by GOPROXY=off",
+		},
+		{
+			name:        "off after not found",
+			goproxy:     public.URL + ",off",
+			module:      "github.com/other/repo",
+			errContains: "unknown revision",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxies, err := parseGOPROXY(tt.goproxy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := NewProxyResolverWithClient("", http.DefaultClient, nil)
+			r.proxies = proxies
+
+			_, err = r.Resolve(t.Context(), tt.module, branchMain)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Fatalf("Resolve: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Fatalf("got error %v, want error containing %q", err, tt.errContains)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v, want it to match %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewProxyResolverNoProxy(t *testing.T) {
+	t.Setenv("GOPROXY", "https://proxy.example.com")
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GOPRIVATE", "corp.example.com")
+
+	r, err := NewProxyResolver(1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Resolve(t.Context(), "corp.example.com/lib", branchMain)
+	if err == nil || !strings.Contains(err.Error(), "GONOPROXY") {
+		t.Errorf("got error %v, want one for GONOPROXY", err)
+	}
+}
@@ -0,0 +1,127 @@
+package check
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HostLimiter limits how many queries may be in flight to a single host and
+// paces the start of successive queries to that host. This avoids tripping
+// rate limits (such as GitHub's secondary rate limits) when many dependencies
+// come from the same host. A nil *HostLimiter imposes no limits.
+type HostLimiter struct {
+	maxConcurrent int
+	interval      time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	sem chan struct{}
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewHostLimiter returns a limiter allowing maxConcurrent queries per host,
+// started at least interval apart. If maxConcurrent is not positive, the
+// number of concurrent queries is not limited.
+func NewHostLimiter(maxConcurrent int, interval time.Duration) *HostLimiter {
+	if maxConcurrent <= 0 && interval <= 0 {
+		return nil
+	}
+
+	return &HostLimiter{
+		maxConcurrent: maxConcurrent,
+		interval:      interval,
+		hosts:         map[string]*hostState{},
+	}
+}
+
+// acquire blocks until a query to the host serving modulePath may start. The
+// returned function must be called when the query completes.
+func (l *HostLimiter) acquire(ctx context.Context, modulePath string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	state := l.state(ModuleHost(modulePath))
+
+	if state.sem != nil {
+		select {
+		case state.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	release := func() {
+		if state.sem != nil {
+			<-state.sem
+		}
+	}
+
+	if err := state.wait(ctx, l.interval); err != nil {
+		release()
+		return nil, err
+	}
+
+	return release, nil
+}
+
+func (l *HostLimiter) state(host string) *hostState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state, ok := l.hosts[host]
+	if !ok {
+		state = &hostState{}
+		if l.maxConcurrent > 0 {
+			state.sem = make(chan struct{}, l.maxConcurrent)
+		}
+		l.hosts[host] = state
+	}
+	return state
+}
+
+// wait reserves the next start slot for the host and sleeps until it arrives.
+func (s *hostState) wait(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	now := time.Now()
+	start := s.next
+	if start.Before(now) {
+		start = now
+	}
+	s.next = start.Add(interval)
+	s.mu.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ModuleHost returns the host portion of a module path, e.g. "github.com" for
+// "github.com/foo/bar". Module paths that are not on a host, such as those
+// of the standard library, are returned whole.
+func ModuleHost(modulePath string) string {
+	host, _, _ := strings.Cut(modulePath, "/")
+	return host
+}
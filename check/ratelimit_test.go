@@ -0,0 +1,105 @@
+package check
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHostLimiterConcurrency(t *testing.T) {
+	limiter := NewHostLimiter(2, 0)
+	ctx := t.Context()
+
+	var inFlight, peak atomic.Int32
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			release, err := limiter.acquire(ctx, "github.com/foo/bar")
+			if err != nil {
+				t.Errorf("acquire: %v", err)
+				return
+			}
+			defer release()
+
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			inFlight.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got > 2 {
+		t.Errorf("got %d concurrent queries, want at most 2", got)
+	}
+}
+
+func TestHostLimiterPacing(t *testing.T) {
+	const interval = 20 * time.Millisecond
+	limiter := NewHostLimiter(0, interval)
+	ctx := t.Context()
+
+	start := time.Now()
+	for range 3 {
+		release, err := limiter.acquire(ctx, "github.com/foo/bar")
+		if err != nil {
+			t.Fatalf("acquire: %v", err)
+		}
+		release()
+	}
+
+	// The first query starts immediately, the next two wait one interval each.
+	if elapsed := time.Since(start); elapsed < 2*interval {
+		t.Errorf("3 queries took %v, want at least %v", elapsed, 2*interval)
+	}
+
+	// A different host is paced independently.
+	start = time.Now()
+	release, err := limiter.acquire(ctx, "go4.org/netipx")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	release()
+	if elapsed := time.Since(start); elapsed >= interval {
+		t.Errorf("query to another host waited %v", elapsed)
+	}
+}
+
+func TestHostLimiterCanceled(t *testing.T) {
+	limiter := NewHostLimiter(1, 0)
+
+	release, err := limiter.acquire(t.Context(), "github.com/foo/bar")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := limiter.acquire(ctx, "github.com/foo/bar"); err == nil {
+		t.Error("expected error acquiring with a canceled context")
+	}
+}
+
+func TestModuleHost(t *testing.T) {
+	tests := map[string]string{
+		"github.com/foo/bar": "github.com",
+		"go4.org/netipx":     "go4.org",
+		"example":            "example",
+	}
+	for modulePath, want := range tests {
+		if got := ModuleHost(modulePath); got != want {
+			t.Errorf("ModuleHost(%q) = %q, want %q", modulePath, got, want)
+		}
+	}
+}
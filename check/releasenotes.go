@@ -0,0 +1,130 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// ReleaseNotesSource finds release notes describing the changes between two
+// revisions of a module's repository.
+type ReleaseNotesSource interface {
+	// ReleaseNotes returns Markdown describing the changes from base to
+	// head, which are commit hashes (or prefixes of them) committed at
+	// baseTime and headTime, or "" if there are none. If the module is not
+	// hosted where the source can look, the error must wrap
+	// ErrUnsupportedHost.
+	ReleaseNotes(
+		ctx context.Context,
+		modulePath,
+		base,
+		head string,
+		baseTime,
+		headTime time.Time,
+	) (string, error)
+}
+
+// WithReleaseNotes includes release notes covering the new commits in each
+// Update, such as the sections added to the repository's changelog. A failed
+// lookup is logged and leaves Update.ReleaseNotes empty. By default release
+// notes are not included.
+func WithReleaseNotes(source ReleaseNotesSource) Option {
+	return func(c *Checker) { c.releaseNotes = source }
+}
+
+// maxReleaseNotesSize limits the size of the release notes included in an
+// Update, in bytes.
+const maxReleaseNotesSize = 8 << 10
+
+// findReleaseNotes returns release notes for the changes from current to latest,
+// which are pseudo-versions of the module.
+func (c *Checker) findReleaseNotes(
+	ctx context.Context,
+	modulePath,
+	current,
+	latest string,
+) (string, error) {
+	base, err := module.PseudoVersionRev(current)
+	if err != nil {
+		return "", fmt.Errorf("parsing version %q: %w", current, err)
+	}
+	head, err := module.PseudoVersionRev(latest)
+	if err != nil {
+		return "", fmt.Errorf("parsing version %q: %w", latest, err)
+	}
+	// Times in valid pseudo-versions always parse.
+	baseTime, _ := module.PseudoVersionTime(current)
+	headTime, _ := module.PseudoVersionTime(latest)
+
+	release, err := c.limiter.acquire(ctx, modulePath)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	notes, err := c.releaseNotes.ReleaseNotes(ctx, modulePath, base, head, baseTime, headTime)
+	if err != nil {
+		return "", err
+	}
+	return truncateNotes(strings.TrimSpace(notes), maxReleaseNotesSize), nil
+}
+
+// truncateNotes shortens notes to at most about size bytes, cutting at a line
+// boundary if possible.
+func truncateNotes(notes string, size int) string {
+	if len(notes) <= size {
+		return notes
+	}
+	cut := notes[:size]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "\n\n…"
+}
+
+// newChangelogSections returns the sections of the changelog newText that do
+// not appear in oldText, in order, for finding the entries added between two
+// revisions. Sections start at level two Markdown headings (e.g.
+// "## v1.2.0"); text before the first one, such as the document's
+// title, is ignored. A section whose entries changed, such as an
+// "Unreleased" section that gained entries, is included in full.
+func newChangelogSections(oldText, newText string) string {
+	oldSections := map[string]bool{}
+	for _, s := range changelogSections(oldText) {
+		oldSections[s] = true
+	}
+
+	var added []string
+	for _, s := range changelogSections(newText) {
+		if !oldSections[s] {
+			added = append(added, s)
+		}
+	}
+	return strings.Join(added, "\n\n")
+}
+
+// changelogSections splits a Markdown changelog into sections (see
+// newChangelogSections), with surrounding whitespace trimmed.
+func changelogSections(text string) []string {
+	var sections []string
+	var current strings.Builder
+	inSection := false
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); inSection && s != "" {
+			sections = append(sections, s)
+		}
+		current.Reset()
+	}
+	for line := range strings.Lines(text) {
+		if strings.HasPrefix(line, "## ") {
+			flush()
+			inSection = true
+		}
+		current.WriteString(strings.TrimRight(line, " \t\r\n") + "\n")
+	}
+	flush()
+	return sections
+}
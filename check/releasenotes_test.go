@@ -0,0 +1,118 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewChangelogSections(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    string
+	}{
+		{
+			name:    "new version",
+			oldText: "# Changelog\n\n## v1.0.0\n\n* Initial release.\n",
+			newText: "# Changelog\n\n## v1.1.0\n\n* Add a feature.\n\n### Fixes\n\n* A fix.\n\n" +
+				"## v1.0.0\n\n* Initial release.\n",
+			want: "## v1.1.0\n\n* Add a feature.\n\n### Fixes\n\n* A fix.",
+		},
+		{
+			name:    "changed unreleased section",
+			oldText: "## Unreleased\n\n* One.\n\n## v1.0.0\n\n* Initial release.\n",
+			newText: "## Unreleased\n\n* Two.\n* One.\n\n## v1.0.0\n\n* Initial release.\n",
+			want:    "## Unreleased\n\n* Two.\n* One.",
+		},
+		{
+			name:    "no changes",
+			oldText: "# Changelog\n\n## v1.0.0\n\n* Initial release.\n",
+			newText: "# Changelog\n\nIntro.\n\n## v1.0.0\n\n* Initial release.\n",
+			want:    "",
+		},
+		{
+			name:    "new changelog",
+			newText: "## v1.0.0\r\n\r\n* Initial release.\r\n",
+			want:    "## v1.0.0\n\n* Initial release.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newChangelogSections(tt.oldText, tt.newText); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateNotes(t *testing.T) {
+	notes := "## v1.0.0\n\n* " + strings.Repeat("a", 20) + "\n* b\n"
+	if got := truncateNotes(notes, len(notes)); got != notes {
+		t.Errorf("got %q, want notes unchanged", got)
+	}
+	want := "## v1.0.0\n\n* " + strings.Repeat("a", 20) + "\n\n…"
+	if got := truncateNotes(notes, len(notes)-2); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+type releaseNotesFunc func(
+	modulePath,
+	base,
+	head string,
+	baseTime,
+	headTime time.Time,
+) (string, error)
+
+func (f releaseNotesFunc) ReleaseNotes(
+	_ context.Context,
+	modulePath,
+	base,
+	head string,
+	baseTime,
+	headTime time.Time,
+) (string, error) {
+	return f(modulePath, base, head, baseTime, headTime)
+}
+
+func TestWithReleaseNotes(t *testing.T) {
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"example.com/a@main": "v0.0.0-20231201000000-bbbbbbbbbbbb",
+			"example.com/b@main": "v0.0.0-20231201000000-dddddddddddd",
+		}),
+		WithBranches(branchMain),
+		WithReleaseNotes(releaseNotesFunc(
+			func(modulePath, base, head string, baseTime, headTime time.Time) (string, error) {
+				if modulePath != "example.com/a" {
+					return "", fmt.Errorf("%s: %w", modulePath, ErrUnsupportedHost)
+				}
+				wantBase := time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)
+				if base != "aaaaaaaaaaaa" || head != "bbbbbbbbbbbb" || !baseTime.Equal(wantBase) {
+					t.Errorf("got %s@%s to %s, want the update's revisions", base, baseTime, head)
+				}
+				return "## v1.1.0\n\n* A fix.\n", nil
+			},
+		)),
+	)
+
+	rep := c.Check(t.Context(), []Dependency{
+		{Module: "example.com/a", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "example.com/b", Version: "v0.0.0-20231101000000-cccccccccccc"},
+	})
+
+	if len(rep.Updates) != 2 {
+		t.Fatalf("got updates %v, want 2", rep.Updates)
+	}
+	if got, want := rep.Updates[0].ReleaseNotes, "## v1.1.0\n\n* A fix."; got != want {
+		t.Errorf("got release notes %q, want %q", got, want)
+	}
+	if got := rep.Updates[1].ReleaseNotes; got != "" {
+		t.Errorf("got release notes %q for unsupported host, want none", got)
+	}
+}
@@ -0,0 +1,236 @@
+package check
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// maxMetaBodySize limits how much of a go-get response is read looking for
+// go-import meta tags.
+const maxMetaBodySize = 1 << 20
+
+// Repo is the source repository of a module.
+type Repo struct {
+	// Root is the import path prefix corresponding to the repository root.
+	Root string
+	// URL is the repository's URL, e.g. https://github.com/owner/repo.
+	URL string
+}
+
+// CompareURL returns a web page comparing commits base and head in the
+// repository, or "" if the repository's host is not known.
+func (r Repo) CompareURL(base, head string) string {
+	u, err := url.Parse(strings.TrimSuffix(r.URL, ".git"))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	u.Scheme = "https"
+	u.User = nil
+	repoURL := strings.TrimSuffix(u.String(), "/")
+
+	switch u.Host {
+	case "github.com", "codeberg.org":
+		return repoURL + "/compare/" + base + "..." + head
+	case "gitlab.com":
+		return repoURL + "/-/compare/" + base + "..." + head
+	default:
+		return ""
+	}
+}
+
+// knownHosts are hosts whose repositories are at <host>/<owner>/<repo>, so a
+// module's repository can be found without a go-get request.
+var knownHosts = map[string]bool{
+	"github.com":   true,
+	"gitlab.com":   true,
+	"codeberg.org": true,
+}
+
+// RepoFinder finds the source repositories of modules. Modules on well-known
+// hosts such as github.com are mapped directly; for others (vanity import
+// paths), the repository is discovered from the go-import meta tag served at
+// https://<module path>?go-get=1, as the go command does. Results are cached,
+// and a RepoFinder is safe for concurrent use.
+type RepoFinder struct {
+	client *http.Client
+
+	mu    sync.Mutex
+	repos map[string]Repo
+}
+
+// NewRepoFinder returns a RepoFinder making go-get requests with client. If
+// client is nil, a client with a one minute timeout is used.
+func NewRepoFinder(client *http.Client) *RepoFinder {
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	return &RepoFinder{client: client, repos: map[string]Repo{}}
+}
+
+// WithRepoFinder finds each updated dependency's repository with f so that
+// updates include a CompareURL. By default repositories are not looked up.
+func WithRepoFinder(f *RepoFinder) Option {
+	return func(c *Checker) { c.repoFinder = f }
+}
+
+// compareURL returns a web page comparing the commits of current and latest,
+// which are pseudo-versions of the module, or "" if there is none.
+func (c *Checker) compareURL(ctx context.Context, modulePath, current, latest string) string {
+	base, err := module.PseudoVersionRev(current)
+	if err != nil {
+		return ""
+	}
+	head, err := module.PseudoVersionRev(latest)
+	if err != nil {
+		return ""
+	}
+
+	release, err := c.limiter.acquire(ctx, modulePath)
+	if err != nil {
+		return ""
+	}
+	repo, err := c.repoFinder.Find(ctx, modulePath)
+	release()
+	if err != nil {
+		c.log().Warn("finding repository failed", "module", modulePath, "error", err)
+		return ""
+	}
+
+	return repo.CompareURL(base, head)
+}
+
+// Find returns the repository of the module.
+func (f *RepoFinder) Find(ctx context.Context, modulePath string) (Repo, error) {
+	host, _, _ := strings.Cut(modulePath, "/")
+	if knownHosts[host] {
+		parts := strings.SplitN(modulePath, "/", 4)
+		if len(parts) < 3 {
+			return Repo{}, fmt.Errorf("%s: not a repository path", modulePath)
+		}
+		root := strings.Join(parts[:3], "/")
+		return Repo{Root: root, URL: "https://" + root}, nil
+	}
+
+	f.mu.Lock()
+	repo, ok := f.repos[modulePath]
+	f.mu.Unlock()
+	if ok {
+		return repo, nil
+	}
+
+	repo, err := f.discover(ctx, modulePath)
+	if err != nil {
+		return Repo{}, err
+	}
+
+	f.mu.Lock()
+	f.repos[modulePath] = repo
+	f.mu.Unlock()
+	return repo, nil
+}
+
+// discover finds the repository of a module from its go-import meta tag.
+func (f *RepoFinder) discover(ctx context.Context, modulePath string) (Repo, error) {
+	u := "https://" + modulePath + "?go-get=1"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return Repo{}, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("fetching %s: %w", u, err)
+		if isTimeout(err) {
+			return Repo{}, classify(ErrTimeout, err)
+		}
+		return Repo{}, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	// Like the go command, look for meta tags even in error responses.
+	imports, err := parseMetaGoImports(io.LimitReader(resp.Body, maxMetaBodySize))
+	if err != nil {
+		return Repo{}, fmt.Errorf("parsing %s: %w", u, err)
+	}
+
+	for _, imp := range imports {
+		if imp.vcs == "mod" {
+			continue
+		}
+		if modulePath == imp.prefix || strings.HasPrefix(modulePath, imp.prefix+"/") {
+			return Repo{Root: imp.prefix, URL: imp.repoURL}, nil
+		}
+	}
+	return Repo{}, classify(
+		ErrModuleNotFound,
+		fmt.Errorf("%s: no go-import meta tag for %s", u, modulePath),
+	)
+}
+
+// metaImport is a go-import meta tag: <meta name="go-import"
+// content="prefix vcs repoURL">.
+type metaImport struct {
+	prefix, vcs, repoURL string
+}
+
+// parseMetaGoImports returns the go-import meta tags in the head of an HTML
+// document. Like the go command, it uses a lenient XML decoder rather than a
+// full HTML parser.
+func parseMetaGoImports(r io.Reader) ([]metaImport, error) {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	d.Strict = false
+
+	var imports []metaImport
+	for {
+		t, err := d.RawToken()
+		if err != nil {
+			if errors.Is(err, io.EOF) || len(imports) > 0 {
+				return imports, nil
+			}
+			return nil, err
+		}
+		if e, ok := t.(xml.StartElement); ok && strings.EqualFold(e.Name.Local, "body") {
+			return imports, nil
+		}
+		if e, ok := t.(xml.EndElement); ok && strings.EqualFold(e.Name.Local, "head") {
+			return imports, nil
+		}
+		e, ok := t.(xml.StartElement)
+		if !ok || !strings.EqualFold(e.Name.Local, "meta") ||
+			attrValue(e.Attr, "name") != "go-import" {
+			continue
+		}
+		if fields := strings.Fields(attrValue(e.Attr, "content")); len(fields) == 3 {
+			imports = append(imports, metaImport{
+				prefix:  fields[0],
+				vcs:     fields[1],
+				repoURL: fields[2],
+			})
+		}
+	}
+}
+
+// attrValue returns the value of the named attribute, or "" if it is absent.
+func attrValue(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,140 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRepoFinder(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Query().Get("go-get") != "1" {
+			t.Errorf("got query %q, want go-get=1", r.URL.RawQuery)
+		}
+		host := r.Host
+		switch r.URL.Path {
+		case "/netipx", "/netipx/sub":
+			fmt.Fprintf(w, `<!DOCTYPE html>
+<html><head>
+<meta name="go-import" content="%[1]s/netipx mod https://proxy.example.com">
+<meta name="go-import" content="%[1]s/netipx git https://github.com/inetaf/netipx.git">
+<meta name="go-source" content="%[1]s/netipx _ _ _">
+</head><body>Nothing to see here.</body></html>`, host)
+		default:
+			http.Error(w, "<html><body>not found</body></html>", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	f := NewRepoFinder(server.Client())
+
+	tests := []struct {
+		name     string
+		module   string
+		wantRoot string
+		wantURL  string
+		wantErr  error
+	}{
+		{
+			name:     "GitHub",
+			module:   "github.com/owner/repo/sub/v2",
+			wantRoot: "github.com/owner/repo",
+			wantURL:  "https://github.com/owner/repo",
+		},
+		{
+			name:     "vanity path",
+			module:   host + "/netipx",
+			wantRoot: host + "/netipx",
+			wantURL:  "https://github.com/inetaf/netipx.git",
+		},
+		{
+			name:     "vanity path subdirectory",
+			module:   host + "/netipx/sub",
+			wantRoot: host + "/netipx",
+			wantURL:  "https://github.com/inetaf/netipx.git",
+		},
+		{name: "no meta tag", module: host + "/missing", wantErr: ErrModuleNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := f.Find(t.Context(), tt.module)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Find: %v", err)
+			}
+			if repo.Root != tt.wantRoot || repo.URL != tt.wantURL {
+				t.Errorf("got %+v, want root %q and URL %q", repo, tt.wantRoot, tt.wantURL)
+			}
+		})
+	}
+
+	// Results are cached.
+	before := requests.Load()
+	if _, err := f.Find(t.Context(), host+"/netipx"); err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if after := requests.Load(); after != before {
+		t.Errorf("got %d more requests, want cached result", after-before)
+	}
+}
+
+func TestRepoCompareURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{
+			url:  "https://github.com/inetaf/netipx.git",
+			want: "https://github.com/inetaf/netipx/compare/aaaa...bbbb",
+		},
+		{
+			url:  "https://gitlab.com/group/project",
+			want: "https://gitlab.com/group/project/-/compare/aaaa...bbbb",
+		},
+		{
+			url:  "https://codeberg.org/owner/repo",
+			want: "https://codeberg.org/owner/repo/compare/aaaa...bbbb",
+		},
+		{url: "https://go.googlesource.com/net", want: ""},
+		{url: "not a url", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := (Repo{URL: tt.url}).CompareURL("aaaa", "bbbb"); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMetaGoImports(t *testing.T) {
+	// Tags after the head are ignored, as by the go command.
+	doc := `<html><head>
+<meta name="go-import" content="example.com/a git https://example.com/a">
+</head><body>
+<meta name="go-import" content="example.com/b git https://example.com/b">
+</body></html>`
+
+	imports, err := parseMetaGoImports(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("parseMetaGoImports: %v", err)
+	}
+	want := []metaImport{{prefix: "example.com/a", vcs: "git", repoURL: "https://example.com/a"}}
+	if len(imports) != 1 || imports[0] != want[0] {
+		t.Errorf("got %+v, want %+v", imports, want)
+	}
+}
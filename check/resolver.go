@@ -0,0 +1,227 @@
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Resolver resolves a query such as module@main to a version. If the branch
+// does not exist, the returned error must wrap ErrBranchNotFound. Other
+// errors should wrap one of the other sentinel errors where possible.
+type Resolver interface {
+	Resolve(ctx context.Context, modulePath, branch string) (string, error)
+}
+
+// CommandRunner runs external commands. It lets GoListResolver be tested
+// without a Go toolchain or network access.
+type CommandRunner interface {
+	// Run runs the named command and returns its standard output. If the
+	// command exits unsuccessfully, the error should be an *exec.ExitError
+	// whose Stderr holds the command's standard error, which is used to
+	// classify the failure.
+	Run(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// commandWaitDelay bounds how long Run waits after ctx is done. Killing the go
+// command does not kill the git processes it started, and those keep its
+// output open, so without this a canceled command could run to completion.
+const commandWaitDelay = 2 * time.Second
+
+// ExecRunner is a CommandRunner that runs commands with os/exec. When ctx is
+// done the command is killed, and Run returns shortly after even if child
+// processes are still running.
+type ExecRunner struct {
+	// Env is the environment of the commands, as for exec.Cmd. If nil, they
+	// inherit the current process's environment.
+	Env []string
+}
+
+// Run implements CommandRunner.
+func (r ExecRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	//nolint:gosec // callers choose the command; module paths are from go.mod
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = r.Env
+	cmd.WaitDelay = commandWaitDelay
+	return cmd.Output()
+}
+
+// GoListResolver resolves queries by running 'go list -m -json'. This
+// requires a Go toolchain and, for modules not served by a proxy, git.
+//
+// The go command runs in a throwaway module outside the project being
+// checked, with -mod=mod, so neither the project's go.mod, go.work, and
+// vendor directory nor a -mod=vendor in GOFLAGS, which makes the go command
+// refuse to query modules, affect the result. The default runner also sets
+// GOWORK=off and drops -mod and -modfile from GOFLAGS (see isolatedGoEnv),
+// while keeping the settings that control where modules are fetched from,
+// such as GOPROXY, GOPRIVATE, GONOSUMDB, and git's configuration.
+type GoListResolver struct {
+	// Logger receives the commands run. If nil, nothing is logged.
+	Logger *slog.Logger
+	// Runner runs the go command. If nil, an ExecRunner with the current
+	// environment adjusted by isolatedGoEnv is used.
+	Runner CommandRunner
+	// Dir is the directory the go command runs in. If empty, a new temporary
+	// module is created for each query.
+	Dir string
+}
+
+// Resolve implements Resolver.
+func (r GoListResolver) Resolve(ctx context.Context, modulePath, branch string) (string, error) {
+	logger := r.Logger
+	if logger == nil {
+		logger = discardLogger()
+	}
+
+	// Only the environment the default runner sets is known, so only it is
+	// logged.
+	runner := r.Runner
+	var env []string
+	if runner == nil {
+		runner = ExecRunner{Env: isolatedGoEnv(os.Environ())}
+		env = goEnvOverrides(os.Environ())
+	}
+
+	dir := r.Dir
+	if dir == "" {
+		tmp, err := tempModule()
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = os.RemoveAll(tmp) }()
+		dir = tmp
+	}
+
+	command := strings.Join(append([]string{"go"}, goListArgs(dir, modulePath, branch)...), " ")
+	attrs := []any{"command", command}
+	if env != nil {
+		attrs = append(attrs, "env", env)
+	}
+	logger.Debug("running command", attrs...)
+
+	start := time.Now()
+	version, err := queryModuleVersion(ctx, runner, dir, modulePath, branch)
+	logger.Debug("command finished", "command", command, "duration", time.Since(start))
+
+	return version, err
+}
+
+// tempModule creates a temporary directory holding an empty module to run
+// the go command in. Its go.mod stops the go command from finding one in a
+// parent directory, e.g. if TMPDIR is inside a project.
+func tempModule() (string, error) {
+	dir, err := os.MkdirTemp("", "check-untagged-go-deps-")
+	if err != nil {
+		return "", fmt.Errorf("creating module for go list: %w", err)
+	}
+	gomod := []byte("module check-untagged-go-deps/query\n")
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), gomod, 0o600); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", fmt.Errorf("creating module for go list: %w", err)
+	}
+	return dir, nil
+}
+
+// isolatedGoEnv returns environ, a list of key=value pairs, adjusted so the
+// settings of the project the tool was run in cannot affect the go command:
+// GOWORK is off, GO111MODULE is on, and -mod and -modfile are dropped from
+// GOFLAGS. Since the environment takes precedence over the go env file, this
+// also overrides settings made with 'go env -w'. Everything else, including
+// GOPROXY, GONOPROXY, GONOSUMDB, GOPRIVATE, GOINSECURE, GOAUTH, and the
+// variables git and the HTTP client use, is passed through.
+func isolatedGoEnv(environ []string) []string {
+	overrides := goEnvOverrides(environ)
+	env := make([]string, 0, len(environ)+len(overrides))
+	for _, kv := range environ {
+		key, _, _ := strings.Cut(kv, "=")
+		switch key {
+		case "GOWORK", "GO111MODULE", "GOFLAGS":
+		default:
+			env = append(env, kv)
+		}
+	}
+	return append(env, overrides...)
+}
+
+// goEnvOverrides returns the variables isolatedGoEnv sets in environ, as
+// key=value pairs.
+func goEnvOverrides(environ []string) []string {
+	var goflags []string
+	for _, kv := range environ {
+		key, value, _ := strings.Cut(kv, "=")
+		if key != "GOFLAGS" {
+			continue
+		}
+		for _, flag := range strings.Fields(value) {
+			name, _, _ := strings.Cut(strings.TrimLeft(flag, "-"), "=")
+			if name != "mod" && name != "modfile" {
+				goflags = append(goflags, flag)
+			}
+		}
+	}
+	return []string{
+		"GOWORK=off",
+		"GO111MODULE=on",
+		"GOFLAGS=" + strings.Join(append(goflags, "-mod=mod"), " "),
+	}
+}
+
+// goListArgs returns the arguments of the go command that queries the
+// module's version at branch in dir. -mod=mod overrides a -mod=vendor in
+// GOFLAGS, with which the go command refuses to query modules at all.
+func goListArgs(dir, modulePath, branch string) []string {
+	return []string{"-C", dir, "list", "-mod=mod", "-m", "-json", modulePath + "@" + branch}
+}
+
+// moduleInfo represents the JSON output from 'go list -m -json'.
+type moduleInfo struct {
+	Path    string `json:"Path"`    //nolint:tagliatelle // matches go list output
+	Version string `json:"Version"` //nolint:tagliatelle // matches go list output
+}
+
+// Note there are at least two cases to consider: If the repo has tagged
+// versions and you're depending on a commit, then `go get -u ./...` won't
+// update it even if you're on a main commit that is behind main. However if
+// the repo does not have tagged versions, it will. This is mostly a
+// consideration for `go get -u` but I wanted to note it somewhere.
+//
+// The query runs in dir (see goListArgs).
+func queryModuleVersion(
+	ctx context.Context,
+	runner CommandRunner,
+	dir,
+	modulePath,
+	branch string,
+) (string, error) {
+	output, err := runner.Run(ctx, "go", goListArgs(dir, modulePath, branch)...)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = fmt.Errorf("running go list: %w", ctxErr)
+			if isTimeout(ctxErr) {
+				return "", classify(ErrTimeout, err)
+			}
+			return "", err
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			msg := strings.TrimSpace(string(exitErr.Stderr))
+			return "", classify(classifyMessage(msg), errors.New(msg))
+		}
+		return "", fmt.Errorf("running go list: %w", err)
+	}
+
+	var info moduleInfo
+	if err := json.Unmarshal(output, &info); err != nil {
+		return "", fmt.Errorf("parsing module info: %w", err)
+	}
+
+	return info.Version, nil
+}
@@ -0,0 +1,180 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// WithRetractionCheck refuses updates to versions that their module has
+// retracted. As the go command does, retract directives are read from the
+// go.mod file of the module's latest version, its highest release (or
+// prerelease) listed by lister, using gomods. If lister is nil or the module
+// has no tags, the go.mod file of the version being updated to is read
+// instead, since that is the newest commit on its branch. Maintainers
+// retract commits they have withdrawn, such as ones published by mistake or
+// found to be broken. If the version found is retracted, the newest
+// candidate that is not is used instead: the head of the next branch set by
+// WithBranches that is newer than the dependency's version, then the
+// module's newest tag, if lister is given and it is newer, and otherwise the
+// dependency's own version, which is reported as up to date. Only if that is
+// retracted as well is the dependency reported as a Failure wrapping
+// ErrRetracted. A failed lookup is logged and keeps the update. By default
+// retractions are not checked.
+func WithRetractionCheck(gomods GoModSource, lister TagLister) Option {
+	return func(c *Checker) {
+		c.retractionSource = gomods
+		c.retractionLister = lister
+	}
+}
+
+// checkRetraction replaces res.Latest with the newest candidate the module
+// has not retracted if it has retracted res.Latest (see WithRetractionCheck).
+func (c *Checker) checkRetraction(ctx context.Context, res *Result) {
+	dep := res.Dependency
+	retractions, tags, err := c.retractions(ctx, dep.Module, res.Latest)
+	if err != nil {
+		c.log().Warn("checking for retraction failed", "module", dep.Module, "error", err)
+		return
+	}
+	r := retractedBy(retractions, res.Latest)
+	if r == nil {
+		return
+	}
+
+	if version, ok := c.unretractedVersion(ctx, *res, retractions, tags); ok {
+		c.log().Info(
+			"latest version retracted",
+			"module", dep.Module,
+			"version", res.Latest,
+			"fallback", version,
+		)
+		// Neither a tag nor the dependency's version is on the branch.
+		res.Latest, res.Branch = version, ""
+		return
+	}
+
+	rationale := ""
+	if r.Rationale != "" {
+		rationale = fmt.Sprintf(" (%s)", r.Rationale)
+	}
+	res.Err = classify(ErrRetracted, fmt.Errorf(
+		"%s@%s is retracted%s, as is %s, and no other branch head or tag is newer and "+
+			"free of the retraction, so there is no eligible target",
+		dep.Module,
+		res.Latest,
+		rationale,
+		dep.Version,
+	))
+	res.Latest = ""
+}
+
+// unretractedVersion returns the newest candidate for res's dependency that
+// is not retracted (see WithRetractionCheck), given the module's retractions
+// and tags. ok is false if there is none, not even the dependency's own
+// version.
+func (c *Checker) unretractedVersion(
+	ctx context.Context,
+	res Result,
+	retractions []*modfile.Retract,
+	tags []string,
+) (string, bool) {
+	dep := res.Dependency
+	eligible := func(version string) bool {
+		if retractedBy(retractions, version) != nil || sameCommit(version, dep.Version) {
+			return false
+		}
+		newer, err := newerVersion(dep.Version, version)
+		return err == nil && newer == version && version != dep.Version
+	}
+
+	// A branch particular to the dependency is the only one it follows.
+	if res.Branch == "" {
+		branches := c.branches
+		if len(branches) == 0 {
+			branches = DefaultBranches
+		}
+		if major := majorBranch(dep.Module); major != "" && !slices.Contains(branches, major) {
+			branches = append(slices.Clip(branches), major)
+		}
+		for _, branch := range branches {
+			version, found, err := c.resolveBranch(ctx, dep.Module, branch)
+			if err != nil {
+				c.log().Warn(
+					"resolving branch for retraction fallback failed",
+					"module", dep.Module,
+					"branch", branch,
+					"error", err,
+				)
+				continue
+			}
+			if found && version != res.Latest && eligible(version) {
+				return version, true
+			}
+		}
+	}
+
+	tags = slices.DeleteFunc(slices.Clone(tags), func(v string) bool {
+		return retractedBy(retractions, v) != nil
+	})
+	if tag := latestTag(tags); tag != "" && eligible(tag) {
+		return tag, true
+	}
+
+	if retractedBy(retractions, dep.Version) == nil {
+		return dep.Version, true
+	}
+	return "", false
+}
+
+// retractions returns the retract directives of the module's latest version
+// (see WithRetractionCheck), where version is the version being updated to,
+// and the module's tags compatible with it, which are nil if no TagLister
+// was given.
+func (c *Checker) retractions(
+	ctx context.Context,
+	modulePath, version string,
+) ([]*modfile.Retract, []string, error) {
+	release, err := c.limiter.acquire(ctx, modulePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+
+	latest := version
+	var tags []string
+	if c.retractionLister != nil {
+		versions, err := c.retractionLister.Versions(ctx, modulePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("listing versions of %s: %w", modulePath, err)
+		}
+		tags = compatibleVersions(version, versions)
+		if tag := latestTag(tags); tag != "" {
+			latest = tag
+		}
+	}
+
+	data, err := c.retractionSource.GoMod(ctx, modulePath, latest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("downloading go.mod of %s@%s: %w", modulePath, latest, err)
+	}
+	f, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing go.mod of %s@%s: %w", modulePath, latest, err)
+	}
+	return f.Retract, tags, nil
+}
+
+// retractedBy returns the directive in retractions that retracts version, or
+// nil if none does.
+func retractedBy(retractions []*modfile.Retract, version string) *modfile.Retract {
+	for _, r := range retractions {
+		if semver.Compare(r.Low, version) <= 0 && semver.Compare(version, r.High) <= 0 {
+			return r
+		}
+	}
+	return nil
+}
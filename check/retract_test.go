@@ -0,0 +1,116 @@
+package check
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWithRetractionCheck(t *testing.T) {
+	const (
+		current = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+		latest  = "v0.0.0-20231201000000-bbbbbbbbbbbb"
+		dev     = "v0.0.0-20231120000000-eeeeeeeeeeee"
+		release = "v0.1.0"
+	)
+	// gomods maps module@version to its go.mod file.
+	gomods := map[string]string{
+		"example.com/self@" + latest: "module example.com/self\n\n" +
+			"// Published by mistake.\nretract " + latest + "\n",
+		"example.com/range@" + latest: "module example.com/range\n\n" +
+			"retract [v0.0.0-20231115000000-cccccccccccc, " + latest + "]\n",
+		"example.com/older@" + latest: "module example.com/older\n\n" +
+			"retract v0.0.0-20231015000000-dddddddddddd\n",
+		"example.com/none@" + latest: "module example.com/none\n",
+		// The head of main is retracted, but not that of the next branch.
+		"example.com/branch@" + latest: "module example.com/branch\n\n" +
+			"retract " + latest + "\n",
+		// The retraction covers the current version too.
+		"example.com/pinned@" + latest: "module example.com/pinned\n\n" +
+			"// Published by mistake.\nretract [" + current + ", " + latest + "]\n",
+		// The latest release retracts the branch head, whose own go.mod
+		// does not.
+		"example.com/tagged@" + release: "module example.com/tagged\n\n" +
+			"retract " + latest + "\n",
+		"example.com/tagged@" + latest: "module example.com/tagged\n",
+		// The branch head retracts itself, but the latest release, whose
+		// retractions the go command reads, does not.
+		"example.com/unretracted@" + release: "module example.com/unretracted\n",
+		"example.com/unretracted@" + latest: "module example.com/unretracted\n\n" +
+			"retract " + latest + "\n",
+	}
+	versions := map[string][]string{
+		"example.com/tagged":      {"v0.0.1", release, "v0.2.0-rc.1"},
+		"example.com/unretracted": {release},
+	}
+
+	resolver := fakeResolver{}
+	var deps []Dependency
+	for _, m := range []string{
+		"example.com/self",
+		"example.com/range",
+		"example.com/older",
+		"example.com/none",
+		"example.com/failed",
+		"example.com/tagged",
+		"example.com/unretracted",
+		"example.com/branch",
+		"example.com/pinned",
+	} {
+		resolver[m+"@main"] = latest
+		deps = append(deps, Dependency{Module: m, Version: current})
+	}
+	resolver["example.com/branch@dev"] = dev
+	c := NewChecker(
+		WithResolver(resolver),
+		WithBranches(branchMain, "dev"),
+		WithRetractionCheck(
+			goModSourceFunc(func(modulePath, version string) ([]byte, error) {
+				gomod, ok := gomods[modulePath+"@"+version]
+				if !ok {
+					return nil, errors.New("not found")
+				}
+				return []byte(gomod), nil
+			}),
+			tagListerFunc(func(modulePath string) ([]string, error) {
+				return versions[modulePath], nil
+			}),
+		),
+	)
+
+	rep := c.Check(t.Context(), deps)
+	// A retracted head falls back to the next branch's head, then to the
+	// newest tag, and then to the current version, which is up to date.
+	got := map[string]string{}
+	for _, u := range rep.Updates {
+		got[u.Module] = u.Latest
+	}
+	want := map[string]string{
+		"example.com/older":       latest,
+		"example.com/none":        latest,
+		"example.com/failed":      latest,
+		"example.com/tagged":      release,
+		"example.com/unretracted": latest,
+		"example.com/branch":      dev,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("got updates %v, want %v", got, want)
+	}
+
+	var failed []string
+	for _, f := range rep.Failures {
+		if !errors.Is(f.Err, ErrRetracted) {
+			t.Errorf("%s: got error %v, want ErrRetracted", f.Module, f.Err)
+		}
+		failed = append(failed, f.Module)
+	}
+	if want := []string{"example.com/pinned"}; !slices.Equal(failed, want) {
+		t.Errorf("got failures %q, want %q", failed, want)
+	}
+	if len(rep.Failures) > 0 &&
+		!strings.Contains(rep.Failures[0].Err.Error(), "(Published by mistake.)") {
+		t.Errorf("got error %q, want it to include the rationale", rep.Failures[0].Err)
+	}
+}
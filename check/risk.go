@@ -0,0 +1,102 @@
+package check
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Risk is a label for how disruptive an update is likely to be, based on
+// its commit messages.
+type Risk string
+
+// Risks, from least to most disruptive.
+const (
+	// RiskUnknown means the risk could not be determined, e.g. because no
+	// commit messages were available or none follow Conventional Commits.
+	RiskUnknown Risk = ""
+	// RiskPatch means the commits only fix bugs or make other changes that
+	// do not add features (fix, chore, docs, refactor, ...).
+	RiskPatch Risk = "patch"
+	// RiskFeature means at least one commit adds a feature (feat).
+	RiskFeature Risk = "feature"
+	// RiskBreaking means at least one commit is marked as a breaking change,
+	// with "!" after its type or a BREAKING CHANGE footer.
+	RiskBreaking Risk = "breaking"
+)
+
+// rank orders risks. Unknown risk ranks with breaking so that policies
+// treat updates they cannot assess conservatively.
+func (r Risk) rank() int {
+	switch r {
+	case RiskPatch:
+		return 1
+	case RiskFeature:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// AtLeast reports whether r is at least as disruptive as threshold. Unknown
+// risk is treated as breaking.
+func (r Risk) AtLeast(threshold Risk) bool {
+	return r.rank() >= threshold.rank()
+}
+
+// ParseRisk parses a risk label: patch, feature, or breaking.
+func ParseRisk(s string) (Risk, bool) {
+	switch r := Risk(s); r {
+	case RiskPatch, RiskFeature, RiskBreaking:
+		return r, true
+	default:
+		return RiskUnknown, false
+	}
+}
+
+// WithRiskClassification assigns each Update a Risk from the commits between
+// the current and latest versions, if the Comparer provides them (see
+// WithComparer). By default risk is not classified.
+func WithRiskClassification(classify bool) Option {
+	return func(c *Checker) { c.classifyRisk = classify }
+}
+
+// conventionalSubject matches a Conventional Commits subject such as
+// "feat(parser)!: add arrays", capturing the type and the "!" marker.
+var conventionalSubject = regexp.MustCompile(`^([a-zA-Z]+)(?:\([^)]*\))?(!)?: \S`)
+
+// classifyRisk returns the risk of commits following Conventional Commits
+// (https://www.conventionalcommits.org). Commits that do not follow it are
+// ignored. If none do, the risk is unknown.
+func classifyRisk(commits []Commit) Risk {
+	risk := RiskUnknown
+	for _, commit := range commits {
+		m := conventionalSubject.FindStringSubmatch(commit.Subject)
+		if m == nil {
+			continue
+		}
+
+		commitRisk := RiskPatch
+		switch {
+		case m[2] == "!" || hasBreakingFooter(commit.Message):
+			return RiskBreaking
+		case strings.EqualFold(m[1], "feat"):
+			commitRisk = RiskFeature
+		}
+		if risk == RiskUnknown || commitRisk.rank() > risk.rank() {
+			risk = commitRisk
+		}
+	}
+	return risk
+}
+
+// hasBreakingFooter reports whether a commit message has a BREAKING CHANGE
+// footer.
+func hasBreakingFooter(message string) bool {
+	for line := range strings.Lines(message) {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") ||
+			strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package check
+
+import "testing"
+
+func TestClassifyRisk(t *testing.T) {
+	tests := []struct {
+		name    string
+		commits []Commit
+		want    Risk
+	}{
+		{name: "no commits", want: RiskUnknown},
+		{
+			name:    "not conventional",
+			commits: []Commit{{Subject: "Update README"}, {Subject: "Fix a thing: again"}},
+			want:    RiskUnknown,
+		},
+		{
+			name:    "fixes and chores",
+			commits: []Commit{{Subject: "fix: handle nil"}, {Subject: "chore(deps): bump x"}},
+			want:    RiskPatch,
+		},
+		{
+			name: "feature",
+			commits: []Commit{
+				{Subject: "fix: handle nil"},
+				{Subject: "feat(parser): support arrays"},
+				{Subject: "Merge pull request #1"},
+			},
+			want: RiskFeature,
+		},
+		{
+			name:    "breaking marker",
+			commits: []Commit{{Subject: "feat!: drop Go 1.20"}, {Subject: "fix: x"}},
+			want:    RiskBreaking,
+		},
+		{
+			name:    "breaking marker with scope",
+			commits: []Commit{{Subject: "refactor(api)!: rename Client"}},
+			want:    RiskBreaking,
+		},
+		{
+			name: "breaking footer",
+			commits: []Commit{{
+				Subject: "fix: stricter validation",
+				Message: "fix: stricter validation\n\nBREAKING CHANGE: empty names are rejected\n",
+			}},
+			want: RiskBreaking,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyRisk(tt.commits); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRiskAtLeast(t *testing.T) {
+	tests := []struct {
+		risk      Risk
+		threshold Risk
+		want      bool
+	}{
+		{RiskPatch, RiskPatch, true},
+		{RiskPatch, RiskFeature, false},
+		{RiskFeature, RiskFeature, true},
+		{RiskFeature, RiskBreaking, false},
+		{RiskBreaking, RiskPatch, true},
+		{RiskUnknown, RiskBreaking, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.risk.AtLeast(tt.threshold); got != tt.want {
+			t.Errorf("Risk(%q).AtLeast(%q) = %v, want %v", tt.risk, tt.threshold, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package check
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+//go:embed report.schema.json
+var reportSchema []byte
+
+// Schema returns the JSON Schema (draft 2020-12) describing the JSON encoding
+// of a Report.
+func Schema() []byte {
+	return bytes.Clone(reportSchema)
+}
+
+// SchemaVersion is the version of the JSON report format described by Schema.
+// It only changes if the format changes in a way that is not backward
+// compatible; adding fields does not change it.
+const SchemaVersion = 1
+
+// report has Report's fields and tags but not its methods.
+type report Report
+
+// MarshalJSON encodes the report, using [] rather than null for empty lists.
+func (r Report) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.withEmptyLists())
+}
+
+func (r Report) withEmptyLists() report {
+	out := report(r)
+	if out.Dependencies == nil {
+		out.Dependencies = []Dependency{}
+	}
+	if out.Updates == nil {
+		out.Updates = []Update{}
+	}
+	if out.Failures == nil {
+		out.Failures = []Failure{}
+	}
+	return out
+}
+
+// Envelope is a Report with metadata recording how and when it was produced,
+// so archived reports remain identifiable as the format evolves. In JSON the
+// metadata fields sit alongside the report's fields rather than wrapping
+// them, so an envelope is also a valid Report.
+type Envelope struct {
+	// SchemaVersion is the report format version, normally SchemaVersion.
+	SchemaVersion int `json:"schema_version"`
+	// ToolVersion is the version of the tool that produced the report.
+	ToolVersion string `json:"tool_version"`
+	// GeneratedAt is when the report was produced.
+	GeneratedAt time.Time `json:"generated_at"`
+	// GoModPath is the path to the go.mod file that was checked.
+	GoModPath string `json:"go_mod_path"`
+	// Run summarizes the run that produced the report, if it is set. It is
+	// omitted from JSON if it is nil.
+	Run *RunSummary `json:"run,omitempty"`
+	// Report is the report itself.
+	Report Report `json:"-"`
+}
+
+// NewEnvelope returns an envelope for rep using the current SchemaVersion and
+// time.
+func NewEnvelope(rep Report, toolVersion, gomodPath string) Envelope {
+	return Envelope{
+		SchemaVersion: SchemaVersion,
+		ToolVersion:   toolVersion,
+		GeneratedAt:   time.Now().UTC().Truncate(time.Second),
+		GoModPath:     gomodPath,
+		Report:        rep,
+	}
+}
+
+// envelopeMetadata has Envelope's fields and tags but not its methods.
+type envelopeMetadata Envelope
+
+// MarshalJSON encodes the envelope's metadata followed by the report.
+func (e Envelope) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		envelopeMetadata
+		report
+	}{
+		envelopeMetadata: envelopeMetadata(e),
+		report:           e.Report.withEmptyLists(),
+	})
+}
+
+// UnmarshalJSON decodes an envelope. A bare Report decodes with zero
+// metadata.
+func (e *Envelope) UnmarshalJSON(data []byte) error {
+	var meta envelopeMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return err
+	}
+	var rep Report
+	if err := json.Unmarshal(data, &rep); err != nil {
+		return err
+	}
+	*e = Envelope(meta)
+	e.Report = rep
+	return nil
+}
+
+// jsonFailure is the JSON representation of a Failure.
+type jsonFailure struct {
+	Module string `json:"module"`
+	// Error is the error message.
+	Error string `json:"error"`
+	// Code is a stable classification of the error (see ErrorCode).
+	Code string `json:"code"`
+}
+
+// MarshalJSON encodes the failure with its error message and code.
+func (f Failure) MarshalJSON() ([]byte, error) {
+	out := jsonFailure{Module: f.Module, Code: ErrorCode(f.Err)}
+	if f.Err != nil {
+		out.Error = f.Err.Error()
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a failure. The decoded Err has the original message
+// and matches the sentinel error for its code with errors.Is.
+func (f *Failure) UnmarshalJSON(data []byte) error {
+	var in jsonFailure
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	f.Module = in.Module
+	f.Err = nil
+	if in.Error != "" || in.Code != "" {
+		f.Err = classify(codeError(in.Code), errors.New(in.Error))
+	}
+	return nil
+}
+
+// codeError returns the sentinel error for an error code, or nil if there is
+// none.
+func codeError(code string) error {
+	switch code {
+	case CodeBranchNotFound:
+		return ErrBranchNotFound
+	case CodeModuleNotFound:
+		return ErrModuleNotFound
+	case CodeAuth:
+		return ErrAuth
+	case CodeRateLimited:
+		return ErrRateLimited
+	case CodeTimeout:
+		return ErrTimeout
+	case CodeUnsigned:
+		return ErrUnsigned
+	case CodePinVanished:
+		return ErrPinVanished
+	case CodeRetracted:
+		return ErrRetracted
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,193 @@
+package check
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReportJSONRoundTrip(t *testing.T) {
+	rep := Report{
+		Dependencies: []Dependency{
+			{Module: "go4.org/netipx", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+			{Module: "example.com/gone", Version: "v0.0.0-20231101000000-bbbbbbbbbbbb"},
+		},
+		Updates: []Update{
+			{
+				Module:  "go4.org/netipx",
+				Current: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+				Latest:  "v0.0.0-20231201000000-cccccccccccc",
+			},
+		},
+		Failures: []Failure{
+			{
+				Module: "example.com/gone",
+				Err:    fmt.Errorf("example.com/gone@main: %w", ErrModuleNotFound),
+			},
+		},
+	}
+
+	data, err := json.Marshal(rep)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var got Report
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if !reflect.DeepEqual(got.Dependencies, rep.Dependencies) {
+		t.Errorf("got dependencies %v, want %v", got.Dependencies, rep.Dependencies)
+	}
+	if !reflect.DeepEqual(got.Updates, rep.Updates) {
+		t.Errorf("got updates %v, want %v", got.Updates, rep.Updates)
+	}
+	if len(got.Failures) != 1 {
+		t.Fatalf("got %d failures, want 1", len(got.Failures))
+	}
+	f := got.Failures[0]
+	if f.Module != "example.com/gone" {
+		t.Errorf("got failure module %q, want example.com/gone", f.Module)
+	}
+	if f.Err == nil || f.Err.Error() != rep.Failures[0].Err.Error() {
+		t.Errorf("got failure error %v, want %v", f.Err, rep.Failures[0].Err)
+	}
+	if !errors.Is(f.Err, ErrModuleNotFound) {
+		t.Errorf("got failure error %v, want it to match ErrModuleNotFound", f.Err)
+	}
+}
+
+func TestReportJSONEmpty(t *testing.T) {
+	data, err := json.Marshal(Report{})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want := `{"dependencies":[],"updates":[],"failures":[]}`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
+
+func TestEnvelopeJSON(t *testing.T) {
+	env := Envelope{
+		SchemaVersion: SchemaVersion,
+		ToolVersion:   "v1.2.0",
+		GeneratedAt:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		GoModPath:     "go.mod",
+		Report: Report{
+			Dependencies: []Dependency{
+				{Module: "go4.org/netipx", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+			},
+		},
+	}
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want := `{"schema_version":1,"tool_version":"v1.2.0","generated_at":"2026-01-02T03:04:05Z",` +
+		`"go_mod_path":"go.mod","dependencies":[{"module":"go4.org/netipx",` +
+		`"version":"v0.0.0-20231101000000-aaaaaaaaaaaa"}],"updates":[],"failures":[]}`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+
+	var got Envelope
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	env.Report.Updates = []Update{}
+	env.Report.Failures = []Failure{}
+	if !reflect.DeepEqual(got, env) {
+		t.Errorf("got %+v, want %+v", got, env)
+	}
+
+	// An envelope is also a valid report.
+	var rep Report
+	if err := json.Unmarshal(data, &rep); err != nil {
+		t.Fatalf("Unmarshal report: %v", err)
+	}
+	if !reflect.DeepEqual(rep.Dependencies, env.Report.Dependencies) {
+		t.Errorf("got dependencies %v, want %v", rep.Dependencies, env.Report.Dependencies)
+	}
+}
+
+// TestSchema checks that the schema describes every field in the JSON
+// encoding of an Envelope, and requires the report's fields.
+func TestSchema(t *testing.T) {
+	type property struct {
+		Properties map[string]property `json:"properties"`
+		Items      *property           `json:"items"`
+		Required   []string            `json:"required"`
+	}
+	var schema property
+	if err := json.Unmarshal(Schema(), &schema); err != nil {
+		t.Fatalf("parsing schema: %v", err)
+	}
+
+	env := Envelope{Run: &RunSummary{}, Report: Report{
+		Dependencies: []Dependency{
+			{
+				Tags:      TagStatusTagged,
+				LatestTag: "v1.1.0",
+				BaseTag:   "v1.0.0",
+				Source:    "Dockerfile:1",
+				Replaces:  "example.com/upstream",
+				Fork:      &ForkStatus{Ahead: 1, Behind: 2},
+			},
+		},
+		Updates: []Update{{
+			CurrentTime: time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC),
+			LatestTime:  time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+			Source:      "Dockerfile:1",
+			Branch:      "release-1.29",
+			Replaces:    "example.com/upstream",
+		}},
+		Failures:  []Failure{{Err: ErrTimeout}},
+		Toolchain: &ToolchainStatus{Go: "1.21", Latest: "go1.23.2"},
+	}}
+	data, err := json.Marshal(env)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var encoded map[string]json.RawMessage
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	lists := 0
+	for field, value := range encoded {
+		prop, ok := schema.Properties[field]
+		if !ok {
+			t.Errorf("schema does not describe %q", field)
+			continue
+		}
+
+		var items []map[string]any
+		if json.Unmarshal(value, &items) != nil {
+			continue
+		}
+		lists++
+		if prop.Items == nil {
+			t.Errorf("schema does not describe the items of %q", field)
+			continue
+		}
+		for itemField := range items[0] {
+			if _, ok := prop.Items.Properties[itemField]; !ok {
+				t.Errorf("schema does not describe %s.%s", field, itemField)
+			}
+		}
+		for _, required := range prop.Items.Required {
+			if _, ok := items[0][required]; !ok {
+				t.Errorf("schema requires %s.%s, but it is not encoded", field, required)
+			}
+		}
+	}
+	if len(schema.Required) != lists {
+		t.Errorf("schema requires %q, encoding has %d lists", schema.Required, lists)
+	}
+}
@@ -0,0 +1,119 @@
+package check
+
+import (
+	"strings"
+	"time"
+)
+
+// Severity is a label for how urgently an update needs attention, from how
+// stale the dependency is, the vulnerabilities it fixes, and how disruptive
+// it is.
+type Severity string
+
+// Severities, from least to most urgent.
+const (
+	// SeverityLow means nothing makes the update more urgent than any other,
+	// or it only fixes vulnerabilities rated low.
+	SeverityLow Severity = "low"
+	// SeverityMedium means the update fixes a vulnerability rated moderate,
+	// or the dependency is stale enough to warn about (see
+	// WithAgeSeverity).
+	SeverityMedium Severity = "medium"
+	// SeverityHigh means the update fixes a vulnerability rated high or not
+	// rated, or is a breaking change (see WithRiskClassification).
+	SeverityHigh Severity = "high"
+	// SeverityCritical means the update fixes a vulnerability rated
+	// critical, the current commit no longer exists upstream (see
+	// WithPinCheck), or the dependency is critically stale (see
+	// WithAgeSeverity).
+	SeverityCritical Severity = "critical"
+)
+
+// WithAgeSeverity raises the severity of updates whose current commit is
+// older than the latest (see Update.Age) by at least warning to
+// SeverityMedium, and by at least critical to SeverityCritical. A threshold
+// that is not positive is not applied. By default age does not affect
+// severity.
+func WithAgeSeverity(warning, critical time.Duration) Option {
+	return func(c *Checker) {
+		c.warningAge = warning
+		c.criticalAge = critical
+	}
+}
+
+// rank orders severities.
+func (s Severity) rank() int {
+	switch s {
+	case SeverityMedium:
+		return 1
+	case SeverityHigh:
+		return 2
+	case SeverityCritical:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// AtLeast reports whether s is at least as urgent as threshold.
+func (s Severity) AtLeast(threshold Severity) bool {
+	return s.rank() >= threshold.rank()
+}
+
+// ParseSeverity parses a severity label: low, medium, high, or critical.
+func ParseSeverity(s string) (Severity, bool) {
+	switch sev := Severity(s); sev {
+	case SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical:
+		return sev, true
+	default:
+		return "", false
+	}
+}
+
+// vulnerabilitySeverity returns the severity of fixing a vulnerability from
+// its database's rating. Vulnerabilities that are not rated with a label,
+// such as those rated only with a CVSS vector, are treated as high.
+func vulnerabilitySeverity(v Vulnerability) Severity {
+	switch strings.ToUpper(v.Severity) {
+	case "CRITICAL":
+		return SeverityCritical
+	case "MODERATE", "MEDIUM":
+		return SeverityMedium
+	case "LOW":
+		return SeverityLow
+	default:
+		return SeverityHigh
+	}
+}
+
+// assessSeverity returns the severity of an update: the most urgent of its
+// age (see WithAgeSeverity), the vulnerabilities it fixes, whether it is a
+// breaking change, and whether its current commit vanished.
+func (c *Checker) assessSeverity(u Update) Severity {
+	severity := SeverityLow
+	raise := func(s Severity) {
+		if s.rank() > severity.rank() {
+			severity = s
+		}
+	}
+	if age := u.Age(); age > 0 {
+		if c.warningAge > 0 && age >= c.warningAge {
+			raise(SeverityMedium)
+		}
+		if c.criticalAge > 0 && age >= c.criticalAge {
+			raise(SeverityCritical)
+		}
+	}
+	for _, v := range u.Vulnerabilities {
+		if v.FixedInLatest {
+			raise(vulnerabilitySeverity(v))
+		}
+	}
+	if u.Risk == RiskBreaking {
+		raise(SeverityHigh)
+	}
+	if u.PinVanished {
+		raise(SeverityCritical)
+	}
+	return severity
+}
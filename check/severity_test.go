@@ -0,0 +1,131 @@
+package check
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAssessSeverity(t *testing.T) {
+	const day = 24 * time.Hour
+	aged := func(age time.Duration) Update {
+		latest := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		return Update{CurrentTime: latest.Add(-age), LatestTime: latest}
+	}
+
+	tests := []struct {
+		name   string
+		opts   []Option
+		update Update
+		want   Severity
+	}{
+		{name: "plain update", want: SeverityLow},
+		{
+			name:   "breaking",
+			update: Update{Risk: RiskBreaking},
+			want:   SeverityHigh,
+		},
+		{
+			name:   "feature",
+			update: Update{Risk: RiskFeature},
+			want:   SeverityLow,
+		},
+		{
+			name: "fixes moderate vulnerability",
+			update: Update{Vulnerabilities: []Vulnerability{
+				{ID: "GO-2024-0001", Severity: "MODERATE", FixedInLatest: true},
+			}},
+			want: SeverityMedium,
+		},
+		{
+			name: "vulnerability not fixed in latest",
+			update: Update{Vulnerabilities: []Vulnerability{
+				{ID: "GO-2024-0001", Severity: "CRITICAL"},
+			}},
+			want: SeverityLow,
+		},
+		{
+			name: "fixes unrated vulnerability",
+			update: Update{Vulnerabilities: []Vulnerability{
+				{ID: "GO-2024-0001", Severity: "LOW", FixedInLatest: true},
+				{ID: "GO-2024-0002", FixedInLatest: true},
+			}},
+			want: SeverityHigh,
+		},
+		{
+			name: "fixes critical vulnerability in breaking update",
+			update: Update{
+				Risk: RiskBreaking,
+				Vulnerabilities: []Vulnerability{
+					{ID: "GO-2024-0001", Severity: "critical", FixedInLatest: true},
+				},
+			},
+			want: SeverityCritical,
+		},
+		{
+			name:   "pin vanished",
+			update: Update{PinVanished: true},
+			want:   SeverityCritical,
+		},
+		{
+			name:   "age without thresholds",
+			update: aged(365 * day),
+			want:   SeverityLow,
+		},
+		{
+			name:   "younger than warning age",
+			opts:   []Option{WithAgeSeverity(30*day, 90*day)},
+			update: aged(29 * day),
+			want:   SeverityLow,
+		},
+		{
+			name:   "warning age",
+			opts:   []Option{WithAgeSeverity(30*day, 90*day)},
+			update: aged(30 * day),
+			want:   SeverityMedium,
+		},
+		{
+			name:   "critical age",
+			opts:   []Option{WithAgeSeverity(30*day, 90*day)},
+			update: aged(91 * day),
+			want:   SeverityCritical,
+		},
+		{
+			name:   "critical age disabled",
+			opts:   []Option{WithAgeSeverity(30*day, 0)},
+			update: aged(365 * day),
+			want:   SeverityMedium,
+		},
+		{
+			name: "warning age with breaking change",
+			opts: []Option{WithAgeSeverity(30*day, 90*day)},
+			update: func() Update {
+				u := aged(60 * day)
+				u.Risk = RiskBreaking
+				return u
+			}(),
+			want: SeverityHigh,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewChecker(tt.opts...).assessSeverity(tt.update); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, s := range []string{"low", "medium", "high", "critical"} {
+		if got, ok := ParseSeverity(s); !ok || string(got) != s {
+			t.Errorf("ParseSeverity(%q) = %q, %t", s, got, ok)
+		}
+	}
+	if _, ok := ParseSeverity("moderate"); ok {
+		t.Error("ParseSeverity(\"moderate\") succeeded")
+	}
+	if !SeverityCritical.AtLeast(SeverityHigh) || SeverityMedium.AtLeast(SeverityHigh) {
+		t.Error("severities are misordered")
+	}
+}
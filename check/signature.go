@@ -0,0 +1,101 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// SignatureVerifier checks whether commits are signed.
+type SignatureVerifier interface {
+	// CommitSignature returns the signature status of the commit rev (a
+	// hash, or a prefix of one) in the module's repository. If the module
+	// is not hosted where the verifier can look, the error must wrap
+	// ErrUnsupportedHost.
+	CommitSignature(ctx context.Context, modulePath, rev string) (Signature, error)
+}
+
+// Signature is the signature status of a commit.
+type Signature struct {
+	// Verified is set if the commit is signed and the forge verified the
+	// signature.
+	Verified bool `json:"verified"`
+	// Reason is the forge's explanation of the status, if any, e.g.
+	// "valid", "unsigned", or "unknown_key" on GitHub.
+	Reason string `json:"reason,omitempty"`
+}
+
+// WithSignatureCheck records whether the latest commit of each update has a
+// verified signature in Update.Signature.
+//
+// required is a list of module path patterns, in the form used by GOPRIVATE
+// (see module.MatchPrefixPatterns), for sensitive modules that must not be
+// updated to unverified commits. For these, an update whose latest commit
+// is not verified, or cannot be verified, is not reported as an Update but
+// as a Failure wrapping ErrUnsigned. For other modules, a failed check is
+// logged and leaves Update.Signature unset.
+//
+// By default signatures are not checked.
+func WithSignatureCheck(verifier SignatureVerifier, required []string) Option {
+	return func(c *Checker) {
+		c.signatureVerifier = verifier
+		c.requireSigned = strings.Join(required, ",")
+	}
+}
+
+// checkSignature returns the signature status of the latest version of a
+// module. It returns an error only if the update must be refused.
+func (c *Checker) checkSignature(
+	ctx context.Context,
+	modulePath,
+	latest string,
+) (*Signature, error) {
+	required := module.MatchPrefixPatterns(c.requireSigned, modulePath)
+
+	sig, err := c.commitSignature(ctx, modulePath, latest)
+	switch {
+	case err == nil && !sig.Verified && required:
+		return nil, classify(ErrUnsigned, fmt.Errorf(
+			"latest version %s is not signed with a verified signature (%s), "+
+				"which is required for this module",
+			latest,
+			sig.Reason,
+		))
+	case err == nil:
+		return &sig, nil
+	case required:
+		// Failures wrap at most one sentinel error, so err is not wrapped.
+		return nil, classify(ErrUnsigned, fmt.Errorf(
+			"verifying the signature of latest version %s, which is required for this module: %v",
+			latest,
+			err,
+		))
+	case errors.Is(err, ErrUnsupportedHost):
+		c.log().Debug("cannot verify commit signature", "module", modulePath, "error", err)
+	default:
+		c.log().Warn("verifying commit signature failed", "module", modulePath, "error", err)
+	}
+	return nil, nil
+}
+
+func (c *Checker) commitSignature(
+	ctx context.Context,
+	modulePath,
+	version string,
+) (Signature, error) {
+	rev, err := module.PseudoVersionRev(version)
+	if err != nil {
+		return Signature{}, fmt.Errorf("parsing version %q: %w", version, err)
+	}
+
+	release, err := c.limiter.acquire(ctx, modulePath)
+	if err != nil {
+		return Signature{}, err
+	}
+	defer release()
+
+	return c.signatureVerifier.CommitSignature(ctx, modulePath, rev)
+}
@@ -0,0 +1,98 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type signatureVerifierFunc func(modulePath, rev string) (Signature, error)
+
+func (f signatureVerifierFunc) CommitSignature(
+	_ context.Context,
+	modulePath,
+	rev string,
+) (Signature, error) {
+	return f(modulePath, rev)
+}
+
+func TestWithSignatureCheck(t *testing.T) {
+	const (
+		current = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+		latest  = "v0.0.0-20231201000000-bbbbbbbbbbbb"
+	)
+	signatures := map[string]Signature{
+		"github.com/example/signed":          {Verified: true, Reason: "valid"},
+		"github.com/example/unsigned":        {Reason: "unsigned"},
+		"github.com/sensitive/signed":        {Verified: true, Reason: "valid"},
+		"github.com/sensitive/unsigned":      {Reason: "unsigned"},
+		"github.com/sensitive/unknown-key/x": {Reason: "unknown_key"},
+	}
+	modules := []string{
+		"github.com/example/signed",
+		"github.com/example/unsigned",
+		"example.com/elsewhere",
+		"github.com/sensitive/signed",
+		"github.com/sensitive/unsigned",
+		"github.com/sensitive/unknown-key/x",
+		"example.com/sensitive",
+	}
+
+	resolver := fakeResolver{}
+	var deps []Dependency
+	for _, m := range modules {
+		resolver[m+"@main"] = latest
+		deps = append(deps, Dependency{Module: m, Version: current})
+	}
+	c := NewChecker(
+		WithResolver(resolver),
+		WithBranches(branchMain),
+		WithSignatureCheck(
+			signatureVerifierFunc(func(modulePath, rev string) (Signature, error) {
+				if rev != "bbbbbbbbbbbb" {
+					t.Errorf("got revision %q, want the latest", rev)
+				}
+				sig, ok := signatures[modulePath]
+				if !ok {
+					return Signature{}, fmt.Errorf("%s: %w", modulePath, ErrUnsupportedHost)
+				}
+				return sig, nil
+			}),
+			[]string{"github.com/sensitive", "example.com/sensitive"},
+		),
+	)
+
+	rep := c.Check(t.Context(), deps)
+
+	got := map[string]*Signature{}
+	for _, u := range rep.Updates {
+		got[u.Module] = u.Signature
+	}
+	want := map[string]*Signature{
+		"github.com/example/signed":   {Verified: true, Reason: "valid"},
+		"github.com/example/unsigned": {Reason: "unsigned"},
+		"example.com/elsewhere":       nil,
+		"github.com/sensitive/signed": {Verified: true, Reason: "valid"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got signatures %v, want %v", got, want)
+	}
+
+	var refused []string
+	for _, f := range rep.Failures {
+		if !errors.Is(f.Err, ErrUnsigned) || ErrorCode(f.Err) != CodeUnsigned {
+			t.Errorf("%s: got error %v, want %v", f.Module, f.Err, ErrUnsigned)
+		}
+		refused = append(refused, f.Module)
+	}
+	wantRefused := []string{
+		"github.com/sensitive/unsigned",
+		"github.com/sensitive/unknown-key/x",
+		"example.com/sensitive",
+	}
+	if !reflect.DeepEqual(refused, wantRefused) {
+		t.Errorf("got refused updates %v, want %v", refused, wantRefused)
+	}
+}
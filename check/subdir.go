@@ -0,0 +1,138 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// DirHistory looks up the history of the directories of modules in
+// repositories, such as monorepos, holding more than one module.
+type DirHistory interface {
+	// LastChange returns the hash of the newest commit reachable from rev (a
+	// hash, or a prefix of one) that changed a file in the module's
+	// directory, or "" if none did. For a module at the repository root, it
+	// returns rev. If the module is not hosted where it can look, the error
+	// must wrap ErrUnsupportedHost.
+	LastChange(ctx context.Context, modulePath, rev string) (string, error)
+}
+
+// WithDirHistory resolves the latest version of a module in a subdirectory
+// of its repository, such as github.com/org/monorepo/sub/mod, to the newest
+// commit on the branch that changed the module's directory, using h. The go
+// command resolves a branch to its head, which may only change other modules
+// in the repository, giving a newer pseudo-version of identical code. The
+// commit found is resolved like a branch, so its pseudo-version is based on
+// the module's own prefixed tags, such as sub/mod/v1.2.0, and the update's
+// comparison and CompareURL end at it. If the module has not changed since
+// the current version, there is no update. A failed lookup is logged and
+// leaves the branch head. By default the branch head is used.
+func WithDirHistory(h DirHistory) Option {
+	return func(c *Checker) {
+		c.dirHistory = h
+		c.trackDirs = true
+	}
+}
+
+// Tracking is how the latest version of a module is found on its branch.
+type Tracking string
+
+// Trackings.
+const (
+	// TrackBranch uses the head of the branch.
+	TrackBranch Tracking = "branch"
+	// TrackDir uses the newest commit on the branch that changed the
+	// module's directory (see WithDirHistory).
+	TrackDir Tracking = "dir"
+)
+
+// WithModuleTracking sets the Tracking of particular modules, keyed by
+// module path or glob pattern as in WithModuleBranches, e.g. to track only
+// the modules of one monorepo by directory, or to exempt one from
+// WithDirHistory. TrackDir uses h, or the DirHistory given to
+// WithDirHistory. Modules not matched use TrackDir if WithDirHistory is set
+// and TrackBranch otherwise.
+func WithModuleTracking(h DirHistory, tracking map[string]Tracking) Option {
+	return func(c *Checker) {
+		if c.dirHistory == nil {
+			c.dirHistory = h
+		}
+		c.moduleTracking = tracking
+	}
+}
+
+// tracksDir reports whether the latest version of the module is resolved to
+// the newest commit that changed its directory.
+func (c *Checker) tracksDir(modulePath string) bool {
+	if c.dirHistory == nil {
+		return false
+	}
+	if t, ok := matchModule(c.moduleTracking, modulePath); ok {
+		return t == TrackDir
+	}
+	return c.trackDirs
+}
+
+// resolveDirChange moves res.Latest back from the branch head to the newest
+// commit that changed the module's directory.
+func (c *Checker) resolveDirChange(ctx context.Context, res *Result) {
+	dep := res.Dependency
+	head, err := module.PseudoVersionRev(res.Latest)
+	if err != nil {
+		return
+	}
+
+	release, err := c.limiter.acquire(ctx, dep.Module)
+	if err != nil {
+		return
+	}
+	last, err := c.dirHistory.LastChange(ctx, dep.Module, head)
+	release()
+	switch {
+	case errors.Is(err, ErrUnsupportedHost):
+		c.log().Debug("cannot look up directory history", "module", dep.Module, "error", err)
+		return
+	case err != nil:
+		c.log().Warn("looking up directory history failed", "module", dep.Module, "error", err)
+		return
+	case last == "" || strings.HasPrefix(last, head):
+		return
+	}
+
+	if current, err := module.PseudoVersionRev(dep.Version); err == nil &&
+		strings.HasPrefix(last, current) {
+		res.Latest = dep.Version
+		return
+	}
+	version, err := c.resolveRev(ctx, dep.Module, last)
+	if err != nil {
+		c.log().Warn("resolving last directory change failed", "module", dep.Module, "error", err)
+		return
+	}
+	// If the pin is newer than the last change, e.g. a commit changing
+	// another module, there is nothing to update to.
+	if newer, err := newerVersion(dep.Version, version); err == nil && newer == dep.Version {
+		res.Latest = dep.Version
+		return
+	}
+	res.Latest = version
+}
+
+// moduleSubdir returns the directory of the module in its repository, whose
+// root is the import path prefix root, or "" if it is at the root. A major
+// version suffix such as /v2 is dropped, since the module may be in a v2
+// subdirectory or on a branch at the root, and either way its files are
+// under the directory returned.
+func moduleSubdir(root, modulePath string) string {
+	prefix, _, ok := module.SplitPathVersion(modulePath)
+	if !ok {
+		prefix = modulePath
+	}
+	subdir, ok := strings.CutPrefix(prefix, root+"/")
+	if !ok {
+		return ""
+	}
+	return subdir
+}
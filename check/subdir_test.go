@@ -0,0 +1,141 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+type dirHistoryFunc func(modulePath, rev string) (string, error)
+
+func (f dirHistoryFunc) LastChange(_ context.Context, modulePath, rev string) (string, error) {
+	return f(modulePath, rev)
+}
+
+func TestModuleSubdir(t *testing.T) {
+	tests := []struct {
+		root, module, want string
+	}{
+		{root: "github.com/org/repo", module: "github.com/org/repo"},
+		{root: "github.com/org/repo", module: "github.com/org/repo/v2"},
+		{root: "github.com/org/repo", module: "github.com/org/repo/sub/mod", want: "sub/mod"},
+		{root: "github.com/org/repo", module: "github.com/org/repo/sub/mod/v3", want: "sub/mod"},
+		{root: "github.com/org/repo", module: "github.com/org/repository"},
+		{root: "go.example.com", module: "go.example.com/otel/sdk", want: "otel/sdk"},
+	}
+	for _, tt := range tests {
+		if got := moduleSubdir(tt.root, tt.module); got != tt.want {
+			t.Errorf("moduleSubdir(%q, %q) = %q, want %q", tt.root, tt.module, got, tt.want)
+		}
+	}
+}
+
+func TestWithDirHistory(t *testing.T) {
+	const (
+		current = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+		head    = "v0.0.0-20231201000000-bbbbbbbbbbbb"
+		changed = "v1.2.1-0.20231115000000-cccccccccccc"
+		older   = "v0.0.0-20231001000000-dddddddddddd"
+	)
+	tests := []struct {
+		name       string
+		lastChange string
+		err        error
+		want       string
+	}{
+		{name: "head changed the directory", lastChange: "bbbbbbbbbbbb1234", want: head},
+		{name: "older commit changed the directory", lastChange: "cccccccccccc1234", want: changed},
+		{name: "unchanged since the pin", lastChange: "aaaaaaaaaaaa1234", want: current},
+		{name: "changed before the pin", lastChange: "dddddddddddd1234", want: current},
+		{name: "unsupported host", err: ErrUnsupportedHost, want: head},
+		{name: "failed lookup", err: errors.New("server error"), want: head},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := dirHistoryFunc(func(modulePath, rev string) (string, error) {
+				if modulePath != "github.com/org/repo/sub/mod" || rev != "bbbbbbbbbbbb" {
+					t.Errorf("LastChange(%q, %q)", modulePath, rev)
+				}
+				return tt.lastChange, tt.err
+			})
+			c := NewChecker(
+				WithResolver(fakeResolver{
+					"github.com/org/repo/sub/mod@main":             head,
+					"github.com/org/repo/sub/mod@cccccccccccc1234": changed,
+					"github.com/org/repo/sub/mod@dddddddddddd1234": older,
+				}),
+				WithBranches(branchMain),
+				WithDirHistory(history),
+			)
+			rep := c.Check(t.Context(), []Dependency{
+				{Module: "github.com/org/repo/sub/mod", Version: current},
+			})
+			got := current
+			if len(rep.Updates) == 1 {
+				got = rep.Updates[0].Latest
+			}
+			if got != tt.want {
+				t.Errorf("got latest %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithModuleTracking(t *testing.T) {
+	const (
+		current = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+		head    = "v0.0.0-20231201000000-bbbbbbbbbbbb"
+		changed = "v0.0.0-20231115000000-cccccccccccc"
+	)
+	history := dirHistoryFunc(func(string, string) (string, error) {
+		return "cccccccccccc1234", nil
+	})
+	resolver := fakeResolver{}
+	var deps []Dependency
+	for _, mod := range []string{
+		"github.com/org/mono/a",
+		"github.com/org/mono/b",
+		"github.com/org/other/c",
+	} {
+		resolver[mod+"@main"] = head
+		resolver[mod+"@cccccccccccc1234"] = changed
+		deps = append(deps, Dependency{Module: mod, Version: current})
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{
+			name: "pattern",
+			opts: []Option{
+				WithModuleTracking(history, map[string]Tracking{"github.com/org/mono/*": TrackDir}),
+			},
+			want: []string{changed, changed, head},
+		},
+		{
+			name: "exempt from dir history",
+			opts: []Option{
+				WithDirHistory(history),
+				WithModuleTracking(nil, map[string]Tracking{"github.com/org/mono/b": TrackBranch}),
+			},
+			want: []string{changed, head, changed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithResolver(resolver), WithBranches(branchMain)}, tt.opts...)
+			rep := NewChecker(opts...).Check(t.Context(), deps)
+			var got []string
+			for _, u := range rep.Updates {
+				got = append(got, u.Latest)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got latest versions %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/sumdb"
+)
+
+// SumDBStatus is the outcome of verifying a version against the checksum
+// database.
+type SumDBStatus string
+
+// Checksum database statuses.
+const (
+	// SumDBVerified means the checksum database has a valid entry for the
+	// version.
+	SumDBVerified SumDBStatus = "verified"
+	// SumDBExempt means the module is not checked against the checksum
+	// database, because it matches GONOSUMDB (or GOPRIVATE) or GOSUMDB is
+	// off. The go command does not verify it either.
+	SumDBExempt SumDBStatus = "exempt"
+	// SumDBUnverified means the version could not be verified: the checksum
+	// database has no entry for it, or the lookup failed.
+	SumDBUnverified SumDBStatus = "unverified"
+)
+
+// sumGolangOrg is the go command's default GOSUMDB, with its public key.
+const sumGolangOrg = "sum.golang.org+033de0ae+" +
+	"Ac4zctda0e5eza+HJyk9SxEdh+s3Ka+ZeSQN5ULUDuBNtzXoNiuY1MxdLz"
+
+// maxSumDBResponseSize limits the size of checksum database responses.
+const maxSumDBResponseSize = 1 << 20
+
+// SumDBVerifier checks that versions have valid entries in a checksum
+// database, such as sum.golang.org, as the go command does before using
+// them. Entries are verified against the database's signed, transparent
+// log. Verified tree heads and tiles are cached in memory only. A
+// SumDBVerifier is safe for concurrent use.
+type SumDBVerifier struct {
+	// ops is nil if GOSUMDB is off.
+	ops       *sumdbOps
+	gonosumdb string
+}
+
+// NewSumDBVerifier returns a verifier for the checksum database described by
+// gosumdb, in the format of the GOSUMDB environment variable: "" for
+// sum.golang.org, "off", a database's name and key, or its name, key, and
+// URL separated by a space. Modules matching the comma-separated path
+// prefix patterns in gonosumdb (see GONOSUMDB) are exempt. If client is
+// nil, a client with a one minute timeout is used.
+func NewSumDBVerifier(
+	gosumdb,
+	gonosumdb string,
+	client *http.Client,
+	logger *slog.Logger,
+) (*SumDBVerifier, error) {
+	if gosumdb == "off" {
+		return &SumDBVerifier{}, nil
+	}
+	switch gosumdb {
+	case "", "sum.golang.org":
+		gosumdb = sumGolangOrg
+	case "sum.golang.google.cn":
+		// The go command knows this mirror of sum.golang.org by name.
+		gosumdb = sumGolangOrg + " https://sum.golang.google.cn"
+	}
+
+	key, serverURL, _ := strings.Cut(gosumdb, " ")
+	name, _, _ := strings.Cut(key, "+")
+	if !strings.Contains(key, "+") {
+		return nil, fmt.Errorf(
+			"GOSUMDB %q: key for checksum database %s is not known",
+			gosumdb,
+			name,
+		)
+	}
+	if serverURL == "" {
+		serverURL = "https://" + name
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	if logger == nil {
+		logger = discardLogger()
+	}
+	ops := &sumdbOps{
+		key:     []byte(key),
+		url:     strings.TrimRight(strings.TrimSpace(serverURL), "/"),
+		client:  client,
+		logger:  logger,
+		configs: map[string][]byte{},
+		cache:   map[string][]byte{},
+	}
+	return &SumDBVerifier{ops: ops, gonosumdb: gonosumdb}, nil
+}
+
+// Verify verifies module@version against the checksum database. An error
+// explains why a version is SumDBUnverified. Requests to the database stop
+// when ctx is done.
+func (v *SumDBVerifier) Verify(
+	ctx context.Context,
+	modulePath,
+	version string,
+) (SumDBStatus, error) {
+	if v.ops == nil {
+		return SumDBExempt, nil
+	}
+	// sumdb.ClientOps has no context, so each lookup has its own client,
+	// which shares the verified tree head and tiles kept by v.ops.
+	client := sumdb.NewClient(&sumdbLookup{sumdbOps: v.ops, ctx: ctx})
+	client.SetGONOSUMDB(v.gonosumdb)
+	if _, err := client.Lookup(modulePath, version); err != nil {
+		if errors.Is(err, sumdb.ErrGONOSUMDB) {
+			return SumDBExempt, nil
+		}
+		return SumDBUnverified, err
+	}
+	return SumDBVerified, nil
+}
+
+// WithSumDBVerifier verifies the latest version of each updated dependency
+// against the checksum database with v, recording the result in
+// Update.SumDB. By default versions are not verified.
+func WithSumDBVerifier(v *SumDBVerifier) Option {
+	return func(c *Checker) { c.sumDBVerifier = v }
+}
+
+// sumdbOps implements sumdb.ClientOps over HTTP, keeping configuration and
+// cache files in memory.
+type sumdbOps struct {
+	key    []byte
+	url    string
+	client *http.Client
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	configs map[string][]byte
+	cache   map[string][]byte
+}
+
+// sumdbLookup implements sumdb.ClientOps for one lookup, making requests with
+// its context.
+type sumdbLookup struct {
+	*sumdbOps
+
+	ctx context.Context //nolint:containedctx // sumdb.ClientOps has no context
+}
+
+func (l *sumdbLookup) ReadRemote(path string) ([]byte, error) {
+	return l.readRemote(l.ctx, path)
+}
+
+// readRemote fetches path from the checksum database.
+func (o *sumdbOps) readRemote(ctx context.Context, path string) ([]byte, error) {
+	u := o.url + path
+	o.logger.Debug("querying checksum database", "url", u)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("querying checksum database: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSumDBResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading checksum database response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(data))
+		if msg == "" {
+			msg = resp.Status
+		}
+		return nil, fmt.Errorf("checksum database: %s", msg)
+	}
+	return data, nil
+}
+
+func (o *sumdbOps) ReadConfig(file string) ([]byte, error) {
+	if file == "key" {
+		return o.key, nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	// A missing latest tree head is empty, meaning none is known yet.
+	return o.configs[file], nil
+}
+
+func (o *sumdbOps) WriteConfig(file string, old, new []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if string(o.configs[file]) != string(old) {
+		return sumdb.ErrWriteConflict
+	}
+	o.configs[file] = new
+	return nil
+}
+
+func (o *sumdbOps) ReadCache(file string) ([]byte, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	data, ok := o.cache[file]
+	if !ok {
+		return nil, errors.New("not cached")
+	}
+	return data, nil
+}
+
+func (o *sumdbOps) WriteCache(file string, data []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.cache[file] = data
+}
+
+func (o *sumdbOps) Log(msg string) {
+	o.logger.Debug(msg)
+}
+
+// SecurityError logs msg. The client then fails the lookup with
+// sumdb.ErrSecurity, so the version is reported as unverified.
+func (o *sumdbOps) SecurityError(msg string) {
+	o.logger.Error("checksum database security error", "error", msg)
+}
@@ -0,0 +1,108 @@
+package check
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+)
+
+func TestSumDBVerifier(t *testing.T) {
+	const (
+		known   = "v0.0.0-20231201000000-bbbbbbbbbbbb"
+		unknown = "v0.0.0-20231201000000-cccccccccccc"
+	)
+
+	skey, vkey, err := note.GenerateKey(rand.Reader, "sumdb.example.com")
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	server := httptest.NewServer(sumdb.NewServer(sumdb.NewTestServer(
+		skey,
+		func(path, vers string) ([]byte, error) {
+			if vers != known {
+				return nil, os.ErrNotExist
+			}
+			return fmt.Appendf(
+				nil,
+				"%s %s h1:AAAA=\n%s %s/go.mod h1:BBBB=\n",
+				path, vers, path, vers,
+			), nil
+		},
+	)))
+	defer server.Close()
+
+	v, err := NewSumDBVerifier(vkey+" "+server.URL, "example.com/private", server.Client(), nil)
+	if err != nil {
+		t.Fatalf("creating verifier: %v", err)
+	}
+
+	tests := []struct {
+		module  string
+		version string
+		want    SumDBStatus
+		wantErr bool
+	}{
+		{module: "example.com/a", version: known, want: SumDBVerified},
+		{module: "example.com/b", version: known, want: SumDBVerified},
+		{module: "example.com/a", version: unknown, want: SumDBUnverified, wantErr: true},
+		{module: "example.com/private/x", version: unknown, want: SumDBExempt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.module+"@"+tt.version, func(t *testing.T) {
+			got, err := v.Verify(t.Context(), tt.module, tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	got, err := v.Verify(ctx, "example.com/c", known)
+	if got != SumDBUnverified || err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Errorf(
+			"got %q, %v after cancel, want %q and a cancellation error",
+			got,
+			err,
+			SumDBUnverified,
+		)
+	}
+
+	c := NewChecker(
+		WithResolver(fakeResolver{"example.com/a@main": known}),
+		WithBranches(branchMain),
+		WithLogger(discardLogger()),
+		WithSumDBVerifier(v),
+	)
+	rep := c.Check(t.Context(), []Dependency{
+		{Module: "example.com/a", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+	})
+	if len(rep.Updates) != 1 || rep.Updates[0].SumDB != SumDBVerified {
+		t.Errorf("got updates %+v, want one verified update", rep.Updates)
+	}
+}
+
+func TestNewSumDBVerifier(t *testing.T) {
+	v, err := NewSumDBVerifier("off", "", nil, nil)
+	if err != nil {
+		t.Fatalf("creating verifier: %v", err)
+	}
+	if got, _ := v.Verify(t.Context(), "example.com/a", "v1.0.0"); got != SumDBExempt {
+		t.Errorf("got %q with GOSUMDB=off, want %q", got, SumDBExempt)
+	}
+
+	if _, err := NewSumDBVerifier("sumdb.example.com", "", nil, nil); err == nil {
+		t.Error("expected error for database without a key, got nil")
+	}
+}
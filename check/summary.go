@@ -0,0 +1,87 @@
+package check
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Summary is aggregate freshness statistics of a Report.
+type Summary struct {
+	// Pinned is how many pseudo-versioned dependencies were checked.
+	Pinned int `json:"pinned"`
+	// Stale is how many of them have updates.
+	Stale int `json:"stale"`
+	// Failed is how many of them could not be checked.
+	Failed int `json:"failed"`
+	// MedianAgeSeconds is the median of how much older the updates' current
+	// commits are than their latest ones, in seconds.
+	MedianAgeSeconds int64 `json:"median_age_seconds"`
+	// MaxAgeSeconds is the largest of those ages, in seconds.
+	MaxAgeSeconds int64 `json:"max_age_seconds"`
+	// Stalest is the module of the update with the largest age, if any.
+	Stalest string `json:"stalest,omitempty"`
+}
+
+// Summarize returns the report's freshness statistics. Ages are those of
+// the updates (see Update.Age).
+func (r Report) Summarize() Summary {
+	s := Summary{
+		Pinned: len(r.Dependencies),
+		Stale:  len(r.Updates),
+		Failed: len(r.Failures),
+	}
+	if len(r.Updates) == 0 {
+		return s
+	}
+
+	ages := make([]time.Duration, len(r.Updates))
+	var stalest Update
+	for i, u := range r.Updates {
+		ages[i] = u.Age()
+		if i == 0 || ages[i] > stalest.Age() {
+			stalest = u
+		}
+	}
+	s.Stalest = stalest.Module
+	s.MaxAgeSeconds = int64(stalest.Age() / time.Second)
+	slices.Sort(ages)
+	median := ages[len(ages)/2]
+	if len(ages)%2 == 0 {
+		median = (ages[len(ages)/2-1] + median) / 2
+	}
+	s.MedianAgeSeconds = int64(median / time.Second)
+	return s
+}
+
+// RunSummary is the outcome of a run of checks, in a form for tracking
+// trends: how many dependencies were checked, how many are stale (have
+// updates), how many failed, and how long it took.
+type RunSummary struct {
+	Checked    int   `json:"checked"`
+	Stale      int   `json:"stale"`
+	Errors     int   `json:"errors"`
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// NewRunSummary returns the summary of a run that produced rep in d.
+func NewRunSummary(rep Report, d time.Duration) RunSummary {
+	return RunSummary{
+		Checked:    len(rep.Dependencies),
+		Stale:      len(rep.Updates),
+		Errors:     len(rep.Failures),
+		DurationMs: d.Milliseconds(),
+	}
+}
+
+// String returns the summary as a line of key=value pairs, such as
+// "checked=12 stale=3 errors=1 duration=8.2s".
+func (s RunSummary) String() string {
+	return fmt.Sprintf(
+		"checked=%d stale=%d errors=%d duration=%.1fs",
+		s.Checked,
+		s.Stale,
+		s.Errors,
+		float64(s.DurationMs)/1000,
+	)
+}
@@ -0,0 +1,84 @@
+package check
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2023, 1, d, 0, 0, 0, 0, time.UTC) }
+	update := func(module string, current, latest int) Update {
+		return Update{Module: module, CurrentTime: day(current), LatestTime: day(latest)}
+	}
+
+	tests := []struct {
+		name string
+		rep  Report
+		want Summary
+	}{
+		{
+			name: "no updates",
+			rep: Report{
+				Dependencies: []Dependency{{Module: "a"}, {Module: "b"}},
+				Failures:     []Failure{{Module: "b", Err: errors.New("x")}},
+			},
+			want: Summary{Pinned: 2, Failed: 1},
+		},
+		{
+			name: "odd number of updates",
+			rep: Report{
+				Dependencies: []Dependency{{Module: "a"}, {Module: "b"}, {Module: "c"}},
+				Updates: []Update{
+					update("a", 1, 3),
+					update("b", 1, 11),
+					update("c", 1, 5),
+				},
+			},
+			want: Summary{
+				Pinned:           3,
+				Stale:            3,
+				MedianAgeSeconds: 4 * 24 * 60 * 60,
+				MaxAgeSeconds:    10 * 24 * 60 * 60,
+				Stalest:          "b",
+			},
+		},
+		{
+			name: "even number of updates",
+			rep: Report{
+				Dependencies: []Dependency{{Module: "a"}, {Module: "b"}},
+				Updates:      []Update{update("a", 1, 2), update("b", 1, 4)},
+			},
+			want: Summary{
+				Pinned:           2,
+				Stale:            2,
+				MedianAgeSeconds: 2 * 24 * 60 * 60,
+				MaxAgeSeconds:    3 * 24 * 60 * 60,
+				Stalest:          "b",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rep.Summarize(); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	rep := Report{
+		Dependencies: make([]Dependency, 12),
+		Updates:      make([]Update, 3),
+		Failures:     make([]Failure, 1),
+	}
+	s := NewRunSummary(rep, 8249*time.Millisecond)
+	if want := (RunSummary{Checked: 12, Stale: 3, Errors: 1, DurationMs: 8249}); s != want {
+		t.Errorf("got %+v, want %+v", s, want)
+	}
+	if got, want := s.String(), "checked=12 stale=3 errors=1 duration=8.2s"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
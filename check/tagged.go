@@ -0,0 +1,130 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// TaggedUpdate is a newer release of a requirement that is not
+// pseudo-versioned (see WithTaggedUpdates).
+type TaggedUpdate struct {
+	// Module is the module path.
+	Module string `json:"module"`
+	// Current is the version required in go.mod.
+	Current string `json:"current"`
+	// Latest is the module's latest tagged version.
+	Latest string `json:"latest"`
+}
+
+// WithTaggedUpdates also checks the requirements in the go.mod file given to
+// CheckGoMod that are at tagged versions, using lister to find their latest
+// releases, and records the newer ones in Report.TaggedUpdates, as
+// 'go list -u -m all' would report them. A +incompatible version is only
+// considered if the requirement is at one, and a prerelease only if the
+// module has no releases, as the go command chooses. Indirect requirements
+// are skipped unless WithIncludeIndirect is set. A failed lookup is a failure.
+//
+// Tagged requirements are not checked if CheckGoMod is given modules to
+// check. Check and Stream do not know the go.mod file, so they ignore this
+// option. By default only pseudo-versioned requirements are checked.
+func WithTaggedUpdates(lister TagLister) Option {
+	return func(c *Checker) {
+		c.taggedLister = lister
+	}
+}
+
+// findTaggedDeps returns the requirements in the go.mod file at gomodPath at
+// tagged versions, that is, not pseudo-versions.
+func findTaggedDeps(gomodPath string, includeIndirect bool) ([]Dependency, error) {
+	data, err := os.ReadFile(filepath.Clean(gomodPath))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", gomodPath, err)
+	}
+	f, err := modfile.Parse(gomodPath, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", gomodPath, err)
+	}
+
+	var deps []Dependency
+	for _, req := range f.Require {
+		if module.IsPseudoVersion(req.Mod.Version) || req.Indirect && !includeIndirect {
+			continue
+		}
+		deps = append(deps, Dependency{Module: req.Mod.Path, Version: req.Mod.Version})
+	}
+	return deps, nil
+}
+
+// checkTaggedUpdates records the newer releases of the tagged requirements
+// in the go.mod file at gomodPath in rep.
+func (c *Checker) checkTaggedUpdates(ctx context.Context, gomodPath string, rep *Report) error {
+	deps, err := findTaggedDeps(gomodPath, c.includeIndirect)
+	if err != nil {
+		return err
+	}
+
+	latest := make([]string, len(deps))
+	errs := make([]error, len(deps))
+	sem := make(chan struct{}, max(c.concurrency, 1))
+	var wg sync.WaitGroup
+	for i, dep := range deps {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			latest[i], errs[i] = c.latestRelease(ctx, dep)
+		})
+	}
+	wg.Wait()
+
+	for i, dep := range deps {
+		if errs[i] != nil {
+			rep.Failures = append(rep.Failures, Failure{Module: dep.Module, Err: errs[i]})
+			continue
+		}
+		if latest[i] != "" && semver.Compare(latest[i], dep.Version) > 0 {
+			rep.TaggedUpdates = append(rep.TaggedUpdates, TaggedUpdate{
+				Module:  dep.Module,
+				Current: dep.Version,
+				Latest:  latest[i],
+			})
+		}
+	}
+	return nil
+}
+
+// latestRelease returns the latest tagged version of the dependency's
+// module that the go command would upgrade to, or "" if there is none.
+func (c *Checker) latestRelease(ctx context.Context, dep Dependency) (string, error) {
+	release, err := c.limiter.acquire(ctx, dep.Module)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	versions, err := c.taggedLister.Versions(ctx, dep.Module)
+	if err != nil {
+		return "", fmt.Errorf("listing versions of %s: %w", dep.Module, err)
+	}
+	return latestTag(compatibleVersions(dep.Version, versions)), nil
+}
+
+// compatibleVersions returns versions without +incompatible ones unless
+// version is itself +incompatible, since the go command does not upgrade a
+// module with a go.mod file to them.
+func compatibleVersions(version string, versions []string) []string {
+	if strings.HasSuffix(version, "+incompatible") {
+		return versions
+	}
+	return slices.DeleteFunc(slices.Clone(versions), func(v string) bool {
+		return strings.HasSuffix(v, "+incompatible")
+	})
+}
@@ -0,0 +1,80 @@
+package check
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWithTaggedUpdates(t *testing.T) {
+	gomod := filepath.Join(t.TempDir(), "go.mod")
+	content := "module example.com/app\n\ngo 1.21\n\nrequire (\n" +
+		"\tgithub.com/example/stale v1.2.0\n" +
+		"\tgithub.com/example/current v1.10.0\n" +
+		"\tgithub.com/example/incompatible v2.0.0+incompatible\n" +
+		"\tgithub.com/example/compatible v1.0.0\n" +
+		"\tgithub.com/example/prerelease v0.2.0-rc.1\n" +
+		"\tgithub.com/example/broken v1.0.0\n" +
+		"\tgithub.com/example/indirect v1.0.0 // indirect\n" +
+		"\tgo4.org/netipx v0.0.0-20231101000000-aaaaaaaaaaaa\n" +
+		")\n"
+	if err := os.WriteFile(gomod, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	versions := map[string][]string{
+		"github.com/example/stale":        {"v1.2.0", "v1.10.0", "v1.11.0-rc.1"},
+		"github.com/example/current":      {"v1.2.0", "v1.10.0"},
+		"github.com/example/incompatible": {"v1.0.0", "v2.0.0+incompatible", "v3.1.0+incompatible"},
+		"github.com/example/compatible":   {"v1.0.0", "v2.0.0+incompatible"},
+		"github.com/example/prerelease":   {"v0.2.0-rc.1", "v0.2.0-rc.2"},
+		"github.com/example/indirect":     {"v1.0.0", "v1.1.0"},
+	}
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"go4.org/netipx@main": "v0.0.0-20231101000000-aaaaaaaaaaaa",
+		}),
+		WithBranches(branchMain),
+		WithTaggedUpdates(tagListerFunc(func(modulePath string) ([]string, error) {
+			v, ok := versions[modulePath]
+			if !ok {
+				return nil, errors.New("proxy unavailable")
+			}
+			return v, nil
+		})),
+	)
+
+	rep, err := c.CheckGoMod(t.Context(), gomod)
+	if err != nil {
+		t.Fatalf("CheckGoMod: %v", err)
+	}
+	want := []TaggedUpdate{
+		{Module: "github.com/example/stale", Current: "v1.2.0", Latest: "v1.10.0"},
+		{
+			Module:  "github.com/example/incompatible",
+			Current: "v2.0.0+incompatible",
+			Latest:  "v3.1.0+incompatible",
+		},
+		{Module: "github.com/example/prerelease", Current: "v0.2.0-rc.1", Latest: "v0.2.0-rc.2"},
+	}
+	if !reflect.DeepEqual(rep.TaggedUpdates, want) {
+		t.Errorf("got tagged updates %+v, want %+v", rep.TaggedUpdates, want)
+	}
+	if len(rep.Failures) != 1 || rep.Failures[0].Module != "github.com/example/broken" {
+		t.Errorf("got failures %+v, want one for github.com/example/broken", rep.Failures)
+	}
+	if len(rep.Dependencies) != 1 {
+		t.Errorf("got dependencies %+v, want only the pseudo-versioned one", rep.Dependencies)
+	}
+
+	// Tagged requirements are not checked when checking given modules.
+	rep, err = c.CheckGoMod(t.Context(), gomod, "go4.org/netipx")
+	if err != nil {
+		t.Fatalf("CheckGoMod: %v", err)
+	}
+	if rep.TaggedUpdates != nil || rep.Failures != nil {
+		t.Errorf("got tagged updates %+v and failures %+v", rep.TaggedUpdates, rep.Failures)
+	}
+}
@@ -0,0 +1,100 @@
+package check
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// TagLister lists the tagged versions of modules.
+type TagLister interface {
+	// Versions returns the module's tagged versions, in any order, as module
+	// proxies serve them from $GOPROXY/<module>/@v/list. Pseudo-versions are
+	// not included.
+	Versions(ctx context.Context, modulePath string) ([]string, error)
+}
+
+// TagStatus is whether a module has tagged releases.
+type TagStatus string
+
+// Tag statuses.
+const (
+	// TagStatusNeverTagged means the module has no tagged versions, so
+	// requiring a commit is the only way to depend on it.
+	TagStatusNeverTagged TagStatus = "never-tagged"
+	// TagStatusTagged means the module has tagged versions, so a tagged
+	// release could be required instead of a commit and kept up to date by
+	// tools such as Dependabot.
+	TagStatusTagged TagStatus = "tagged"
+)
+
+// WithTagCheck uses lister to look up whether each dependency's module has
+// any tagged versions, whether or not it has an update, and records it in
+// Dependency.Tags and Dependency.LatestTag. As for WithTaggedUpdates,
+// +incompatible versions only count for +incompatible pseudo-versions.
+// Dependencies whose pseudo-version is based on a tag also get
+// Dependency.BaseTag, so tags released since can be reported (see
+// Dependency.BaseTagOutdated). A failed lookup is logged and leaves them
+// empty. By default tags are not looked up.
+func WithTagCheck(lister TagLister) Option {
+	return func(c *Checker) {
+		c.tagLister = lister
+	}
+}
+
+// checkTags sets the tag status of dep.
+func (c *Checker) checkTags(ctx context.Context, dep *Dependency) error {
+	release, err := c.limiter.acquire(ctx, dep.Module)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	versions, err := c.tagLister.Versions(ctx, dep.Module)
+	if err != nil {
+		return fmt.Errorf("listing versions of %s: %w", dep.Module, err)
+	}
+	dep.LatestTag = latestTag(compatibleVersions(dep.Version, versions))
+	dep.Tags = TagStatusNeverTagged
+	if dep.LatestTag != "" {
+		dep.Tags = TagStatusTagged
+	}
+	// Pseudo-versions such as v0.0.0-20231101000000-aaaaaaaaaaaa have no
+	// base, and are not expected to fail to parse.
+	dep.BaseTag, _ = module.PseudoVersionBase(dep.Version)
+	return nil
+}
+
+// BaseTagOutdated reports whether the dependency's pseudo-version is based on
+// a tag and a newer tag has since been released (see WithTagCheck). For
+// example, v1.1.1-0.20231101000000-aaaaaaaaaaaa is based on v1.1.0, so it is
+// outdated once v1.4.0 is released. This is a stronger signal than a newer
+// commit: upstream has cut releases since the commit was chosen.
+func (d Dependency) BaseTagOutdated() bool {
+	return d.BaseTag != "" && d.LatestTag != "" && semver.Compare(d.LatestTag, d.BaseTag) > 0
+}
+
+// latestTag returns the highest release in versions, or the highest
+// prerelease if there are no releases, as the go command picks the latest
+// version. It returns "" if versions has no valid semantic versions.
+func latestTag(versions []string) string {
+	var release, prerelease string
+	for _, v := range versions {
+		if !semver.IsValid(v) {
+			continue
+		}
+		if semver.Prerelease(v) == "" {
+			if release == "" || semver.Compare(v, release) > 0 {
+				release = v
+			}
+		} else if prerelease == "" || semver.Compare(v, prerelease) > 0 {
+			prerelease = v
+		}
+	}
+	if release != "" {
+		return release
+	}
+	return prerelease
+}
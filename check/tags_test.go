@@ -0,0 +1,140 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type tagListerFunc func(modulePath string) ([]string, error)
+
+func (f tagListerFunc) Versions(_ context.Context, modulePath string) ([]string, error) {
+	return f(modulePath)
+}
+
+func TestWithTagCheck(t *testing.T) {
+	versions := map[string][]string{
+		"github.com/example/tagged":   {"v1.2.0", "v1.10.0", "v2.0.0-rc.1"},
+		"github.com/example/based":    {"v1.1.0"},
+		"github.com/example/untagged": nil,
+		// A module with a go.mod file is not upgraded to +incompatible
+		// versions, but one without is.
+		"github.com/example/compatible": {"v1.0.0", "v2.0.0+incompatible"},
+		"github.com/example/legacy":     {"v2.0.0+incompatible", "v2.1.0+incompatible"},
+		"github.com/example/pre":        {"v1.2.4-pre", "v1.2.4"},
+	}
+	const (
+		compatible = "v1.0.1-0.20231101000000-aaaaaaaaaaaa"
+		legacy     = "v2.0.1-0.20231101000000-aaaaaaaaaaaa+incompatible"
+		pre        = "v1.2.4-pre.0.20231101000000-aaaaaaaaaaaa"
+	)
+
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"github.com/example/tagged@main":     "v0.0.0-20231201000000-bbbbbbbbbbbb",
+			"github.com/example/untagged@main":   "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			"github.com/example/based@main":      "v1.1.1-0.20231101000000-aaaaaaaaaaaa",
+			"github.com/example/broken@main":     "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			"github.com/example/compatible@main": compatible,
+			"github.com/example/legacy@main":     legacy,
+			"github.com/example/pre@main":        pre,
+		}),
+		WithBranches(branchMain),
+		WithTagCheck(tagListerFunc(func(modulePath string) ([]string, error) {
+			v, ok := versions[modulePath]
+			if !ok {
+				return nil, errors.New("proxy unavailable")
+			}
+			return v, nil
+		})),
+	)
+
+	deps := []Dependency{
+		{Module: "github.com/example/tagged", Version: "v1.2.1-0.20231101000000-aaaaaaaaaaaa"},
+		{Module: "github.com/example/untagged", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "github.com/example/based", Version: "v1.1.1-0.20231101000000-aaaaaaaaaaaa"},
+		{Module: "github.com/example/broken", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "github.com/example/compatible", Version: compatible},
+		{Module: "github.com/example/legacy", Version: legacy},
+		{Module: "github.com/example/pre", Version: pre},
+	}
+	rep := c.Check(t.Context(), deps)
+
+	want := []Dependency{
+		{
+			Module:    "github.com/example/tagged",
+			Version:   "v1.2.1-0.20231101000000-aaaaaaaaaaaa",
+			Tags:      TagStatusTagged,
+			LatestTag: "v1.10.0",
+			BaseTag:   "v1.2.0",
+		},
+		{
+			Module:  "github.com/example/untagged",
+			Version: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			Tags:    TagStatusNeverTagged,
+		},
+		{
+			Module:    "github.com/example/based",
+			Version:   "v1.1.1-0.20231101000000-aaaaaaaaaaaa",
+			Tags:      TagStatusTagged,
+			LatestTag: "v1.1.0",
+			BaseTag:   "v1.1.0",
+		},
+		{Module: "github.com/example/broken", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{
+			Module:    "github.com/example/compatible",
+			Version:   compatible,
+			Tags:      TagStatusTagged,
+			LatestTag: "v1.0.0",
+			BaseTag:   "v1.0.0",
+		},
+		{
+			Module:    "github.com/example/legacy",
+			Version:   legacy,
+			Tags:      TagStatusTagged,
+			LatestTag: "v2.1.0+incompatible",
+			BaseTag:   "v2.0.0+incompatible",
+		},
+		{
+			Module:    "github.com/example/pre",
+			Version:   pre,
+			Tags:      TagStatusTagged,
+			LatestTag: "v1.2.4",
+			BaseTag:   "v1.2.4-pre",
+		},
+	}
+	if !reflect.DeepEqual(rep.Dependencies, want) {
+		t.Errorf("got dependencies %+v, want %+v", rep.Dependencies, want)
+	}
+	for i, want := range []bool{true, false, false, false, false, true, true} {
+		if got := rep.Dependencies[i].BaseTagOutdated(); got != want {
+			t.Errorf("%s: got BaseTagOutdated %t, want %t", deps[i].Module, got, want)
+		}
+	}
+	if deps[0].Tags != "" {
+		t.Error("Check modified its argument")
+	}
+	if len(rep.Failures) != 0 {
+		t.Errorf("got failures %+v, want none", rep.Failures)
+	}
+}
+
+func TestLatestTag(t *testing.T) {
+	tests := []struct {
+		versions []string
+		want     string
+	}{
+		{versions: nil, want: ""},
+		{versions: []string{"v1.2.0", "v1.10.0", "v1.9.0"}, want: "v1.10.0"},
+		{versions: []string{"v1.2.0", "v2.0.0-beta.1"}, want: "v1.2.0"},
+		{versions: []string{"v0.1.0-alpha", "v0.1.0-beta"}, want: "v0.1.0-beta"},
+		{versions: []string{"latest"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := latestTag(tt.versions); got != tt.want {
+			t.Errorf("latestTag(%q) = %q, want %q", tt.versions, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package check
+
+import (
+	"context"
+	"time"
+)
+
+// Timing is how long checking a dependency took, and how its branches were
+// resolved, to find slow proxies and hosts and tune concurrency (see
+// WithTimings).
+type Timing struct {
+	Module string `json:"module"`
+	// DurationMs is how long checking the dependency took in all, in
+	// milliseconds, including waiting for host limits and the lookups of
+	// other options, such as WithComparer.
+	DurationMs int64 `json:"duration_ms"`
+	// ResolveMs is how long resolving its branches with the Resolver took,
+	// in milliseconds.
+	ResolveMs int64 `json:"resolve_ms"`
+	// Queries is how many branches were resolved with the Resolver, and
+	// CacheHits how many were served from the cache (see WithCache).
+	Queries   int `json:"queries"`
+	CacheHits int `json:"cache_hits"`
+}
+
+// WithTimings adds the Timing of each dependency checked to
+// Report.Timings. Timings are always logged at the info level. By default
+// they are not reported.
+func WithTimings(enabled bool) Option {
+	return func(c *Checker) { c.timings = enabled }
+}
+
+// timingKey is the context key of the *timing of the dependency being
+// checked.
+type timingKey struct{}
+
+// timing accumulates a dependency's Timing while it is checked. Its branches
+// are resolved one at a time, so it needs no lock.
+type timing struct {
+	resolve            time.Duration
+	queries, cacheHits int
+}
+
+// withTiming returns a context carrying a new timing.
+func withTiming(ctx context.Context) (context.Context, *timing) {
+	t := &timing{}
+	return context.WithValue(ctx, timingKey{}, t), t
+}
+
+// timingFrom returns the timing carried by ctx, or nil.
+func timingFrom(ctx context.Context) *timing {
+	t, _ := ctx.Value(timingKey{}).(*timing)
+	return t
+}
+
+// recordQuery records a branch resolved with the Resolver in d.
+func (t *timing) recordQuery(d time.Duration) {
+	if t != nil {
+		t.resolve += d
+		t.queries++
+	}
+}
+
+// recordCacheHit records a branch served from the cache.
+func (t *timing) recordCacheHit() {
+	if t != nil {
+		t.cacheHits++
+	}
+}
@@ -0,0 +1,67 @@
+package check
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithTimings(t *testing.T) {
+	const (
+		current = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+		latest  = "v0.0.0-20231201000000-bbbbbbbbbbbb"
+	)
+	cache, err := NewCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deps := []Dependency{
+		{Module: "example.com/a", Version: current},
+		{Module: "example.com/b", Version: current},
+	}
+	resolver := fakeResolver{"example.com/a@main": latest}
+	opts := []Option{
+		WithResolver(resolver),
+		WithBranches(branchMain, branchMaster),
+		WithCache(cache),
+		WithTimings(true),
+	}
+
+	rep := NewChecker(opts...).Check(t.Context(), deps)
+	want := []Timing{
+		// main exists, master does not.
+		{Module: "example.com/a", Queries: 2},
+		// Neither exists.
+		{Module: "example.com/b", Queries: 2},
+	}
+	checkTimings(t, rep.Timings, want)
+
+	// The second check is served from the cache.
+	rep = NewChecker(opts...).Check(t.Context(), deps)
+	want = []Timing{
+		{Module: "example.com/a", CacheHits: 2},
+		{Module: "example.com/b", CacheHits: 2},
+	}
+	checkTimings(t, rep.Timings, want)
+
+	rep = NewChecker(WithResolver(resolver), WithBranches(branchMain)).Check(t.Context(), deps)
+	if rep.Timings != nil {
+		t.Errorf("got timings %+v without WithTimings", rep.Timings)
+	}
+}
+
+// checkTimings compares timings with want, ignoring durations.
+func checkTimings(t *testing.T, got, want []Timing) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got timings %+v, want %+v", got, want)
+	}
+	for i := range got {
+		if got[i].DurationMs < got[i].ResolveMs {
+			t.Errorf("timing %+v: duration is less than resolution", got[i])
+		}
+		got[i].DurationMs, got[i].ResolveMs = 0, 0
+		if got[i] != want[i] {
+			t.Errorf("got timing %+v, want %+v", got[i], want[i])
+		}
+	}
+}
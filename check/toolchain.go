@@ -0,0 +1,179 @@
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/version"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+)
+
+// DefaultGoDownloadsURL is the URL of the list of Go releases served by
+// go.dev.
+const DefaultGoDownloadsURL = "https://go.dev/dl/?mode=json"
+
+// GoReleaseSource looks up Go releases.
+type GoReleaseSource interface {
+	// LatestGo returns the latest stable Go release, such as go1.23.2.
+	LatestGo(ctx context.Context) (string, error)
+}
+
+// GoDownloads is a GoReleaseSource using the JSON list of releases on the Go
+// download page.
+type GoDownloads struct {
+	url    string
+	client *http.Client
+}
+
+// NewGoDownloads returns a GoDownloads fetching the list of releases from url
+// (DefaultGoDownloadsURL if empty). If client is nil, a client with a one
+// minute timeout is used.
+func NewGoDownloads(url string, client *http.Client) *GoDownloads {
+	if url == "" {
+		url = DefaultGoDownloadsURL
+	}
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	return &GoDownloads{url: url, client: client}
+}
+
+// LatestGo implements GoReleaseSource.
+func (g *GoDownloads) LatestGo(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.url, nil)
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("listing Go releases: %w", err)
+		if isTimeout(err) {
+			return "", classify(ErrTimeout, err)
+		}
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+		return "", classify(
+			classifyStatus(resp.StatusCode, ""),
+			errors.New("listing Go releases: "+resp.Status),
+		)
+	}
+
+	var releases []struct {
+		Version string `json:"version"`
+		Stable  bool   `json:"stable"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return "", fmt.Errorf("parsing Go releases: %w", err)
+	}
+	var latest string
+	for _, r := range releases {
+		if r.Stable && version.IsValid(r.Version) && version.Compare(r.Version, latest) > 0 {
+			latest = r.Version
+		}
+	}
+	if latest == "" {
+		return "", errors.New("listing Go releases: no stable release found")
+	}
+	return latest, nil
+}
+
+// ToolchainStatus is how the Go version a go.mod file declares compares with
+// the latest Go release (see WithToolchainCheck).
+type ToolchainStatus struct {
+	// Go is the go directive's version, such as 1.21 or 1.21.3.
+	Go string `json:"go"`
+	// Toolchain is the toolchain directive's name, such as go1.21.5, if
+	// there is one.
+	Toolchain string `json:"toolchain,omitempty"`
+	// Latest is the latest stable Go release, such as go1.23.2.
+	Latest string `json:"latest"`
+	// Outdated is set if the toolchain, or the go version if there is no
+	// toolchain directive, is older than Latest.
+	Outdated bool `json:"outdated,omitempty"`
+	// Unsupported is set if it is older than the two most recent major
+	// releases, which are the ones that receive security fixes.
+	Unsupported bool `json:"unsupported,omitempty"`
+}
+
+// Current returns the toolchain the module asks for: the toolchain
+// directive's, or if there is none, the go directive's version, such as
+// go1.21.
+func (s ToolchainStatus) Current() string {
+	if s.Toolchain != "" {
+		return s.Toolchain
+	}
+	return "go" + s.Go
+}
+
+// WithToolchainCheck compares the go and toolchain directives of the go.mod
+// file given to CheckGoMod with the latest Go release from src, and records
+// the result in Report.Toolchain. A go.mod file without a go directive is
+// skipped, and a failed lookup is logged. The directives are not checked if
+// CheckGoMod is given modules to check. Check and Stream do not know the
+// go.mod file, so they ignore this option. By default they are not checked.
+func WithToolchainCheck(src GoReleaseSource) Option {
+	return func(c *Checker) {
+		c.goReleases = src
+	}
+}
+
+// checkToolchain records in rep how the Go version of the go.mod file at
+// gomodPath compares with the latest release.
+func (c *Checker) checkToolchain(ctx context.Context, gomodPath string, rep *Report) {
+	data, err := os.ReadFile(filepath.Clean(gomodPath))
+	if err != nil {
+		c.log().Warn("reading go directive failed", "error", err)
+		return
+	}
+	f, err := modfile.Parse(gomodPath, data, nil)
+	if err != nil {
+		c.log().Warn("reading go directive failed", "error", err)
+		return
+	}
+	if f.Go == nil {
+		return
+	}
+
+	latest, err := c.goReleases.LatestGo(ctx)
+	if err != nil {
+		c.log().Warn("looking up the latest Go release failed", "error", err)
+		return
+	}
+	s := &ToolchainStatus{Go: f.Go.Version, Latest: latest}
+	if f.Toolchain != nil {
+		s.Toolchain = f.Toolchain.Name
+	}
+	current := s.Current()
+	s.Outdated = version.Compare(current, latest) < 0
+	s.Unsupported = version.IsValid(current) && minorVersion(latest)-minorVersion(current) >= 2
+	rep.Toolchain = s
+}
+
+// minorVersion returns the minor version of a Go version such as go1.21.3,
+// that is, 21, or 0 if it is not a valid Go 1 version.
+func minorVersion(v string) int {
+	lang, ok := strings.CutPrefix(version.Lang(v), "go1.")
+	if !ok {
+		return 0
+	}
+	n, err := strconv.Atoi(lang)
+	if err != nil {
+		return 0
+	}
+	return n
+}
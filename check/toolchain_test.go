@@ -0,0 +1,117 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type goReleaseFunc func() (string, error)
+
+func (f goReleaseFunc) LatestGo(context.Context) (string, error) {
+	return f()
+}
+
+func TestGoDownloads(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("mode") != "json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"version": "go1.24rc1", "stable": false},
+			{"version": "go1.23.2", "stable": true},
+			{"version": "go1.22.8", "stable": true}
+		]`))
+	}))
+	defer srv.Close()
+
+	latest, err := NewGoDownloads(srv.URL+"/dl/?mode=json", srv.Client()).LatestGo(t.Context())
+	if err != nil {
+		t.Fatalf("LatestGo: %v", err)
+	}
+	if latest != "go1.23.2" {
+		t.Errorf("got %q, want go1.23.2", latest)
+	}
+
+	_, err = NewGoDownloads(srv.URL+"/dl/", srv.Client()).LatestGo(t.Context())
+	if !errors.Is(err, ErrModuleNotFound) {
+		t.Errorf("got error %v, want one matching ErrModuleNotFound", err)
+	}
+}
+
+func TestWithToolchainCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		directive string
+		want      *ToolchainStatus
+	}{
+		{
+			name:      "current toolchain",
+			directive: "go 1.21\n\ntoolchain go1.23.2\n",
+			want:      &ToolchainStatus{Go: "1.21", Toolchain: "go1.23.2", Latest: "go1.23.2"},
+		},
+		{
+			name:      "outdated patch release",
+			directive: "go 1.23.1\n",
+			want:      &ToolchainStatus{Go: "1.23.1", Latest: "go1.23.2", Outdated: true},
+		},
+		{
+			name:      "supported language version",
+			directive: "go 1.22\n",
+			want:      &ToolchainStatus{Go: "1.22", Latest: "go1.23.2", Outdated: true},
+		},
+		{
+			name:      "unsupported toolchain",
+			directive: "go 1.20\n\ntoolchain go1.21.5\n",
+			want: &ToolchainStatus{
+				Go:          "1.20",
+				Toolchain:   "go1.21.5",
+				Latest:      "go1.23.2",
+				Outdated:    true,
+				Unsupported: true,
+			},
+		},
+		{name: "no go directive"},
+	}
+
+	latest := goReleaseFunc(func() (string, error) { return "go1.23.2", nil })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gomod := filepath.Join(t.TempDir(), "go.mod")
+			content := "module example.com/app\n\n" + tt.directive
+			if err := os.WriteFile(gomod, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			rep, err := NewChecker(WithToolchainCheck(latest)).CheckGoMod(t.Context(), gomod)
+			if err != nil {
+				t.Fatalf("CheckGoMod: %v", err)
+			}
+			switch {
+			case tt.want == nil && rep.Toolchain != nil:
+				t.Errorf("got %+v, want none", *rep.Toolchain)
+			case tt.want != nil && (rep.Toolchain == nil || *rep.Toolchain != *tt.want):
+				t.Errorf("got %+v, want %+v", rep.Toolchain, *tt.want)
+			}
+		})
+	}
+
+	// A failed lookup is logged and leaves the status unknown.
+	gomod := filepath.Join(t.TempDir(), "go.mod")
+	content := "module example.com/app\n\ngo 1.21\n"
+	if err := os.WriteFile(gomod, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	failing := goReleaseFunc(func() (string, error) { return "", errors.New("go.dev unavailable") })
+	rep, err := NewChecker(WithToolchainCheck(failing)).CheckGoMod(t.Context(), gomod)
+	if err != nil {
+		t.Fatalf("CheckGoMod: %v", err)
+	}
+	if rep.Toolchain != nil {
+		t.Errorf("got %+v after a failed lookup", *rep.Toolchain)
+	}
+}
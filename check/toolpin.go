@@ -0,0 +1,231 @@
+package check
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// ToolPin is a 'go install' or 'go run' invocation outside go.mod that pins
+// a package to a pseudo-version or commit, such as
+// "go install golang.org/x/tools/cmd/stringer@v0.0.0-20231101000000-aaaaaaaaaaaa"
+// in a Dockerfile.
+type ToolPin struct {
+	// Package is the package path installed or run.
+	Package string
+	// Rev is the pinned pseudo-version or commit hash.
+	Rev string
+	// File is the path of the file, relative to the directory searched.
+	File string
+	// Line is the line of the invocation.
+	Line int
+}
+
+// Source returns where the pin is, such as "Dockerfile:12".
+func (p ToolPin) Source() string {
+	return p.File + ":" + strconv.Itoa(p.Line)
+}
+
+// WithToolPins also checks the tools pinned to pseudo-versions or commits by
+// 'go install' and 'go run' in the Dockerfiles, Makefiles, shell scripts, and
+// GitHub Actions workflows under the directory of the go.mod file given to
+// CheckGoMod (see FindToolPins). Each pin's module is found by resolving the
+// pinned revision for the package path and its parents, longest first, and
+// its dependency's Source is set to where the pin is. A pin that cannot be
+// resolved is a failure.
+//
+// Pins are not checked if CheckGoMod is given modules to check. Check and
+// Stream do not know the go.mod file, so they ignore this option. By default
+// tool pins are not checked.
+func WithToolPins(enabled bool) Option {
+	return func(c *Checker) {
+		c.toolPins = enabled
+	}
+}
+
+// FindToolPins returns the 'go install' and 'go run' invocations pinned to
+// pseudo-versions or commit hashes in the Dockerfiles, Makefiles (including
+// *.mk), and shell scripts under dir, and in the GitHub Actions workflows in
+// dir/.github/workflows. Other hidden directories, and vendor, node_modules,
+// and testdata directories, are skipped. Invocations split across lines, or
+// whose versions come from variables or workflow expressions, are not found.
+func FindToolPins(dir string) ([]ToolPin, error) {
+	var pins []ToolPin
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel != "." && skipToolPinDir(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isToolPinFile(rel) {
+			return nil
+		}
+
+		filePins, err := findFileToolPins(p, rel)
+		if err != nil {
+			return err
+		}
+		pins = append(pins, filePins...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pins, nil
+}
+
+// workflowDir is where GitHub Actions workflows are, relative to the
+// repository root.
+const workflowDir = ".github/workflows"
+
+// skipToolPinDir reports whether FindToolPins skips the directory at the
+// slash-separated path rel.
+func skipToolPinDir(rel string) bool {
+	if rel == workflowDir || rel == path.Dir(workflowDir) {
+		return false
+	}
+	switch name := path.Base(rel); name {
+	case "vendor", "node_modules", "testdata":
+		return true
+	default:
+		return strings.HasPrefix(name, ".")
+	}
+}
+
+// isToolPinFile reports whether FindToolPins searches the file at the
+// slash-separated path rel.
+func isToolPinFile(rel string) bool {
+	name := path.Base(rel)
+	if path.Dir(rel) == workflowDir {
+		return path.Ext(name) == ".yml" || path.Ext(name) == ".yaml"
+	}
+	switch name {
+	case "Dockerfile", "Containerfile", "Makefile", "makefile", "GNUmakefile":
+		return true
+	}
+	switch path.Ext(name) {
+	case ".dockerfile", ".mk", ".sh", ".bash":
+		return true
+	}
+	return strings.HasPrefix(name, "Dockerfile.")
+}
+
+// findFileToolPins returns the pins in the file at p, whose path is
+// recorded as rel.
+func findFileToolPins(p, rel string) ([]ToolPin, error) {
+	f, err := os.Open(filepath.Clean(p))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck // read-only
+
+	var pins []ToolPin
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		for _, pin := range parseToolPins(scanner.Text()) {
+			pin.File = rel
+			pin.Line = line
+			pins = append(pins, pin)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", rel, err)
+	}
+	return pins, nil
+}
+
+// commitHashRE matches abbreviated and full commit hashes.
+var commitHashRE = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// parseToolPins returns the pinned packages of the 'go install' or 'go run'
+// commands on a line, up to the end of each command.
+func parseToolPins(line string) []ToolPin {
+	var pins []ToolPin
+	fields := strings.Fields(line)
+	for i := 0; i+1 < len(fields); i++ {
+		if strings.HasPrefix(fields[i], "#") {
+			break
+		}
+		// A quoted command, such as a workflow's run: "go install ...",
+		// starts with a quote.
+		if strings.TrimLeft(fields[i], `"'`) != "go" ||
+			fields[i+1] != "install" && fields[i+1] != "run" {
+			continue
+		}
+		for i += 2; i < len(fields); i++ {
+			f := fields[i]
+			if f == "&&" || f == "||" || f == "|" || f == ";" {
+				i--
+				break
+			}
+			if strings.HasPrefix(f, "#") {
+				return pins
+			}
+			end := strings.HasSuffix(f, ";")
+			f = strings.Trim(strings.TrimSuffix(f, ";"), `"'`)
+			pkg, rev, ok := strings.Cut(f, "@")
+			if ok && module.CheckImportPath(pkg) == nil &&
+				(module.IsPseudoVersion(rev) || commitHashRE.MatchString(rev)) {
+				pins = append(pins, ToolPin{Package: pkg, Rev: rev})
+			}
+			if end {
+				break
+			}
+		}
+	}
+	return pins
+}
+
+// findToolDeps finds the tool pins under dir and resolves them to
+// dependencies, concurrently.
+func (c *Checker) findToolDeps(ctx context.Context, dir string) (resolvedPins, error) {
+	pins, err := FindToolPins(dir)
+	if err != nil {
+		return resolvedPins{}, fmt.Errorf("finding tool pins: %w", err)
+	}
+	return c.resolvePins(ctx, len(pins), func(ctx context.Context, i int) (Dependency, error) {
+		pin := pins[i]
+		dep, err := c.resolveToolPin(ctx, pin)
+		dep.Source = pin.Source()
+		return dep, err
+	}), nil
+}
+
+// resolveToolPin returns the module providing the pinned package, at the
+// pinned revision. Since the package path alone does not say which of its
+// prefixes is the module path, it resolves the revision for each, longest
+// first, down to two path elements. If none resolves, the dependency has the
+// package path and revision, and the error is the last prefix's.
+func (c *Checker) resolveToolPin(ctx context.Context, pin ToolPin) (Dependency, error) {
+	err := errors.New("no module found")
+	for prefix := pin.Package; strings.Contains(prefix, "/"); prefix = path.Dir(prefix) {
+		var version string
+		version, err = c.resolveRev(ctx, prefix, pin.Rev)
+		if err == nil {
+			return Dependency{Module: prefix, Version: version}, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return Dependency{Module: pin.Package, Version: pin.Rev}, err
+}
@@ -0,0 +1,159 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseToolPins(t *testing.T) {
+	tests := []struct {
+		line string
+		want []ToolPin
+	}{
+		{
+			line: "RUN go install " +
+				"golang.org/x/tools/cmd/stringer@v0.0.0-20231101000000-aaaaaaaaaaaa",
+			want: []ToolPin{{
+				Package: "golang.org/x/tools/cmd/stringer",
+				Rev:     "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			}},
+		},
+		{
+			line: "\tgo run -mod=mod " +
+				"example.com/gen@0123456789abcdef0123456789abcdef01234567 ./...",
+			want: []ToolPin{{
+				Package: "example.com/gen",
+				Rev:     "0123456789abcdef0123456789abcdef01234567",
+			}},
+		},
+		{
+			line: `go install "example.com/a/cmd/a@abcdef0"; go run example.com/b@abcdef1 && ` +
+				"go install example.com/c@v1.2.0",
+			want: []ToolPin{
+				{Package: "example.com/a/cmd/a", Rev: "abcdef0"},
+				{Package: "example.com/b", Rev: "abcdef1"},
+			},
+		},
+		{
+			line: `      - run: "go install example.com/lint@abcdef0"`,
+			want: []ToolPin{{Package: "example.com/lint", Rev: "abcdef0"}},
+		},
+		{line: "go install example.com/tool@${{ env.TOOL_VERSION }}"},
+		{line: "go install example.com/tool@latest"},
+		{line: "go install example.com/tool@$(TOOL_VERSION)"},
+		{line: "go build example.com/tool@abcdef0"},
+		{line: "# go install example.com/tool@abcdef0"},
+		{line: "go vet ./... # go install example.com/tool@abcdef0"},
+	}
+
+	for _, tt := range tests {
+		if got := parseToolPins(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseToolPins(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestWithToolPins(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.21\n\nrequire (\n" +
+			"\tgo4.org/netipx v0.0.0-20231201000000-cccccccccccc\n" +
+			")\n",
+		"Dockerfile": "FROM golang:1.22\n" +
+			"RUN go install golang.org/x/tools/cmd/stringer@v0.0.0-20231101000000-aaaaaaaaaaaa\n",
+		"scripts/gen.sh": "#!/bin/sh\n" +
+			"go run example.com/gone/cmd/gen@abcdef0 ./...\n",
+		"vendor/example.com/lib/build.sh": "go install example.com/vendored@abcdef0\n",
+		".hidden/Makefile":                "tools:\n\tgo install example.com/hidden@abcdef0\n",
+		".github/workflows/lint.yml": "jobs:\n  lint:\n    steps:\n" +
+			"      - run: |\n" +
+			"          go install example.com/lint/cmd/lint@v0.0.0-20231101000000-bbbbbbbbbbbb\n",
+		".github/dependabot.yml": "# go install example.com/nope@abcdef0\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pins, err := FindToolPins(dir)
+	if err != nil {
+		t.Fatalf("FindToolPins: %v", err)
+	}
+	wantPins := []ToolPin{
+		{
+			Package: "example.com/lint/cmd/lint",
+			Rev:     "v0.0.0-20231101000000-bbbbbbbbbbbb",
+			File:    ".github/workflows/lint.yml",
+			Line:    5,
+		},
+		{
+			Package: "golang.org/x/tools/cmd/stringer",
+			Rev:     "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			File:    "Dockerfile",
+			Line:    2,
+		},
+		{Package: "example.com/gone/cmd/gen", Rev: "abcdef0", File: "scripts/gen.sh", Line: 2},
+	}
+	if !reflect.DeepEqual(pins, wantPins) {
+		t.Errorf("got pins %+v, want %+v", pins, wantPins)
+	}
+
+	const (
+		stringer = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+		lint     = "v0.0.0-20231101000000-bbbbbbbbbbbb"
+	)
+	c := NewChecker(
+		WithResolver(fakeResolver{
+			"go4.org/netipx@main":            "v0.0.0-20231201000000-cccccccccccc",
+			"golang.org/x/tools@" + stringer: stringer,
+			"example.com/lint@" + lint:       lint,
+			"example.com/lint@main":          lint,
+			"golang.org/x/tools@main":        "v0.0.0-20231201000000-dddddddddddd",
+		}),
+		WithBranches(branchMain),
+		WithToolPins(true),
+	)
+	rep, err := c.CheckGoMod(t.Context(), filepath.Join(dir, "go.mod"))
+	if err != nil {
+		t.Fatalf("CheckGoMod: %v", err)
+	}
+	wantDeps := []Dependency{
+		{Module: "go4.org/netipx", Version: "v0.0.0-20231201000000-cccccccccccc"},
+		{Module: "example.com/lint", Version: lint, Source: ".github/workflows/lint.yml:5"},
+		{
+			Module:  "golang.org/x/tools",
+			Version: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			Source:  "Dockerfile:2",
+		},
+		{Module: "example.com/gone/cmd/gen", Version: "abcdef0", Source: "scripts/gen.sh:2"},
+	}
+	// Positions in go.mod are tested with FindPseudoVersionedDeps.
+	for i := range rep.Dependencies {
+		rep.Dependencies[i].Span = nil
+	}
+	if !reflect.DeepEqual(rep.Dependencies, wantDeps) {
+		t.Errorf("got dependencies %+v, want %+v", rep.Dependencies, wantDeps)
+	}
+	if len(rep.Updates) != 1 || rep.Updates[0].Source != "Dockerfile:2" {
+		t.Errorf("got updates %+v, want one from Dockerfile:2", rep.Updates)
+	}
+	if len(rep.Failures) != 1 || rep.Failures[0].Module != "example.com/gone/cmd/gen" {
+		t.Errorf("got failures %+v, want one for example.com/gone/cmd/gen", rep.Failures)
+	}
+
+	// Tool pins are not checked when checking given modules.
+	rep, err = c.CheckGoMod(t.Context(), filepath.Join(dir, "go.mod"), "go4.org/netipx")
+	if err != nil {
+		t.Fatalf("CheckGoMod: %v", err)
+	}
+	if len(rep.Dependencies) != 1 {
+		t.Errorf("got dependencies %+v, want only go4.org/netipx", rep.Dependencies)
+	}
+}
@@ -0,0 +1,137 @@
+package check
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// VendorMismatch is a dependency whose vendored copy, as recorded in
+// vendor/modules.txt, is not the version go.mod requires (see
+// WithVendorCheck). Builds using the vendor directory fail, or use the wrong
+// code, until 'go mod vendor' is run.
+type VendorMismatch struct {
+	// Module is the module path.
+	Module string `json:"module"`
+	// Required is the pseudo-version required in go.mod.
+	Required string `json:"required"`
+	// Vendored is the version in vendor/modules.txt, or the module path and
+	// version if a different module is vendored in its place, such as when
+	// go.mod replaces it with a fork that was not re-vendored.
+	Vendored string `json:"vendored"`
+	// Older is set if Vendored is older than Required, which is the usual
+	// case: go.mod was updated without re-vendoring.
+	Older bool `json:"older,omitempty"`
+}
+
+// WithVendorCheck cross-checks the versions in vendor/modules.txt, next to
+// the go.mod file given to CheckGoMod, against the pseudo-versions go.mod
+// requires, and records those that differ in Report.VendorMismatches.
+// Modules that are not vendored are skipped, as is the check if the module
+// has no vendor directory. A failure to read vendor/modules.txt is logged.
+// Check and Stream do not know the go.mod file, so they ignore this option.
+// By default the vendor directory is not checked.
+func WithVendorCheck(enabled bool) Option {
+	return func(c *Checker) {
+		c.vendorCheck = enabled
+	}
+}
+
+// checkVendor records the dependencies in rep whose vendored versions differ
+// from those required in the go.mod file at gomodPath.
+func (c *Checker) checkVendor(gomodPath string, rep *Report) {
+	mismatches, err := FindVendorMismatches(gomodPath, rep.Dependencies)
+	if err != nil {
+		c.log().Warn("reading vendored modules failed", "error", err)
+		return
+	}
+	rep.VendorMismatches = mismatches
+}
+
+// FindVendorMismatches returns the dependencies in deps whose vendored
+// copies, as recorded in vendor/modules.txt next to the go.mod file at
+// gomodPath, are not at their versions (see WithVendorCheck). A fork is
+// matched by the module it replaces. Dependencies found outside go.mod and
+// modules that are not vendored are skipped. It returns nil if the module
+// has no vendor directory.
+func FindVendorMismatches(gomodPath string, deps []Dependency) ([]VendorMismatch, error) {
+	path := filepath.Join(filepath.Dir(gomodPath), "vendor", "modules.txt")
+	data, err := os.ReadFile(path) //nolint:gosec // next to the user's go.mod
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	vendored := parseVendoredModules(data)
+	var mismatches []VendorMismatch
+	for _, dep := range deps {
+		required := dep.Module
+		if dep.Replaces != "" {
+			required = dep.Replaces
+		}
+		v, ok := vendored[required]
+		if !ok || dep.Source != "" || v.Path == dep.Module && v.Version == dep.Version {
+			continue
+		}
+		m := VendorMismatch{Module: dep.Module, Required: dep.Version, Vendored: v.Version}
+		if v.Path == dep.Module {
+			m.Older = vendoredOlder(v.Version, dep.Version)
+		} else {
+			m.Vendored = v.String()
+		}
+		mismatches = append(mismatches, m)
+	}
+	return mismatches, nil
+}
+
+// vendoredOlder reports whether the vendored version is older than the
+// required pseudo-version, comparing commit times if both are
+// pseudo-versions.
+func vendoredOlder(vendored, required string) bool {
+	vendoredTime, err := module.PseudoVersionTime(vendored)
+	if err != nil {
+		return false
+	}
+	requiredTime, err := module.PseudoVersionTime(required)
+	if err != nil {
+		return false
+	}
+	return vendoredTime.Before(requiredTime)
+}
+
+// parseVendoredModules parses vendor/modules.txt and returns the module
+// vendored for each required module, keyed by the required module's path.
+// Each required module has a line such as "# example.com/a v1.0.0",
+// optionally followed by "=> example.com/fork v1.1.0" if it is replaced, in
+// which case the replacement is what is vendored. The required version is
+// omitted if every version is replaced. A module replaced by a directory has
+// no version, and is skipped.
+func parseVendoredModules(data []byte) map[string]module.Version {
+	modules := map[string]module.Version{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, ok := strings.CutPrefix(scanner.Text(), "# ")
+		if !ok {
+			continue
+		}
+		line, replacement, replaced := strings.Cut(line, "=>")
+		required := strings.Fields(line)
+		vendored := required
+		if replaced {
+			vendored = strings.Fields(replacement)
+		}
+		if len(required) == 0 || len(required) > 2 || len(vendored) != 2 {
+			continue
+		}
+		modules[required[0]] = module.Version{Path: vendored[0], Version: vendored[1]}
+	}
+	return modules
+}
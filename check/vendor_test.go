@@ -0,0 +1,108 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWithVendorCheck(t *testing.T) {
+	dir := t.TempDir()
+	gomod := filepath.Join(dir, "go.mod")
+	content := "module example.com/app\n\ngo 1.21\n\nrequire (\n" +
+		"\tgo4.org/netipx v0.0.0-20231201000000-cccccccccccc\n" +
+		"\texample.com/current v0.0.0-20231101000000-aaaaaaaaaaaa\n" +
+		"\texample.com/newer v0.0.0-20231101000000-aaaaaaaaaaaa\n" +
+		"\texample.com/unvendored v0.0.0-20231101000000-aaaaaaaaaaaa\n" +
+		"\texample.com/upstream v1.0.0\n" +
+		"\texample.com/matched v1.0.0\n" +
+		"\texample.com/unforked v1.0.0\n" +
+		")\n\n" +
+		"replace (\n" +
+		"\texample.com/upstream => example.com/fork v0.0.0-20231201000000-cccccccccccc\n" +
+		"\texample.com/matched v1.0.0 => example.com/fork2 v0.0.0-20231101000000-aaaaaaaaaaaa\n" +
+		"\texample.com/unforked => example.com/fork3 v0.0.0-20231101000000-aaaaaaaaaaaa\n" +
+		")\n"
+	if err := os.WriteFile(gomod, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	resolver := fakeResolver{
+		"go4.org/netipx@main":         "v0.0.0-20231201000000-cccccccccccc",
+		"example.com/current@main":    "v0.0.0-20231101000000-aaaaaaaaaaaa",
+		"example.com/newer@main":      "v0.0.0-20231101000000-aaaaaaaaaaaa",
+		"example.com/unvendored@main": "v0.0.0-20231101000000-aaaaaaaaaaaa",
+		"example.com/fork@main":       "v0.0.0-20231201000000-cccccccccccc",
+		"example.com/fork2@main":      "v0.0.0-20231101000000-aaaaaaaaaaaa",
+		"example.com/fork3@main":      "v0.0.0-20231101000000-aaaaaaaaaaaa",
+	}
+	c := NewChecker(WithResolver(resolver), WithBranches(branchMain), WithVendorCheck(true))
+
+	// Without a vendor directory, nothing is reported.
+	rep, err := c.CheckGoMod(t.Context(), gomod)
+	if err != nil {
+		t.Fatalf("CheckGoMod: %v", err)
+	}
+	if rep.VendorMismatches != nil {
+		t.Errorf("got mismatches %+v without a vendor directory", rep.VendorMismatches)
+	}
+
+	modulesTxt := "# go4.org/netipx v0.0.0-20231101000000-aaaaaaaaaaaa\n" +
+		"## explicit; go 1.18\n" +
+		"go4.org/netipx\n" +
+		"# example.com/current v0.0.0-20231101000000-aaaaaaaaaaaa\n" +
+		"## explicit\n" +
+		"example.com/current\n" +
+		"# example.com/newer v0.0.0-20231201000000-bbbbbbbbbbbb\n" +
+		"## explicit\n" +
+		"example.com/newer\n" +
+		"# example.com/upstream v1.0.0 => " +
+		"example.com/fork v0.0.0-20231101000000-aaaaaaaaaaaa\n" +
+		"## explicit\n" +
+		"# example.com/matched v1.0.0 => " +
+		"example.com/fork2 v0.0.0-20231101000000-aaaaaaaaaaaa\n" +
+		"## explicit\n" +
+		"# example.com/unforked v1.0.0\n" +
+		"## explicit\n" +
+		"# example.com/local => ../local\n"
+	if err := os.Mkdir(filepath.Join(dir, "vendor"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	modulesPath := filepath.Join(dir, "vendor", "modules.txt")
+	if err := os.WriteFile(modulesPath, []byte(modulesTxt), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err = c.CheckGoMod(t.Context(), gomod)
+	if err != nil {
+		t.Fatalf("CheckGoMod: %v", err)
+	}
+	want := []VendorMismatch{
+		{
+			Module:   "go4.org/netipx",
+			Required: "v0.0.0-20231201000000-cccccccccccc",
+			Vendored: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			Older:    true,
+		},
+		{
+			Module:   "example.com/newer",
+			Required: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			Vendored: "v0.0.0-20231201000000-bbbbbbbbbbbb",
+		},
+		// Forks are matched by the module they replace.
+		{
+			Module:   "example.com/fork",
+			Required: "v0.0.0-20231201000000-cccccccccccc",
+			Vendored: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			Older:    true,
+		},
+		{
+			Module:   "example.com/fork3",
+			Required: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			Vendored: "example.com/unforked@v1.0.0",
+		},
+	}
+	if !reflect.DeepEqual(rep.VendorMismatches, want) {
+		t.Errorf("got mismatches %+v, want %+v", rep.VendorMismatches, want)
+	}
+}
@@ -0,0 +1,222 @@
+package check
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// VulnSource looks up known vulnerabilities in Go modules.
+type VulnSource interface {
+	// Vulns returns the entries describing vulnerabilities affecting the
+	// version of the module. It may also return entries that do not affect
+	// it, which are ignored. Each entry's ranges must cover all versions,
+	// so it can be checked against others.
+	Vulns(ctx context.Context, modulePath, version string) ([]OSVEntry, error)
+}
+
+// OSVEntry is a vulnerability report in the Open Source Vulnerability
+// format (https://ossf.github.io/osv-schema/), as served by the Go
+// vulnerability database. Only the fields that are used are decoded.
+type OSVEntry struct {
+	ID       string        `json:"id"`
+	Aliases  []string      `json:"aliases,omitempty"`
+	Summary  string        `json:"summary,omitempty"`
+	Severity []OSVSeverity `json:"severity,omitempty"`
+	Affected []OSVAffected `json:"affected"`
+	// DatabaseSpecific holds fields specific to the database the entry is
+	// from. GitHub advisories have a severity label here.
+	DatabaseSpecific struct {
+		Severity string `json:"severity,omitempty"`
+	} `json:"database_specific,omitzero"`
+}
+
+// OSVSeverity is a severity score, such as a CVSS vector.
+type OSVSeverity struct {
+	// Type is the scoring system, e.g. CVSS_V3.
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// severity returns the entry's severity: its database's label (e.g. HIGH)
+// if it has one, or else its first score, or "" if it has neither.
+func (e OSVEntry) severity() string {
+	if e.DatabaseSpecific.Severity != "" {
+		return strings.ToUpper(e.DatabaseSpecific.Severity)
+	}
+	if len(e.Severity) > 0 {
+		return e.Severity[0].Score
+	}
+	return ""
+}
+
+// OSVAffected lists the affected versions of one package.
+type OSVAffected struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Ranges []OSVRange `json:"ranges,omitempty"`
+}
+
+// OSVRange is a range of affected versions, described by events.
+type OSVRange struct {
+	Type   string     `json:"type"`
+	Events []OSVEvent `json:"events"`
+}
+
+// OSVEvent is a version at which a vulnerability was introduced or fixed.
+// Versions are semantic versions without the "v" prefix, and "0" for
+// introduced means all versions.
+type OSVEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+// Affects reports whether the vulnerability affects version of the module.
+// Only SEMVER ranges are considered; an entry for the module without ranges
+// affects all versions.
+func (e OSVEntry) Affects(modulePath, version string) bool {
+	for _, a := range e.Affected {
+		if a.Package.Name != modulePath {
+			continue
+		}
+		if len(a.Ranges) == 0 {
+			return true
+		}
+		for _, r := range a.Ranges {
+			if r.Type == "SEMVER" && rangeContains(r.Events, version) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rangeContains reports whether version is within the range described by
+// events, in the way govulncheck evaluates them.
+func rangeContains(events []OSVEvent, version string) bool {
+	if len(events) == 0 {
+		return true
+	}
+
+	events = slices.Clone(events)
+	slices.SortStableFunc(events, func(a, b OSVEvent) int {
+		if a.Introduced == "0" {
+			return -1
+		}
+		if b.Introduced == "0" {
+			return 1
+		}
+		return semver.Compare(a.version(), b.version())
+	})
+
+	affected := false
+	for _, e := range events {
+		switch {
+		case !affected && e.Introduced != "":
+			affected = e.Introduced == "0" || semver.Compare(version, osvVersion(e.Introduced)) >= 0
+		case affected && e.Fixed != "":
+			affected = semver.Compare(version, osvVersion(e.Fixed)) < 0
+		case affected && e.LastAffected != "":
+			affected = semver.Compare(version, osvVersion(e.LastAffected)) <= 0
+		}
+	}
+	return affected
+}
+
+// version returns the event's version with a "v" prefix.
+func (e OSVEvent) version() string {
+	return osvVersion(cmp.Or(e.Introduced, e.Fixed, e.LastAffected))
+}
+
+// osvVersion adds the "v" prefix Go uses to an OSV version.
+func osvVersion(v string) string {
+	return "v" + strings.TrimPrefix(v, "v")
+}
+
+// Vulnerability is a known vulnerability affecting the current version of an
+// updated dependency.
+type Vulnerability struct {
+	// ID identifies the vulnerability, e.g. GO-2023-1234.
+	ID string `json:"id"`
+	// Aliases are other identifiers for it, such as CVE IDs.
+	Aliases []string `json:"aliases,omitempty"`
+	// Summary describes it briefly.
+	Summary string `json:"summary,omitempty"`
+	// Severity rates it, if the database does: either a label such as HIGH
+	// or a CVSS vector.
+	Severity string `json:"severity,omitempty"`
+	// FixedInLatest is set if the latest version is not affected, i.e.
+	// updating fixes it.
+	FixedInLatest bool `json:"fixed_in_latest"`
+}
+
+// WithVulnerabilities looks up known vulnerabilities in each updated
+// dependency with source, and reports those affecting the current version in
+// Update.Vulnerabilities, noting which the update fixes. Vulnerabilities are
+// matched by module and version only, not by whether the vulnerable code is
+// reachable. A failed lookup is logged and leaves Update.Vulnerabilities
+// empty. By default vulnerabilities are not looked up.
+func WithVulnerabilities(source VulnSource) Option {
+	return func(c *Checker) { c.vulnSource = source }
+}
+
+// findVulnerabilities returns the known vulnerabilities affecting current,
+// sorted by ID, noting whether each affects latest.
+func (c *Checker) findVulnerabilities(
+	ctx context.Context,
+	modulePath,
+	current,
+	latest string,
+) ([]Vulnerability, error) {
+	entries, err := c.vulnSource.Vulns(ctx, modulePath, current)
+	if err != nil {
+		return nil, err
+	}
+
+	// Databases such as OSV.dev have separate entries for the same
+	// vulnerability from different sources, listing each other as aliases.
+	// Go vulnerability database entries are preferred, and the others are
+	// merged into them.
+	slices.SortStableFunc(entries, func(a, b OSVEntry) int {
+		return cmp.Compare(goVulnRank(a.ID), goVulnRank(b.ID))
+	})
+
+	var vulns []Vulnerability
+	for _, e := range entries {
+		if !e.Affects(modulePath, current) {
+			continue
+		}
+		i := slices.IndexFunc(vulns, func(v Vulnerability) bool {
+			return slices.Contains(v.Aliases, e.ID) || slices.Contains(e.Aliases, v.ID)
+		})
+		if i >= 0 {
+			if vulns[i].Severity == "" {
+				vulns[i].Severity = e.severity()
+			}
+			continue
+		}
+		vulns = append(vulns, Vulnerability{
+			ID:            e.ID,
+			Aliases:       e.Aliases,
+			Summary:       e.Summary,
+			Severity:      e.severity(),
+			FixedInLatest: !e.Affects(modulePath, latest),
+		})
+	}
+	slices.SortFunc(vulns, func(a, b Vulnerability) int { return strings.Compare(a.ID, b.ID) })
+	return vulns, nil
+}
+
+// goVulnRank orders Go vulnerability database IDs before others.
+func goVulnRank(id string) int {
+	if strings.HasPrefix(id, "GO-") {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,263 @@
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOSVEntryAffects(t *testing.T) {
+	const modulePath = "example.com/m"
+	entry := func(events ...OSVEvent) OSVEntry {
+		var a OSVAffected
+		a.Package.Name = modulePath
+		a.Package.Ecosystem = "Go"
+		a.Ranges = []OSVRange{{Type: "SEMVER", Events: events}}
+		return OSVEntry{ID: "GO-2024-0001", Affected: []OSVAffected{a}}
+	}
+
+	tests := []struct {
+		name    string
+		entry   OSVEntry
+		module  string
+		version string
+		want    bool
+	}{
+		{
+			name: "pseudo-version before fix",
+			entry: entry(
+				OSVEvent{Introduced: "0"},
+				OSVEvent{Fixed: "0.0.0-20231115000000-bbbbbbbbbbbb"},
+			),
+			module:  modulePath,
+			version: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			want:    true,
+		},
+		{
+			name: "pseudo-version after fix",
+			entry: entry(
+				OSVEvent{Introduced: "0"},
+				OSVEvent{Fixed: "0.0.0-20231115000000-bbbbbbbbbbbb"},
+			),
+			module:  modulePath,
+			version: "v0.0.0-20231201000000-cccccccccccc",
+		},
+		{
+			name:    "before introduced",
+			entry:   entry(OSVEvent{Fixed: "1.2.0"}, OSVEvent{Introduced: "1.1.0"}),
+			module:  modulePath,
+			version: "v1.0.5",
+		},
+		{
+			name: "reintroduced",
+			entry: entry(
+				OSVEvent{Introduced: "0"},
+				OSVEvent{Fixed: "1.0.0"},
+				OSVEvent{Introduced: "1.5.0"},
+			),
+			module:  modulePath,
+			version: "v1.7.0",
+			want:    true,
+		},
+		{
+			name:    "last affected",
+			entry:   entry(OSVEvent{Introduced: "0"}, OSVEvent{LastAffected: "1.0.0"}),
+			module:  modulePath,
+			version: "v1.0.1",
+		},
+		{
+			name:    "other module",
+			entry:   entry(OSVEvent{Introduced: "0"}),
+			module:  "example.com/other",
+			version: "v1.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.Affects(tt.module, tt.version); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+type vulnSourceFunc func(modulePath, version string) ([]OSVEntry, error)
+
+func (f vulnSourceFunc) Vulns(_ context.Context, modulePath, version string) ([]OSVEntry, error) {
+	return f(modulePath, version)
+}
+
+func TestWithVulnerabilities(t *testing.T) {
+	const modulePath = "example.com/a"
+	fixed := OSVEntry{
+		ID:      "GO-2023-0002",
+		Summary: "Fixed upstream",
+		Affected: []OSVAffected{{
+			Ranges: []OSVRange{{Type: "SEMVER", Events: []OSVEvent{
+				{Introduced: "0"},
+				{Fixed: "0.0.0-20231115000000-ffffffffffff"},
+			}}},
+		}},
+	}
+	fixed.Affected[0].Package.Name = modulePath
+	unfixed := OSVEntry{
+		ID:       "GO-2023-0001",
+		Aliases:  []string{"CVE-2023-1234"},
+		Affected: []OSVAffected{{}},
+	}
+	unfixed.Affected[0].Package.Name = modulePath
+
+	c := NewChecker(
+		WithResolver(fakeResolver{"example.com/a@main": "v0.0.0-20231201000000-bbbbbbbbbbbb"}),
+		WithBranches(branchMain),
+		WithVulnerabilities(vulnSourceFunc(func(string, string) ([]OSVEntry, error) {
+			return []OSVEntry{fixed, unfixed}, nil
+		})),
+	)
+
+	rep := c.Check(t.Context(), []Dependency{
+		{Module: modulePath, Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+	})
+
+	if len(rep.Updates) != 1 {
+		t.Fatalf("got updates %v, want 1", rep.Updates)
+	}
+	want := []Vulnerability{
+		{ID: "GO-2023-0001", Aliases: []string{"CVE-2023-1234"}},
+		{ID: "GO-2023-0002", Summary: "Fixed upstream", FixedInLatest: true},
+	}
+	if got := rep.Updates[0].Vulnerabilities; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestVulnDBClient(t *testing.T) {
+	var indexRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index/modules.json":
+			indexRequests.Add(1)
+			fmt.Fprint(
+				w,
+				`[{"path":"example.com/a","vulns":[{"id":"GO-2023-0001","fixed":"1.0.0"}]}]`,
+			)
+		case "/ID/GO-2023-0001.json":
+			fmt.Fprint(w, `{"id":"GO-2023-0001","summary":"Bad","affected":[`+
+				`{"package":{"name":"example.com/a","ecosystem":"Go"},`+
+				`"ranges":[{"type":"SEMVER","events":[{"introduced":"0"},{"fixed":"1.0.0"}]}]}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	v := NewVulnDBClient(server.URL, server.Client())
+
+	entries, err := v.Vulns(t.Context(), "example.com/a", "v0.9.0")
+	if err != nil {
+		t.Fatalf("vulns: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != "GO-2023-0001" ||
+		!entries[0].Affects("example.com/a", "v0.9.0") {
+		t.Errorf("got %+v, want GO-2023-0001 affecting v0.9.0", entries)
+	}
+
+	entries, err = v.Vulns(t.Context(), "example.com/b", "v0.9.0")
+	if err != nil {
+		t.Fatalf("vulns: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("got %+v, want none", entries)
+	}
+	if got := indexRequests.Load(); got != 1 {
+		t.Errorf("got %d index requests, want 1", got)
+	}
+}
+
+func TestOSVClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		var q osvQuery
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+			t.Errorf("decoding query: %v", err)
+		}
+		if q.Package.Name != "example.com/a" || q.Package.Ecosystem != "Go" ||
+			q.Version != "v0.0.0-20231101000000-aaaaaaaaaaaa" {
+			t.Errorf("got query %+v, want example.com/a at its pseudo-version", q)
+		}
+		switch q.PageToken {
+		case "":
+			fmt.Fprint(w, `{"vulns":[{"id":"GHSA-aaaa-bbbb-cccc","aliases":["GO-2023-0001"],`+
+				`"database_specific":{"severity":"high"},"affected":[]}],"next_page_token":"2"}`)
+		case "2":
+			fmt.Fprint(
+				w,
+				`{"vulns":[{"id":"GO-2023-0001","aliases":["GHSA-aaaa-bbbb-cccc"],"affected":[]}]}`,
+			)
+		default:
+			t.Errorf("got unexpected page token %q", q.PageToken)
+		}
+	}))
+	defer server.Close()
+
+	o := NewOSVClient(server.URL, server.Client())
+	entries, err := o.Vulns(t.Context(), "example.com/a", "v0.0.0-20231101000000-aaaaaaaaaaaa")
+	if err != nil {
+		t.Fatalf("vulns: %v", err)
+	}
+	if len(entries) != 2 || entries[0].severity() != "HIGH" {
+		t.Errorf("got %+v, want both pages with the first rated HIGH", entries)
+	}
+}
+
+func TestFindVulnerabilitiesMergesAliases(t *testing.T) {
+	const modulePath = "example.com/a"
+	affectsAll := []OSVAffected{{}}
+	affectsAll[0].Package.Name = modulePath
+
+	ghsa := OSVEntry{
+		ID:       "GHSA-aaaa-bbbb-cccc",
+		Aliases:  []string{"CVE-2023-1234"},
+		Affected: affectsAll,
+		Severity: []OSVSeverity{
+			{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+		},
+	}
+	goVuln := OSVEntry{
+		ID:       "GO-2023-0001",
+		Aliases:  []string{"CVE-2023-1234", "GHSA-aaaa-bbbb-cccc"},
+		Summary:  "Bad",
+		Affected: affectsAll,
+	}
+
+	c := NewChecker(WithVulnerabilities(vulnSourceFunc(func(string, string) ([]OSVEntry, error) {
+		return []OSVEntry{ghsa, goVuln}, nil
+	})))
+	got, err := c.findVulnerabilities(
+		t.Context(),
+		modulePath,
+		"v0.0.0-20231101000000-aaaaaaaaaaaa",
+		"v0.0.0-20231201000000-bbbbbbbbbbbb",
+	)
+	if err != nil {
+		t.Fatalf("finding vulnerabilities: %v", err)
+	}
+	want := []Vulnerability{{
+		ID:       "GO-2023-0001",
+		Aliases:  []string{"CVE-2023-1234", "GHSA-aaaa-bbbb-cccc"},
+		Summary:  "Bad",
+		Severity: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
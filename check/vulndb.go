@@ -0,0 +1,127 @@
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultVulnDBURL is the Go vulnerability database's URL.
+const DefaultVulnDBURL = "https://vuln.go.dev"
+
+// VulnDBClient is a VulnSource using the Go vulnerability database's HTTP
+// API (https://go.dev/doc/security/vuln/database), as govulncheck does. The
+// module index is fetched once and reused. A VulnDBClient is safe for
+// concurrent use.
+type VulnDBClient struct {
+	baseURL string
+	client  *http.Client
+
+	mu    sync.Mutex
+	index map[string][]string
+}
+
+// NewVulnDBClient returns a client for the database at baseURL
+// (DefaultVulnDBURL if empty). If client is nil, a client with a one minute
+// timeout is used.
+func NewVulnDBClient(baseURL string, client *http.Client) *VulnDBClient {
+	if baseURL == "" {
+		baseURL = DefaultVulnDBURL
+	}
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	return &VulnDBClient{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+// Vulns implements VulnSource. It returns the entries for all versions of
+// the module.
+func (v *VulnDBClient) Vulns(ctx context.Context, modulePath, _ string) ([]OSVEntry, error) {
+	index, err := v.moduleIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []OSVEntry
+	for _, id := range index[modulePath] {
+		var entry OSVEntry
+		if err := v.get(ctx, "/ID/"+url.PathEscape(id)+".json", &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// moduleIndex returns the IDs of the vulnerabilities in each module.
+func (v *VulnDBClient) moduleIndex(ctx context.Context) (map[string][]string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.index != nil {
+		return v.index, nil
+	}
+
+	var modules []struct {
+		Path  string `json:"path"`
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+	}
+	if err := v.get(ctx, "/index/modules.json", &modules); err != nil {
+		return nil, err
+	}
+
+	index := make(map[string][]string, len(modules))
+	for _, m := range modules {
+		for _, vuln := range m.Vulns {
+			index[m.Path] = append(index[m.Path], vuln.ID)
+		}
+	}
+	v.index = index
+	return index, nil
+}
+
+// get requests path from the database and decodes the JSON response into
+// out.
+func (v *VulnDBClient) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("querying vulnerability database: %w", err)
+		if isTimeout(err) {
+			return classify(ErrTimeout, err)
+		}
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		msg := strings.TrimSpace(string(body))
+		if msg == "" {
+			msg = resp.Status
+		}
+		return classify(
+			classifyStatus(resp.StatusCode, ""),
+			errors.New("querying vulnerability database: "+msg),
+		)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("parsing vulnerability database response: %w", err)
+	}
+	return nil
+}
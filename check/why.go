@@ -0,0 +1,203 @@
+package check
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// WithImportChains runs 'go mod why -m' with runner in the directory of the
+// go.mod file given to CheckGoMod, and records the shortest chain of imports
+// from the main module to each updated dependency in Update.ImportChain. This
+// shows which of the module's packages pull in a dependency, which is most
+// useful for indirect dependencies. A nil runner means ExecRunner.
+//
+// The go command must be able to load the module's packages, which may need
+// network access. A failed lookup is logged and leaves the chains empty.
+// Check and Stream do not know the go.mod file, so they ignore this option.
+// By default chains are not looked up.
+func WithImportChains(runner CommandRunner) Option {
+	return func(c *Checker) {
+		c.importChains = true
+		c.goRunner = runner
+	}
+}
+
+// WithTestOnlyCheck runs 'go mod why -m' and 'go list -deps ./...' with
+// runner in the directory of the go.mod file given to CheckGoMod to find the
+// updated dependencies that are needed, but not by the main module's
+// packages when built without tests, and marks them with Update.TestOnly.
+// If skip is set, their updates are left out of the report instead, though
+// they remain in Report.Dependencies. A nil runner means ExecRunner.
+//
+// As with WithImportChains, the go command must be able to load the module's
+// packages, a failed lookup is logged and marks no updates, and Check and
+// Stream ignore this option. By default updates are not checked.
+func WithTestOnlyCheck(runner CommandRunner, skip bool) Option {
+	return func(c *Checker) {
+		c.testOnlyCheck = true
+		c.skipTestOnly = skip
+		c.goRunner = runner
+	}
+}
+
+// explainUpdates looks up why the main module needs each update in rep, as
+// requested by WithImportChains, WithTestOnlyCheck, and WithRequirers, by
+// running the go command in the directory of the go.mod file at gomodPath.
+func (c *Checker) explainUpdates(ctx context.Context, gomodPath string, rep *Report) {
+	if len(rep.Updates) == 0 {
+		return
+	}
+
+	runner := c.goRunner
+	if runner == nil {
+		runner = ExecRunner{}
+	}
+	dir := filepath.Dir(gomodPath)
+	if c.importChains || c.testOnlyCheck {
+		c.addImportChains(ctx, runner, dir, rep)
+	}
+	if c.requirers {
+		c.addRequirers(ctx, runner, dir, rep)
+	}
+}
+
+// addImportChains sets the import chains of the updates in rep and marks or
+// removes those only needed by tests (see WithImportChains and
+// WithTestOnlyCheck).
+func (c *Checker) addImportChains(
+	ctx context.Context,
+	runner CommandRunner,
+	dir string,
+	rep *Report,
+) {
+	modules := make([]string, len(rep.Updates))
+	for i, u := range rep.Updates {
+		modules[i] = u.requiredPath()
+	}
+
+	chains, err := modWhy(ctx, runner, dir, modules)
+	if err != nil {
+		c.log().Warn("finding import chains failed", "error", err)
+		return
+	}
+	if c.importChains {
+		for i := range rep.Updates {
+			rep.Updates[i].ImportChain = chains[rep.Updates[i].requiredPath()]
+		}
+	}
+	if !c.testOnlyCheck {
+		return
+	}
+
+	built, err := buildModules(ctx, runner, dir)
+	if err != nil {
+		c.log().Warn("finding test-only dependencies failed", "error", err)
+		return
+	}
+	for i := range rep.Updates {
+		u := &rep.Updates[i]
+		u.TestOnly = len(chains[u.requiredPath()]) > 0 && !built[u.requiredPath()]
+	}
+	if c.skipTestOnly {
+		rep.Updates = slices.DeleteFunc(rep.Updates, func(u Update) bool { return u.TestOnly })
+	}
+}
+
+// modWhy runs 'go mod why -m' in dir for modules and returns the import chain
+// of each module the main module needs, keyed by module path. The chains
+// include the packages' tests and their dependencies' tests.
+func modWhy(
+	ctx context.Context,
+	runner CommandRunner,
+	dir string,
+	modules []string,
+) (map[string][]string, error) {
+	args := append([]string{"mod", "why", "-m"}, modules...)
+	output, err := runGo(ctx, runner, dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseModWhy(output), nil
+}
+
+// buildModules runs 'go list -deps ./...' in dir and returns the modules
+// providing the packages that the main module's packages import, without
+// their tests.
+func buildModules(
+	ctx context.Context,
+	runner CommandRunner,
+	dir string,
+) (map[string]bool, error) {
+	output, err := runGo(
+		ctx,
+		runner,
+		dir,
+		"list", "-e", "-deps", "-f", "{{with .Module}}{{.Path}}{{end}}", "./...",
+	)
+	if err != nil {
+		return nil, err
+	}
+	modules := map[string]bool{}
+	for line := range strings.Lines(string(output)) {
+		if line = strings.TrimSpace(line); line != "" {
+			modules[line] = true
+		}
+	}
+	return modules, nil
+}
+
+// runGo runs the go command in dir with args, which start with the
+// subcommand, and returns its standard output. If it fails, the error
+// includes its standard error.
+func runGo(
+	ctx context.Context,
+	runner CommandRunner,
+	dir string,
+	args ...string,
+) ([]byte, error) {
+	command := "go " + args[0]
+	output, err := runner.Run(ctx, "go", append([]string{"-C", dir}, args...)...)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("running %s: %w", command, ctxErr)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf(
+				"running %s: %s",
+				command,
+				strings.TrimSpace(string(exitErr.Stderr)),
+			)
+		}
+		return nil, fmt.Errorf("running %s: %w", command, err)
+	}
+	return output, nil
+}
+
+// parseModWhy parses the output of 'go mod why -m': for each module, a
+// "# module" line followed by the packages in the chain, one per line, or by
+// a parenthesized note if the main module does not need the module.
+func parseModWhy(output []byte) map[string][]string {
+	chains := map[string][]string{}
+	var module string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			module = ""
+		case strings.HasPrefix(line, "# "):
+			module = strings.TrimPrefix(line, "# ")
+		case module != "" && !strings.HasPrefix(line, "("):
+			chains[module] = append(chains[module], line)
+		}
+	}
+	return chains
+}
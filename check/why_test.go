@@ -0,0 +1,174 @@
+package check
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestWithImportChains(t *testing.T) {
+	dir := t.TempDir()
+	gomod := filepath.Join(dir, "go.mod")
+	content := "module example.com/app\n\ngo 1.21\n\nrequire (\n" +
+		"\tgo4.org/netipx v0.0.0-20231101000000-aaaaaaaaaaaa // indirect\n" +
+		"\texample.com/unused v0.0.0-20231101000000-bbbbbbbbbbbb\n" +
+		"\texample.com/current v0.0.0-20231101000000-cccccccccccc\n" +
+		")\n"
+	if err := os.WriteFile(gomod, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	const output = `# go4.org/netipx
+example.com/app/server
+golang.org/x/net/http2
+go4.org/netipx
+
+# example.com/unused
+(main module does not need module example.com/unused)
+`
+	var gotArgs []string
+	runner := runnerFunc(func(_ context.Context, name string, args ...string) ([]byte, error) {
+		gotArgs = append([]string{name}, args...)
+		return []byte(output), nil
+	})
+	resolver := fakeResolver{
+		"go4.org/netipx@main":      "v0.0.0-20231201000000-dddddddddddd",
+		"example.com/unused@main":  "v0.0.0-20231201000000-eeeeeeeeeeee",
+		"example.com/current@main": "v0.0.0-20231101000000-cccccccccccc",
+	}
+	c := NewChecker(
+		WithResolver(resolver),
+		WithBranches(branchMain),
+		WithIncludeIndirect(true),
+		WithImportChains(runner),
+	)
+
+	rep, err := c.CheckGoMod(t.Context(), gomod)
+	if err != nil {
+		t.Fatalf("CheckGoMod: %v", err)
+	}
+
+	wantArgs := []string{
+		"go", "-C", dir, "mod", "why", "-m", "go4.org/netipx", "example.com/unused",
+	}
+	if !slices.Equal(gotArgs, wantArgs) {
+		t.Errorf("ran %q, want %q", gotArgs, wantArgs)
+	}
+	var got [][]string
+	for _, u := range rep.Updates {
+		got = append(got, u.ImportChain)
+	}
+	want := [][]string{
+		{"example.com/app/server", "golang.org/x/net/http2", "go4.org/netipx"},
+		nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got import chains %q, want %q", got, want)
+	}
+}
+
+func TestWithImportChainsFailure(t *testing.T) {
+	gomod := filepath.Join(t.TempDir(), "go.mod")
+	content := "module example.com/app\n\ngo 1.21\n\n" +
+		"require go4.org/netipx v0.0.0-20231101000000-aaaaaaaaaaaa\n"
+	if err := os.WriteFile(gomod, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := runnerFunc(func(context.Context, string, ...string) ([]byte, error) {
+		return nil, &exec.ExitError{Stderr: []byte("go: updates to go.mod needed")}
+	})
+	c := NewChecker(
+		WithResolver(fakeResolver{"go4.org/netipx@main": "v0.0.0-20231201000000-dddddddddddd"}),
+		WithBranches(branchMain),
+		WithImportChains(runner),
+	)
+
+	rep, err := c.CheckGoMod(t.Context(), gomod)
+	if err != nil {
+		t.Fatalf("CheckGoMod: %v", err)
+	}
+	if len(rep.Updates) != 1 || rep.Updates[0].ImportChain != nil {
+		t.Errorf("got updates %+v, want one without an import chain", rep.Updates)
+	}
+	if len(rep.Failures) != 0 {
+		t.Errorf("got failures %+v, want none", rep.Failures)
+	}
+}
+
+func TestWithTestOnlyCheck(t *testing.T) {
+	dir := t.TempDir()
+	gomod := filepath.Join(dir, "go.mod")
+	content := "module example.com/app\n\ngo 1.21\n\nrequire (\n" +
+		"\tgo4.org/netipx v0.0.0-20231101000000-aaaaaaaaaaaa\n" +
+		"\texample.com/assert v0.0.0-20231101000000-bbbbbbbbbbbb\n" +
+		")\n"
+	if err := os.WriteFile(gomod, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := runnerFunc(func(_ context.Context, name string, args ...string) ([]byte, error) {
+		if name != "go" || len(args) < 3 || !slices.Equal(args[:2], []string{"-C", dir}) {
+			t.Fatalf("unexpected command %s %q", name, args)
+		}
+		switch args[2] {
+		case "mod":
+			return []byte("# go4.org/netipx\nexample.com/app\ngo4.org/netipx\n\n" +
+				"# example.com/assert\nexample.com/app\nexample.com/app.test\n" +
+				"example.com/assert\n"), nil
+		case "list":
+			return []byte("go4.org/netipx\ngo4.org/netipx\n\nexample.com/app\n"), nil
+		default:
+			t.Fatalf("unexpected command %s %q", name, args)
+			return nil, nil
+		}
+	})
+	resolver := fakeResolver{
+		"go4.org/netipx@main":     "v0.0.0-20231201000000-cccccccccccc",
+		"example.com/assert@main": "v0.0.0-20231201000000-dddddddddddd",
+	}
+
+	tests := []struct {
+		name string
+		skip bool
+		want map[string]bool
+	}{
+		{
+			name: "label",
+			want: map[string]bool{"go4.org/netipx": false, "example.com/assert": true},
+		},
+		{name: "skip", skip: true, want: map[string]bool{"go4.org/netipx": false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker(
+				WithResolver(resolver),
+				WithBranches(branchMain),
+				WithTestOnlyCheck(runner, tt.skip),
+			)
+			rep, err := c.CheckGoMod(t.Context(), gomod)
+			if err != nil {
+				t.Fatalf("CheckGoMod: %v", err)
+			}
+
+			got := map[string]bool{}
+			for _, u := range rep.Updates {
+				got[u.Module] = u.TestOnly
+				if u.ImportChain != nil {
+					t.Errorf("got import chain for %s without WithImportChains", u.Module)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got test-only updates %v, want %v", got, tt.want)
+			}
+			if len(rep.Dependencies) != 2 {
+				t.Errorf("got %d dependencies, want 2", len(rep.Dependencies))
+			}
+		})
+	}
+}
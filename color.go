@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+)
+
+// colorizer wraps text in ANSI escape sequences when enabled.
+type colorizer struct {
+	enabled bool
+}
+
+func (c colorizer) wrap(code, s string) string {
+	if !c.enabled {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+func (c colorizer) bold(s string) string   { return c.wrap(ansiBold, s) }
+func (c colorizer) red(s string) string    { return c.wrap(ansiRed, s) }
+func (c colorizer) green(s string) string  { return c.wrap(ansiGreen, s) }
+func (c colorizer) yellow(s string) string { return c.wrap(ansiYellow, s) }
+
+// newColorizer decides whether to color output written to out. In auto mode,
+// color is used only when out is a terminal and NO_COLOR is not set (see
+// https://no-color.org). An explicit -color=always overrides NO_COLOR.
+func newColorizer(mode string, out *os.File) (colorizer, error) {
+	switch mode {
+	case colorAlways:
+		return colorizer{enabled: true}, nil
+	case colorNever:
+		return colorizer{}, nil
+	case colorAuto:
+		if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+			return colorizer{}, nil
+		}
+		return colorizer{enabled: isTerminal(out)}, nil
+	default:
+		return colorizer{}, fmt.Errorf(
+			"invalid -color value %q: must be %s, %s, or %s",
+			mode,
+			colorAuto,
+			colorAlways,
+			colorNever,
+		)
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestNewColorizer(t *testing.T) {
+	// A regular file is never a terminal, so auto mode disables color.
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatalf("creating temp file: %v", err)
+	}
+	defer f.Close()
+
+	tests := []struct {
+		name    string
+		mode    string
+		noColor string
+		want    bool
+		wantErr bool
+	}{
+		{name: "always", mode: colorAlways, want: true},
+		{name: "always overrides NO_COLOR", mode: colorAlways, noColor: "1", want: true},
+		{name: "never", mode: colorNever},
+		{name: "auto with non-terminal", mode: colorAuto},
+		{name: "auto with NO_COLOR", mode: colorAuto, noColor: "1"},
+		{name: "invalid", mode: "sometimes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+
+			c, err := newColorizer(tt.mode, f)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newColorizer: %v", err)
+			}
+			if c.enabled != tt.want {
+				t.Errorf("got enabled %v, want %v", c.enabled, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorizer(t *testing.T) {
+	if got := (colorizer{}).bold("x"); got != "x" {
+		t.Errorf("disabled bold = %q, want %q", got, "x")
+	}
+	if got := (colorizer{enabled: true}).red("x"); got != "\x1b[31mx\x1b[0m" {
+		t.Errorf("enabled red = %q", got)
+	}
+}
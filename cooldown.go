@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// defaultCooldown is how long -update waits to propose an update again after
+// -verify failed for it, if -cooldown is not set.
+const defaultCooldown = 7 * 24 * time.Hour
+
+// cooldownState records the updates -verify failed for, persisted to a file
+// (-cooldown-state), so that -update does not propose them again, failing
+// the same way every run, until -cooldown has passed or a newer version is
+// out.
+type cooldownState struct {
+	path string
+	// failed maps go.mod paths to the updates that failed for them, as
+	// module@version, and when.
+	failed map[string]map[string]time.Time
+}
+
+// cooldownStateFile is the format of the -cooldown-state file.
+type cooldownStateFile struct {
+	Failed map[string]map[string]time.Time `json:"failed"`
+}
+
+// loadCooldownState reads the state file at path. It is not an error for the
+// file not to exist yet.
+func loadCooldownState(path string) (*cooldownState, error) {
+	s := &cooldownState{path: path, failed: map[string]map[string]time.Time{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading cooldown state: %w", err)
+	}
+	var file cooldownStateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing cooldown state %s: %w", path, err)
+	}
+	if file.Failed != nil {
+		s.failed = file.Failed
+	}
+	return s, nil
+}
+
+// cooling returns the edits of the go.mod file that failed less than
+// cooldown before now, held back until the cooldown ends, and the others.
+func (s *cooldownState) cooling(
+	gomodPath string,
+	edits []goModEdit,
+	now time.Time,
+	cooldown time.Duration,
+) ([]goModEdit, []heldEdit) {
+	failed := s.failed[stateKey(gomodPath)]
+	var (
+		ready []goModEdit
+		held  []heldEdit
+	)
+	for _, e := range edits {
+		at, ok := failed[e.module+"@"+e.to]
+		if !ok || !now.Before(at.Add(cooldown)) {
+			ready = append(ready, e)
+			continue
+		}
+		held = append(held, heldEdit{
+			goModEdit: e,
+			reason:    "-verify failed for it on " + at.Format(time.DateOnly),
+			hint:      "it is retried after " + at.Add(cooldown).Format(time.DateOnly),
+		})
+	}
+	return ready, held
+}
+
+// record records that the edits of the go.mod file failed at now, forgets
+// failures whose cooldown has ended, and writes the state file.
+func (s *cooldownState) record(
+	gomodPath string,
+	failed []heldEdit,
+	now time.Time,
+	cooldown time.Duration,
+) error {
+	key := stateKey(gomodPath)
+	previous := s.failed[key]
+	current := map[string]time.Time{}
+	for target, at := range previous {
+		if now.Before(at.Add(cooldown)) {
+			current[target] = at
+		}
+	}
+	for _, h := range failed {
+		current[h.module+"@"+h.to] = now
+	}
+	if len(current) == 0 {
+		delete(s.failed, key)
+	} else {
+		s.failed[key] = current
+	}
+	return writeStateFile(s.path, "cooldown state", cooldownStateFile{Failed: s.failed})
+}
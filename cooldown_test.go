@@ -0,0 +1,67 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCooldownState(t *testing.T) {
+	const current = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+	path := filepath.Join(t.TempDir(), "cooldown.json")
+	failedAt := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	edits := []goModEdit{
+		{module: "example.com/a", from: current, to: "v0.0.0-20231201000000-bbbbbbbbbbbb"},
+		{module: "example.com/b", from: current, to: "v0.0.0-20231201000000-bbbbbbbbbbbb"},
+	}
+
+	state, err := loadCooldownState(path)
+	if err != nil {
+		t.Fatalf("loadCooldownState: %v", err)
+	}
+	err = state.record("go.mod", []heldEdit{{goModEdit: edits[0]}}, failedAt, defaultCooldown)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	state, err = loadCooldownState(path)
+	if err != nil {
+		t.Fatalf("loadCooldownState: %v", err)
+	}
+	ready, held := state.cooling("go.mod", edits, failedAt.Add(24*time.Hour), defaultCooldown)
+	if !reflect.DeepEqual(ready, edits[1:]) {
+		t.Errorf("got ready %+v, want %+v", ready, edits[1:])
+	}
+	wantHeld := []heldEdit{{
+		goModEdit: edits[0],
+		reason:    "-verify failed for it on 2026-03-02",
+		hint:      "it is retried after 2026-03-09",
+	}}
+	if !reflect.DeepEqual(held, wantHeld) {
+		t.Errorf("got held %+v, want %+v", held, wantHeld)
+	}
+
+	// Another go.mod file, and a newer version, are not cooling down.
+	if _, held := state.cooling("other/go.mod", edits, failedAt, defaultCooldown); len(held) != 0 {
+		t.Errorf("got held %+v for another go.mod file", held)
+	}
+	newer := edits[0]
+	newer.to = "v0.0.0-20231215000000-cccccccccccc"
+	_, held = state.cooling("go.mod", []goModEdit{newer}, failedAt, defaultCooldown)
+	if len(held) != 0 {
+		t.Errorf("got held %+v for a newer version", held)
+	}
+
+	// Once the cooldown ends, the update is proposed again and forgotten.
+	after := failedAt.Add(defaultCooldown)
+	if ready, _ := state.cooling("go.mod", edits, after, defaultCooldown); len(ready) != 2 {
+		t.Errorf("got ready %+v after the cooldown, want both", ready)
+	}
+	if err := state.record("go.mod", nil, after, defaultCooldown); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if len(state.failed) != 0 {
+		t.Errorf("got failures %+v after the cooldown, want none", state.failed)
+	}
+}
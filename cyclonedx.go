@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+	"golang.org/x/mod/module"
+)
+
+// toolName identifies this tool in generated documents.
+const toolName = "check-untagged-go-deps"
+
+// cdxLatestProperty is the CycloneDX property recording a component's latest
+// version, namespaced by the tool's name as the specification recommends.
+const cdxLatestProperty = toolName + ":latestVersion"
+
+// cdxBOM is a CycloneDX (https://cyclonedx.org) bill of materials. Only the
+// fields that are used are included.
+type cdxBOM struct { //nolint:tagliatelle // matches CycloneDX
+	BOMFormat    string         `json:"bomFormat"`
+	SpecVersion  string         `json:"specVersion"`
+	SerialNumber string         `json:"serialNumber"`
+	Version      int            `json:"version"`
+	Metadata     cdxMetadata    `json:"metadata"`
+	Components   []cdxComponent `json:"components"`
+}
+
+type cdxMetadata struct {
+	Timestamp time.Time `json:"timestamp"`
+	Tools     struct {
+		Components []cdxComponent `json:"components"`
+	} `json:"tools"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxComponent struct { //nolint:tagliatelle // matches CycloneDX
+	Type       string        `json:"type"`
+	BOMRef     string        `json:"bom-ref,omitempty"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Pedigree   *cdxPedigree  `json:"pedigree,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxPedigree struct {
+	Commits []cdxCommit `json:"commits"`
+}
+
+type cdxCommit struct {
+	UID       string       `json:"uid"`
+	Committer *cdxIdentity `json:"committer,omitempty"`
+}
+
+type cdxIdentity struct {
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// printCycloneDX writes the pseudo-versioned dependencies in the report as a
+// CycloneDX SBOM to w, for merging into a project's SBOM. Each component's
+// version is its pseudo-version, matching other Go SBOM tools, and its
+// pedigree records the pinned commit. Available updates are recorded as
+// properties.
+func printCycloneDX(w io.Writer, env check.Envelope) error {
+	bom := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Components:   []cdxComponent{},
+	}
+	bom.Metadata.Timestamp = env.GeneratedAt
+	bom.Metadata.Tools.Components = []cdxComponent{
+		{Type: "application", Name: toolName, Version: env.ToolVersion},
+	}
+	bom.Metadata.Properties = []cdxProperty{{Name: toolName + ":goModPath", Value: env.GoModPath}}
+
+	latest := map[string]string{}
+	for _, u := range env.Report.Updates {
+		latest[u.Module] = u.Latest
+	}
+
+	for _, dep := range env.Report.Dependencies {
+		purl := goPURL(dep.Module, dep.Version)
+		c := cdxComponent{
+			Type:    "library",
+			BOMRef:  purl,
+			Name:    dep.Module,
+			Version: dep.Version,
+			PURL:    purl,
+		}
+		if rev, err := module.PseudoVersionRev(dep.Version); err == nil {
+			commit := cdxCommit{UID: rev}
+			if t, err := module.PseudoVersionTime(dep.Version); err == nil {
+				commit.Committer = &cdxIdentity{Timestamp: t}
+			}
+			c.Pedigree = &cdxPedigree{Commits: []cdxCommit{commit}}
+		}
+		if v, ok := latest[dep.Module]; ok {
+			c.Properties = []cdxProperty{{Name: cdxLatestProperty, Value: v}}
+		}
+		bom.Components = append(bom.Components, c)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(bom); err != nil {
+		return fmt.Errorf("writing CycloneDX: %w", err)
+	}
+	return nil
+}
+
+// goPURL returns the package URL (https://github.com/package-url/purl-spec)
+// of a Go module version.
+func goPURL(modulePath, version string) string {
+	return "pkg:golang/" + modulePath + "@" + version
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
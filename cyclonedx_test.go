@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// uuidPattern matches a random (version 4) UUID.
+const uuidPattern = `[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}`
+
+func TestPrintCycloneDX(t *testing.T) {
+	env := check.Envelope{
+		ToolVersion: "v1.2.0",
+		GeneratedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		GoModPath:   "go.mod",
+		Report: check.Report{
+			Dependencies: []check.Dependency{
+				{Module: "go4.org/netipx", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+				{Module: "example.com/gone", Version: "v0.0.0-20231101000000-bbbbbbbbbbbb"},
+			},
+			Updates: []check.Update{
+				{
+					Module:  "go4.org/netipx",
+					Current: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:  "v0.0.0-20231201000000-cccccccccccc",
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := printCycloneDX(&buf, env); err != nil {
+		t.Fatalf("printCycloneDX: %v", err)
+	}
+
+	serial := regexp.MustCompile(`"urn:uuid:` + uuidPattern + `"`)
+	got := serial.ReplaceAllString(buf.String(), `"urn:uuid:SERIAL"`)
+
+	want := `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "serialNumber": "urn:uuid:SERIAL",
+  "version": 1,
+  "metadata": {
+    "timestamp": "2026-01-02T03:04:05Z",
+    "tools": {
+      "components": [
+        {
+          "type": "application",
+          "name": "check-untagged-go-deps",
+          "version": "v1.2.0"
+        }
+      ]
+    },
+    "properties": [
+      {
+        "name": "check-untagged-go-deps:goModPath",
+        "value": "go.mod"
+      }
+    ]
+  },
+  "components": [
+    {
+      "type": "library",
+      "bom-ref": "pkg:golang/go4.org/netipx@v0.0.0-20231101000000-aaaaaaaaaaaa",
+      "name": "go4.org/netipx",
+      "version": "v0.0.0-20231101000000-aaaaaaaaaaaa",
+      "purl": "pkg:golang/go4.org/netipx@v0.0.0-20231101000000-aaaaaaaaaaaa",
+      "pedigree": {
+        "commits": [
+          {
+            "uid": "aaaaaaaaaaaa",
+            "committer": {
+              "timestamp": "2023-11-01T00:00:00Z"
+            }
+          }
+        ]
+      },
+      "properties": [
+        {
+          "name": "check-untagged-go-deps:latestVersion",
+          "value": "v0.0.0-20231201000000-cccccccccccc"
+        }
+      ]
+    },
+    {
+      "type": "library",
+      "bom-ref": "pkg:golang/example.com/gone@v0.0.0-20231101000000-bbbbbbbbbbbb",
+      "name": "example.com/gone",
+      "version": "v0.0.0-20231101000000-bbbbbbbbbbbb",
+      "purl": "pkg:golang/example.com/gone@v0.0.0-20231101000000-bbbbbbbbbbbb",
+      "pedigree": {
+        "commits": [
+          {
+            "uid": "bbbbbbbbbbbb",
+            "committer": {
+              "timestamp": "2023-11-01T00:00:00Z"
+            }
+          }
+        ]
+      }
+    }
+  ]
+}
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// daemon re-checks go.mod files on a schedule (-schedule) or on request
+// (-listen), keeping the latest report for each in memory and notifying when
+// they change.
+type daemon struct {
+	checker *check.Checker
+	// schedule, if set, is when to check. Otherwise, checks only run at
+	// startup and when triggered.
+	schedule *schedule
+	// location is the time zone of the schedule. It is the local time zone
+	// if nil.
+	location *time.Location
+	// window, if set, limits notifications to its days (-schedule-days).
+	// Changes found outside it are announced at the first check within it.
+	window *window
+	// trigger requests a check. Requests made during a check are coalesced
+	// into one check after it.
+	trigger    chan struct{}
+	gomodPaths []string
+	only       []string
+	// format is the -format each report is written to out in.
+	format string
+	// summary adds freshness statistics to each report (-summary).
+	summary bool
+	// sortBy is the -sort order of each report.
+	sortBy string
+	// groupBy is the -group-by key of each report.
+	groupBy string
+	colors  colorizer
+	out     io.Writer
+	// errOut receives errors, which do not stop the daemon, unless logger is
+	// set (-log-format json), in which case they are logged.
+	errOut io.Writer
+	logger *slog.Logger
+	// notifier, if set, is sent a report when it changes.
+	notifier *notifier
+	// now returns the current time. It is time.Now if nil.
+	now func() time.Time
+
+	mu      sync.Mutex
+	reports map[string]check.Envelope
+	// notified is the report last notified for each go.mod file.
+	notified map[string]check.Report
+	stats    map[string]*checkStats
+}
+
+// checkStats are statistics about the checks of a go.mod file, exposed as
+// metrics.
+type checkStats struct {
+	// checks and errors count the checks, and the checks that failed
+	// entirely (such as when go.mod could not be read).
+	checks, errors int
+	// duration is how long the last check took, and finished is when it
+	// finished.
+	duration time.Duration
+	finished time.Time
+}
+
+// run checks the go.mod files once, then again at each time in the schedule
+// and whenever triggered, until ctx is done.
+func (d *daemon) run(ctx context.Context) {
+	for {
+		d.checkAll(ctx)
+
+		// Without a schedule, the timer never fires.
+		timer := time.NewTimer(0)
+		timer.Stop()
+		if d.schedule != nil {
+			now := d.clock()
+			if d.location != nil {
+				now = now.In(d.location)
+			}
+			next := d.schedule.next(now)
+			if next.IsZero() {
+				d.logError(errors.New("schedule has no future runs"))
+				return
+			}
+			timer.Reset(time.Until(next))
+		}
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		case <-d.trigger:
+			timer.Stop()
+		}
+	}
+}
+
+// requestCheck asks run to check the go.mod files, unless a request is
+// already pending.
+func (d *daemon) requestCheck() {
+	select {
+	case d.trigger <- struct{}{}:
+	default:
+	}
+}
+
+func (d *daemon) clock() time.Time {
+	if d.now != nil {
+		return d.now()
+	}
+	return time.Now()
+}
+
+// checkAll checks each go.mod file, writing, storing, and if it changed
+// since it was last notified, notifying its report. Failures are reported
+// with logError.
+func (d *daemon) checkAll(ctx context.Context) {
+	// Each check of the go.mod files ends with a summary line, as a run
+	// does, counting a go.mod file that could not be checked as an error.
+	var cycle check.RunSummary
+	cycleStart := d.clock()
+	defer func() {
+		cycle.DurationMs = d.clock().Sub(cycleStart).Milliseconds()
+		d.printRunSummary(cycle)
+	}()
+
+	for _, path := range d.gomodPaths {
+		if ctx.Err() != nil {
+			return
+		}
+		start := d.clock()
+		rep, err := d.checker.CheckGoMod(ctx, path, d.only...)
+		d.recordCheck(path, start, err)
+		if err != nil {
+			cycle.Errors++
+			d.logError(fmt.Errorf("checking %s: %w", path, err))
+			continue
+		}
+		sortReport(&rep, d.sortBy)
+		if d.summary {
+			addSummary(&rep)
+		}
+
+		env := check.NewEnvelope(rep, toolVersion(), path)
+		summary := check.NewRunSummary(rep, d.clock().Sub(start))
+		env.Run = &summary
+		cycle.Checked += summary.Checked
+		cycle.Stale += summary.Stale
+		cycle.Errors += summary.Errors
+		if err := writeReport(d.out, d.format, d.groupBy, env, d.colors); err != nil {
+			d.logError(err)
+		}
+
+		d.mu.Lock()
+		if d.reports == nil {
+			d.reports = map[string]check.Envelope{}
+		}
+		d.reports[path] = env
+		d.mu.Unlock()
+
+		if d.notifier == nil || (d.window != nil && !d.window.contains(d.clock())) {
+			continue
+		}
+		previous, notified := d.notified[path]
+		if notified && !reportChanged(previous, rep) {
+			continue
+		}
+		if d.notified == nil {
+			d.notified = map[string]check.Report{}
+		}
+		d.notified[path] = rep
+		if err := d.notifier.notify(ctx, env); err != nil {
+			d.logError(err)
+		}
+	}
+}
+
+// logError reports an error that does not stop the daemon.
+func (d *daemon) logError(err error) {
+	if d.logger != nil {
+		d.logger.Error("daemon error", "error", err)
+		return
+	}
+	fmt.Fprintf(d.errOut, "Error: %v\n", err)
+}
+
+// printRunSummary writes the summary line of a check of the go.mod files to
+// errOut, as a JSON record if logger is set (-log-format json).
+func (d *daemon) printRunSummary(s check.RunSummary) {
+	format := logFormatText
+	if d.logger != nil {
+		format = logFormatJSON
+	}
+	printRunSummary(d.errOut, format, s)
+}
+
+// recordCheck updates the statistics of path for a check that started at
+// start and failed with err, if it is not nil.
+func (d *daemon) recordCheck(path string, start time.Time, err error) {
+	finished := d.clock()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stats == nil {
+		d.stats = map[string]*checkStats{}
+	}
+	stats, ok := d.stats[path]
+	if !ok {
+		stats = &checkStats{}
+		d.stats[path] = stats
+	}
+	stats.checks++
+	if err != nil {
+		stats.errors++
+	}
+	stats.duration = finished.Sub(start)
+	stats.finished = finished
+}
+
+// latest returns the most recent report for each go.mod file checked, in
+// the order of gomodPaths.
+func (d *daemon) latest() []check.Envelope {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var envs []check.Envelope
+	for _, path := range d.gomodPaths {
+		if env, ok := d.reports[path]; ok {
+			envs = append(envs, env)
+		}
+	}
+	return envs
+}
+
+// reportChanged reports whether rep has different updates (module and latest
+// version) or failing modules than previous.
+func reportChanged(previous, rep check.Report) bool {
+	return !slices.Equal(reportKeys(previous), reportKeys(rep))
+}
+
+// reportKeys returns the updates and failures of a report as sorted strings.
+func reportKeys(rep check.Report) []string {
+	var keys []string
+	for _, u := range rep.Updates {
+		keys = append(keys, "update "+u.Module+"@"+u.Latest)
+	}
+	for _, f := range rep.Failures {
+		keys = append(keys, "failure "+f.Module)
+	}
+	slices.Sort(keys)
+	return keys
+}
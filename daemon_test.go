@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+	"github.com/horgh/check-untagged-go-deps/check/checktest"
+)
+
+func TestDaemonCheckAll(t *testing.T) {
+	var posts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		posts++
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	gomodPath := filepath.Join(dir, "go.mod")
+	gomod := "module example.com/app\n\ngo 1.25\n\n" +
+		"require go4.org/netipx v0.0.0-20230719000000-aaaaaaaaaaaa\n"
+	if err := os.WriteFile(gomodPath, []byte(gomod), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := &checktest.Resolver{
+		Versions: map[string]string{
+			"go4.org/netipx@main": "v0.0.0-20231201000000-cccccccccccc",
+		},
+	}
+	var out, errOut bytes.Buffer
+	d := &daemon{
+		checker: check.NewChecker(
+			check.WithResolver(resolver),
+			check.WithBranches("main"),
+		),
+		gomodPaths: []string{gomodPath, filepath.Join(dir, "missing", "go.mod")},
+		format:     formatText,
+		out:        &out,
+		errOut:     &errOut,
+		notifier: &notifier{
+			target:     notifySlack,
+			webhookURL: server.URL,
+			client:     server.Client(),
+		},
+	}
+
+	ctx := context.Background()
+	checks := []struct {
+		latest    string
+		wantPosts int
+	}{
+		// The first report is always sent.
+		{latest: "v0.0.0-20231201000000-cccccccccccc", wantPosts: 1},
+		// Unchanged.
+		{latest: "v0.0.0-20231201000000-cccccccccccc", wantPosts: 1},
+		// A newer commit.
+		{latest: "v0.0.0-20240101000000-dddddddddddd", wantPosts: 2},
+	}
+	for i, c := range checks {
+		resolver.Versions["go4.org/netipx@main"] = c.latest
+		d.checkAll(ctx)
+		if posts != c.wantPosts {
+			t.Errorf("check %d: got %d notifications, want %d", i, posts, c.wantPosts)
+		}
+	}
+
+	if got := strings.Count(out.String(), "Updates available:"); got != len(checks) {
+		t.Errorf("got %d reports written, want %d:\n%s", got, len(checks), out.String())
+	}
+	if !strings.Contains(errOut.String(), "missing") {
+		t.Errorf("expected error about missing go.mod, got %q", errOut.String())
+	}
+	// Each check ends with a summary line, counting the missing go.mod file
+	// as an error.
+	summaries := strings.Count(errOut.String(), "checked=1 stale=1 errors=1 duration=")
+	if summaries != len(checks) {
+		t.Errorf("got %d summary lines, want %d:\n%s", summaries, len(checks), errOut.String())
+	}
+
+	envs := d.latest()
+	if len(envs) != 1 {
+		t.Fatalf("got %d reports, want 1", len(envs))
+	}
+	if envs[0].GoModPath != gomodPath {
+		t.Errorf("got go.mod path %q, want %q", envs[0].GoModPath, gomodPath)
+	}
+	if u := envs[0].Report.Updates; len(u) != 1 || u[0].Latest != checks[2].latest {
+		t.Errorf("got updates %+v, want latest %s", u, checks[2].latest)
+	}
+}
+
+func TestReportChanged(t *testing.T) {
+	update := check.Update{Module: "go4.org/netipx", Latest: "v0.0.0-20231201000000-cccccccccccc"}
+	failure := check.Failure{Module: "example.com/a"}
+
+	tests := []struct {
+		name           string
+		previous, next check.Report
+		want           bool
+	}{
+		{name: "both clean"},
+		{
+			name:     "same update",
+			previous: check.Report{Updates: []check.Update{update}},
+			next:     check.Report{Updates: []check.Update{update}},
+		},
+		{
+			name:     "newer latest",
+			previous: check.Report{Updates: []check.Update{update}},
+			next: check.Report{Updates: []check.Update{{
+				Module: update.Module,
+				Latest: "v0.0.0-20240101000000-dddddddddddd",
+			}}},
+			want: true,
+		},
+		{
+			name:     "new failure",
+			previous: check.Report{Updates: []check.Update{update}},
+			next: check.Report{
+				Updates:  []check.Update{update},
+				Failures: []check.Failure{failure},
+			},
+			want: true,
+		},
+		{
+			name:     "update applied",
+			previous: check.Report{Updates: []check.Update{update}},
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reportChanged(tt.previous, tt.next); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDaemonWindow(t *testing.T) {
+	var posts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		posts++
+	}))
+	defer server.Close()
+
+	gomodPath := filepath.Join(t.TempDir(), "go.mod")
+	gomod := "module example.com/app\n\ngo 1.25\n\n" +
+		"require go4.org/netipx v0.0.0-20230719000000-aaaaaaaaaaaa\n"
+	if err := os.WriteFile(gomodPath, []byte(gomod), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	window, err := parseWindow("monday", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2026-03-07 is a Saturday.
+	now := time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC)
+	resolver := &checktest.Resolver{
+		Versions: map[string]string{
+			"go4.org/netipx@main": "v0.0.0-20231201000000-cccccccccccc",
+		},
+	}
+	d := &daemon{
+		checker: check.NewChecker(
+			check.WithResolver(resolver),
+			check.WithBranches("main"),
+		),
+		window:     window,
+		gomodPaths: []string{gomodPath},
+		format:     formatText,
+		out:        io.Discard,
+		errOut:     io.Discard,
+		notifier: &notifier{
+			target:     notifySlack,
+			webhookURL: server.URL,
+			client:     server.Client(),
+		},
+		now: func() time.Time { return now },
+	}
+
+	ctx := context.Background()
+	d.checkAll(ctx)
+	if posts != 0 {
+		t.Errorf("got %d notifications outside the window, want 0", posts)
+	}
+	if len(d.latest()) != 1 {
+		t.Errorf("got %d reports outside the window, want 1", len(d.latest()))
+	}
+
+	// The update found on Saturday is announced on Monday.
+	now = now.AddDate(0, 0, 2)
+	d.checkAll(ctx)
+	if posts != 1 {
+		t.Errorf("got %d notifications in the window, want 1", posts)
+	}
+	d.checkAll(ctx)
+	if posts != 1 {
+		t.Errorf("got %d notifications after an unchanged check, want 1", posts)
+	}
+}
+
+func TestDaemonLogError(t *testing.T) {
+	var errOut bytes.Buffer
+	d := &daemon{errOut: &errOut}
+	d.logError(errors.New("checking go.mod: boom"))
+	if got, want := errOut.String(), "Error: checking go.mod: boom\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	var logs bytes.Buffer
+	d = &daemon{errOut: &errOut, logger: newLogger(&logs, logFormatJSON, false, false)}
+	d.logError(errors.New("checking go.mod: boom"))
+	var record struct {
+		Level string `json:"level"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(logs.Bytes(), &record); err != nil {
+		t.Fatalf("decoding log record %q: %v", logs.String(), err)
+	}
+	if record.Level != "ERROR" || record.Error != "checking go.mod: boom" {
+		t.Errorf("got record %+v", record)
+	}
+}
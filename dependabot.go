@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+	"golang.org/x/mod/module"
+)
+
+// dependabotUpdateTypes map update risks to Dependabot's update types.
+var dependabotUpdateTypes = map[check.Risk]string{
+	check.RiskBreaking: "version-update:semver-major",
+	check.RiskFeature:  "version-update:semver-minor",
+	check.RiskPatch:    "version-update:semver-patch",
+}
+
+// shortVersion returns the commit hash of a pseudo-version, or the version if
+// it is not one.
+func shortVersion(version string) string {
+	if rev, err := module.PseudoVersionRev(version); err == nil {
+		return rev
+	}
+	return version
+}
+
+// printDependabot writes a commit message for applying the report's updates
+// to w, following Dependabot's conventions: a "Bump" subject and a YAML block
+// of metadata about each updated dependency, which tools such as
+// dependabot/fetch-metadata parse. Nothing is written if there are no
+// updates.
+func printDependabot(w io.Writer, env check.Envelope) {
+	updates := env.Report.Updates
+	if len(updates) == 0 {
+		return
+	}
+
+	if len(updates) == 1 {
+		u := updates[0]
+		fmt.Fprintf(
+			w,
+			"Bump %s from %s to %s\n\n",
+			u.Module,
+			shortVersion(u.Current),
+			shortVersion(u.Latest),
+		)
+	} else {
+		fmt.Fprintf(w, "Bump %d pseudo-versioned dependencies\n\n", len(updates))
+	}
+
+	for _, u := range updates {
+		fmt.Fprintf(w, "Bumps %s from %s to %s.", u.Module, u.Current, u.Latest)
+		if u.CompareURL != "" {
+			fmt.Fprintf(w, "\n- [Commits](%s)", u.CompareURL)
+		}
+		fmt.Fprint(w, "\n\n")
+	}
+
+	fmt.Fprintln(w, "---")
+	fmt.Fprintln(w, "updated-dependencies:")
+	for _, u := range updates {
+		fmt.Fprintf(w, "- dependency-name: %s\n", u.Module)
+		fmt.Fprintf(w, "  dependency-version: %s\n", u.Latest)
+		fmt.Fprintf(w, "  from-version: %s\n", u.Current)
+		fmt.Fprintf(w, "  to-version: %s\n", u.Latest)
+		if u.Span != nil {
+			depType := "direct:production"
+			if u.Indirect {
+				depType = "indirect"
+			}
+			fmt.Fprintf(w, "  dependency-type: %s\n", depType)
+		}
+		if updateType, ok := dependabotUpdateTypes[u.Risk]; ok {
+			fmt.Fprintf(w, "  update-type: %s\n", updateType)
+		}
+	}
+	fmt.Fprintln(w, "...")
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestPrintDependabot(t *testing.T) {
+	gomodPath, deps := readGoMod(t, "module example.com/app\n\ngo 1.25\n\nrequire (\n"+
+		"\tgithub.com/example/module v0.0.0-20231101000000-bbbbbbbbbbbb // indirect\n"+
+		"\tgo4.org/netipx v0.0.0-20230719000000-aaaaaaaaaaaa\n"+
+		")\n")
+
+	netipx := check.Update{
+		Module:     "go4.org/netipx",
+		Current:    "v0.0.0-20230719000000-aaaaaaaaaaaa",
+		Latest:     "v0.0.0-20231201000000-cccccccccccc",
+		Risk:       check.RiskPatch,
+		CompareURL: "https://github.com/inetaf/netipx/compare/aaaaaaaaaaaa...cccccccccccc",
+		Span:       deps["go4.org/netipx"].Span,
+	}
+	indirect := check.Update{
+		Module:   "github.com/example/module",
+		Current:  "v0.0.0-20231101000000-bbbbbbbbbbbb",
+		Latest:   "v0.0.0-20231201000000-dddddddddddd",
+		Indirect: deps["github.com/example/module"].Indirect,
+		Span:     deps["github.com/example/module"].Span,
+	}
+
+	netipxBumps := "Bumps go4.org/netipx from v0.0.0-20230719000000-aaaaaaaaaaaa to " +
+		"v0.0.0-20231201000000-cccccccccccc."
+
+	tests := []struct {
+		name    string
+		updates []check.Update
+		want    string
+	}{
+		{name: "no updates"},
+		{
+			name:    "one update",
+			updates: []check.Update{netipx},
+			want: `Bump go4.org/netipx from aaaaaaaaaaaa to cccccccccccc
+
+` + netipxBumps + `
+- [Commits](https://github.com/inetaf/netipx/compare/aaaaaaaaaaaa...cccccccccccc)
+
+---
+updated-dependencies:
+- dependency-name: go4.org/netipx
+  dependency-version: v0.0.0-20231201000000-cccccccccccc
+  from-version: v0.0.0-20230719000000-aaaaaaaaaaaa
+  to-version: v0.0.0-20231201000000-cccccccccccc
+  dependency-type: direct:production
+  update-type: version-update:semver-patch
+...
+`,
+		},
+		{
+			name:    "several updates",
+			updates: []check.Update{netipx, indirect},
+			want: `Bump 2 pseudo-versioned dependencies
+
+` + netipxBumps + `
+- [Commits](https://github.com/inetaf/netipx/compare/aaaaaaaaaaaa...cccccccccccc)
+
+Bumps github.com/example/module from v0.0.0-20231101000000-bbbbbbbbbbbb to ` +
+				`v0.0.0-20231201000000-dddddddddddd.
+
+---
+updated-dependencies:
+- dependency-name: go4.org/netipx
+  dependency-version: v0.0.0-20231201000000-cccccccccccc
+  from-version: v0.0.0-20230719000000-aaaaaaaaaaaa
+  to-version: v0.0.0-20231201000000-cccccccccccc
+  dependency-type: direct:production
+  update-type: version-update:semver-patch
+- dependency-name: github.com/example/module
+  dependency-version: v0.0.0-20231201000000-dddddddddddd
+  from-version: v0.0.0-20231101000000-bbbbbbbbbbbb
+  to-version: v0.0.0-20231201000000-dddddddddddd
+  dependency-type: indirect
+...
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := check.Envelope{GoModPath: gomodPath, Report: check.Report{Updates: tt.updates}}
+			var b strings.Builder
+			printDependabot(&b, env)
+			if got := b.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
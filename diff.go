@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// diffCommand is the subcommand comparing the pins of two go.mod files.
+const diffCommand = "diff"
+
+// diffOptions holds the diff subcommand's command line options.
+type diffOptions struct {
+	includeIndirect bool
+	format          string
+	// ref is the git ref to read the old go.mod file from (-ref). If it is
+	// set, oldPath is the new go.mod file's path, relative to its directory.
+	ref     string
+	oldPath string
+	newPath string
+}
+
+// parseDiffFlags parses the arguments of the diff subcommand: either two
+// go.mod files, or -ref and an optional go.mod file, which defaults to
+// go.mod.
+func parseDiffFlags(args []string) (diffOptions, error) {
+	fs := flag.NewFlagSet("check-untagged-go-deps diff", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(
+			fs.Output(),
+			"Usage: check-untagged-go-deps diff [flags] old/go.mod new/go.mod\n"+
+				"       check-untagged-go-deps diff [flags] -ref <ref> [go.mod]\n\n"+
+				"Report the pseudo-versioned requirements that changed between two go.mod "+
+				"files.\n\n",
+		)
+		fs.PrintDefaults()
+	}
+
+	var opts diffOptions
+	fs.BoolVar(&opts.includeIndirect, "i", false, "include indirect dependencies")
+	fs.StringVar(&opts.format, "format", formatText, "output format: text or json")
+	fs.StringVar(
+		&opts.ref,
+		"ref",
+		"",
+		"compare go.mod with its content at this git ref (e.g. main or origin/main)",
+	)
+	if err := fs.Parse(args); err != nil {
+		return diffOptions{}, err
+	}
+
+	if opts.format != formatText && opts.format != formatJSON {
+		return diffOptions{}, &usageError{
+			msg: fmt.Sprintf("invalid -format value %q: must be text or json", opts.format),
+		}
+	}
+	switch {
+	case opts.ref != "" && fs.NArg() <= 1:
+		opts.newPath = fs.Arg(0)
+		if opts.newPath == "" {
+			opts.newPath = "go.mod"
+		}
+		opts.oldPath = filepath.Base(opts.newPath)
+	case opts.ref == "" && fs.NArg() == 2:
+		opts.oldPath, opts.newPath = fs.Arg(0), fs.Arg(1)
+	default:
+		return diffOptions{}, &usageError{
+			msg: "diff needs two go.mod files, or -ref and at most one go.mod file",
+		}
+	}
+	return opts, nil
+}
+
+// pinChange is a requirement that was, or became, pseudo-versioned, and
+// whose version differs between two go.mod files.
+type pinChange struct {
+	Module string `json:"module"`
+	// Old is the version in the old go.mod file. It is empty if the
+	// requirement was added.
+	Old string `json:"old,omitempty"`
+	// New is the version in the new go.mod file. It is empty if the
+	// requirement was removed.
+	New string `json:"new,omitempty"`
+	// OldTime and NewTime are the commit times encoded in Old and New, if
+	// they are pseudo-versions.
+	OldTime time.Time `json:"old_time,omitzero"`
+	NewTime time.Time `json:"new_time,omitzero"`
+}
+
+// runDiff runs the diff subcommand with args and writes its report to w. It
+// returns the exit code.
+func runDiff(
+	ctx context.Context,
+	args []string,
+	w io.Writer,
+	runner check.CommandRunner,
+) (int, error) {
+	opts, err := parseDiffFlags(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, nil
+		}
+		return exitUsage, err
+	}
+
+	var oldData []byte
+	oldName := opts.oldPath
+	if opts.ref != "" {
+		oldName = opts.ref + ":" + opts.oldPath
+		oldData, err = gitShow(
+			ctx,
+			runner,
+			filepath.Dir(opts.newPath),
+			opts.ref+":./"+filepath.ToSlash(opts.oldPath),
+		)
+	} else {
+		oldData, err = os.ReadFile(opts.oldPath)
+	}
+	if err != nil {
+		return exitError, err
+	}
+	newData, err := os.ReadFile(opts.newPath)
+	if err != nil {
+		return exitError, err
+	}
+
+	changes, err := diffPins(oldName, oldData, opts.newPath, newData, opts.includeIndirect)
+	if err != nil {
+		return exitError, err
+	}
+
+	if opts.format == formatJSON {
+		if changes == nil {
+			changes = []pinChange{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(struct {
+			Changes []pinChange `json:"changes"`
+		}{changes}); err != nil {
+			return exitError, fmt.Errorf("writing JSON: %w", err)
+		}
+		return exitOK, nil
+	}
+	printDiff(w, changes)
+	return exitOK, nil
+}
+
+// diffPins returns the requirements whose versions differ between the old
+// and new go.mod files where either version is a pseudo-version, in the new
+// file's order followed by removed requirements in the old file's order.
+// Indirect requirements are skipped unless includeIndirect is set.
+func diffPins(
+	oldPath string,
+	oldData []byte,
+	newPath string,
+	newData []byte,
+	includeIndirect bool,
+) ([]pinChange, error) {
+	oldFile, err := modfile.ParseLax(oldPath, oldData, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", oldPath, err)
+	}
+	newFile, err := modfile.ParseLax(newPath, newData, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", newPath, err)
+	}
+
+	oldReqs := map[string]*modfile.Require{}
+	for _, r := range oldFile.Require {
+		oldReqs[r.Mod.Path] = r
+	}
+	newReqs := map[string]*modfile.Require{}
+	for _, r := range newFile.Require {
+		newReqs[r.Mod.Path] = r
+	}
+
+	var changes []pinChange
+	add := func(modulePath string, oldReq, newReq *modfile.Require) {
+		var c pinChange
+		c.Module = modulePath
+		indirect := true
+		if oldReq != nil {
+			c.Old = oldReq.Mod.Version
+			indirect = oldReq.Indirect
+		}
+		if newReq != nil {
+			c.New = newReq.Mod.Version
+			indirect = indirect && newReq.Indirect
+		}
+		if c.Old == c.New || indirect && !includeIndirect ||
+			!module.IsPseudoVersion(c.Old) && !module.IsPseudoVersion(c.New) {
+			return
+		}
+		// Versions that are not pseudo-versions have no time.
+		c.OldTime, _ = module.PseudoVersionTime(c.Old)
+		c.NewTime, _ = module.PseudoVersionTime(c.New)
+		changes = append(changes, c)
+	}
+	for _, r := range newFile.Require {
+		add(r.Mod.Path, oldReqs[r.Mod.Path], r)
+	}
+	for _, r := range oldFile.Require {
+		if newReqs[r.Mod.Path] == nil {
+			add(r.Mod.Path, r, nil)
+		}
+	}
+	return changes, nil
+}
+
+// printDiff writes the changed pins as text, with how far each moved in
+// time.
+func printDiff(w io.Writer, changes []pinChange) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No pseudo-versioned requirements changed.")
+		return
+	}
+
+	fmt.Fprintln(w, "Pseudo-versioned requirements changed:")
+	for _, c := range changes {
+		switch {
+		case c.Old == "":
+			fmt.Fprintf(w, "  %s: added %s\n", c.Module, c.New)
+		case c.New == "":
+			fmt.Fprintf(w, "  %s: removed %s\n", c.Module, c.Old)
+		default:
+			fmt.Fprintf(w, "  %s: %s -> %s%s\n", c.Module, c.Old, c.New, describePinMove(c))
+		}
+	}
+}
+
+// describePinMove describes how far a changed pin moved, e.g. " (4 months 12
+// days newer)", or "" if the change is not between two pseudo-versions.
+func describePinMove(c pinChange) string {
+	switch {
+	case !module.IsPseudoVersion(c.New):
+		return " (now a tagged version)"
+	case !module.IsPseudoVersion(c.Old):
+		return " (was a tagged version)"
+	case c.OldTime.IsZero() || c.NewTime.IsZero():
+		return ""
+	case c.NewTime.Before(c.OldTime):
+		return fmt.Sprintf(" (%s older)", formatAge(c.NewTime, c.OldTime))
+	default:
+		return fmt.Sprintf(" (%s newer)", formatAge(c.OldTime, c.NewTime))
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// gitRunner is a check.CommandRunner that serves canned git output.
+type gitRunner func(args []string) ([]byte, error)
+
+func (f gitRunner) Run(_ context.Context, name string, args ...string) ([]byte, error) {
+	if name != "git" {
+		return nil, errors.New("unexpected command " + name)
+	}
+	return f(args)
+}
+
+const diffOldGoMod = `module example.com/app
+
+go 1.21
+
+require (
+	go4.org/netipx v0.0.0-20230719000000-aaaaaaaaaaaa
+	example.com/tagged v0.0.0-20231101000000-bbbbbbbbbbbb
+	example.com/removed v0.0.0-20231101000000-cccccccccccc
+	example.com/same v0.0.0-20231101000000-dddddddddddd
+	example.com/semver v1.0.0
+	example.com/indirect v0.0.0-20231101000000-eeeeeeeeeeee // indirect
+)
+`
+
+const diffNewGoMod = `module example.com/app
+
+go 1.21
+
+require (
+	go4.org/netipx v0.0.0-20231201000000-ffffffffffff
+	example.com/tagged v1.4.0
+	example.com/same v0.0.0-20231101000000-dddddddddddd
+	example.com/semver v1.1.0
+	example.com/added v0.0.0-20231101000000-111111111111
+	example.com/indirect v0.0.0-20231201000000-222222222222 // indirect
+)
+`
+
+func TestRunDiff(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.mod")
+	newPath := filepath.Join(dir, "go.mod")
+	if err := os.WriteFile(oldPath, []byte(diffOldGoMod), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newPath, []byte(diffNewGoMod), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotArgs []string
+	runner := gitRunner(func(args []string) ([]byte, error) {
+		gotArgs = args
+		if args[len(args)-1] != "main:./go.mod" {
+			return nil, &exec.ExitError{Stderr: []byte("fatal: invalid object name 'nope'.")}
+		}
+		return []byte(diffOldGoMod), nil
+	})
+
+	const changed = "Pseudo-versioned requirements changed:\n" +
+		"  go4.org/netipx: v0.0.0-20230719000000-aaaaaaaaaaaa -> " +
+		"v0.0.0-20231201000000-ffffffffffff (4 months 12 days newer)\n" +
+		"  example.com/tagged: v0.0.0-20231101000000-bbbbbbbbbbbb -> v1.4.0 " +
+		"(now a tagged version)\n" +
+		"  example.com/added: added v0.0.0-20231101000000-111111111111\n" +
+		"  example.com/removed: removed v0.0.0-20231101000000-cccccccccccc\n"
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		want     string
+		wantErr  string
+	}{
+		{name: "files", args: []string{oldPath, newPath}, want: changed},
+		{
+			name: "indirect",
+			args: []string{"-i", oldPath, newPath},
+			want: "Pseudo-versioned requirements changed:\n" +
+				"  go4.org/netipx: v0.0.0-20230719000000-aaaaaaaaaaaa -> " +
+				"v0.0.0-20231201000000-ffffffffffff (4 months 12 days newer)\n" +
+				"  example.com/tagged: v0.0.0-20231101000000-bbbbbbbbbbbb -> v1.4.0 " +
+				"(now a tagged version)\n" +
+				"  example.com/added: added v0.0.0-20231101000000-111111111111\n" +
+				"  example.com/indirect: v0.0.0-20231101000000-eeeeeeeeeeee -> " +
+				"v0.0.0-20231201000000-222222222222 (1 month newer)\n" +
+				"  example.com/removed: removed v0.0.0-20231101000000-cccccccccccc\n",
+		},
+		{
+			name: "unchanged",
+			args: []string{newPath, newPath},
+			want: "No pseudo-versioned requirements changed.\n",
+		},
+		{name: "ref", args: []string{"-ref", "main", newPath}, want: changed},
+		{
+			name: "json",
+			args: []string{"-format", "json", newPath, newPath},
+			want: "{\n  \"changes\": []\n}\n",
+		},
+		{
+			name:     "unknown ref",
+			args:     []string{"-ref", "nope"},
+			wantCode: exitError,
+			wantErr:  "reading nope:./go.mod: fatal: invalid object name 'nope'.",
+		},
+		{name: "one file", args: []string{newPath}, wantCode: exitUsage, wantErr: "diff needs"},
+		{
+			name:     "ref and two files",
+			args:     []string{"-ref", "main", oldPath, newPath},
+			wantCode: exitUsage,
+			wantErr:  "diff needs",
+		},
+		{
+			name:     "invalid format",
+			args:     []string{"-format", "markdown", oldPath, newPath},
+			wantCode: exitUsage,
+			wantErr:  `invalid -format value "markdown": must be text or json`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			code, err := runDiff(t.Context(), tt.args, &buf, runner)
+			if code != tt.wantCode {
+				t.Errorf("got exit code %d, want %d", code, tt.wantCode)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one starting with %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runDiff: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+
+	want := []string{"-C", dir, "show", "main:./go.mod"}
+	runDiffArgs := []string{"-ref", "main", newPath}
+	if _, err := runDiff(t.Context(), runDiffArgs, &bytes.Buffer{}, runner); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(gotArgs, want) {
+		t.Errorf("ran git %q, want %q", gotArgs, want)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+	"golang.org/x/mod/modfile"
+)
+
+// dotEscaper escapes the contents of quoted DOT strings.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// dotQuote returns s as a quoted DOT string.
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+// dotLabel returns a quoted DOT string of lines, which are centered.
+func dotLabel(lines []string) string {
+	for i, l := range lines {
+		lines[i] = dotEscaper.Replace(l)
+	}
+	return `"` + strings.Join(lines, `\n`) + `"`
+}
+
+// printDOT writes a Graphviz DOT graph of the pseudo-versioned dependencies
+// in the report to w: the main module links to each dependency, dashed for
+// indirect ones, and dependencies with updates are red, labeled with how far
+// behind they are, and failed ones grey. Indirect dependencies with known
+// requirers (see check.WithRequirers) are linked from those instead.
+func printDOT(w io.Writer, env check.Envelope) {
+	root := env.GoModPath
+	if data, err := os.ReadFile(env.GoModPath); err == nil {
+		if path := modfile.ModulePath(data); path != "" {
+			root = path
+		}
+	}
+	deps := goModDependencies(env.Report)
+	updates := map[string]check.Update{}
+	for _, u := range env.Report.Updates {
+		updates[u.Module] = u
+	}
+	failed := map[string]bool{}
+	for _, f := range env.Report.Failures {
+		failed[f.Module] = true
+	}
+	isDep := map[string]bool{}
+	for _, dep := range env.Report.Dependencies {
+		isDep[dep.Module] = true
+	}
+	indirect := func(module string) bool {
+		return deps[module].Indirect
+	}
+	linked := map[string]bool{}
+	// linkFromRoot links the main module to module, once.
+	linkFromRoot := func(module string) {
+		if linked[module] {
+			return
+		}
+		linked[module] = true
+		edge := ""
+		if indirect(module) {
+			edge = ` [style=dashed, label="indirect"]`
+		}
+		fmt.Fprintf(w, "\t%s -> %s%s;\n", dotQuote(root), dotQuote(module), edge)
+	}
+
+	fmt.Fprintln(w, "digraph {")
+	fmt.Fprintln(w, "\trankdir=LR;")
+	fmt.Fprintln(w, "\tnode [shape=box];")
+	fmt.Fprintf(w, "\t%s [style=bold];\n", dotQuote(root))
+	for _, dep := range env.Report.Dependencies {
+		label := []string{dep.Module, dep.Version}
+		var style string
+		if u, ok := updates[dep.Module]; ok {
+			label = append(label, "→ "+u.Latest)
+			if u.Age() > 0 {
+				label = append(label, formatAge(u.CurrentTime, u.LatestTime)+" behind")
+			}
+			style = ", color=red, fontcolor=red, style=bold"
+		} else if failed[dep.Module] {
+			style = ", color=grey, fontcolor=grey, style=dashed"
+		}
+		fmt.Fprintf(w, "\t%s [label=%s%s];\n", dotQuote(dep.Module), dotLabel(label), style)
+
+		requiredBy := updates[dep.Module].RequiredBy
+		if !indirect(dep.Module) || len(requiredBy) == 0 {
+			linkFromRoot(dep.Module)
+			continue
+		}
+		for _, r := range requiredBy {
+			if !isDep[r.Module] && !linked[r.Module] {
+				fmt.Fprintf(
+					w,
+					"\t%s [label=%s];\n",
+					dotQuote(r.Module),
+					dotLabel([]string{r.Module, r.Version}),
+				)
+			}
+			linkFromRoot(r.Module)
+			fmt.Fprintf(w, "\t%s -> %s;\n", dotQuote(r.Module), dotQuote(dep.Module))
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestPrintDOT(t *testing.T) {
+	gomodPath, deps := readGoMod(t, "module example.com/app\n\ngo 1.25\n\nrequire (\n"+
+		"\tgo4.org/netipx v0.0.0-20231101000000-aaaaaaaaaaaa\n"+
+		"\texample.com/current v0.0.0-20231102000000-bbbbbbbbbbbb // indirect\n"+
+		"\texample.com/gone v0.0.0-20231103000000-dddddddddddd\n"+
+		")\n")
+
+	env := check.Envelope{
+		GoModPath: gomodPath,
+		Report: check.Report{
+			Dependencies: []check.Dependency{
+				deps["go4.org/netipx"],
+				deps["example.com/current"],
+				deps["example.com/gone"],
+			},
+			Updates: []check.Update{
+				{
+					Module:      "go4.org/netipx",
+					Current:     "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:      "v0.0.0-20231201000000-cccccccccccc",
+					CurrentTime: time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC),
+					LatestTime:  time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					Span:        deps["go4.org/netipx"].Span,
+				},
+			},
+			Failures: []check.Failure{{Module: "example.com/gone", Err: errors.New("not found")}},
+		},
+	}
+
+	var buf bytes.Buffer
+	printDOT(&buf, env)
+
+	want := `digraph {
+	rankdir=LR;
+	node [shape=box];
+	"example.com/app" [style=bold];
+	"go4.org/netipx" [label="go4.org/netipx\nv0.0.0-20231101000000-aaaaaaaaaaaa\n` +
+		`→ v0.0.0-20231201000000-cccccccccccc\n1 month behind", ` +
+		`color=red, fontcolor=red, style=bold];
+	"example.com/app" -> "go4.org/netipx";
+	"example.com/current" [label="example.com/current\nv0.0.0-20231102000000-bbbbbbbbbbbb"];
+	"example.com/app" -> "example.com/current" [style=dashed, label="indirect"];
+	"example.com/gone" [label="example.com/gone\nv0.0.0-20231103000000-dddddddddddd", ` +
+		`color=grey, fontcolor=grey, style=dashed];
+	"example.com/app" -> "example.com/gone";
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrintDOTRequirers(t *testing.T) {
+	gomodPath, deps := readGoMod(t, "module example.com/app\n\ngo 1.25\n\nrequire (\n"+
+		"\tgolang.org/x/net v0.20.0\n"+
+		"\tgo4.org/netipx v0.0.0-20231101000000-aaaaaaaaaaaa // indirect\n"+
+		")\n")
+
+	env := check.Envelope{
+		GoModPath: gomodPath,
+		Report: check.Report{
+			Dependencies: []check.Dependency{deps["go4.org/netipx"]},
+			Updates: []check.Update{
+				{
+					Module:  "go4.org/netipx",
+					Current: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:  "v0.0.0-20231201000000-cccccccccccc",
+					RequiredBy: []check.Requirer{
+						{
+							Module:   "golang.org/x/net",
+							Version:  "v0.20.0",
+							Requires: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	printDOT(&buf, env)
+
+	want := `digraph {
+	rankdir=LR;
+	node [shape=box];
+	"example.com/app" [style=bold];
+	"go4.org/netipx" [label="go4.org/netipx\nv0.0.0-20231101000000-aaaaaaaaaaaa\n` +
+		`→ v0.0.0-20231201000000-cccccccccccc", color=red, fontcolor=red, style=bold];
+	"golang.org/x/net" [label="golang.org/x/net\nv0.20.0"];
+	"example.com/app" -> "golang.org/x/net";
+	"golang.org/x/net" -> "go4.org/netipx";
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
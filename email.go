@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// notifyEmail is the -notify target that emails the report.
+const notifyEmail = "email"
+
+// smtpsPort is the port of SMTP over implicit TLS. On other ports, STARTTLS
+// is used if the server offers it.
+const smtpsPort = "465"
+
+// smtpConfig is how -notify email sends mail.
+type smtpConfig struct {
+	// addr is the SMTP server's host:port.
+	addr string
+	// username and password authenticate with PLAIN authentication if
+	// username is set. net/smtp refuses to send them unencrypted except to
+	// localhost.
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+// emailPart is a body part of an email.
+type emailPart struct {
+	contentType string
+	body        string
+}
+
+// sendEmail emails the report with parts as alternative bodies.
+func (n notifier) sendEmail(ctx context.Context, env check.Envelope, parts []emailPart) error {
+	subject := emailSubject(env.Report, env.GoModPath)
+	msg, err := emailMessage(subject, n.email.from, n.email.to, time.Now(), parts)
+	if err != nil {
+		return err
+	}
+
+	host, port, err := net.SplitHostPort(n.email.addr)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP address %q: %w", n.email.addr, err)
+	}
+	var conn net.Conn
+	if port == smtpsPort {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: host}}
+		conn, err = dialer.DialContext(ctx, "tcp", n.email.addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", n.email.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("connecting to SMTP server: %w", err)
+	}
+	// net/smtp does not take a context, so bound the whole exchange.
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(time.Minute))
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("starting SMTP session: %w", err)
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && port != smtpsPort {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("starting TLS: %w", err)
+		}
+	}
+	if n.email.username != "" {
+		auth := smtp.PlainAuth("", n.email.username, n.email.password, host)
+		if err := c.Auth(auth); err != nil {
+			return fmt.Errorf("authenticating to SMTP server: %w", err)
+		}
+	}
+	if err := c.Mail(n.email.from); err != nil {
+		return fmt.Errorf("sending MAIL FROM: %w", err)
+	}
+	for _, to := range n.email.to {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("sending RCPT TO %s: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("sending DATA: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("sending message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("sending message: %w", err)
+	}
+	return c.Quit()
+}
+
+// emailSubject returns the subject of the report's email.
+func emailSubject(rep check.Report, goModPath string) string {
+	if len(rep.Updates) > 0 {
+		return fmt.Sprintf(
+			"%s available for pseudo-versioned dependencies in %s",
+			plural(len(rep.Updates), "update"),
+			goModPath,
+		)
+	}
+	return fmt.Sprintf("Failed to check pseudo-versioned dependencies in %s", goModPath)
+}
+
+// emailParts returns the report as alternative Markdown (as text/plain) and
+// HTML email parts.
+func emailParts(env check.Envelope) ([]emailPart, error) {
+	var markdown strings.Builder
+	printMarkdown(&markdown, env.Report, "")
+	var html strings.Builder
+	if err := emailHTML.Execute(&html, env); err != nil {
+		return nil, fmt.Errorf("rendering HTML report: %w", err)
+	}
+	return []emailPart{
+		{contentType: "text/plain; charset=utf-8", body: markdown.String()},
+		{contentType: "text/html; charset=utf-8", body: html.String()},
+	}, nil
+}
+
+// emailMessage returns an email message with parts as alternative bodies.
+func emailMessage(
+	subject,
+	from string,
+	to []string,
+	date time.Time,
+	parts []emailPart,
+) ([]byte, error) {
+	var msg bytes.Buffer
+	mw := multipart.NewWriter(&msg)
+	header := []struct{ key, value string }{
+		{"From", from},
+		{"To", strings.Join(to, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + mw.Boundary()},
+	}
+	for _, h := range header {
+		fmt.Fprintf(&msg, "%s: %s\r\n", h.key, h.value)
+	}
+	msg.WriteString("\r\n")
+
+	for _, part := range parts {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("writing message: %w", err)
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := io.WriteString(qp, part.body); err != nil {
+			return nil, fmt.Errorf("writing message: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("writing message: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("writing message: %w", err)
+	}
+	return msg.Bytes(), nil
+}
+
+// emailHTML renders an Envelope as the HTML part of the report's email.
+var emailHTML = template.Must(template.New("email").Funcs(template.FuncMap{
+	"age":       formatAge,
+	"abandoned": func(a check.Abandoned) string { return describeAbandoned(a, colorizer{}) },
+	"plural":    plural,
+}).Parse(`<!DOCTYPE html>
+<html>
+<body>
+{{- with .Report}}
+{{- if .Updates}}
+<h2>{{plural (len .Updates) "update"}} available for pseudo-versioned dependencies in
+<code>{{$.GoModPath}}</code></h2>
+<table>
+<tr><th>Module</th><th>Current</th><th>Latest</th><th>Behind by</th><th></th></tr>
+{{- range .Updates}}
+<tr>
+<td><code>{{.Module}}</code></td>
+<td><code>{{.Current}}</code></td>
+<td><code>{{.Latest}}</code></td>
+<td>{{if gt .Age 0}}{{age .CurrentTime .LatestTime}}{{end}}</td>
+<td>{{if .CompareURL}}<a href="{{.CompareURL}}">Compare</a>{{end}}</td>
+</tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Abandoned}}
+<h3>Abandoned upstream</h3>
+<p>These repositories are archived or inactive. Consider forking or replacing these
+dependencies rather than updating them.</p>
+<ul>
+{{- range .Abandoned}}
+<li><code>{{.Module}}</code>: {{abandoned .}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Failures}}
+<h3>Failed to check</h3>
+<ul>
+{{- range .Failures}}
+<li><code>{{.Module}}</code>: {{.Err}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+var emailTestEnvelope = check.Envelope{
+	GoModPath: "go.mod",
+	Report: check.Report{
+		Dependencies: []check.Dependency{
+			{Module: "go4.org/netipx", Version: "v0.0.0-20230719000000-aaaaaaaaaaaa"},
+			{Module: "example.com/broken", Version: "v0.0.0-20231101000000-bbbbbbbbbbbb"},
+		},
+		Updates: []check.Update{
+			{
+				Module:      "go4.org/netipx",
+				Current:     "v0.0.0-20230719000000-aaaaaaaaaaaa",
+				Latest:      "v0.0.0-20231201000000-cccccccccccc",
+				CurrentTime: time.Date(2023, 7, 19, 0, 0, 0, 0, time.UTC),
+				LatestTime:  time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+				CompareURL:  "https://github.com/inetaf/netipx/compare/aaaa...cccc",
+			},
+		},
+		Failures: []check.Failure{
+			{Module: "example.com/broken", Err: errors.New("<server> error")},
+		},
+	},
+}
+
+// readEmail parses an email from emailMessage, returning its subject and the
+// bodies of its parts by content type.
+func readEmail(t *testing.T, r io.Reader) (subject string, parts map[string]string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		t.Fatalf("parsing message: %v", err)
+	}
+	subject, err = new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decoding subject: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("got content type %q (%v)", msg.Header.Get("Content-Type"), err)
+	}
+
+	parts = map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		// NextPart decodes quoted-printable.
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+		body, err := io.ReadAll(p)
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+		parts[p.Header.Get("Content-Type")] = string(body)
+	}
+	return subject, parts
+}
+
+func TestEmailMessage(t *testing.T) {
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	parts, err := emailParts(emailTestEnvelope)
+	if err != nil {
+		t.Fatalf("emailParts: %v", err)
+	}
+	msg, err := emailMessage(
+		emailSubject(emailTestEnvelope.Report, emailTestEnvelope.GoModPath),
+		"deps@example.com",
+		[]string{"a@example.com", "b@example.com"},
+		date,
+		parts,
+	)
+	if err != nil {
+		t.Fatalf("emailMessage: %v", err)
+	}
+	for _, header := range []string{
+		"From: deps@example.com\r\n",
+		"To: a@example.com, b@example.com\r\n",
+		"Date: Fri, 02 Jan 2026 03:04:05 +0000\r\n",
+	} {
+		if !strings.Contains(string(msg), header) {
+			t.Errorf("message does not contain header %q", header)
+		}
+	}
+
+	subject, got := readEmail(t, strings.NewReader(string(msg)))
+	if want := "1 update available for pseudo-versioned dependencies in go.mod"; subject != want {
+		t.Errorf("got subject %q, want %q", subject, want)
+	}
+
+	var markdown strings.Builder
+	printMarkdown(&markdown, emailTestEnvelope.Report, "")
+	// Quoted-printable text uses CRLF line endings.
+	text := strings.ReplaceAll(got["text/plain; charset=utf-8"], "\r\n", "\n")
+	if text != markdown.String() {
+		t.Errorf("got text part\n%s\nwant the Markdown report\n%s", text, markdown.String())
+	}
+
+	html := strings.ReplaceAll(got["text/html; charset=utf-8"], "\r\n", "\n")
+	for _, want := range []string{
+		"<h2>1 update available for pseudo-versioned dependencies in\n<code>go.mod</code></h2>",
+		"<td><code>go4.org/netipx</code></td>",
+		"<td>4 months 12 days</td>",
+		`<a href="https://github.com/inetaf/netipx/compare/aaaa...cccc">Compare</a>`,
+		"<li><code>example.com/broken</code>: &lt;server&gt; error</li>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML part does not contain %q:\n%s", want, html)
+		}
+	}
+}
+
+// fakeSMTPServer accepts one SMTP session on a local port, sending the
+// message it receives on the returned channel.
+func fakeSMTPServer(t *testing.T) (addr string, messages <-chan string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	ch := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		tp := textproto.NewConn(conn)
+		_ = tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			switch verb, _, _ := strings.Cut(line, " "); strings.ToUpper(verb) {
+			case "EHLO", "HELO", "MAIL", "RCPT":
+				_ = tp.PrintfLine("250 OK")
+			case "DATA":
+				_ = tp.PrintfLine("354 Go ahead")
+				data, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				ch <- string(data)
+				_ = tp.PrintfLine("250 Queued")
+			case "QUIT":
+				_ = tp.PrintfLine("221 Bye")
+				return
+			default:
+				_ = tp.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+	return l.Addr().String(), ch
+}
+
+func TestNotifyEmail(t *testing.T) {
+	addr, messages := fakeSMTPServer(t)
+
+	n := notifier{
+		target: notifyEmail,
+		email: smtpConfig{
+			addr: addr,
+			from: "deps@example.com",
+			to:   []string{"a@example.com"},
+		},
+	}
+
+	// A clean report is not sent.
+	clean := check.Envelope{GoModPath: "go.mod"}
+	if err := n.notify(t.Context(), clean); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if err := n.notify(t.Context(), emailTestEnvelope); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	select {
+	case msg := <-messages:
+		subject, _ := readEmail(t, strings.NewReader(msg))
+		if !strings.HasPrefix(subject, "1 update available") {
+			t.Errorf("got subject %q", subject)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("no message received")
+	}
+}
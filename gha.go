@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// ghaDataEscaper and ghaPropertyEscaper escape the message and properties of
+// GitHub Actions workflow commands.
+var (
+	ghaDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	ghaPropertyEscaper = strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+		":", "%3A",
+		",", "%2C",
+	)
+)
+
+// printGHAAnnotations writes a GitHub Actions warning annotation for each
+// update and an error annotation for each failure, positioned at its require
+// directive in go.mod.
+func printGHAAnnotations(w io.Writer, env check.Envelope) {
+	deps := goModDependencies(env.Report)
+	annotate := func(level string, span *check.Span, title, msg string) {
+		props := "file=" + ghaPropertyEscaper.Replace(env.GoModPath)
+		if span != nil {
+			props += ",line=" + strconv.Itoa(span.Start.Line)
+		}
+		props += ",title=" + ghaPropertyEscaper.Replace(title)
+		fmt.Fprintf(w, "::%s %s::%s\n", level, props, ghaDataEscaper.Replace(msg))
+	}
+
+	for _, u := range env.Report.Updates {
+		msg := fmt.Sprintf("%s: %s -> %s", u.Module, u.Current, u.Latest)
+		if u.Age() > 0 {
+			msg += fmt.Sprintf(
+				" (current commit is %s older than latest)",
+				formatAge(u.CurrentTime, u.LatestTime),
+			)
+		}
+		if u.CompareURL != "" {
+			msg += "\n" + u.CompareURL
+		}
+		annotate("warning", u.Span, "Update available for "+u.Module, msg)
+	}
+	for _, f := range env.Report.Failures {
+		span := deps[f.Module].Span
+		annotate("error", span, "Failed to check "+f.Module, fmt.Sprintf("%v", f.Err))
+	}
+}
+
+// ghaFailureMessage summarizes why the run failed, for a report whose exit
+// code is not exitOK.
+func ghaFailureMessage(rep check.Report, goModPath string) string {
+	var parts []string
+	if len(rep.Updates) > 0 {
+		parts = append(parts, fmt.Sprintf(
+			"%s available for pseudo-versioned dependencies in %s",
+			plural(len(rep.Updates), "update"),
+			goModPath,
+		))
+	}
+	if len(rep.Failures) > 0 {
+		parts = append(parts, "failed to check "+plural(len(rep.Failures), "module"))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// writeGHAOutputs appends the run's step outputs to the $GITHUB_OUTPUT file
+// and, if it failed, its failure message to the $GITHUB_STATE file. Files
+// that are not set are skipped, such as when not running in GitHub Actions.
+func writeGHAOutputs(env check.Envelope, code int) error {
+	updates := env.Report.Updates
+	if updates == nil {
+		updates = []check.Update{}
+	}
+	updatesJSON, err := json.Marshal(updates)
+	if err != nil {
+		return fmt.Errorf("encoding updates: %w", err)
+	}
+
+	var failureMessage string
+	if code != exitOK {
+		failureMessage = ghaFailureMessage(env.Report, env.GoModPath)
+	}
+
+	outputs := [][2]string{
+		{"updates_json", string(updatesJSON)},
+		{"updates_count", strconv.Itoa(len(env.Report.Updates))},
+		{"has_updates", strconv.FormatBool(len(env.Report.Updates) > 0)},
+		{"failures_count", strconv.Itoa(len(env.Report.Failures))},
+		{"failure_message", failureMessage},
+		{"max_risk", ghaMaxRisk(env.Report.Updates)},
+	}
+	if err := appendGHAFile(os.Getenv("GITHUB_OUTPUT"), outputs); err != nil {
+		return fmt.Errorf("writing GitHub Actions outputs: %w", err)
+	}
+	if failureMessage != "" {
+		state := [][2]string{{"failure_message", failureMessage}}
+		if err := appendGHAFile(os.Getenv("GITHUB_STATE"), state); err != nil {
+			return fmt.Errorf("writing GitHub Actions state: %w", err)
+		}
+	}
+	return nil
+}
+
+// ghaMaxRisk returns the most disruptive risk of the updates (see -risk):
+// patch, feature, or breaking, or unknown if any update's risk is unknown.
+// It is empty if there are no updates.
+func ghaMaxRisk(updates []check.Update) string {
+	if len(updates) == 0 {
+		return ""
+	}
+	highest := check.RiskPatch
+	for _, u := range updates {
+		if u.Risk == check.RiskUnknown {
+			return "unknown"
+		}
+		if u.Risk.AtLeast(highest) {
+			highest = u.Risk
+		}
+	}
+	return string(highest)
+}
+
+// appendGHAFile appends name=value lines to a GitHub Actions environment file
+// such as $GITHUB_OUTPUT. Nothing is written if path is empty. Values must
+// not contain newlines.
+func appendGHAFile(path string, values [][2]string) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		if _, err := fmt.Fprintf(f, "%s=%s\n", v[0], v[1]); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestPrintGHAAnnotations(t *testing.T) {
+	gomodPath, deps := readGoMod(t, "module example.com/app\n\ngo 1.25\n\nrequire (\n"+
+		"\tgithub.com/example/module v0.0.0-20231101000000-bbbbbbbbbbbb\n"+
+		"\tgo4.org/netipx v0.0.0-20230719000000-aaaaaaaaaaaa\n"+
+		"\tgithub.com/upstream/lib v1.2.0\n"+
+		")\n\n"+
+		"replace github.com/upstream/lib => "+
+		"github.com/fork/lib v0.0.0-20230801000000-eeeeeeeeeeee\n")
+
+	env := check.Envelope{
+		GoModPath: gomodPath,
+		Report: check.Report{
+			Dependencies: []check.Dependency{
+				deps["github.com/example/module"],
+				deps["go4.org/netipx"],
+				deps["github.com/fork/lib"],
+			},
+			Updates: []check.Update{
+				{
+					Module:      "go4.org/netipx",
+					Current:     "v0.0.0-20230719000000-aaaaaaaaaaaa",
+					Latest:      "v0.0.0-20231201000000-cccccccccccc",
+					CurrentTime: time.Date(2023, 7, 19, 0, 0, 0, 0, time.UTC),
+					LatestTime:  time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					CompareURL: "https://github.com/inetaf/netipx/compare/" +
+						"aaaaaaaaaaaa...cccccccccccc",
+					Span: deps["go4.org/netipx"].Span,
+				},
+				{
+					Module:   "github.com/fork/lib",
+					Replaces: "github.com/upstream/lib",
+					Current:  "v0.0.0-20230801000000-eeeeeeeeeeee",
+					Latest:   "v0.0.0-20230901000000-ffffffffffff",
+					Span:     deps["github.com/fork/lib"].Span,
+				},
+				{Module: "example.com/tool", Current: "v1", Latest: "v2"},
+			},
+			Failures: []check.Failure{
+				{Module: "github.com/example/module", Err: errors.New("100% broken\nbadly")},
+			},
+		},
+	}
+
+	var b strings.Builder
+	printGHAAnnotations(&b, env)
+
+	want := "::warning file=" + gomodPath + ",line=7,title=Update available for go4.org/netipx::" +
+		"go4.org/netipx: v0.0.0-20230719000000-aaaaaaaaaaaa -> " +
+		"v0.0.0-20231201000000-cccccccccccc (current commit is 4 months 12 days older than " +
+		"latest)%0Ahttps://github.com/inetaf/netipx/compare/aaaaaaaaaaaa...cccccccccccc\n" +
+		"::warning file=" + gomodPath + ",line=11," +
+		"title=Update available for github.com/fork/lib::github.com/fork/lib: " +
+		"v0.0.0-20230801000000-eeeeeeeeeeee -> v0.0.0-20230901000000-ffffffffffff\n" +
+		"::warning file=" + gomodPath + ",title=Update available for example.com/tool::" +
+		"example.com/tool: v1 -> v2\n" +
+		"::error file=" + gomodPath + ",line=6,title=Failed to check github.com/example/module::" +
+		"100%25 broken%0Abadly\n"
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteGHAOutputs(t *testing.T) {
+	dir := t.TempDir()
+	outputPath := filepath.Join(dir, "output")
+	statePath := filepath.Join(dir, "state")
+	t.Setenv("GITHUB_OUTPUT", outputPath)
+	t.Setenv("GITHUB_STATE", statePath)
+
+	tests := []struct {
+		name      string
+		rep       check.Report
+		code      int
+		wantOut   string
+		wantState string
+	}{
+		{
+			name: "clean",
+			code: exitOK,
+			wantOut: "updates_json=[]\nupdates_count=0\nhas_updates=false\n" +
+				"failures_count=0\nfailure_message=\nmax_risk=\n",
+		},
+		{
+			name: "updates",
+			rep: check.Report{
+				Updates: []check.Update{
+					{Module: "go4.org/netipx", Current: "v1", Latest: "v2"},
+				},
+				Failures: []check.Failure{{Module: "example.com/a", Err: errors.New("x")}},
+			},
+			code: exitUpdates,
+			wantOut: `updates_json=[{"module":"go4.org/netipx","current":"v1","latest":"v2"}]` +
+				"\nupdates_count=1\nhas_updates=true\nfailures_count=1\n" +
+				"failure_message=1 update available for pseudo-versioned dependencies in " +
+				"go.mod; failed to check 1 module\nmax_risk=unknown\n",
+			wantState: "failure_message=1 update available for pseudo-versioned dependencies " +
+				"in go.mod; failed to check 1 module\n",
+		},
+		{
+			name: "updates with exit zero",
+			rep: check.Report{
+				Updates: []check.Update{
+					{Module: "go4.org/netipx", Current: "v1", Latest: "v2"},
+				},
+			},
+			code: exitOK,
+			wantOut: `updates_json=[{"module":"go4.org/netipx","current":"v1","latest":"v2"}]` +
+				"\nupdates_count=1\nhas_updates=true\nfailures_count=0\nfailure_message=\n" +
+				"max_risk=unknown\n",
+		},
+		{
+			name: "risks",
+			rep: check.Report{
+				Updates: []check.Update{
+					{Module: "a", Current: "v1", Latest: "v2", Risk: check.RiskPatch},
+					{Module: "b", Current: "v1", Latest: "v2", Risk: check.RiskFeature},
+				},
+			},
+			code: exitOK,
+			wantOut: `updates_json=[{"module":"a","current":"v1","latest":"v2","risk":"patch"},` +
+				`{"module":"b","current":"v1","latest":"v2","risk":"feature"}]` +
+				"\nupdates_count=2\nhas_updates=true\nfailures_count=0\nfailure_message=\n" +
+				"max_risk=feature\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, path := range []string{outputPath, statePath} {
+				if err := os.WriteFile(path, nil, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			env := check.Envelope{GoModPath: "go.mod", Report: tt.rep}
+			if err := writeGHAOutputs(env, tt.code); err != nil {
+				t.Fatalf("writeGHAOutputs: %v", err)
+			}
+
+			out, err := os.ReadFile(outputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tt.wantOut {
+				t.Errorf("got outputs:\n%s\nwant:\n%s", out, tt.wantOut)
+			}
+			state, err := os.ReadFile(statePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(state) != tt.wantState {
+				t.Errorf("got state:\n%s\nwant:\n%s", state, tt.wantState)
+			}
+		})
+	}
+}
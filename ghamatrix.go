@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// ghaMatrix is the -format gha-matrix output: a GitHub Actions strategy
+// matrix with a job per update.
+type ghaMatrix struct {
+	Include []ghaMatrixEntry `json:"include"`
+}
+
+// ghaMatrixEntry is the matrix variables of an update's job.
+type ghaMatrixEntry struct {
+	Module string `json:"module"`
+	Latest string `json:"latest"`
+}
+
+// printGHAMatrix writes a GitHub Actions strategy matrix with an entry for
+// each update to w, on a single line so that it can be written to
+// $GITHUB_OUTPUT as is.
+func printGHAMatrix(w io.Writer, rep check.Report) error {
+	matrix := ghaMatrix{Include: []ghaMatrixEntry{}}
+	for _, u := range rep.Updates {
+		matrix.Include = append(matrix.Include, ghaMatrixEntry{Module: u.Module, Latest: u.Latest})
+	}
+	if err := json.NewEncoder(w).Encode(matrix); err != nil {
+		return fmt.Errorf("writing GitHub Actions matrix: %w", err)
+	}
+	return nil
+}
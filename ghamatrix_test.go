@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestPrintGHAMatrix(t *testing.T) {
+	tests := []struct {
+		name string
+		rep  check.Report
+		want string
+	}{
+		{name: "no updates", want: `{"include":[]}` + "\n"},
+		{
+			name: "updates",
+			rep: check.Report{Updates: []check.Update{
+				{
+					Module:  "go4.org/netipx",
+					Current: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:  "v0.0.0-20231201000000-cccccccccccc",
+				},
+				{
+					Module:  "example.com/a",
+					Current: "v0.0.0-20231102000000-bbbbbbbbbbbb",
+					Latest:  "v0.0.0-20231202000000-dddddddddddd",
+				},
+			}},
+			want: `{"include":[` +
+				`{"module":"go4.org/netipx","latest":"v0.0.0-20231201000000-cccccccccccc"},` +
+				`{"module":"example.com/a","latest":"v0.0.0-20231202000000-dddddddddddd"}` +
+				`]}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := printGHAMatrix(&buf, tt.rep); err != nil {
+				t.Fatalf("printGHAMatrix: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
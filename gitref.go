@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// gitShow runs 'git show' in dir and returns the content of object, such as
+// main:./go.mod.
+func gitShow(ctx context.Context, runner check.CommandRunner, dir, object string) ([]byte, error) {
+	output, err := runner.Run(ctx, "git", "-C", dir, "show", object)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", object, gitError(err))
+	}
+	return output, nil
+}
+
+// gitError returns git's error message from stderr if err is its exit
+// status, and otherwise err.
+func gitError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
+
+// writeGitRefGoMod reads the go.mod file named by a -git-ref value, REF or
+// REF:PATH, without checking the ref out, and writes it to go.mod in dir.
+// Without a PATH, gomodPath is read at REF (see gitRefPath). A PATH is
+// relative to the root of the repository, as with 'git show'. It returns a
+// name for the file, such as origin/main:go.mod.
+func writeGitRefGoMod(
+	ctx context.Context,
+	runner check.CommandRunner,
+	spec,
+	gomodPath,
+	dir string,
+) (string, error) {
+	object := spec
+	if !strings.Contains(spec, ":") {
+		path, err := gitRefPath(ctx, runner, gomodPath)
+		if err != nil {
+			return "", err
+		}
+		object = spec + ":" + path
+	}
+	data, err := gitShow(ctx, runner, ".", object)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), data, 0o600); err != nil {
+		return "", fmt.Errorf("writing %s: %w", object, err)
+	}
+	return object, nil
+}
+
+// gitRefPath returns the path that names the file at path in a 'git show'
+// object: ./PATH, relative to the current directory, if path is relative and
+// within it, and otherwise relative to the top level of the repository. A
+// path outside the repository is a usage error.
+func gitRefPath(ctx context.Context, runner check.CommandRunner, path string) (string, error) {
+	path = filepath.Clean(path)
+	parent := ".." + string(filepath.Separator)
+	if !filepath.IsAbs(path) && path != ".." && !strings.HasPrefix(path, parent) {
+		return "./" + filepath.ToSlash(path), nil
+	}
+
+	output, err := runner.Run(ctx, "git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("finding the git repository: %w", gitError(err))
+	}
+	top := strings.TrimSpace(string(output))
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", path, err)
+	}
+	// git reports the top level with symbolic links resolved. The file need
+	// not exist in the working tree, so resolve its directory.
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		abs = filepath.Join(dir, filepath.Base(abs))
+	}
+
+	rel, err := filepath.Rel(top, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, parent) {
+		return "", &usageError{
+			msg: fmt.Sprintf("-git-ref: %s is outside the git repository %s", path, top),
+		}
+	}
+	return filepath.ToSlash(rel), nil
+}
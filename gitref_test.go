@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWriteGitRefGoMod(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Pretend the repository's top level is the parent directory, so that
+	// ../go.mod is within it.
+	top := filepath.Dir(wd)
+
+	tests := []struct {
+		name       string
+		spec       string
+		gomodPath  string
+		wantObject string
+		wantUsage  bool
+	}{
+		{
+			name:       "ref",
+			spec:       "origin/main",
+			gomodPath:  "go.mod",
+			wantObject: "origin/main:./go.mod",
+		},
+		{
+			name:       "ref with go.mod argument",
+			spec:       "origin/main",
+			gomodPath:  "sub/../tools/go.mod",
+			wantObject: "origin/main:./tools/go.mod",
+		},
+		{
+			name:       "ref and path",
+			spec:       "origin/main:tools/go.mod",
+			gomodPath:  "go.mod",
+			wantObject: "origin/main:tools/go.mod",
+		},
+		{
+			name:       "absolute go.mod argument",
+			spec:       "origin/main",
+			gomodPath:  filepath.Join(wd, "tools", "go.mod"),
+			wantObject: "origin/main:" + filepath.Base(wd) + "/tools/go.mod",
+		},
+		{
+			name:       "go.mod argument in a parent directory",
+			spec:       "origin/main",
+			gomodPath:  filepath.Join("..", "go.mod"),
+			wantObject: "origin/main:go.mod",
+		},
+		{
+			name:      "go.mod argument outside the repository",
+			spec:      "origin/main",
+			gomodPath: filepath.Join(filepath.Dir(top), "go.mod"),
+			wantUsage: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotArgs []string
+			runner := gitRunner(func(args []string) ([]byte, error) {
+				if slices.Equal(args, []string{"rev-parse", "--show-toplevel"}) {
+					return []byte(top + "\n"), nil
+				}
+				gotArgs = args
+				return []byte(diffNewGoMod), nil
+			})
+			dir := t.TempDir()
+
+			name, err := writeGitRefGoMod(t.Context(), runner, tt.spec, tt.gomodPath, dir)
+			if tt.wantUsage {
+				var usageErr *usageError
+				if !errors.As(err, &usageErr) {
+					t.Fatalf("got error %v, want a usage error", err)
+				}
+				if gotArgs != nil {
+					t.Errorf("ran git %q, want no git show", gotArgs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("writeGitRefGoMod: %v", err)
+			}
+			if name != tt.wantObject {
+				t.Errorf("got name %q, want %q", name, tt.wantObject)
+			}
+			if want := []string{"-C", ".", "show", tt.wantObject}; !slices.Equal(gotArgs, want) {
+				t.Errorf("ran git %q, want %q", gotArgs, want)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+			if err != nil || string(data) != diffNewGoMod {
+				t.Errorf("got go.mod %q, %v, want the ref's content", data, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import "github.com/horgh/check-untagged-go-deps/check"
+
+// -group-by values.
+const groupHost = "host"
+
+// updateGroup is updates that share a -group-by key.
+type updateGroup struct {
+	// name is the key, such as a host. It is empty if updates are not
+	// grouped.
+	name    string
+	updates []check.Update
+}
+
+// groupUpdates groups updates by the -group-by key, in the order each group
+// first appears, so that groups follow the -sort order. If by is empty, all
+// updates are in one unnamed group.
+func groupUpdates(updates []check.Update, by string) []updateGroup {
+	if by != groupHost {
+		return []updateGroup{{updates: updates}}
+	}
+
+	var groups []updateGroup
+	index := map[string]int{}
+	for _, u := range updates {
+		host := check.ModuleHost(u.Module)
+		i, ok := index[host]
+		if !ok {
+			i = len(groups)
+			index[host] = i
+			groups = append(groups, updateGroup{name: host})
+		}
+		groups[i].updates = append(groups[i].updates, u)
+	}
+	return groups
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// The -log-format values.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// newLogger returns a logger writing to w in format (-log-format). debug
+// takes precedence over verbose. If neither is set, nothing is logged in
+// text, but warnings and errors are still logged in JSON, for log pipelines.
+func newLogger(w io.Writer, format string, verbose, debug bool) *slog.Logger {
+	var level slog.Level
+	switch {
+	case debug:
+		level = slog.LevelDebug
+	case verbose:
+		level = slog.LevelInfo
+	case format == logFormatJSON:
+		level = slog.LevelWarn
+	default:
+		return slog.New(slog.DiscardHandler)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	if format == logFormatJSON {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// printRunSummary writes the run's summary line to w, such as
+// "checked=12 stale=3 errors=1 duration=8.2s", or as a JSON record in the
+// JSON -log-format, whatever the log level.
+func printRunSummary(w io.Writer, format string, s check.RunSummary) {
+	if format != logFormatJSON {
+		fmt.Fprintln(w, s)
+		return
+	}
+	newLogger(w, logFormatJSON, true, false).Info(
+		"run summary",
+		"checked", s.Checked,
+		"stale", s.Stale,
+		"errors", s.Errors,
+		"duration_ms", s.DurationMs,
+	)
+}
+
+// printRunError writes why the run failed to w, as a JSON record in the JSON
+// -log-format.
+func printRunError(w io.Writer, format string, err error) {
+	if format == logFormatJSON {
+		newLogger(w, logFormatJSON, false, false).Error("run failed", "error", err)
+		return
+	}
+	fmt.Fprintf(w, "Error: %v\n", err)
+}
+
+// printSelections writes why each requirement in the go.mod file named
+// gomodName is or is not checked (-explain), as text lines or, in the JSON
+// -log-format, JSON records.
+func printSelections(w io.Writer, format, gomodName string, sels []check.Selection) {
+	if format == logFormatJSON {
+		logger := newLogger(w, logFormatJSON, true, false)
+		for _, s := range sels {
+			logger.Info(
+				"explain",
+				"gomod", gomodName,
+				"module", s.Module,
+				"version", s.Version,
+				"checked", s.Checked,
+				"reason", s.Reason,
+			)
+		}
+		return
+	}
+	for _, s := range sels {
+		verb := "checking"
+		if !s.Checked {
+			verb = "not checking"
+		}
+		fmt.Fprintf(w, "%s: %s %s %s: %s\n", gomodName, verb, s.Module, s.Version, s.Reason)
+	}
+}
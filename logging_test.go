@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    string
+		verbose   bool
+		debug     bool
+		wantWarn  bool
+		wantInfo  bool
+		wantDebug bool
+	}{
+		{name: "quiet", format: logFormatText},
+		{name: "verbose", format: logFormatText, verbose: true, wantWarn: true, wantInfo: true},
+		{
+			name:      "debug",
+			format:    logFormatText,
+			debug:     true,
+			wantWarn:  true,
+			wantInfo:  true,
+			wantDebug: true,
+		},
+		{name: "json", format: logFormatJSON, wantWarn: true},
+		{
+			name:     "json verbose",
+			format:   logFormatJSON,
+			verbose:  true,
+			wantWarn: true,
+			wantInfo: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newLogger(&buf, tt.format, tt.verbose, tt.debug)
+			logger.Warn("warn message")
+			logger.Info("info message")
+			logger.Debug("debug message")
+
+			out := buf.String()
+			if got := strings.Contains(out, "warn message"); got != tt.wantWarn {
+				t.Errorf("warning logged = %v, want %v", got, tt.wantWarn)
+			}
+			if got := strings.Contains(out, "info message"); got != tt.wantInfo {
+				t.Errorf("info logged = %v, want %v", got, tt.wantInfo)
+			}
+			if got := strings.Contains(out, "debug message"); got != tt.wantDebug {
+				t.Errorf("debug logged = %v, want %v", got, tt.wantDebug)
+			}
+			if tt.format != logFormatJSON {
+				return
+			}
+			for line := range strings.Lines(out) {
+				var record map[string]any
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Errorf("log line %q is not JSON: %v", line, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPrintRunSummary(t *testing.T) {
+	s := check.RunSummary{Checked: 12, Stale: 3, Errors: 1, DurationMs: 8200}
+
+	var buf bytes.Buffer
+	printRunSummary(&buf, logFormatText, s)
+	if got, want := buf.String(), "checked=12 stale=3 errors=1 duration=8.2s\n"; got != want {
+		t.Errorf("text summary = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	printRunSummary(&buf, logFormatJSON, s)
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("summary %q is not JSON: %v", buf.String(), err)
+	}
+	for key, want := range map[string]float64{
+		"checked":     12,
+		"stale":       3,
+		"errors":      1,
+		"duration_ms": 8200,
+	} {
+		if got := record[key]; got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestPrintSelections(t *testing.T) {
+	sels := []check.Selection{
+		{
+			Module:  "go4.org/netipx",
+			Version: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			Checked: true,
+			Reason:  "pseudo-version",
+		},
+		{Module: "golang.org/x/mod", Version: "v0.20.0", Reason: "not a pseudo-version"},
+	}
+
+	var buf bytes.Buffer
+	printSelections(&buf, logFormatText, "go.mod", sels)
+	want := "go.mod: checking go4.org/netipx v0.0.0-20231101000000-aaaaaaaaaaaa: " +
+		"pseudo-version\n" +
+		"go.mod: not checking golang.org/x/mod v0.20.0: not a pseudo-version\n"
+	if got := buf.String(); got != want {
+		t.Errorf("text explanation = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	printSelections(&buf, logFormatJSON, "go.mod", sels)
+	var records []map[string]any
+	for line := range strings.Lines(buf.String()) {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("explanation %q is not JSON: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != len(sels) {
+		t.Fatalf("got %d records, want %d", len(records), len(sels))
+	}
+	for i, s := range sels {
+		if records[i]["module"] != s.Module || records[i]["checked"] != s.Checked ||
+			records[i]["reason"] != s.Reason {
+			t.Errorf("record %d = %v, want %+v", i, records[i], s)
+		}
+	}
+}
@@ -1,5 +1,6 @@
 // check-untagged-go-deps checks for updates to pseudo-versioned (commit-pinned)
-// Go dependencies.
+// Go dependencies. The checking logic lives in the check package so other
+// tools can embed it.
 //
 // Dependabot does not support updating Go dependencies pinned to commits
 // (pseudo-versions like v0.0.0-20231129151722-fdeea329fbba).
@@ -11,237 +12,1661 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
-	"os/exec"
+	"os/signal"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
-	"golang.org/x/mod/modfile"
-	"golang.org/x/mod/module"
+	"github.com/horgh/check-untagged-go-deps/check"
 )
 
-func main() {
-	includeIndirect := flag.Bool("i", false, "include indirect dependencies")
-	flag.Parse()
+// Exit codes.
+const (
+	// exitOK means no updates were found and every dependency was checked.
+	exitOK = 0
+	// exitUpdates means updates are available. It takes precedence over
+	// exitError so that pipelines treating errors as warnings still fail when
+	// updates are found.
+	exitUpdates = 1
+	// exitError means one or more dependencies could not be checked, or the
+	// run failed entirely (e.g. go.mod could not be read).
+	exitError = 2
+	// exitUsage means the command line was invalid.
+	exitUsage = 3
+)
 
-	gomodPath := "go.mod"
-	if flag.NArg() > 0 {
-		gomodPath = flag.Arg(0)
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case diffCommand:
+			exitSubcommand(
+				runDiff(context.Background(), os.Args[2:], os.Stdout, check.ExecRunner{}),
+			)
+		case probeCommand:
+			exitSubcommand(runProbe(os.Args[2:], os.Stdin, os.Stdout))
+		case bazelCommand:
+			exitSubcommand(runBazel(os.Args[2:], os.Stdout))
+		}
 	}
 
-	updatesFound, err := run(gomodPath, *includeIndirect)
+	opts, err := parseFlags(os.Args[1:])
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(exitOK)
+		}
+		// The flag package already reported its own parse errors.
+		var usageErr *usageError
+		if errors.As(err, &usageErr) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(exitUsage)
 	}
-	if updatesFound {
-		os.Exit(1)
+
+	if opts.showVersion {
+		fmt.Println(versionString())
+		return
 	}
+	if opts.printSchema {
+		_, _ = os.Stdout.Write(check.Schema())
+		return
+	}
+
+	// run reports its own error, before its summary line.
+	code, err := run(opts)
+	if err != nil {
+		var usageErr *usageError
+		if errors.As(err, &usageErr) {
+			os.Exit(exitUsage)
+		}
+		os.Exit(exitError)
+	}
+	os.Exit(code)
 }
 
-func run(gomodPath string, includeIndirect bool) (bool, error) {
-	deps, updates, err := checkGoMod(context.Background(), gomodPath, includeIndirect)
+// exitSubcommand reports a subcommand's error, if any, and exits with its
+// exit code.
+func exitSubcommand(code int, err error) {
 	if err != nil {
-		return false, err
+		// The flag package already reported its own parse errors.
+		var usageErr *usageError
+		if code != exitUsage || errors.As(err, &usageErr) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
 	}
+	os.Exit(code)
+}
 
-	if len(deps) == 0 {
-		fmt.Println("No pseudo-versioned dependencies found in go.mod.")
-		return false, nil
+// usageError is an invalid command line option value.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+func parseFlags(args []string) (options, error) {
+	fs := flag.NewFlagSet("check-untagged-go-deps", flag.ContinueOnError)
+
+	var opts options
+	fs.BoolVar(&opts.includeIndirect, "i", false, "include indirect dependencies")
+	fs.DurationVar(
+		&opts.cacheTTL,
+		"cache-ttl",
+		0,
+		"cache resolved versions on disk for this long (e.g. 1h); 0 disables caching",
+	)
+	fs.IntVar(&opts.concurrency, "concurrency", 4, "number of dependencies to check concurrently")
+	fs.IntVar(
+		&opts.hostConcurrency,
+		"host-concurrency",
+		2,
+		"maximum concurrent queries per host; 0 means no limit",
+	)
+	fs.DurationVar(
+		&opts.hostDelay,
+		"host-delay",
+		0,
+		"minimum delay between starting queries to the same host (e.g. 500ms)",
+	)
+	fs.DurationVar(
+		&opts.moduleTimeout,
+		"module-timeout",
+		0,
+		"give up on a dependency if checking it takes longer than this (e.g. 1m); 0 means no limit",
+	)
+	fs.StringVar(
+		&opts.resolver,
+		"resolver",
+		resolverGo,
+		"how to resolve versions: go (run go list) or proxy (query GOPROXY over HTTP)",
+	)
+	fs.BoolVar(
+		&opts.compare,
+		"compare",
+		false,
+		"count how many commits behind each update is using the GitHub API "+
+			"(GitHub-hosted modules only; uses GITHUB_TOKEN if set)",
+	)
+	fs.IntVar(
+		&opts.commits,
+		"commits",
+		0,
+		"list up to this many of the newest commit subjects for each update (implies -compare)",
+	)
+	fs.IntVar(
+		&opts.minCommitsBehind,
+		"min-commits-behind",
+		0,
+		"only report updates at least this many commits ahead of the current version; "+
+			"updates that cannot be compared are still reported (implies -compare)",
+	)
+	fs.BoolVar(
+		&opts.risk,
+		"risk",
+		false,
+		"label each update patch, feature, or breaking from its Conventional Commits messages "+
+			"(implies -compare)",
+	)
+	failOn := fs.String(
+		"fail-on",
+		failOnAny,
+		"exit 1 only for updates matching this comma-separated policy: any, a risk "+
+			"(patch, feature, or breaking) that updates must be at least, and severities "+
+			"(low, medium, high, or critical); a risk implies -risk, and updates of unknown "+
+			"risk count as breaking",
+	)
+	fs.IntVar(
+		&opts.warningDays,
+		"warning-days",
+		30,
+		"raise the severity of updates whose current commit is at least this many days older "+
+			"than the latest to medium (0 disables)",
+	)
+	fs.IntVar(
+		&opts.criticalDays,
+		"critical-days",
+		90,
+		"raise the severity of updates whose current commit is at least this many days older "+
+			"than the latest to critical (0 disables)",
+	)
+	fs.BoolVar(
+		&opts.authors,
+		"authors",
+		false,
+		"list the authors of the new commits in each update (implies -compare)",
+	)
+	fs.BoolVar(
+		&opts.changes,
+		"changes",
+		false,
+		"summarize the files changed by each update, noting go.mod and exported API changes "+
+			"(implies -compare)",
+	)
+	fs.BoolVar(
+		&opts.releaseNotes,
+		"release-notes",
+		false,
+		"include the changelog sections or GitHub release notes covering each update "+
+			"(GitHub-hosted modules only)",
+	)
+	fs.BoolVar(
+		&opts.vuln,
+		"vuln",
+		false,
+		"report known vulnerabilities affecting the current version of each update, "+
+			"and whether the update fixes them",
+	)
+	fs.StringVar(
+		&opts.vulnSource,
+		"vuln-source",
+		vulnSourceGo,
+		"where -vuln looks up vulnerabilities: vulndb (the Go vulnerability database) "+
+			"or osv (the OSV.dev API, which includes severities); implies -vuln",
+	)
+	fs.StringVar(
+		&opts.vulnDBURL,
+		"vulndb-url",
+		defaultVulnDBURL(),
+		"Go vulnerability database URL for -vuln",
+	)
+	fs.BoolVar(
+		&opts.verifySumDB,
+		"verify-sumdb",
+		false,
+		"verify that each update's latest version is in the checksum database (GOSUMDB), "+
+			"noting modules exempted by GONOSUMDB or GOPRIVATE",
+	)
+	fs.BoolVar(
+		&opts.compareURLs,
+		"compare-urls",
+		false,
+		"include a link comparing the current and latest commits for each update",
+	)
+	fs.BoolVar(
+		&opts.apiDiff,
+		"api-diff",
+		false,
+		"compare the exported API of each update with the current version, "+
+			"downloading both from GOPROXY, and list incompatible changes",
+	)
+	fs.BoolVar(
+		&opts.licenses,
+		"licenses",
+		false,
+		"flag updates that change the module's license, downloading both versions from GOPROXY",
+	)
+	fs.BoolVar(
+		&opts.verifySignatures,
+		"verify-signatures",
+		false,
+		"check whether the latest commit of each update has a verified signature "+
+			"(GitHub-hosted modules only)",
+	)
+	requireSigned := fs.String(
+		"require-signed",
+		"",
+		"comma-separated module path patterns (as in GOPRIVATE) whose updates to commits "+
+			"without a verified signature are refused and reported as failures "+
+			"(implies -verify-signatures)",
+	)
+	fs.BoolVar(
+		&opts.pins,
+		"pins",
+		false,
+		"check that each dependency's pinned commit still exists upstream, flagging rewritten "+
+			"history and deleted repositories (GitHub-hosted modules only)",
+	)
+	fs.BoolVar(
+		&opts.forkDivergence,
+		"fork-divergence",
+		false,
+		"for forks that replace modules in go.mod, report how many commits they are ahead of "+
+			"and behind their upstreams' latest versions (GitHub-hosted forks only)",
+	)
+	fs.BoolVar(
+		&opts.monorepo,
+		"monorepo",
+		false,
+		"resolve modules in subdirectories of GitHub repositories to the newest commit that "+
+			"changed their directory rather than the branch head",
+	)
+	fs.BoolVar(
+		&opts.skipRetracted,
+		"skip-retracted",
+		false,
+		"skip updates to versions that the latest version's go.mod file retracts in favor "+
+			"of the next branch head, newest tag, or current version, failing with code "+
+			"retracted if all are retracted (implied by -update)",
+	)
+	moduleTracking := fs.String(
+		"module-tracking",
+		"",
+		"comma-separated module=branch|dir pairs choosing, for particular modules, whether "+
+			"the latest version is the branch head or, as with -monorepo, the newest commit "+
+			"that changed the module's directory; the module may be a glob pattern",
+	)
+	fs.BoolVar(
+		&opts.vendor,
+		"vendor",
+		false,
+		"report dependencies whose versions in vendor/modules.txt differ from those go.mod "+
+			"requires",
+	)
+	fs.BoolVar(
+		&opts.all,
+		"all",
+		false,
+		"also report newer releases of requirements at tagged versions, as go list -u -m all "+
+			"would",
+	)
+	fs.BoolVar(
+		&opts.toolchain,
+		"toolchain",
+		false,
+		"also report whether go.mod's go and toolchain directives are older than the latest "+
+			"Go release, from go.dev",
+	)
+	fs.BoolVar(
+		&opts.toolPins,
+		"tool-pins",
+		false,
+		"also check tools pinned to commits by go install or go run in Dockerfiles, "+
+			"Makefiles, shell scripts, and GitHub Actions workflows next to go.mod",
+	)
+	fs.BoolVar(
+		&opts.tags,
+		"tags",
+		false,
+		"report whether each dependency's module has any tagged releases, which could be "+
+			"required instead of a commit",
+	)
+	fs.BoolVar(
+		&opts.why,
+		"why",
+		false,
+		"show the shortest chain of imports from this module to each updated dependency "+
+			"(runs go mod why -m, which needs the module's dependencies)",
+	)
+	fs.BoolVar(
+		&opts.summary,
+		"summary",
+		false,
+		"add freshness statistics: how many dependencies are pinned, stale, and failed, "+
+			"the median and largest staleness, and the most out-of-date module",
+	)
+	fs.BoolVar(
+		&opts.timings,
+		"timings",
+		false,
+		"add how long each dependency took to check, and how many of its branches were "+
+			"queried or served from the cache, to the JSON report",
+	)
+	fs.BoolVar(
+		&opts.requiredBy,
+		"required-by",
+		false,
+		"show which other modules' go.mod files require each updated dependency "+
+			"(runs go mod graph, which needs the module's dependencies)",
+	)
+	fs.BoolVar(
+		&opts.testOnly,
+		"test-only",
+		false,
+		"label updated dependencies that only tests need (runs go mod why -m and go list, "+
+			"which need the module's dependencies)",
+	)
+	fs.BoolVar(
+		&opts.skipTestOnly,
+		"skip-test-only",
+		false,
+		"leave out updates of dependencies that only tests need (implies -test-only)",
+	)
+	fs.BoolVar(
+		&opts.pathChanges,
+		"path-changes",
+		false,
+		"flag updates whose module path changed, as declared by the latest go.mod (from GOPROXY) "+
+			"or because the GitHub repository moved",
+	)
+	fs.BoolVar(
+		&opts.abandoned,
+		"abandoned",
+		false,
+		"flag dependencies whose repositories are archived or inactive (see -abandoned-months), "+
+			"whether or not they have updates (GitHub-hosted modules only)",
+	)
+	fs.IntVar(
+		&opts.abandonedMonths,
+		"abandoned-months",
+		12,
+		"with -abandoned, also flag repositories with no commits on their default branch "+
+			"for this many months (0 to flag only archived repositories)",
+	)
+	fs.StringVar(
+		&opts.githubAPIURL,
+		"github-api-url",
+		defaultGitHubAPIURL(),
+		"GitHub API base URL for -compare, -release-notes, -verify-signatures, -pins, "+
+			"-path-changes, and -abandoned",
+	)
+	fs.BoolVar(&opts.verbose, "v", false, "log each dependency resolution to stderr")
+	fs.BoolVar(
+		&opts.debug,
+		"debug",
+		false,
+		"log resolver queries, go list commands, timings, and cache hits to stderr",
+	)
+	fs.StringVar(
+		&opts.logFormat,
+		"log-format",
+		logFormatText,
+		"format of the logs on stderr: text, or json (slog JSON records, including "+
+			"warnings and errors without -v)",
+	)
+	fs.StringVar(&opts.color, "color", colorAuto, "color output: auto, always, or never")
+	fs.StringVar(
+		&opts.format,
+		"format",
+		formatText,
+		"output format: text, json, markdown (e.g. for a pull request body), "+
+			"cyclonedx or spdx (SBOMs), renovate (for a Renovate custom datasource), "+
+			"dependabot (a commit message with Dependabot's metadata), rdjson (for reviewdog), "+
+			"gha-matrix (a GitHub Actions strategy matrix of the updates), "+
+			"or dot (a Graphviz graph)",
+	)
+	fs.StringVar(
+		&opts.sort,
+		"sort",
+		sortGoMod,
+		"order of dependencies in the output: gomod, age (most stale first), name, host, or "+
+			"severity (most urgent first)",
+	)
+	fs.StringVar(
+		&opts.groupBy,
+		"group-by",
+		"",
+		"group updates in text and markdown output: host (e.g. github.com)",
+	)
+	fs.StringVar(
+		&opts.notify,
+		"notify",
+		"",
+		"post updates and failures to a chat webhook: slack, discord, or teams, "+
+			"or by email (nothing is sent when clean); or the JSON report to any URL: webhook",
+	)
+	webhooks := map[string]webhookFlag{
+		notifySlack: {
+			url: fs.String(
+				"slack-webhook",
+				"",
+				"Slack incoming webhook URL for -notify slack (default $SLACK_WEBHOOK_URL)",
+			),
+			flag: "slack-webhook",
+			env:  "SLACK_WEBHOOK_URL",
+		},
+		notifyDiscord: {
+			url: fs.String(
+				"discord-webhook",
+				"",
+				"Discord webhook URL for -notify discord (default $DISCORD_WEBHOOK_URL)",
+			),
+			flag: "discord-webhook",
+			env:  "DISCORD_WEBHOOK_URL",
+		},
+		notifyTeams: {
+			url: fs.String(
+				"teams-webhook",
+				"",
+				"Microsoft Teams workflow or incoming webhook URL for -notify teams "+
+					"(default $TEAMS_WEBHOOK_URL)",
+			),
+			flag: "teams-webhook",
+			env:  "TEAMS_WEBHOOK_URL",
+		},
+		notifyWebhook: {
+			url: fs.String(
+				"webhook-url",
+				"",
+				"URL to POST the JSON report to for -notify webhook (default $WEBHOOK_URL)",
+			),
+			flag: "webhook-url",
+			env:  "WEBHOOK_URL",
+		},
 	}
+	webhookSecret := fs.String(
+		"webhook-secret",
+		"",
+		"key to sign -notify webhook requests with HMAC-SHA256 in the "+signatureHeader+
+			" header (default $WEBHOOK_SECRET)",
+	)
+	fs.StringVar(
+		&opts.email.addr,
+		"smtp-addr",
+		"",
+		"SMTP server host:port for -notify email; port 465 uses TLS, "+
+			"other ports STARTTLS if offered",
+	)
+	fs.StringVar(
+		&opts.email.username,
+		"smtp-username",
+		"",
+		"SMTP username for -notify email; the password is read from $SMTP_PASSWORD",
+	)
+	fs.StringVar(&opts.email.from, "email-from", "", "sender address for -notify email")
+	emailTo := fs.String("email-to", "", "comma-separated recipients for -notify email")
+	fs.StringVar(
+		&opts.notifyTemplate,
+		"notify-template",
+		"",
+		"file with a Go text/template over the JSON report's structure to render -notify "+
+			"messages with, instead of the default format",
+	)
+	fs.StringVar(
+		&opts.notifyState,
+		"notify-state",
+		"",
+		"file recording the updates and failures -notify has announced, so that each is "+
+			"announced once rather than on every run",
+	)
+	fs.BoolVar(
+		&opts.precommit,
+		"precommit",
+		false,
+		"pre-commit hook mode: report only updates at least -precommit-days old, tersely, "+
+			"using cached resolutions where possible; failures to check are only warnings",
+	)
+	fs.IntVar(
+		&opts.precommitDays,
+		"precommit-days",
+		90,
+		"with -precommit, how much older than the latest a pinned commit must be to be reported",
+	)
+	fs.BoolVar(
+		&opts.update,
+		"update",
+		false,
+		"rewrite go.mod, moving each pseudo-versioned requirement with an update to its "+
+			"latest version (then run go mod tidy)",
+	)
+	fs.BoolVar(
+		&opts.preferTags,
+		"prefer-tags",
+		false,
+		"with -update, move requirements whose modules have a newer tagged release to that "+
+			"tag instead of the latest commit (implies -tags)",
+	)
+	fs.BoolVar(
+		&opts.allowBreaking,
+		"allow-breaking",
+		false,
+		"with -update, also update dependencies whose updates -risk or -api-diff found to "+
+			"be breaking",
+	)
+	fs.IntVar(
+		&opts.maxUpdates,
+		"max-updates",
+		0,
+		"with -update, make at most this many updates, deferring the rest to a later run "+
+			"(0 means no limit)",
+	)
+	fs.StringVar(
+		&opts.verify,
+		"verify",
+		"",
+		"with -update, make the updates one at a time, running this shell command (e.g. "+
+			"\"go mod tidy && go test ./...\") in go.mod's directory after each and undoing "+
+			"those it fails for",
+	)
+	fs.StringVar(
+		&opts.cooldownState,
+		"cooldown-state",
+		"",
+		"with -verify, record the updates it fails for in this JSON file and do not "+
+			"propose them again until -cooldown has passed",
+	)
+	fs.DurationVar(
+		&opts.cooldown,
+		"cooldown",
+		0,
+		"with -cooldown-state, how long to wait before proposing an update -verify failed "+
+			"for again (default 168h)",
+	)
+	fs.BoolVar(
+		&opts.updateVendor,
+		"update-vendor",
+		false,
+		"with -update, run go mod vendor after the updates if the module has a vendor "+
+			"directory, undoing them if it fails",
+	)
+	fs.StringVar(
+		&opts.badge,
+		"badge",
+		"",
+		"write a shields.io endpoint badge (e.g. \"untagged deps: 2 behind\") to this JSON file",
+	)
+	fs.BoolVar(
+		&opts.gha,
+		"gha",
+		false,
+		"annotate go.mod with updates and failures using GitHub Actions workflow commands, "+
+			"and write step outputs to $GITHUB_OUTPUT",
+	)
+	fs.BoolVar(
+		&opts.exitZero,
+		"exit-zero",
+		false,
+		"exit 0 even when updates are found (errors still exit non-zero)",
+	)
+	branches := fs.String(
+		"branches",
+		strings.Join(check.DefaultBranches, ","),
+		"comma-separated branches to check; the most recent commit among them is used",
+	)
+	moduleBranches := fs.String(
+		"module-branches",
+		"",
+		"comma-separated module=branch pairs setting the branch checked for particular "+
+			"modules instead of -branches, e.g. k8s.io/client-go=release-1.29; the module "+
+			"may be a glob pattern as in GOPRIVATE, e.g. k8s.io/*=master",
+	)
+	fs.BoolVar(
+		&opts.releaseBranches,
+		"release-branches",
+		false,
+		"check dependencies whose pseudo-versions are based on a release against their "+
+			"release line's branch, such as release-1.29, if there is one",
+	)
+	only := fs.String(
+		"only",
+		"",
+		"comma-separated list of modules to check "+
+			"(may also be given as arguments after the go.mod path)",
+	)
+	fs.BoolVar(
+		&opts.explain,
+		"explain",
+		false,
+		"print to stderr, for each requirement in go.mod, whether it is checked and why, "+
+			"e.g. that it is not a pseudo-version or is indirect and -i is not set",
+	)
+	fs.StringVar(
+		&opts.gitRef,
+		"git-ref",
+		"",
+		"check go.mod as of this git ref without checking it out, e.g. origin/main, or "+
+			"origin/main:path/to/go.mod for a path from the repository root",
+	)
+	scheduleSpec := fs.String(
+		"schedule",
+		"",
+		"run as a daemon, checking now and then on this cron schedule (e.g. \"0 14 * * *\" "+
+			"or @daily, in local time); arguments are then go.mod files to check",
+	)
+	scheduleDays := fs.String(
+		"schedule-days",
+		"",
+		"comma-separated days of the week (e.g. monday) on which the daemon notifies "+
+			"and -respect-schedule runs, so updates arrive in batches",
+	)
+	scheduleTimezone := fs.String(
+		"schedule-timezone",
+		"",
+		"IANA time zone (e.g. America/New_York) of -schedule and -schedule-days "+
+			"(default local time)",
+	)
+	fs.BoolVar(
+		&opts.respectSchedule,
+		"respect-schedule",
+		false,
+		"exit without checking, successfully, when run outside -schedule-days",
+	)
+	fs.StringVar(
+		&opts.listen,
+		"listen",
+		"",
+		"run as a server on this address (e.g. :9090), with GET /report, POST /check, and "+
+			"Prometheus metrics at GET /metrics; checks run at startup, on -schedule, and "+
+			"when requested, and arguments are then go.mod files to check",
+	)
+	fs.BoolVar(&opts.showVersion, "version", false, "print version information and exit")
+	fs.BoolVar(
+		&opts.printSchema,
+		"print-schema",
+		false,
+		"print the JSON Schema for -format json output and exit",
+	)
 
-	fmt.Println("Pseudo-versioned dependencies in go.mod:")
-	for _, dep := range deps {
-		fmt.Printf("  %s\n", dep.module)
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
 	}
-	fmt.Println()
 
-	if len(updates) > 0 {
-		fmt.Println("Updates available:")
-		for _, u := range updates {
-			fmt.Printf("  %s: %s -> %s\n", u.module, u.current, u.latest)
+	switch opts.resolver {
+	case resolverGo, resolverProxy:
+	default:
+		return options{}, &usageError{
+			msg: fmt.Sprintf("invalid -resolver value %q: must be go or proxy", opts.resolver),
 		}
-		return true, nil
 	}
 
-	fmt.Println("No updates found for pseudo-versioned dependencies.")
-	return false, nil
-}
+	switch opts.sort {
+	case sortGoMod, sortAge, sortName, sortHost, sortSeverity:
+	default:
+		return options{}, &usageError{
+			msg: fmt.Sprintf(
+				"invalid -sort value %q: must be gomod, age, name, host, or severity",
+				opts.sort,
+			),
+		}
+	}
 
-// checkGoMod finds pseudo-versioned dependencies in the given go.mod file and
-// checks if updates are available for them.
-func checkGoMod(
-	ctx context.Context,
-	gomodPath string,
-	includeIndirect bool,
-) ([]dependency, []update, error) {
-	deps, err := findPseudoVersionedDeps(gomodPath, includeIndirect)
-	if err != nil {
-		return nil, nil, fmt.Errorf("reading %s: %w", gomodPath, err)
+	switch opts.groupBy {
+	case "", groupHost:
+	default:
+		return options{}, &usageError{
+			msg: fmt.Sprintf("invalid -group-by value %q: must be host", opts.groupBy),
+		}
+	}
+
+	switch opts.format {
+	case formatText,
+		formatJSON,
+		formatMarkdown,
+		formatCycloneDX,
+		formatSPDX,
+		formatRenovate,
+		formatDependabot,
+		formatRDJSON,
+		formatGHAMatrix,
+		formatDOT:
+	default:
+		return options{}, &usageError{
+			msg: fmt.Sprintf(
+				"invalid -format value %q: must be text, json, markdown, cyclonedx, spdx, "+
+					"renovate, dependabot, rdjson, gha-matrix, or dot",
+				opts.format,
+			),
+		}
+	}
+
+	if opts.notifyTemplate != "" && opts.notify == "" {
+		return options{}, &usageError{msg: "-notify-template requires -notify"}
+	}
+	if opts.notifyState != "" && opts.notify == "" {
+		return options{}, &usageError{msg: "-notify-state requires -notify"}
+	}
+	if opts.notify == notifyEmail {
+		opts.email.password = os.Getenv("SMTP_PASSWORD")
+		opts.email.to = splitList(*emailTo)
+		if opts.email.addr == "" || opts.email.from == "" || len(opts.email.to) == 0 {
+			return options{}, &usageError{
+				msg: "-notify email requires -smtp-addr, -email-from, and -email-to",
+			}
+		}
+	} else if opts.notify != "" {
+		webhook, ok := webhooks[opts.notify]
+		if !ok {
+			return options{}, &usageError{
+				msg: fmt.Sprintf(
+					"invalid -notify value %q: must be slack, discord, teams, email, or webhook",
+					opts.notify,
+				),
+			}
+		}
+		opts.webhookURL = *webhook.url
+		if opts.webhookURL == "" {
+			opts.webhookURL = os.Getenv(webhook.env)
+		}
+		if opts.webhookURL == "" {
+			return options{}, &usageError{
+				msg: fmt.Sprintf(
+					"-notify %s requires -%s or %s",
+					opts.notify,
+					webhook.flag,
+					webhook.env,
+				),
+			}
+		}
+	}
+	if opts.notify == notifyWebhook {
+		opts.webhookSecret = *webhookSecret
+		if opts.webhookSecret == "" {
+			opts.webhookSecret = os.Getenv("WEBHOOK_SECRET")
+		}
 	}
 
-	if len(deps) == 0 {
-		return nil, nil, nil
+	switch opts.vulnSource {
+	case vulnSourceGo:
+	case vulnSourceOSV:
+		opts.vuln = true
+	default:
+		return options{}, &usageError{
+			msg: fmt.Sprintf(
+				"invalid -vuln-source value %q: must be vulndb or osv",
+				opts.vulnSource,
+			),
+		}
+	}
+
+	switch opts.logFormat {
+	case logFormatText, logFormatJSON:
+	default:
+		return options{}, &usageError{
+			msg: fmt.Sprintf("invalid -log-format value %q: must be text or json", opts.logFormat),
+		}
 	}
 
-	updates, err := checkForUpdates(ctx, deps)
+	switch opts.color {
+	case colorAuto, colorAlways, colorNever:
+	default:
+		return options{}, &usageError{
+			msg: fmt.Sprintf("invalid -color value %q: must be auto, always, or never", opts.color),
+		}
+	}
+
+	policy, err := parseFailPolicy(*failOn)
 	if err != nil {
-		return nil, nil, err
+		return options{}, &usageError{msg: err.Error()}
+	}
+	opts.failOn = policy
+	if policy.risk != check.RiskUnknown {
+		opts.risk = true
+	}
+
+	for _, days := range []struct {
+		flag  string
+		value int
+	}{
+		{"warning-days", opts.warningDays},
+		{"critical-days", opts.criticalDays},
+	} {
+		if days.value < 0 {
+			return options{}, &usageError{
+				msg: fmt.Sprintf(
+					"invalid -%s value %d: must not be negative",
+					days.flag,
+					days.value,
+				),
+			}
+		}
+	}
+
+	if opts.minCommitsBehind < 0 {
+		return options{}, &usageError{msg: "-min-commits-behind must not be negative"}
 	}
 
-	return deps, updates, nil
+	if opts.abandonedMonths < 0 {
+		return options{}, &usageError{
+			msg: fmt.Sprintf(
+				"invalid -abandoned-months value %d: must not be negative",
+				opts.abandonedMonths,
+			),
+		}
+	}
+
+	opts.gomodPath = "go.mod"
+	if fs.NArg() > 0 {
+		opts.gomodPath = fs.Arg(0)
+	}
+
+	opts.branches = splitList(*branches)
+	if len(opts.branches) == 0 {
+		return options{}, &usageError{msg: "-branches must list at least one branch"}
+	}
+
+	opts.requireSigned = splitList(*requireSigned)
+	if len(opts.requireSigned) > 0 {
+		opts.verifySignatures = true
+	}
+
+	if opts.moduleBranches, err = parseModuleBranches(*moduleBranches); err != nil {
+		return options{}, &usageError{msg: "-module-branches: " + err.Error()}
+	}
+	if opts.moduleTracking, err = parseModuleTracking(*moduleTracking); err != nil {
+		return options{}, &usageError{msg: "-module-tracking: " + err.Error()}
+	}
+
+	opts.only = splitList(*only)
+	if *scheduleSpec != "" {
+		var err error
+		if opts.schedule, err = parseSchedule(*scheduleSpec); err != nil {
+			return options{}, &usageError{msg: "-schedule: " + err.Error()}
+		}
+	}
+	opts.location = time.Local
+	if *scheduleTimezone != "" {
+		if *scheduleSpec == "" && *scheduleDays == "" {
+			return options{}, &usageError{
+				msg: "-schedule-timezone requires -schedule or -schedule-days",
+			}
+		}
+		var err error
+		if opts.location, err = time.LoadLocation(*scheduleTimezone); err != nil {
+			return options{}, &usageError{msg: "-schedule-timezone: " + err.Error()}
+		}
+	}
+	if *scheduleDays != "" {
+		var err error
+		if opts.window, err = parseWindow(*scheduleDays, opts.location); err != nil {
+			return options{}, &usageError{msg: "-schedule-days: " + err.Error()}
+		}
+	}
+	if opts.respectSchedule {
+		switch {
+		case opts.window == nil:
+			return options{}, &usageError{msg: "-respect-schedule requires -schedule-days"}
+		case opts.schedule != nil || opts.listen != "":
+			// The daemon always keeps to -schedule-days.
+			return options{}, &usageError{
+				msg: "-respect-schedule cannot be used with -schedule or -listen",
+			}
+		}
+	}
+	if opts.explain && (opts.schedule != nil || opts.listen != "") {
+		return options{}, &usageError{msg: "-explain cannot be used with -schedule or -listen"}
+	}
+	if opts.gha && (opts.schedule != nil || opts.listen != "") {
+		return options{}, &usageError{msg: "-gha cannot be used with -schedule or -listen"}
+	}
+	if opts.badge != "" && (opts.schedule != nil || opts.listen != "") {
+		return options{}, &usageError{msg: "-badge cannot be used with -schedule or -listen"}
+	}
+	if opts.precommit {
+		if opts.schedule != nil || opts.listen != "" {
+			return options{}, &usageError{
+				msg: "-precommit cannot be used with -schedule or -listen",
+			}
+		}
+		if opts.precommitDays < 0 {
+			return options{}, &usageError{msg: "-precommit-days must not be negative"}
+		}
+		if opts.cacheTTL == 0 {
+			opts.cacheTTL = precommitCacheTTL
+		}
+	}
+	if opts.preferTags {
+		if !opts.update {
+			return options{}, &usageError{msg: "-prefer-tags requires -update"}
+		}
+		opts.tags = true
+	}
+	if opts.allowBreaking && !opts.update {
+		return options{}, &usageError{msg: "-allow-breaking requires -update"}
+	}
+	if opts.maxUpdates != 0 {
+		switch {
+		case !opts.update:
+			return options{}, &usageError{msg: "-max-updates requires -update"}
+		case opts.maxUpdates < 0:
+			return options{}, &usageError{msg: "-max-updates must not be negative"}
+		}
+	}
+	if opts.verify != "" && !opts.update {
+		return options{}, &usageError{msg: "-verify requires -update"}
+	}
+	if opts.updateVendor && !opts.update {
+		return options{}, &usageError{msg: "-update-vendor requires -update"}
+	}
+	if opts.cooldownState != "" && opts.verify == "" {
+		return options{}, &usageError{msg: "-cooldown-state requires -verify"}
+	}
+	switch {
+	case opts.cooldown < 0:
+		return options{}, &usageError{msg: "-cooldown must not be negative"}
+	case opts.cooldown > 0 && opts.cooldownState == "":
+		return options{}, &usageError{msg: "-cooldown requires -cooldown-state"}
+	case opts.cooldown == 0:
+		opts.cooldown = defaultCooldown
+	}
+	if opts.update {
+		// The requirers order the edits (see orderEdits).
+		opts.requiredBy = true
+		opts.skipRetracted = true
+		switch {
+		case opts.schedule != nil || opts.listen != "":
+			return options{}, &usageError{msg: "-update cannot be used with -schedule or -listen"}
+		case opts.gitRef != "":
+			return options{}, &usageError{msg: "-update cannot be used with -git-ref"}
+		case opts.precommit:
+			return options{}, &usageError{msg: "-update cannot be used with -precommit"}
+		}
+	}
+	if opts.gitRef != "" {
+		switch {
+		case opts.schedule != nil || opts.listen != "":
+			return options{}, &usageError{
+				msg: "-git-ref cannot be used with -schedule or -listen",
+			}
+		case opts.precommit:
+			return options{}, &usageError{msg: "-git-ref cannot be used with -precommit"}
+		case opts.why || opts.testOnly || opts.skipTestOnly || opts.requiredBy || opts.vendor ||
+			opts.toolPins:
+			// These need the module's source, not just its go.mod file.
+			return options{}, &usageError{
+				msg: "-git-ref cannot be used with -why, -test-only, -skip-test-only, " +
+					"-required-by, -vendor, or -tool-pins",
+			}
+		}
+	}
+	if opts.schedule != nil || opts.listen != "" {
+		// In daemon mode, the arguments are go.mod files.
+		opts.gomodPaths = fs.Args()
+		if len(opts.gomodPaths) == 0 {
+			opts.gomodPaths = []string{opts.gomodPath}
+		}
+	} else if fs.NArg() > 1 {
+		opts.only = append(opts.only, fs.Args()[1:]...)
+	}
+
+	return opts, nil
 }
 
-// dependency represents a pseudo-versioned dependency found in go.mod.
-type dependency struct {
-	module  string
-	version string
+// splitList splits a comma-separated flag value, dropping empty elements.
+func splitList(s string) []string {
+	var list []string
+	for v := range strings.SplitSeq(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
 }
 
-func findPseudoVersionedDeps(gomodPath string, includeIndirect bool) ([]dependency, error) {
-	data, err := os.ReadFile(filepath.Clean(gomodPath))
+// parseModuleBranches parses a -module-branches value, comma-separated
+// module=branch pairs, into a map from module path or pattern to branch.
+func parseModuleBranches(s string) (map[string]string, error) {
+	return parseModulePairs(s, "branch")
+}
+
+// parseModuleTracking parses a -module-tracking value, comma-separated
+// module=branch|dir pairs, into a map from module path or pattern to
+// tracking.
+func parseModuleTracking(s string) (map[string]check.Tracking, error) {
+	pairs, err := parseModulePairs(s, "branch|dir")
 	if err != nil {
-		return nil, fmt.Errorf("reading file: %w", err)
+		return nil, err
+	}
+	tracking := map[string]check.Tracking{}
+	for mod, t := range pairs {
+		switch t := check.Tracking(t); t {
+		case check.TrackBranch, check.TrackDir:
+			tracking[mod] = t
+		default:
+			return nil, fmt.Errorf("invalid tracking %q for %s: must be branch or dir", t, mod)
+		}
 	}
+	return tracking, nil
+}
 
-	f, err := modfile.Parse(gomodPath, data, nil)
-	if err != nil {
-		return nil, fmt.Errorf("parsing go.mod: %w", err)
+// parseModulePairs parses comma-separated module=value pairs into a map from
+// module path or pattern to value. name describes the value in errors.
+func parseModulePairs(s, name string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, pair := range splitList(s) {
+		mod, value, ok := strings.Cut(pair, "=")
+		mod, value = strings.TrimSpace(mod), strings.TrimSpace(value)
+		if !ok || mod == "" || value == "" {
+			return nil, fmt.Errorf("invalid pair %q: must be module=%s", pair, name)
+		}
+		if _, err := path.Match(mod, ""); err != nil {
+			return nil, fmt.Errorf("invalid module pattern %q: %w", mod, err)
+		}
+		if _, dup := values[mod]; dup {
+			return nil, fmt.Errorf("%s is listed more than once", mod)
+		}
+		values[mod] = value
 	}
+	return values, nil
+}
 
-	var deps []dependency
-	for _, req := range f.Require {
-		if !module.IsPseudoVersion(req.Mod.Version) {
+// options holds the command line options.
+type options struct {
+	gomodPath        string
+	gitRef           string
+	includeIndirect  bool
+	cacheTTL         time.Duration
+	concurrency      int
+	hostConcurrency  int
+	hostDelay        time.Duration
+	moduleTimeout    time.Duration
+	resolver         string
+	compare          bool
+	commits          int
+	minCommitsBehind int
+	risk             bool
+	failOn           failPolicy
+	warningDays      int
+	criticalDays     int
+	authors          bool
+	changes          bool
+	releaseNotes     bool
+	vuln             bool
+	vulnSource       string
+	vulnDBURL        string
+	verifySumDB      bool
+	compareURLs      bool
+	apiDiff          bool
+	licenses         bool
+	verifySignatures bool
+	requireSigned    []string
+	pins             bool
+	monorepo         bool
+	forkDivergence   bool
+	tags             bool
+	vendor           bool
+	toolPins         bool
+	all              bool
+	toolchain        bool
+	why              bool
+	requiredBy       bool
+	summary          bool
+	timings          bool
+	testOnly         bool
+	skipTestOnly     bool
+	pathChanges      bool
+	abandoned        bool
+	abandonedMonths  int
+	githubAPIURL     string
+	verbose          bool
+	debug            bool
+	logFormat        string
+	color            string
+	format           string
+	sort             string
+	groupBy          string
+	notify           string
+	webhookURL       string
+	webhookSecret    string
+	email            smtpConfig
+	notifyTemplate   string
+	notifyState      string
+	schedule         *schedule
+	location         *time.Location
+	window           *window
+	respectSchedule  bool
+	listen           string
+	gomodPaths       []string
+	gha              bool
+	badge            string
+	precommit        bool
+	precommitDays    int
+	update           bool
+	preferTags       bool
+	allowBreaking    bool
+	maxUpdates       int
+	skipRetracted    bool
+	explain          bool
+	verify           string
+	cooldownState    string
+	cooldown         time.Duration
+	updateVendor     bool
+	exitZero         bool
+	only             []string
+	branches         []string
+	moduleBranches   map[string]string
+	moduleTracking   map[string]check.Tracking
+	releaseBranches  bool
+	showVersion      bool
+	printSchema      bool
+}
+
+// webhookFlag is the flag, and the environment variable used if it is not
+// set, giving a -notify target's webhook URL. The URL is a secret, so it is
+// not shown as the flag's default.
+type webhookFlag struct {
+	url  *string
+	flag string
+	env  string
+}
+
+// failOnAny is the -fail-on value meaning any update fails, regardless of
+// risk or severity.
+const failOnAny = "any"
+
+// failPolicy is which updates cause a non-zero exit code, from -fail-on. The
+// zero value means any update does.
+type failPolicy struct {
+	// risk, if set, is how risky an update must at least be.
+	risk check.Risk
+	// severities, if set, are the severities of updates that fail.
+	severities []check.Severity
+}
+
+// parseFailPolicy parses a -fail-on value: a comma-separated list of any, at
+// most one risk, and severities. An update fails if it is at least the risk
+// or has one of the severities.
+func parseFailPolicy(s string) (failPolicy, error) {
+	var policy failPolicy
+	for v := range strings.SplitSeq(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == failOnAny {
 			continue
 		}
-		if req.Indirect && !includeIndirect {
+		if risk, ok := check.ParseRisk(v); ok {
+			if policy.risk != check.RiskUnknown && policy.risk != risk {
+				return failPolicy{}, fmt.Errorf(
+					"invalid -fail-on value %q: at most one risk may be given",
+					s,
+				)
+			}
+			policy.risk = risk
 			continue
 		}
-		deps = append(deps, dependency{
-			module:  req.Mod.Path,
-			version: req.Mod.Version,
-		})
+		if severity, ok := check.ParseSeverity(v); ok {
+			if !slices.Contains(policy.severities, severity) {
+				policy.severities = append(policy.severities, severity)
+			}
+			continue
+		}
+		return failPolicy{}, fmt.Errorf(
+			"invalid -fail-on value %q: must be any, patch, feature, breaking, low, "+
+				"medium, high, or critical",
+			s,
+		)
 	}
+	return policy, nil
+}
 
-	return deps, nil
+// fails reports whether u causes a non-zero exit code under the policy.
+func (p failPolicy) fails(u check.Update) bool {
+	if p.failsAny() {
+		return true
+	}
+	if p.risk != check.RiskUnknown && u.Risk.AtLeast(p.risk) {
+		return true
+	}
+	return slices.Contains(p.severities, u.Severity)
 }
 
-// update represents an available update for a dependency.
-type update struct {
-	module  string
-	current string
-	latest  string
+// failsAny reports whether every update fails, as with -fail-on any. Newer
+// releases of tagged requirements (-all) and an outdated Go toolchain
+// (-toolchain) have no risk or severity, so they only fail then.
+func (p failPolicy) failsAny() bool {
+	return p.risk == check.RiskUnknown && len(p.severities) == 0
 }
 
-func checkForUpdates(ctx context.Context, deps []dependency) ([]update, error) {
-	var updates []update
+const (
+	resolverGo    = "go"
+	resolverProxy = "proxy"
+)
+
+// Values of -vuln-source.
+const (
+	vulnSourceGo  = "vulndb"
+	vulnSourceOSV = "osv"
+)
+
+// defaultGitHubAPIURL returns the GitHub API URL from GITHUB_API_URL, which
+// GitHub Actions sets (including on GitHub Enterprise Server), or the public
+// API's URL.
+func defaultGitHubAPIURL() string {
+	if u := os.Getenv("GITHUB_API_URL"); u != "" {
+		return u
+	}
+	return "https://api.github.com"
+}
 
-	for _, dep := range deps {
-		latest, err := getLatestVersion(ctx, dep.module)
+// defaultVulnDBURL returns the vulnerability database URL from GOVULNDB, which
+// govulncheck also uses, or the public database's URL.
+func defaultVulnDBURL() string {
+	if u := os.Getenv("GOVULNDB"); u != "" {
+		return u
+	}
+	return check.DefaultVulnDBURL
+}
+
+// newSumDBVerifier returns a checksum database verifier configured from the
+// environment like the go command: GOSUMDB, and GONOSUMDB or else GOPRIVATE.
+func newSumDBVerifier(logger *slog.Logger) (*check.SumDBVerifier, error) {
+	noSumDB, ok := os.LookupEnv("GONOSUMDB")
+	if !ok {
+		noSumDB = os.Getenv("GOPRIVATE")
+	}
+	return check.NewSumDBVerifier(os.Getenv("GOSUMDB"), noSumDB, nil, logger)
+}
+
+// newResolver returns the resolver with the given name.
+func newResolver(name string, concurrency int, logger *slog.Logger) (check.Resolver, error) {
+	switch name {
+	case resolverGo:
+		return check.GoListResolver{Logger: logger}, nil
+	case resolverProxy:
+		return check.NewProxyResolver(concurrency, logger)
+	default:
+		return nil, fmt.Errorf("unknown resolver %q", name)
+	}
+}
+
+// run checks the go.mod file and prints the report. It returns the exit code.
+func run(opts options) (code int, err error) {
+	// Report an error, then end the run with the summary line, whatever
+	// happens. A run that fails before checking finishes counts as one
+	// error. The daemon writes a summary line per check instead.
+	start := time.Now()
+	var summary *check.RunSummary
+	summarize := opts.schedule == nil && opts.listen == ""
+	defer func() {
 		if err != nil {
-			return nil, fmt.Errorf("checking %s: %w", dep.module, err)
+			printRunError(os.Stderr, opts.logFormat, err)
+		}
+		if !summarize {
+			return
+		}
+		if summary == nil {
+			summary = &check.RunSummary{Errors: 1, DurationMs: time.Since(start).Milliseconds()}
 		}
+		printRunSummary(os.Stderr, opts.logFormat, *summary)
+	}()
 
-		if dep.version != latest {
-			updates = append(updates, update{
-				module:  dep.module,
-				current: dep.version,
-				latest:  latest,
-			})
+	// Stop in-flight queries on interrupt rather than waiting for them.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger := newLogger(os.Stderr, opts.logFormat, opts.verbose, opts.debug)
+
+	if opts.respectSchedule && !opts.window.contains(time.Now()) {
+		summarize = false
+		if opts.logFormat == logFormatJSON {
+			logger.Info("not checking: outside -schedule-days", "window", opts.window.String())
+		} else {
+			fmt.Fprintf(os.Stderr, "Not checking: outside -schedule-days, %s.\n", opts.window)
 		}
+		return exitOK, nil
 	}
 
-	return updates, nil
-}
+	colors, err := newColorizer(opts.color, os.Stdout)
+	if err != nil {
+		return exitError, err
+	}
 
-const (
-	branchMain   = "main"
-	branchMaster = "master"
-)
+	cache, err := check.NewCache("", opts.cacheTTL)
+	if err != nil {
+		return exitError, err
+	}
+	if cache != nil {
+		cache.SetModCacheDir(check.FindModCacheDir(ctx))
+	}
 
-// getLatestVersion queries the Go module proxy for the latest version on the
-// default branch. It queries both @main and @master and returns the one with
-// the more recent timestamp (in case both exist).
-func getLatestVersion(ctx context.Context, modulePath string) (string, error) {
-	branches := []string{branchMain, branchMaster}
+	n := notifier{
+		target:     opts.notify,
+		webhookURL: opts.webhookURL,
+		secret:     opts.webhookSecret,
+		email:      opts.email,
+		client:     &http.Client{Timeout: time.Minute},
+	}
+	if opts.notifyTemplate != "" {
+		// Load the template before checking so mistakes are found quickly.
+		if n.template, err = loadNotifyTemplate(opts.notifyTemplate); err != nil {
+			return exitError, err
+		}
+	}
+	if opts.notifyState != "" {
+		if n.state, err = loadNotifyState(opts.notifyState); err != nil {
+			return exitError, err
+		}
+	}
+
+	res, err := newResolver(opts.resolver, opts.concurrency, logger)
+	if err != nil {
+		return exitError, err
+	}
 
-	var versions []string
-	for _, branch := range branches {
-		version, err := queryModuleVersion(ctx, modulePath, branch)
+	checkerOpts := []check.Option{
+		check.WithResolver(res),
+		check.WithLogger(logger),
+		check.WithCache(cache),
+		check.WithHostLimiter(check.NewHostLimiter(opts.hostConcurrency, opts.hostDelay)),
+		check.WithConcurrency(opts.concurrency),
+		check.WithPerModuleTimeout(opts.moduleTimeout),
+		check.WithTimings(opts.timings),
+		check.WithBranches(opts.branches...),
+		check.WithModuleBranches(opts.moduleBranches),
+		check.WithReleaseBranches(opts.releaseBranches),
+		check.WithIncludeIndirect(opts.includeIndirect),
+		check.WithAgeSeverity(
+			time.Duration(opts.warningDays)*24*time.Hour,
+			time.Duration(opts.criticalDays)*24*time.Hour,
+		),
+	}
+	github := check.NewGitHubClient(opts.githubAPIURL, os.Getenv("GITHUB_TOKEN"), nil)
+	if opts.compare || opts.commits > 0 || opts.minCommitsBehind > 0 || opts.risk ||
+		opts.authors || opts.changes {
+		checkerOpts = append(
+			checkerOpts,
+			check.WithComparer(github),
+			check.WithMaxCommits(opts.commits),
+			check.WithMinCommitsBehind(opts.minCommitsBehind),
+			check.WithRiskClassification(opts.risk),
+			check.WithAuthors(opts.authors),
+			check.WithChangeSummary(opts.changes),
+		)
+	}
+	if opts.releaseNotes {
+		checkerOpts = append(checkerOpts, check.WithReleaseNotes(github))
+	}
+	if opts.verifySignatures {
+		checkerOpts = append(checkerOpts, check.WithSignatureCheck(github, opts.requireSigned))
+	}
+	if opts.pins {
+		checkerOpts = append(checkerOpts, check.WithPinCheck(github))
+	}
+	if opts.monorepo {
+		checkerOpts = append(checkerOpts, check.WithDirHistory(github))
+	}
+	if len(opts.moduleTracking) > 0 {
+		checkerOpts = append(checkerOpts, check.WithModuleTracking(github, opts.moduleTracking))
+	}
+	if opts.forkDivergence {
+		checkerOpts = append(checkerOpts, check.WithForkDivergence(github))
+	}
+	if opts.why {
+		checkerOpts = append(checkerOpts, check.WithImportChains(nil))
+	}
+	if opts.vendor {
+		checkerOpts = append(checkerOpts, check.WithVendorCheck(true))
+	}
+	if opts.toolPins {
+		checkerOpts = append(checkerOpts, check.WithToolPins(true))
+	}
+	if opts.toolchain {
+		checkerOpts = append(checkerOpts, check.WithToolchainCheck(check.NewGoDownloads("", nil)))
+	}
+	if opts.requiredBy {
+		checkerOpts = append(checkerOpts, check.WithRequirers(nil))
+	}
+	if opts.testOnly || opts.skipTestOnly {
+		checkerOpts = append(checkerOpts, check.WithTestOnlyCheck(nil, opts.skipTestOnly))
+	}
+	if opts.abandoned {
+		// Months are approximated as 30 days.
+		staleAfter := time.Duration(opts.abandonedMonths) * 30 * 24 * time.Hour
+		checkerOpts = append(checkerOpts, check.WithAbandonedCheck(github, staleAfter))
+	}
+	if opts.vuln {
+		var source check.VulnSource = check.NewVulnDBClient(opts.vulnDBURL, nil)
+		if opts.vulnSource == vulnSourceOSV {
+			source = check.NewOSVClient("", nil)
+		}
+		checkerOpts = append(checkerOpts, check.WithVulnerabilities(source))
+	}
+	if opts.verifySumDB {
+		verifier, err := newSumDBVerifier(logger)
+		if err != nil {
+			return exitError, err
+		}
+		checkerOpts = append(checkerOpts, check.WithSumDBVerifier(verifier))
+	}
+	if opts.compareURLs {
+		checkerOpts = append(checkerOpts, check.WithRepoFinder(check.NewRepoFinder(nil)))
+	}
+	if opts.apiDiff || opts.licenses || opts.pathChanges || opts.tags || opts.all ||
+		opts.skipRetracted {
+		// Module files and version lists always come from the proxy,
+		// whichever resolver is used.
+		proxy, err := check.NewProxyResolver(opts.concurrency, logger)
 		if err != nil {
-			// "unknown revision" means the branch doesn't exist, try next
-			if strings.Contains(err.Error(), "unknown revision") {
-				continue
+			return exitError, fmt.Errorf("downloading modules: %w", err)
+		}
+		if opts.apiDiff {
+			checkerOpts = append(checkerOpts, check.WithCompatibility(proxy))
+		}
+		if opts.licenses {
+			checkerOpts = append(checkerOpts, check.WithLicenseCheck(proxy))
+		}
+		if opts.pathChanges {
+			checkerOpts = append(checkerOpts, check.WithPathChangeCheck(proxy, github))
+		}
+		if opts.tags {
+			checkerOpts = append(checkerOpts, check.WithTagCheck(proxy))
+		}
+		if opts.all {
+			checkerOpts = append(checkerOpts, check.WithTaggedUpdates(proxy))
+		}
+		if opts.skipRetracted {
+			checkerOpts = append(checkerOpts, check.WithRetractionCheck(proxy, proxy))
+		}
+	}
+	c := check.NewChecker(checkerOpts...)
+
+	if opts.schedule != nil || opts.listen != "" {
+		d := &daemon{
+			checker:    c,
+			schedule:   opts.schedule,
+			location:   opts.location,
+			window:     opts.window,
+			trigger:    make(chan struct{}, 1),
+			gomodPaths: opts.gomodPaths,
+			only:       opts.only,
+			format:     opts.format,
+			summary:    opts.summary,
+			sortBy:     opts.sort,
+			groupBy:    opts.groupBy,
+			colors:     colors,
+			out:        os.Stdout,
+			errOut:     os.Stderr,
+		}
+		if opts.logFormat == logFormatJSON {
+			d.logger = logger
+		}
+		if opts.notify != "" {
+			d.notifier = &n
+		}
+		if opts.listen != "" {
+			ln, err := net.Listen("tcp", opts.listen)
+			if err != nil {
+				return exitError, fmt.Errorf("listening: %w", err)
 			}
-			return "", err
+			go d.serve(ctx, ln)
 		}
-		versions = append(versions, version)
+		d.run(ctx)
+		return exitOK, nil
 	}
 
-	if len(versions) == 0 {
-		return "", errors.New("neither main nor master branch found")
+	// gomodName is how reports refer to the go.mod file, which is only
+	// gomodPath if it was not read from a git ref.
+	gomodPath, gomodName := opts.gomodPath, opts.gomodPath
+	if opts.gitRef != "" {
+		dir, err := os.MkdirTemp("", toolName+"-")
+		if err != nil {
+			return exitError, err
+		}
+		defer func() {
+			_ = os.RemoveAll(dir)
+		}()
+		gomodName, err = writeGitRefGoMod(ctx, check.ExecRunner{}, opts.gitRef, gomodPath, dir)
+		if err != nil {
+			return exitError, err
+		}
+		gomodPath = filepath.Join(dir, "go.mod")
 	}
 
-	// If we have both, return the one with the newer timestamp
-	if len(versions) == 2 {
-		return newerVersion(versions[0], versions[1])
+	if opts.explain {
+		sels, err := c.ExplainGoMod(gomodPath, opts.only...)
+		if err != nil {
+			return exitError, err
+		}
+		printSelections(os.Stderr, opts.logFormat, gomodName, sels)
 	}
 
-	return versions[0], nil
-}
+	rep, err := c.CheckGoMod(ctx, gomodPath, opts.only...)
+	if err != nil {
+		var unknownErr *check.UnknownModuleError
+		if errors.As(err, &unknownErr) {
+			return exitUsage, &usageError{msg: err.Error()}
+		}
+		return exitError, err
+	}
+	runSummary := check.NewRunSummary(rep, time.Since(start))
+	summary = &runSummary
+	sortReport(&rep, opts.sort)
 
-// moduleInfo represents the JSON output from 'go list -m -json'.
-type moduleInfo struct {
-	Path    string `json:"Path"`    //nolint:tagliatelle // matches go list output
-	Version string `json:"Version"` //nolint:tagliatelle // matches go list output
-}
+	if opts.precommit {
+		stale := staleUpdates(rep, time.Duration(opts.precommitDays)*24*time.Hour)
+		printPrecommit(os.Stdout, os.Stderr, stale, rep.Failures, gomodName, colors)
+		return exitCode(stale, opts.exitZero, opts.failOn), nil
+	}
 
-// Note there are at least two cases to consider: If the repo has tagged
-// versions and you're depending on a commit, then `go get -u ./...` won't
-// update it even if you're on a main commit that is behind main. However if
-// the repo does not have tagged versions, it will. This is mostly a
-// consideration for `go get -u` but I wanted to note it somewhere.
-func queryModuleVersion(
-	ctx context.Context,
-	modulePath,
-	branch string,
-) (string, error) {
-	//nolint:gosec // modulePath and branch are from go.mod, intentional
-	cmd := exec.CommandContext(ctx, "go", "list", "-m", "-json", modulePath+"@"+branch)
-	output, err := cmd.Output()
-	if err != nil {
-		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) {
-			return "", errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+	if opts.summary {
+		addSummary(&rep)
+	}
+	env := check.NewEnvelope(rep, toolVersion(), gomodName)
+	env.Run = summary
+	if err := writeReport(os.Stdout, opts.format, opts.groupBy, env, colors); err != nil {
+		return exitError, err
+	}
+
+	if opts.notify != "" {
+		if err := n.notify(ctx, env); err != nil {
+			return exitError, err
 		}
-		return "", fmt.Errorf("running go list: %w", err)
 	}
 
-	var info moduleInfo
-	if err := json.Unmarshal(output, &info); err != nil {
-		return "", fmt.Errorf("parsing module info: %w", err)
+	if opts.badge != "" {
+		if err := writeBadge(opts.badge, rep); err != nil {
+			return exitError, err
+		}
 	}
 
-	return info.Version, nil
-}
+	if opts.update {
+		edits, held, err := runUpdate(ctx, opts, rep, gomodPath)
+		if err != nil {
+			return exitError, err
+		}
+		stale, err := check.FindVendorMismatches(gomodPath, editedDependencies(edits))
+		if err != nil {
+			return exitError, fmt.Errorf("reading vendored modules: %w", err)
+		}
+		printGoModEdits(os.Stderr, gomodName, edits, held, stale)
+	}
 
-// newerVersion compares two pseudo-versions and returns the one with the more
-// recent timestamp.
-func newerVersion(a, b string) (string, error) {
-	tsA, err := module.PseudoVersionTime(a)
-	if err != nil {
-		return "", fmt.Errorf("parsing version %q: %w", a, err)
+	code = exitCode(rep, opts.exitZero, opts.failOn)
+	if opts.gha {
+		// Annotations go to stderr, where the runner also reads workflow
+		// commands, so that stdout stays parseable in any format.
+		printGHAAnnotations(os.Stderr, env)
+		if err := writeGHAOutputs(env, code); err != nil {
+			return exitError, err
+		}
 	}
-	tsB, err := module.PseudoVersionTime(b)
-	if err != nil {
-		return "", fmt.Errorf("parsing version %q: %w", b, err)
+	return code, nil
+}
+
+// writeReport writes the report to w in the given -format. Text and Markdown
+// group updates by the -group-by key, if any.
+func writeReport(
+	w io.Writer,
+	format string,
+	groupBy string,
+	env check.Envelope,
+	colors colorizer,
+) error {
+	switch format {
+	case formatJSON:
+		return printJSON(w, env)
+	case formatMarkdown:
+		printMarkdown(w, env.Report, groupBy)
+	case formatCycloneDX:
+		return printCycloneDX(w, env)
+	case formatSPDX:
+		return printSPDX(w, env)
+	case formatRenovate:
+		return printRenovate(w, env.Report)
+	case formatDependabot:
+		printDependabot(w, env)
+	case formatRDJSON:
+		return printRDJSON(w, env)
+	case formatGHAMatrix:
+		return printGHAMatrix(w, env.Report)
+	case formatDOT:
+		printDOT(w, env)
+	default:
+		printText(w, env.Report, groupBy, colors)
 	}
-	if !tsA.Before(tsB) {
-		return a, nil
+	return nil
+}
+
+// exitCode returns the process exit code for the report. If exitZero is set,
+// available updates do not cause a non-zero exit code, but failures still do.
+// Otherwise, only updates that fail under the failOn policy do.
+func exitCode(rep check.Report, exitZero bool, failOn failPolicy) int {
+	failingUpdate := slices.ContainsFunc(rep.Updates, failOn.fails) ||
+		(len(rep.TaggedUpdates) > 0 || rep.Toolchain != nil && rep.Toolchain.Outdated) &&
+			failOn.failsAny()
+	switch {
+	case failingUpdate && !exitZero:
+		return exitUpdates
+	case len(rep.Failures) > 0:
+		return exitError
+	default:
+		return exitOK
 	}
-	return b, nil
 }
@@ -1,339 +1,415 @@
 package main
 
 import (
-	"os"
-	"path/filepath"
-	"strings"
+	"errors"
+	"slices"
 	"testing"
 
-	"golang.org/x/mod/module"
+	"github.com/horgh/check-untagged-go-deps/check"
 )
 
-func TestCheckGoMod(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test in short mode")
-	}
-
-	// Create a go.mod with intentionally old pseudo-versions.
-	// These are real old commits that should have newer versions available.
-	content := `module test
-
-go 1.25
-
-require (
-	github.com/maxmind/mmdbwriter v1.1.1-0.20240104181157-4f07c5502982
-	go4.org/netipx v0.0.0-20220925034521-797b0c90d8ab
-)
-`
-	dir := t.TempDir()
-	gomodPath := filepath.Join(dir, "go.mod")
-	if err := os.WriteFile(gomodPath, []byte(content), 0o644); err != nil {
-		t.Fatalf("writing go.mod: %v", err)
-	}
-
-	ctx := t.Context()
-	deps, updates, err := checkGoMod(ctx, gomodPath, false)
-	if err != nil {
-		t.Fatalf("checkGoMod: %v", err)
-	}
-
-	// Should find 2 pseudo-versioned deps
-	if len(deps) != 2 {
-		t.Errorf("got %d deps, want 2", len(deps))
-	}
-
-	// Should find updates for both (since we used old versions)
-	if len(updates) != 2 {
-		t.Errorf("got %d updates, want 2", len(updates))
-	}
-
-	// Verify updates are for the expected modules
-	foundModules := map[string]bool{}
-	for _, u := range updates {
-		foundModules[u.module] = true
-
-		// The latest version should be newer (different) than current
-		if u.current == u.latest {
-			t.Errorf("expected update for %s, but current == latest (%s)", u.module, u.current)
-		}
-
-		// Latest should also be a pseudo-version
-		if !module.IsPseudoVersion(u.latest) {
-			t.Errorf("expected latest to be pseudo-version, got %q", u.latest)
-		}
-	}
-
-	if !foundModules["github.com/maxmind/mmdbwriter"] {
-		t.Error("expected update for github.com/maxmind/mmdbwriter")
-	}
-	if !foundModules["go4.org/netipx"] {
-		t.Error("expected update for go4.org/netipx")
-	}
-}
-
-func TestFindPseudoVersionedDeps(t *testing.T) {
-	gomodContent := `module test
-
-go 1.25
-
-require (
-	github.com/maxmind/mmdbwriter v1.1.1-0.20251215205057-2f3252140e00
-	github.com/oschwald/maxminddb-golang/v2 v2.1.1
-	go4.org/netipx v0.0.0-20231129151722-fdeea329fbba
-)
-
-require (
-	github.com/example/indirect v0.0.0-20231129151722-abcdef123456 // indirect
-)
-`
-
+func TestExitCode(t *testing.T) {
 	tests := []struct {
-		name            string
-		includeIndirect bool
-		want            map[string]string
+		name     string
+		rep      check.Report
+		exitZero bool
+		failOn   failPolicy
+		want     int
 	}{
+		{name: "clean", want: exitOK},
+		{name: "updates", rep: check.Report{Updates: []check.Update{{}}}, want: exitUpdates},
+		{name: "failures", rep: check.Report{Failures: []check.Failure{{}}}, want: exitError},
+		{
+			name: "updates take precedence over failures",
+			rep:  check.Report{Updates: []check.Update{{}}, Failures: []check.Failure{{}}},
+			want: exitUpdates,
+		},
+		{
+			name: "tagged updates",
+			rep:  check.Report{TaggedUpdates: []check.TaggedUpdate{{}}},
+			want: exitUpdates,
+		},
+		{
+			name:   "fail on breaking with tagged updates",
+			rep:    check.Report{TaggedUpdates: []check.TaggedUpdate{{}}},
+			failOn: failPolicy{risk: check.RiskBreaking},
+			want:   exitOK,
+		},
+		{
+			name: "outdated toolchain",
+			rep:  check.Report{Toolchain: &check.ToolchainStatus{Outdated: true}},
+			want: exitUpdates,
+		},
+		{
+			name: "current toolchain",
+			rep:  check.Report{Toolchain: &check.ToolchainStatus{}},
+			want: exitOK,
+		},
+		{
+			name:     "exit zero with updates",
+			rep:      check.Report{Updates: []check.Update{{}}},
+			exitZero: true,
+			want:     exitOK,
+		},
+		{
+			name:     "exit zero still reports failures",
+			rep:      check.Report{Updates: []check.Update{{}}, Failures: []check.Failure{{}}},
+			exitZero: true,
+			want:     exitError,
+		},
+		{
+			name:   "fail on breaking with feature update",
+			rep:    check.Report{Updates: []check.Update{{Risk: check.RiskFeature}}},
+			failOn: failPolicy{risk: check.RiskBreaking},
+			want:   exitOK,
+		},
+		{
+			name: "fail on feature with feature update",
+			rep: check.Report{Updates: []check.Update{
+				{Risk: check.RiskPatch},
+				{Risk: check.RiskFeature},
+			}},
+			failOn: failPolicy{risk: check.RiskFeature},
+			want:   exitUpdates,
+		},
+		{
+			name:   "fail on breaking with unknown risk",
+			rep:    check.Report{Updates: []check.Update{{}}},
+			failOn: failPolicy{risk: check.RiskBreaking},
+			want:   exitUpdates,
+		},
+		{
+			name: "fail on critical with high update",
+			rep: check.Report{Updates: []check.Update{
+				{Severity: check.SeverityLow},
+				{Severity: check.SeverityHigh},
+			}},
+			failOn: failPolicy{severities: []check.Severity{check.SeverityCritical}},
+			want:   exitOK,
+		},
 		{
-			name:            "exclude indirect",
-			includeIndirect: false,
-			want: map[string]string{
-				"github.com/maxmind/mmdbwriter": "v1.1.1-0.20251215205057-2f3252140e00",
-				"go4.org/netipx":                "v0.0.0-20231129151722-fdeea329fbba",
+			name: "fail on critical or high with high update",
+			rep:  check.Report{Updates: []check.Update{{Severity: check.SeverityHigh}}},
+			failOn: failPolicy{
+				severities: []check.Severity{check.SeverityCritical, check.SeverityHigh},
 			},
+			want: exitUpdates,
 		},
 		{
-			name:            "include indirect",
-			includeIndirect: true,
-			want: map[string]string{
-				"github.com/maxmind/mmdbwriter": "v1.1.1-0.20251215205057-2f3252140e00",
-				"go4.org/netipx":                "v0.0.0-20231129151722-fdeea329fbba",
-				"github.com/example/indirect":   "v0.0.0-20231129151722-abcdef123456",
+			name: "fail on breaking or critical with critical patch",
+			rep: check.Report{Updates: []check.Update{
+				{Risk: check.RiskPatch, Severity: check.SeverityCritical},
+			}},
+			failOn: failPolicy{
+				risk:       check.RiskBreaking,
+				severities: []check.Severity{check.SeverityCritical},
 			},
+			want: exitUpdates,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			dir := t.TempDir()
-			gomodPath := filepath.Join(dir, "go.mod")
-			if err := os.WriteFile(gomodPath, []byte(gomodContent), 0o644); err != nil {
-				t.Fatalf("writing go.mod: %v", err)
-			}
-
-			deps, err := findPseudoVersionedDeps(gomodPath, tt.includeIndirect)
-			if err != nil {
-				t.Fatalf("findPseudoVersionedDeps: %v", err)
-			}
-
-			if len(deps) != len(tt.want) {
-				t.Errorf("got %d deps, want %d", len(deps), len(tt.want))
-			}
-
-			for _, dep := range deps {
-				expectedVersion, ok := tt.want[dep.module]
-				if !ok {
-					t.Errorf("unexpected module: %s", dep.module)
-					continue
-				}
-				if dep.version != expectedVersion {
-					t.Errorf(
-						"module %s: got version %s, want %s",
-						dep.module,
-						dep.version,
-						expectedVersion,
-					)
-				}
+			if got := exitCode(tt.rep, tt.exitZero, tt.failOn); got != tt.want {
+				t.Errorf("got exit code %d, want %d", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestIsPseudoVersion(t *testing.T) {
+func TestParseFlags(t *testing.T) {
 	tests := []struct {
-		name    string
-		input   string
-		matches bool
+		name      string
+		args      []string
+		wantPath  string
+		wantOnly  []string
+		wantUsage bool
+		wantErr   bool
 	}{
+		{name: "defaults", wantPath: "go.mod"},
+		{name: "go.mod path", args: []string{"sub/go.mod"}, wantPath: "sub/go.mod"},
 		{
-			name:    "pseudo-version without base tag",
-			input:   "v0.0.0-20231129151722-fdeea329fbba",
-			matches: true,
+			name:     "modules as arguments",
+			args:     []string{"go.mod", "go4.org/netipx", "example.com/a"},
+			wantPath: "go.mod",
+			wantOnly: []string{"go4.org/netipx", "example.com/a"},
 		},
 		{
-			name:    "pseudo-version with base tag",
-			input:   "v1.1.1-0.20251215205057-2f3252140e00",
-			matches: true,
+			name:     "only flag",
+			args:     []string{"-only", "go4.org/netipx, example.com/a"},
+			wantPath: "go.mod",
+			wantOnly: []string{"go4.org/netipx", "example.com/a"},
 		},
+		{name: "unknown flag", args: []string{"-nope"}, wantErr: true},
+		{name: "invalid color", args: []string{"-color", "sometimes"}, wantUsage: true},
+		{name: "invalid log format", args: []string{"-log-format", "logfmt"}, wantUsage: true},
+		{name: "json logs", args: []string{"-log-format", "json"}, wantPath: "go.mod"},
+		{name: "invalid resolver", args: []string{"-resolver", "git"}, wantUsage: true},
+		{name: "invalid fail-on", args: []string{"-fail-on", "minor"}, wantUsage: true},
+		{name: "invalid sort", args: []string{"-sort", "stars"}, wantUsage: true},
+		{name: "invalid group-by", args: []string{"-group-by", "owner"}, wantUsage: true},
 		{
-			name:    "tagged version",
-			input:   "v1.2.3",
-			matches: false,
+			name: "module branches",
+			args: []string{
+				"-module-branches", "k8s.io/client-go=release-1.29, example.com/a=dev",
+			},
+			wantPath: "go.mod",
 		},
 		{
-			name:    "tagged version with prerelease",
-			input:   "v1.2.3-beta.1",
-			matches: false,
+			name:      "module branch without branch",
+			args:      []string{"-module-branches", "k8s.io/client-go"},
+			wantUsage: true,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := module.IsPseudoVersion(tt.input)
-			if got != tt.matches {
-				t.Errorf("module.IsPseudoVersion(%q) = %v, want %v", tt.input, got, tt.matches)
-			}
-		})
-	}
-}
-
-func TestGetLatestVersion(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test in short mode")
-	}
-
-	tests := []struct {
-		name   string
-		module string
-	}{
 		{
-			name:   "netipx has no tagged versions",
-			module: "go4.org/netipx",
+			name:      "invalid module branch pattern",
+			args:      []string{"-module-branches", "k8s.io/[=master"},
+			wantUsage: true,
 		},
 		{
-			name:   "mmdbwriter has tagged versions",
-			module: "github.com/maxmind/mmdbwriter",
+			name:      "module branch listed twice",
+			args:      []string{"-module-branches", "example.com/a=dev,example.com/a=main"},
+			wantUsage: true,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctx := t.Context()
-
-			version, err := getLatestVersion(ctx, tt.module)
-			if err != nil {
-				t.Fatalf("getLatestVersion: %v", err)
-			}
-
-			if !module.IsPseudoVersion(version) {
-				t.Errorf("expected pseudo-version, got %q", version)
-			}
-		})
-	}
-}
-
-func TestQueryModuleVersion(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test in short mode")
-	}
-
-	tests := []struct {
-		name        string
-		module      string
-		branch      string
-		wantPseudo  bool
-		wantErr     bool
-		errContains string
-	}{
 		{
-			name:       "netipx has no tagged versions",
-			module:     "go4.org/netipx",
-			branch:     branchMain,
-			wantPseudo: true,
+			name: "module tracking",
+			args: []string{
+				"-module-tracking", "github.com/org/mono/*=dir,example.com/a=branch",
+			},
+			wantPath: "go.mod",
 		},
 		{
-			name:       "mmdbwriter has tagged versions but main returns pseudo",
-			module:     "github.com/maxmind/mmdbwriter",
-			branch:     branchMain,
-			wantPseudo: true,
+			name:      "invalid module tracking",
+			args:      []string{"-module-tracking", "example.com/a=latest"},
+			wantUsage: true,
 		},
 		{
-			name:        "nonexistent branch returns error",
-			module:      "go4.org/netipx",
-			branch:      "nonexistent-branch",
-			wantErr:     true,
-			errContains: "unknown revision",
+			name:      "git-ref with why",
+			args:      []string{"-git-ref", "origin/main", "-why"},
+			wantUsage: true,
+		},
+		{
+			name:      "git-ref with tool-pins",
+			args:      []string{"-git-ref", "origin/main", "-tool-pins"},
+			wantUsage: true,
+		},
+		{
+			name:      "git-ref with schedule",
+			args:      []string{"-git-ref", "origin/main", "-schedule", "@daily"},
+			wantUsage: true,
+		},
+		{
+			name:     "update preferring tags",
+			args:     []string{"-update", "-prefer-tags"},
+			wantPath: "go.mod",
+		},
+		{
+			name:      "prefer-tags without update",
+			args:      []string{"-prefer-tags"},
+			wantUsage: true,
+		},
+		{
+			name:      "verify without update",
+			args:      []string{"-verify", "go test ./..."},
+			wantUsage: true,
+		},
+		{
+			name:      "cooldown-state without verify",
+			args:      []string{"-update", "-cooldown-state", "cooldown.json"},
+			wantUsage: true,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctx := t.Context()
-
-			version, err := queryModuleVersion(ctx, tt.module, tt.branch)
-			if tt.wantErr {
-				if err == nil {
-					t.Fatal("expected error, got nil")
-				}
-				if tt.errContains != "" && !strings.Contains(err.Error(), tt.errContains) {
-					t.Errorf("expected error to contain %q, got %q", tt.errContains, err.Error())
-				}
-				return
-			}
-			if err != nil {
-				t.Fatalf("queryModuleVersion: %v", err)
-			}
-
-			if tt.wantPseudo && !module.IsPseudoVersion(version) {
-				t.Errorf("expected pseudo-version, got %q", version)
-			}
-		})
-	}
-}
-
-func TestNewerVersion(t *testing.T) {
-	tests := []struct {
-		name    string
-		a       string
-		b       string
-		want    string
-		wantErr bool
-	}{
 		{
-			name: "a is newer",
-			a:    "v0.0.0-20231201000000-aaaaaaaaaaaa",
-			b:    "v0.0.0-20231101000000-bbbbbbbbbbbb",
-			want: "v0.0.0-20231201000000-aaaaaaaaaaaa",
+			name:      "cooldown without cooldown-state",
+			args:      []string{"-update", "-verify", "go test ./...", "-cooldown", "24h"},
+			wantUsage: true,
+		},
+		{
+			name: "verify with cooldown",
+			args: []string{
+				"-update", "-verify", "go test ./...",
+				"-cooldown-state", "cooldown.json", "-cooldown", "24h",
+			},
+			wantPath: "go.mod",
 		},
 		{
-			name: "b is newer",
-			a:    "v0.0.0-20231101000000-aaaaaaaaaaaa",
-			b:    "v0.0.0-20231201000000-bbbbbbbbbbbb",
-			want: "v0.0.0-20231201000000-bbbbbbbbbbbb",
+			name:      "max-updates without update",
+			args:      []string{"-max-updates", "2"},
+			wantUsage: true,
 		},
 		{
-			name: "same timestamp returns a",
-			a:    "v0.0.0-20231201000000-aaaaaaaaaaaa",
-			b:    "v0.0.0-20231201000000-bbbbbbbbbbbb",
-			want: "v0.0.0-20231201000000-aaaaaaaaaaaa",
+			name:      "negative max-updates",
+			args:      []string{"-update", "-max-updates", "-1"},
+			wantUsage: true,
 		},
 		{
-			name:    "invalid version a returns error",
-			a:       "v1.2.3",
-			b:       "v0.0.0-20231201000000-bbbbbbbbbbbb",
-			wantErr: true,
+			name:      "allow-breaking without update",
+			args:      []string{"-allow-breaking"},
+			wantUsage: true,
 		},
 		{
-			name:    "invalid version b returns error",
-			a:       "v0.0.0-20231201000000-aaaaaaaaaaaa",
-			b:       "v1.2.3",
-			wantErr: true,
+			name:      "update with git-ref",
+			args:      []string{"-update", "-git-ref", "origin/main"},
+			wantUsage: true,
+		},
+		{
+			name:      "negative min-commits-behind",
+			args:      []string{"-min-commits-behind", "-1"},
+			wantUsage: true,
+		},
+		{
+			name:      "negative warning-days",
+			args:      []string{"-warning-days", "-1"},
+			wantUsage: true,
+		},
+		{
+			name:     "fail-on severities",
+			args:     []string{"-fail-on", "critical, high"},
+			wantPath: "go.mod",
+		},
+		{
+			name:      "fail-on two risks",
+			args:      []string{"-fail-on", "feature,breaking"},
+			wantUsage: true,
+		},
+		{name: "invalid vuln-source", args: []string{"-vuln-source", "nvd"}, wantUsage: true},
+		{
+			name:      "negative abandoned-months",
+			args:      []string{"-abandoned-months", "-1"},
+			wantUsage: true,
+		},
+		{name: "invalid notify", args: []string{"-notify", "pager"}, wantUsage: true},
+		{
+			name:      "notify template without notify",
+			args:      []string{"-notify-template", "notify.tmpl"},
+			wantUsage: true,
+		},
+		{
+			name:      "notify state without notify",
+			args:      []string{"-notify-state", "state.json"},
+			wantUsage: true,
+		},
+		{
+			name:      "notify email without recipients",
+			args:      []string{"-notify", "email", "-smtp-addr", "localhost:25"},
+			wantUsage: true,
+		},
+		{name: "notify without webhook", args: []string{"-notify", "slack"}, wantUsage: true},
+		{
+			name: "notify discord without webhook",
+			args: []string{
+				"-notify", "discord",
+				"-slack-webhook", "https://hooks.example.com/x",
+			},
+			wantUsage: true,
+		},
+		{
+			name:     "notify with webhook",
+			args:     []string{"-notify", "slack", "-slack-webhook", "https://hooks.example.com/x"},
+			wantPath: "go.mod",
+		},
+		{
+			name:     "listen with go.mod files",
+			args:     []string{"-listen", ":9090", "a/go.mod", "go4.org/netipx"},
+			wantPath: "a/go.mod",
+		},
+		{
+			name:     "explain",
+			args:     []string{"-explain"},
+			wantPath: "go.mod",
+		},
+		{
+			name:      "explain with listen",
+			args:      []string{"-explain", "-listen", ":9090"},
+			wantUsage: true,
+		},
+		{
+			// The action passes -gha=false unless its gha input is true.
+			name:     "gha disabled",
+			args:     []string{"-gha=false"},
+			wantPath: "go.mod",
+		},
+		{
+			name:      "gha with schedule",
+			args:      []string{"-gha", "-schedule", "@daily"},
+			wantUsage: true,
+		},
+		{
+			name:      "precommit with listen",
+			args:      []string{"-precommit", "-listen", ":9090"},
+			wantUsage: true,
+		},
+		{
+			name:      "negative precommit-days",
+			args:      []string{"-precommit", "-precommit-days", "-1"},
+			wantUsage: true,
+		},
+		{
+			name:      "badge with schedule",
+			args:      []string{"-badge", "badge.json", "-schedule", "@daily"},
+			wantUsage: true,
+		},
+		{name: "invalid schedule", args: []string{"-schedule", "every day"}, wantUsage: true},
+		{name: "invalid schedule days", args: []string{"-schedule-days", "mo"}, wantUsage: true},
+		{
+			name:      "invalid schedule timezone",
+			args:      []string{"-schedule-days", "monday", "-schedule-timezone", "Nowhere/Land"},
+			wantUsage: true,
+		},
+		{
+			name:      "schedule timezone alone",
+			args:      []string{"-schedule-timezone", "UTC"},
+			wantUsage: true,
+		},
+		{
+			name:      "respect-schedule without days",
+			args:      []string{"-respect-schedule"},
+			wantUsage: true,
+		},
+		{
+			name: "respect-schedule with schedule",
+			args: []string{
+				"-respect-schedule", "-schedule-days", "mon", "-schedule", "@daily",
+			},
+			wantUsage: true,
+		},
+		{
+			name:     "respect-schedule",
+			args:     []string{"-respect-schedule", "-schedule-days", "mon,thu", "go.mod"},
+			wantPath: "go.mod",
+		},
+		{
+			name:     "schedule with go.mod files",
+			args:     []string{"-schedule", "@daily", "a/go.mod", "b/go.mod"},
+			wantPath: "a/go.mod",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := newerVersion(tt.a, tt.b)
+			t.Setenv("SLACK_WEBHOOK_URL", "")
+			t.Setenv("DISCORD_WEBHOOK_URL", "")
+			opts, err := parseFlags(tt.args)
+
+			var usageErr *usageError
+			if tt.wantUsage {
+				if !errors.As(err, &usageErr) {
+					t.Fatalf("got error %v, want usage error", err)
+				}
+				return
+			}
 			if tt.wantErr {
 				if err == nil {
-					t.Errorf("newerVersion(%q, %q) expected error, got nil", tt.a, tt.b)
+					t.Fatal("expected error, got nil")
 				}
 				return
 			}
 			if err != nil {
-				t.Errorf("newerVersion(%q, %q) unexpected error: %v", tt.a, tt.b, err)
-				return
+				t.Fatalf("parseFlags: %v", err)
+			}
+			if opts.gomodPath != tt.wantPath {
+				t.Errorf("got go.mod path %q, want %q", opts.gomodPath, tt.wantPath)
 			}
-			if got != tt.want {
-				t.Errorf("newerVersion(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+			if !slices.Equal(opts.only, tt.wantOnly) {
+				t.Errorf("got only %q, want %q", opts.only, tt.wantOnly)
 			}
 		})
 	}
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// markdownEscaper escapes characters that Markdown would otherwise interpret
+// in text that comes from upstream repositories, such as commit subjects.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+	"#", `\#`,
+	"|", `\|`,
+)
+
+// printMarkdown writes the report to w as Markdown suitable for the body of a
+// pull request or issue, with a section per update, grouped by the -group-by
+// key, if any.
+func printMarkdown(w io.Writer, rep check.Report, groupBy string) {
+	if len(rep.Dependencies) == 0 {
+		fmt.Fprintln(w, "No pseudo-versioned dependencies found in go.mod.")
+		printMarkdownTaggedUpdates(w, rep.TaggedUpdates)
+		printMarkdownToolchain(w, rep.Toolchain)
+		return
+	}
+
+	if len(rep.Updates) > 0 {
+		fmt.Fprintf(
+			w,
+			"%s available for pseudo-versioned dependencies:\n",
+			plural(len(rep.Updates), "update"),
+		)
+		for i, g := range groupUpdates(rep.Updates, groupBy) {
+			// Each update ends with a blank line, which separates the groups.
+			if g.name != "" {
+				if i == 0 {
+					fmt.Fprintln(w)
+				}
+				fmt.Fprintf(w, "## %s\n", g.name)
+			}
+			for _, u := range g.updates {
+				fmt.Fprintln(w)
+				printMarkdownUpdate(w, u)
+			}
+		}
+	} else if len(rep.Failures) == 0 {
+		fmt.Fprintln(w, "No updates found for pseudo-versioned dependencies.")
+	}
+
+	if len(rep.Abandoned) > 0 {
+		if len(rep.Updates) > 0 || len(rep.Failures) == 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, "### Abandoned upstream")
+		fmt.Fprintln(w)
+		fmt.Fprintln(
+			w,
+			"These repositories are archived or inactive. "+
+				"Consider forking or replacing these dependencies rather than updating them.",
+		)
+		fmt.Fprintln(w)
+		for _, a := range rep.Abandoned {
+			fmt.Fprintf(w, "- `%s`: %s\n", a.Module, describeAbandoned(a, colorizer{}))
+		}
+	}
+
+	if len(rep.Failures) > 0 {
+		if len(rep.Updates) > 0 || len(rep.Abandoned) > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, "### Failed to check")
+		fmt.Fprintln(w)
+		for _, f := range rep.Failures {
+			fmt.Fprintf(w, "- `%s`: %s\n", f.Module, markdownEscaper.Replace(f.Err.Error()))
+		}
+	}
+
+	if len(rep.VendorMismatches) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "### Vendored copies out of date")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Run `go mod vendor` to update `vendor/modules.txt` to match go.mod.")
+		fmt.Fprintln(w)
+		for _, m := range rep.VendorMismatches {
+			fmt.Fprintf(w, "- `%s`: %s\n", m.Module, describeVendorMismatch(m, colorizer{}))
+		}
+	}
+
+	printMarkdownTagged(w, rep.Dependencies)
+	printMarkdownForks(w, rep.Dependencies)
+	printMarkdownTaggedUpdates(w, rep.TaggedUpdates)
+	printMarkdownToolchain(w, rep.Toolchain)
+
+	if s := rep.Summary; s != nil {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "### Summary")
+		fmt.Fprintln(w)
+		fmt.Fprintln(
+			w,
+			"| Pseudo-versioned | Stale | Failed | Median staleness | Most out of date |",
+		)
+		fmt.Fprintln(w, "| --- | --- | --- | --- | --- |")
+		median, stalest := "-", "-"
+		if s.Stale > 0 {
+			median = formatSeconds(s.MedianAgeSeconds)
+			stalest = fmt.Sprintf("`%s` (%s)", s.Stalest, formatSeconds(s.MaxAgeSeconds))
+		}
+		fmt.Fprintf(
+			w,
+			"| %d | %d | %d | %s | %s |\n",
+			s.Pinned,
+			s.Stale,
+			s.Failed,
+			median,
+			stalest,
+		)
+	}
+}
+
+// printMarkdownUpdate writes one update's section.
+func printMarkdownUpdate(w io.Writer, u check.Update) {
+	fmt.Fprintf(w, "### `%s`\n\n", u.Module)
+	fmt.Fprintf(w, "`%s` → `%s`\n\n", u.Current, u.Latest)
+
+	if u.Source != "" {
+		fmt.Fprintf(w, "- Pinned in `%s`\n", u.Source)
+	}
+	if u.Branch != "" {
+		fmt.Fprintf(w, "- On branch `%s`\n", u.Branch)
+	}
+	switch u.Severity {
+	case check.SeverityMedium:
+		fmt.Fprintf(w, "- Severity: %s\n", u.Severity)
+	case check.SeverityHigh, check.SeverityCritical:
+		fmt.Fprintf(w, "- :warning: Severity: **%s**\n", u.Severity)
+	}
+	if u.PinVanished {
+		fmt.Fprintf(
+			w,
+			"- :warning: The pinned commit **no longer exists upstream** (history rewritten?): "+
+				"re-pin with `go get %s@%s`\n",
+			u.Module,
+			u.Latest,
+		)
+	}
+	switch {
+	case u.DeclaredPath != "":
+		fmt.Fprintf(
+			w,
+			"- :warning: Module path changed to `%s` (declared in the latest `go.mod`): "+
+				"consider updating the import path rather than the version\n",
+			u.DeclaredPath,
+		)
+	case u.MovedTo != "":
+		fmt.Fprintf(
+			w,
+			"- :warning: Repository moved, the module path may now be `%s`: "+
+				"consider updating the import path\n",
+			u.MovedTo,
+		)
+	}
+
+	for _, v := range u.Vulnerabilities {
+		status := "fixed by this update"
+		if !v.FixedInLatest {
+			status = "**not** fixed by this update"
+		}
+		id := v.ID
+		if strings.HasPrefix(id, "GO-") {
+			id = fmt.Sprintf("[%s](https://pkg.go.dev/vuln/%s)", id, id)
+		}
+		if v.Severity != "" {
+			status = "severity `" + v.Severity + "`, " + status
+		}
+		fmt.Fprintf(w, "- :warning: Affected by %s (%s)", id, status)
+		if v.Summary != "" {
+			fmt.Fprintf(w, ": %s", markdownEscaper.Replace(v.Summary))
+		}
+		fmt.Fprintln(w)
+	}
+	switch u.SumDB {
+	case check.SumDBVerified:
+		fmt.Fprintln(w, "- Checksum database: verified")
+	case check.SumDBExempt:
+		fmt.Fprintln(w, "- Checksum database: exempt (GONOSUMDB, GOPRIVATE, or GOSUMDB=off)")
+	case check.SumDBUnverified:
+		fmt.Fprintln(w, "- :warning: Checksum database: **not verified**")
+	}
+	if sig := u.Signature; sig != nil {
+		if sig.Verified {
+			fmt.Fprintln(w, "- Signature: verified")
+		} else {
+			fmt.Fprint(w, "- :warning: Signature: **not verified**")
+			if sig.Reason != "" {
+				fmt.Fprintf(w, " (`%s`)", sig.Reason)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	if u.Age() > 0 {
+		fmt.Fprintf(
+			w,
+			"- Current commit is %s older than latest\n",
+			formatAge(u.CurrentTime, u.LatestTime),
+		)
+	}
+	if u.Risk != check.RiskUnknown {
+		fmt.Fprintf(w, "- Risk: %s\n", u.Risk)
+	}
+	if u.CommitsBehind > 0 {
+		fmt.Fprintf(w, "- Behind by %s\n", plural(u.CommitsBehind, "commit"))
+	}
+	if len(u.Authors) > 0 {
+		fmt.Fprintf(w, "- Authors: %s", markdownEscaper.Replace(strings.Join(u.Authors, ", ")))
+		if !slices.ContainsFunc(u.Authors, func(a string) bool { return !check.IsBot(a) }) {
+			fmt.Fprint(w, " (bot commits only)")
+		}
+		fmt.Fprintln(w)
+	}
+	if u.TestOnly {
+		fmt.Fprintln(w, "- Only needed by tests")
+	}
+	if len(u.ImportChain) > 0 {
+		fmt.Fprintf(w, "- Imported via: `%s`\n", strings.Join(u.ImportChain, "` → `"))
+	}
+	for _, r := range u.RequiredBy {
+		fmt.Fprintf(w, "- Required by `%s@%s` (%s)\n", r.Module, r.Version, describeRequirer(u, r))
+	}
+	if c := u.Changes; c != nil {
+		fmt.Fprintf(
+			w,
+			"- Changed %s (+%d -%d)\n",
+			plural(c.Files, "file"),
+			c.Additions,
+			c.Deletions,
+		)
+	}
+	printMarkdownCompatibility(w, u.Compatibility)
+	if lc := u.LicenseChange; lc != nil {
+		if lc.Current != lc.Latest {
+			fmt.Fprintf(w, "- :warning: License changed from `%s` to `%s`\n", lc.Current, lc.Latest)
+		} else {
+			fmt.Fprintf(w, "- :warning: License text changed (`%s`)\n", lc.Latest)
+		}
+	}
+	if u.CompareURL != "" {
+		fmt.Fprintf(w, "- [Compare changes](%s)\n", u.CompareURL)
+	}
+
+	if len(u.Commits) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "<details>")
+		fmt.Fprintf(
+			w,
+			"<summary>%s</summary>\n\n",
+			plural(max(u.CommitsBehind, len(u.Commits)), "new commit"),
+		)
+		for _, commit := range u.Commits {
+			sha := commit.SHA
+			if len(sha) > shortSHALength {
+				sha = sha[:shortSHALength]
+			}
+			fmt.Fprintf(w, "- %s %s\n", sha, markdownEscaper.Replace(commit.Subject))
+		}
+		if u.CommitsBehind > len(u.Commits) {
+			fmt.Fprintf(w, "- ... and %d more\n", u.CommitsBehind-len(u.Commits))
+		}
+		fmt.Fprintln(w, "\n</details>")
+	}
+
+	if u.ReleaseNotes != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "<details>")
+		fmt.Fprintln(w, "<summary>Release notes</summary>")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, u.ReleaseNotes)
+		fmt.Fprintln(w, "\n</details>")
+	}
+}
+
+// printMarkdownTaggedUpdates writes a section listing the newer releases of
+// tagged requirements, if any (see -all).
+func printMarkdownTaggedUpdates(w io.Writer, updates []check.TaggedUpdate) {
+	if len(updates) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "### Newer releases of tagged dependencies")
+	fmt.Fprintln(w)
+	for _, u := range updates {
+		fmt.Fprintf(w, "- `%s`: `%s` → `%s`\n", u.Module, u.Current, u.Latest)
+	}
+}
+
+// printMarkdownToolchain writes a section reporting an outdated Go toolchain,
+// if it is (see -toolchain).
+func printMarkdownToolchain(w io.Writer, s *check.ToolchainStatus) {
+	if s == nil || !s.Outdated {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "### Go toolchain out of date")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "go.mod asks for `%s`; the latest Go release is `%s`.", s.Current(), s.Latest)
+	if s.Unsupported {
+		fmt.Fprint(w, " It no longer receives security fixes.")
+	}
+	fmt.Fprintln(w)
+}
+
+// printMarkdownForks writes a section listing how far the forks replacing
+// modules have diverged from their upstreams, if any were compared (see
+// -fork-divergence).
+func printMarkdownForks(w io.Writer, deps []check.Dependency) {
+	var forks []check.Dependency
+	for _, dep := range deps {
+		if dep.Fork != nil {
+			forks = append(forks, dep)
+		}
+	}
+	if len(forks) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "### Forks")
+	fmt.Fprintln(w)
+	for _, dep := range forks {
+		fmt.Fprintf(
+			w,
+			"- `%s` (replacing `%s`): %d commits ahead of and %d behind upstream `%s`\n",
+			dep.Module,
+			dep.Replaces,
+			dep.Fork.Ahead,
+			dep.Fork.Behind,
+			dep.Fork.UpstreamLatest,
+		)
+	}
+}
+
+// printMarkdownTagged writes a section listing the dependencies whose
+// modules have tagged releases, if any (see -tags).
+func printMarkdownTagged(w io.Writer, deps []check.Dependency) {
+	var tagged []check.Dependency
+	for _, dep := range deps {
+		if dep.Tags == check.TagStatusTagged {
+			tagged = append(tagged, dep)
+		}
+	}
+	if len(tagged) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "### Tagged upstream")
+	fmt.Fprintln(w)
+	fmt.Fprintln(
+		w,
+		"These modules have tagged releases. Consider requiring a release rather than a "+
+			"commit, so tools such as Dependabot can keep them up to date.",
+	)
+	fmt.Fprintln(w)
+	for _, dep := range tagged {
+		if dep.BaseTagOutdated() {
+			fmt.Fprintf(
+				w,
+				"- `%s`: based on `%s`, `%s` now released\n",
+				dep.Module,
+				dep.BaseTag,
+				dep.LatestTag,
+			)
+		} else {
+			fmt.Fprintf(w, "- `%s`: latest `%s`\n", dep.Module, dep.LatestTag)
+		}
+	}
+}
+
+// printMarkdownCompatibility writes the API compatibility assessment, if
+// there is one: either that the update is compatible or its incompatible
+// changes.
+func printMarkdownCompatibility(w io.Writer, compat *check.Compatibility) {
+	if compat == nil {
+		return
+	}
+	if compat.Compatible {
+		fmt.Fprintln(w, "- API: compatible")
+		return
+	}
+
+	fmt.Fprintf(w, "- API: **%s**\n", plural(len(compat.Incompatible), "incompatible change"))
+	changes := compat.Incompatible
+	if len(changes) > maxIncompatibleChanges {
+		changes = changes[:maxIncompatibleChanges]
+	}
+	for _, change := range changes {
+		fmt.Fprintf(w, "  - `%s`\n", change)
+	}
+	if len(compat.Incompatible) > len(changes) {
+		fmt.Fprintf(w, "  - ... and %d more\n", len(compat.Incompatible)-len(changes))
+	}
+}
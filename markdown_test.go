@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestPrintMarkdown(t *testing.T) {
+	deps := []check.Dependency{
+		{Module: "go4.org/netipx", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "github.com/example/module", Version: "v0.0.0-20231101000000-bbbbbbbbbbbb"},
+	}
+
+	tests := []struct {
+		name      string
+		deps      []check.Dependency
+		updates   []check.Update
+		failures  []check.Failure
+		abandoned []check.Abandoned
+		vendor    []check.VendorMismatch
+		tagged    []check.TaggedUpdate
+		toolchain *check.ToolchainStatus
+		summary   bool
+		groupBy   string
+		want      string
+	}{
+		{
+			name: "no dependencies",
+			want: "No pseudo-versioned dependencies found in go.mod.\n",
+		},
+		{
+			name: "no updates",
+			deps: deps,
+			want: "No updates found for pseudo-versioned dependencies.\n",
+		},
+		{
+			name: "vendor mismatches",
+			deps: deps,
+			vendor: []check.VendorMismatch{
+				{
+					Module:   "go4.org/netipx",
+					Required: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Vendored: "v0.0.0-20231201000000-cccccccccccc",
+				},
+			},
+			want: "No updates found for pseudo-versioned dependencies.\n" +
+				"\n" +
+				"### Vendored copies out of date\n" +
+				"\n" +
+				"Run `go mod vendor` to update `vendor/modules.txt` to match go.mod.\n" +
+				"\n" +
+				"- `go4.org/netipx`: vendored v0.0.0-20231201000000-cccccccccccc differs from " +
+				"required v0.0.0-20231101000000-aaaaaaaaaaaa\n",
+		},
+		{
+			name: "tagged updates",
+			deps: deps,
+			tagged: []check.TaggedUpdate{
+				{Module: "github.com/example/tagged", Current: "v1.2.0", Latest: "v1.10.0"},
+			},
+			want: "No updates found for pseudo-versioned dependencies.\n" +
+				"\n" +
+				"### Newer releases of tagged dependencies\n" +
+				"\n" +
+				"- `github.com/example/tagged`: `v1.2.0` → `v1.10.0`\n",
+		},
+		{
+			name: "forks",
+			deps: []check.Dependency{
+				{
+					Module:   "github.com/ourorg/fork",
+					Version:  "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Replaces: "github.com/upstream/fork",
+					Fork: &check.ForkStatus{
+						UpstreamLatest: "v1.3.1-0.20231215000000-cccccccccccc",
+						Ahead:          3,
+						Behind:         12,
+					},
+				},
+			},
+			want: "No updates found for pseudo-versioned dependencies.\n" +
+				"\n" +
+				"### Forks\n" +
+				"\n" +
+				"- `github.com/ourorg/fork` (replacing `github.com/upstream/fork`): 3 commits " +
+				"ahead of and 12 behind upstream `v1.3.1-0.20231215000000-cccccccccccc`\n",
+		},
+		{
+			name:      "outdated toolchain",
+			deps:      deps,
+			toolchain: &check.ToolchainStatus{Go: "1.22", Latest: "go1.23.2", Outdated: true},
+			want: "No updates found for pseudo-versioned dependencies.\n" +
+				"\n" +
+				"### Go toolchain out of date\n" +
+				"\n" +
+				"go.mod asks for `go1.22`; the latest Go release is `go1.23.2`.\n",
+		},
+		{
+			name: "tagged dependencies",
+			deps: []check.Dependency{
+				{
+					Module:    "go4.org/netipx",
+					Version:   "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Tags:      check.TagStatusTagged,
+					LatestTag: "v0.1.0",
+				},
+				{
+					Module:  "github.com/example/module",
+					Version: "v0.0.0-20231101000000-bbbbbbbbbbbb",
+					Tags:    check.TagStatusNeverTagged,
+				},
+				{
+					Module:    "github.com/example/based",
+					Version:   "v1.1.1-0.20231101000000-bbbbbbbbbbbb",
+					Tags:      check.TagStatusTagged,
+					LatestTag: "v1.4.0",
+					BaseTag:   "v1.1.0",
+				},
+			},
+			want: "No updates found for pseudo-versioned dependencies.\n" +
+				"\n" +
+				"### Tagged upstream\n" +
+				"\n" +
+				"These modules have tagged releases. Consider requiring a release rather than a " +
+				"commit, so tools such as Dependabot can keep them up to date.\n" +
+				"\n" +
+				"- `go4.org/netipx`: latest `v0.1.0`\n" +
+				"- `github.com/example/based`: based on `v1.1.0`, `v1.4.0` now released\n",
+		},
+		{
+			name: "updates grouped by host",
+			deps: deps,
+			updates: []check.Update{
+				{
+					Module:  "go4.org/netipx",
+					Current: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:  "v0.0.0-20231201000000-cccccccccccc",
+				},
+				{
+					Module:  "github.com/example/module",
+					Current: "v0.0.0-20231101000000-bbbbbbbbbbbb",
+					Latest:  "v0.0.0-20231201000000-dddddddddddd",
+				},
+			},
+			groupBy: groupHost,
+			want: "2 updates available for pseudo-versioned dependencies:\n" +
+				"\n" +
+				"## go4.org\n" +
+				"\n" +
+				"### `go4.org/netipx`\n" +
+				"\n" +
+				"`v0.0.0-20231101000000-aaaaaaaaaaaa` → `v0.0.0-20231201000000-cccccccccccc`\n" +
+				"\n" +
+				"## github.com\n" +
+				"\n" +
+				"### `github.com/example/module`\n" +
+				"\n" +
+				"`v0.0.0-20231101000000-bbbbbbbbbbbb` → `v0.0.0-20231201000000-dddddddddddd`\n" +
+				"\n",
+		},
+		{
+			name: "updates",
+			deps: deps,
+			updates: []check.Update{
+				{
+					Module:        "go4.org/netipx",
+					Current:       "v0.0.0-20230719000000-aaaaaaaaaaaa",
+					Latest:        "v0.0.0-20231201000000-cccccccccccc",
+					CurrentTime:   time.Date(2023, 7, 19, 0, 0, 0, 0, time.UTC),
+					LatestTime:    time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					CommitsBehind: 3,
+					Commits: []check.Commit{
+						{SHA: "cccccccccccc1234567890", Subject: "Fix *the* bug"},
+						{SHA: "bbbbbbbbbbbb1234567890", Subject: "Add a feature"},
+					},
+					Risk:          check.RiskFeature,
+					SumDB:         check.SumDBVerified,
+					Signature:     &check.Signature{Verified: true, Reason: "valid"},
+					Compatibility: &check.Compatibility{Compatible: true},
+					CompareURL: "https://github.com/inetaf/netipx/compare/" +
+						"aaaaaaaaaaaa...cccccccccccc",
+				},
+				{
+					Module:  "github.com/example/module",
+					Current: "v0.0.0-20231101000000-bbbbbbbbbbbb",
+					Latest:  "v0.0.0-20231101000000-dddddddddddd",
+					Vulnerabilities: []check.Vulnerability{
+						{ID: "GO-2023-0001", Summary: "Panic on bad input", FixedInLatest: true},
+						{ID: "GHSA-xxxx-yyyy-zzzz", Severity: "MODERATE"},
+					},
+					Signature:    &check.Signature{Reason: "unsigned"},
+					Authors:      []string{"dependabot[bot]"},
+					ReleaseNotes: "## v1.1.0\n\n* Add a feature.",
+					Compatibility: &check.Compatibility{
+						Incompatible: []string{"github.com/example/module.Func: removed"},
+					},
+					LicenseChange: &check.LicenseChange{
+						Current:     "MIT",
+						Latest:      "MIT",
+						TextChanged: true,
+					},
+				},
+			},
+			want: "2 updates available for pseudo-versioned dependencies:\n" +
+				"\n" +
+				"### `go4.org/netipx`\n" +
+				"\n" +
+				"`v0.0.0-20230719000000-aaaaaaaaaaaa` → `v0.0.0-20231201000000-cccccccccccc`\n" +
+				"\n" +
+				"- Checksum database: verified\n" +
+				"- Signature: verified\n" +
+				"- Current commit is 4 months 12 days older than latest\n" +
+				"- Risk: feature\n" +
+				"- Behind by 3 commits\n" +
+				"- API: compatible\n" +
+				"- [Compare changes](https://github.com/inetaf/netipx/compare/" +
+				"aaaaaaaaaaaa...cccccccccccc)\n" +
+				"\n" +
+				"<details>\n" +
+				"<summary>3 new commits</summary>\n" +
+				"\n" +
+				"- cccccccccccc Fix \\*the\\* bug\n" +
+				"- bbbbbbbbbbbb Add a feature\n" +
+				"- ... and 1 more\n" +
+				"\n" +
+				"</details>\n" +
+				"\n" +
+				"### `github.com/example/module`\n" +
+				"\n" +
+				"`v0.0.0-20231101000000-bbbbbbbbbbbb` → `v0.0.0-20231101000000-dddddddddddd`\n" +
+				"\n" +
+				"- :warning: Affected by [GO-2023-0001](https://pkg.go.dev/vuln/GO-2023-0001) " +
+				"(fixed by this update): Panic on bad input\n" +
+				"- :warning: Affected by GHSA-xxxx-yyyy-zzzz " +
+				"(severity `MODERATE`, **not** fixed by this update)\n" +
+				"- :warning: Signature: **not verified** (`unsigned`)\n" +
+				"- Authors: dependabot\\[bot\\] (bot commits only)\n" +
+				"- API: **1 incompatible change**\n" +
+				"  - `github.com/example/module.Func: removed`\n" +
+				"- :warning: License text changed (`MIT`)\n" +
+				"\n" +
+				"<details>\n" +
+				"<summary>Release notes</summary>\n" +
+				"\n" +
+				"## v1.1.0\n" +
+				"\n" +
+				"* Add a feature.\n" +
+				"\n" +
+				"</details>\n",
+		},
+		{
+			name: "failures",
+			deps: deps,
+			failures: []check.Failure{
+				{Module: "github.com/example/module", Err: errors.New("server error")},
+			},
+			want: "### Failed to check\n" +
+				"\n" +
+				"- `github.com/example/module`: server error\n",
+		},
+		{
+			name: "module path changed",
+			deps: deps,
+			updates: []check.Update{
+				{
+					Module:       "go4.org/netipx",
+					Current:      "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:       "v0.0.0-20231101000000-cccccccccccc",
+					DeclaredPath: "example.com/netipx",
+				},
+			},
+			want: "1 update available for pseudo-versioned dependencies:\n" +
+				"\n" +
+				"### `go4.org/netipx`\n" +
+				"\n" +
+				"`v0.0.0-20231101000000-aaaaaaaaaaaa` → `v0.0.0-20231101000000-cccccccccccc`\n" +
+				"\n" +
+				"- :warning: Module path changed to `example.com/netipx` (declared in the latest " +
+				"`go.mod`): consider updating the import path rather than the version\n",
+		},
+		{
+			name: "pin vanished",
+			deps: deps,
+			updates: []check.Update{
+				{
+					Module:      "go4.org/netipx",
+					Current:     "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:      "v0.0.0-20231101000000-cccccccccccc",
+					PinVanished: true,
+				},
+			},
+			want: "1 update available for pseudo-versioned dependencies:\n" +
+				"\n" +
+				"### `go4.org/netipx`\n" +
+				"\n" +
+				"`v0.0.0-20231101000000-aaaaaaaaaaaa` → `v0.0.0-20231101000000-cccccccccccc`\n" +
+				"\n" +
+				"- :warning: The pinned commit **no longer exists upstream** " +
+				"(history rewritten?): re-pin with " +
+				"`go get go4.org/netipx@v0.0.0-20231101000000-cccccccccccc`\n",
+		},
+		{
+			name: "abandoned",
+			deps: deps,
+			abandoned: []check.Abandoned{
+				{Module: "go4.org/netipx", LastCommit: time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)},
+			},
+			want: "No updates found for pseudo-versioned dependencies.\n" +
+				"\n" +
+				"### Abandoned upstream\n" +
+				"\n" +
+				"These repositories are archived or inactive. Consider forking or replacing " +
+				"these dependencies rather than updating them.\n" +
+				"\n" +
+				"- `go4.org/netipx`: last commit 2021-03-04\n",
+		},
+		{
+			name:    "summary without updates",
+			deps:    deps,
+			summary: true,
+			want: "No updates found for pseudo-versioned dependencies.\n" +
+				"\n" +
+				"### Summary\n" +
+				"\n" +
+				"| Pseudo-versioned | Stale | Failed | Median staleness | Most out of date |\n" +
+				"| --- | --- | --- | --- | --- |\n" +
+				"| 2 | 0 | 0 | - | - |\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			rep := check.Report{
+				Dependencies:     tt.deps,
+				Updates:          tt.updates,
+				Failures:         tt.failures,
+				Abandoned:        tt.abandoned,
+				VendorMismatches: tt.vendor,
+				TaggedUpdates:    tt.tagged,
+				Toolchain:        tt.toolchain,
+			}
+			if tt.summary {
+				addSummary(&rep)
+			}
+			printMarkdown(&buf, rep, tt.groupBy)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
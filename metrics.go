@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// metricsContentType is the content type of the Prometheus text exposition
+// format.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metric is a Prometheus metric and its samples.
+type metric struct {
+	name, help, kind string
+	samples          []sample
+}
+
+// sample is a value of a metric with labels, given as name and value pairs.
+type sample struct {
+	labels []string
+	value  float64
+}
+
+// serveMetrics serves the daemon's metrics in the Prometheus text exposition
+// format.
+func (d *daemon) serveMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", metricsContentType)
+	writeMetrics(w, d.metrics())
+}
+
+// metrics returns the daemon's metrics: for each dependency in the latest
+// report of each go.mod file, whether an update is available and how much
+// older the current commit is than the latest, and for each go.mod file,
+// statistics about its checks. Dependencies pinned outside go.mod (see
+// check.WithToolPins) are labeled with their source, since a module may be
+// pinned in several places, and a dependency with the same labels as an
+// earlier one is left out, as Prometheus rejects duplicate series.
+func (d *daemon) metrics() []metric {
+	updates := metric{
+		name: "untagged_dep_updates_available",
+		help: "Whether a newer commit is available for a pseudo-versioned dependency " +
+			"(1) or not (0).",
+		kind: "gauge",
+	}
+	age := metric{
+		name: "untagged_dep_age_seconds",
+		help: "How much older the current commit of a pseudo-versioned dependency is " +
+			"than the latest.",
+		kind: "gauge",
+	}
+	failures := metric{
+		name: "untagged_dep_failures",
+		help: "Dependencies that could not be checked in the last check.",
+		kind: "gauge",
+	}
+	for _, env := range d.latest() {
+		failed := map[string]bool{}
+		for _, f := range env.Report.Failures {
+			failed[f.Module] = true
+		}
+		seen := map[string]bool{}
+		for _, dep := range env.Report.Dependencies {
+			if failed[dep.Module] || seen[dep.Module+" "+dep.Source] {
+				continue
+			}
+			seen[dep.Module+" "+dep.Source] = true
+			labels := []string{"gomod", env.GoModPath, "module", dep.Module}
+			if dep.Source != "" {
+				labels = append(labels, "source", dep.Source)
+			}
+			available := 0.0
+			var seconds float64
+			i := slices.IndexFunc(env.Report.Updates, func(u check.Update) bool {
+				return u.Module == dep.Module && u.Source == dep.Source
+			})
+			if i >= 0 {
+				available = 1
+				seconds = env.Report.Updates[i].Age().Seconds()
+			}
+			updates.samples = append(updates.samples, sample{labels: labels, value: available})
+			age.samples = append(age.samples, sample{labels: labels, value: seconds})
+		}
+		failures.samples = append(failures.samples, sample{
+			labels: []string{"gomod", env.GoModPath},
+			value:  float64(len(env.Report.Failures)),
+		})
+	}
+
+	checks := metric{
+		name: "untagged_dep_checks_total",
+		help: "Checks of a go.mod file.",
+		kind: "counter",
+	}
+	checkErrors := metric{
+		name: "untagged_dep_check_errors_total",
+		help: "Checks of a go.mod file that failed entirely, such as when it could not " +
+			"be read.",
+		kind: "counter",
+	}
+	duration := metric{
+		name: "untagged_dep_check_duration_seconds",
+		help: "How long the last check of a go.mod file took.",
+		kind: "gauge",
+	}
+	finished := metric{
+		name: "untagged_dep_last_check_timestamp_seconds",
+		help: "When the last check of a go.mod file finished, in seconds since the " +
+			"Unix epoch.",
+		kind: "gauge",
+	}
+	d.mu.Lock()
+	for _, path := range d.gomodPaths {
+		labels := []string{"gomod", path}
+		var stats checkStats
+		if s, ok := d.stats[path]; ok {
+			stats = *s
+		}
+		checks.samples = append(checks.samples, sample{
+			labels: labels,
+			value:  float64(stats.checks),
+		})
+		checkErrors.samples = append(checkErrors.samples, sample{
+			labels: labels,
+			value:  float64(stats.errors),
+		})
+		if stats.checks == 0 {
+			continue
+		}
+		duration.samples = append(duration.samples, sample{
+			labels: labels,
+			value:  stats.duration.Seconds(),
+		})
+		finished.samples = append(finished.samples, sample{
+			labels: labels,
+			value:  float64(stats.finished.UnixMilli()) / 1000,
+		})
+	}
+	d.mu.Unlock()
+
+	return []metric{updates, age, failures, checks, checkErrors, duration, finished}
+}
+
+// writeMetrics writes metrics in the Prometheus text exposition format.
+func writeMetrics(w io.Writer, metrics []metric) {
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", m.name, m.kind)
+		for _, s := range m.samples {
+			fmt.Fprint(w, m.name)
+			if len(s.labels) > 0 {
+				var labels []string
+				for i := 0; i+1 < len(s.labels); i += 2 {
+					labels = append(
+						labels,
+						s.labels[i]+`="`+metricLabelEscaper.Replace(s.labels[i+1])+`"`,
+					)
+				}
+				fmt.Fprintf(w, "{%s}", strings.Join(labels, ","))
+			}
+			fmt.Fprintf(w, " %s\n", strconv.FormatFloat(s.value, 'g', -1, 64))
+		}
+	}
+}
+
+// metricLabelEscaper escapes label values in the text exposition format.
+var metricLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
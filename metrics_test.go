@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestDaemonMetrics(t *testing.T) {
+	finished := time.Date(2026, 3, 4, 14, 0, 0, 500_000_000, time.UTC)
+	d := &daemon{
+		gomodPaths: []string{"go.mod", `odd"dir/go.mod`},
+		reports: map[string]check.Envelope{
+			"go.mod": {
+				GoModPath: "go.mod",
+				Report: check.Report{
+					Dependencies: []check.Dependency{
+						{Module: "go4.org/netipx"},
+						{Module: "example.com/a"},
+						{Module: "example.com/b"},
+						// Also pinned outside go.mod, twice in the same place.
+						{Module: "go4.org/netipx", Source: "Dockerfile:2"},
+						{Module: "go4.org/netipx", Source: "Dockerfile:2"},
+					},
+					Updates: []check.Update{
+						{
+							Module:      "go4.org/netipx",
+							CurrentTime: time.Date(2023, 7, 19, 0, 0, 0, 0, time.UTC),
+							LatestTime:  time.Date(2023, 7, 21, 0, 0, 0, 0, time.UTC),
+						},
+					},
+					Failures: []check.Failure{{Module: "example.com/b"}},
+				},
+			},
+		},
+		stats: map[string]*checkStats{
+			"go.mod": {checks: 3, errors: 1, duration: 1500 * time.Millisecond, finished: finished},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	d.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != metricsContentType {
+		t.Errorf("got content type %q", got)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		"# TYPE untagged_dep_updates_available gauge\n",
+		`untagged_dep_updates_available{gomod="go.mod",module="go4.org/netipx"} 1` + "\n",
+		`untagged_dep_updates_available{gomod="go.mod",module="example.com/a"} 0` + "\n",
+		`untagged_dep_age_seconds{gomod="go.mod",module="go4.org/netipx"} 172800` + "\n",
+		`untagged_dep_age_seconds{gomod="go.mod",module="example.com/a"} 0` + "\n",
+		`untagged_dep_updates_available{gomod="go.mod",module="go4.org/netipx",` +
+			`source="Dockerfile:2"} 0` + "\n",
+		`untagged_dep_failures{gomod="go.mod"} 1` + "\n",
+		"# TYPE untagged_dep_checks_total counter\n",
+		`untagged_dep_checks_total{gomod="go.mod"} 3` + "\n",
+		`untagged_dep_checks_total{gomod="odd\"dir/go.mod"} 0` + "\n",
+		`untagged_dep_check_errors_total{gomod="go.mod"} 1` + "\n",
+		`untagged_dep_check_errors_total{gomod="odd\"dir/go.mod"} 0` + "\n",
+		`untagged_dep_check_duration_seconds{gomod="go.mod"} 1.5` + "\n",
+		`untagged_dep_last_check_timestamp_seconds{gomod="go.mod"} 1.7726328005e+09` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+	// Each series is written once.
+	series := map[string]bool{}
+	for line := range strings.Lines(body) {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, _, _ := strings.Cut(line, " ")
+		if series[name] {
+			t.Errorf("duplicate series %s:\n%s", name, body)
+		}
+		series[name] = true
+	}
+	// Failed dependencies have no update metrics, and go.mod files not yet
+	// checked have no duration.
+	for _, unwanted := range []string{`module="example.com/b"`, `duration_seconds{gomod="odd`} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("metrics contain %q:\n%s", unwanted, body)
+		}
+	}
+}
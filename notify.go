@@ -0,0 +1,567 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// Notification targets for -notify.
+const (
+	notifySlack   = "slack"
+	notifyDiscord = "discord"
+	notifyTeams   = "teams"
+	notifyWebhook = "webhook"
+)
+
+// signatureHeader carries the HMAC-SHA256 signature of -notify webhook
+// requests, in the form GitHub uses for its webhooks.
+const signatureHeader = "X-Signature-256"
+
+// maxWebhookErrorSize limits how much of an error response from a webhook is
+// included in errors.
+const maxWebhookErrorSize = 4096
+
+// notifier posts reports to a -notify target.
+type notifier struct {
+	// target is the -notify target.
+	target string
+	// webhookURL is where reports are posted.
+	webhookURL string
+	// secret, if set, is the key used to sign -notify webhook requests.
+	secret string
+	// email configures -notify email.
+	email smtpConfig
+	// template, if set, renders the message body instead of the target's
+	// default format (see -notify-template).
+	template *template.Template
+	// state, if set, records what has been announced so that it is not sent
+	// again (see -notify-state).
+	state  *notifyState
+	client *http.Client
+}
+
+// notify sends the report to the target. If there is a state, only the
+// updates and failures not already announced are sent, and nothing is sent
+// if there are none.
+func (n notifier) notify(ctx context.Context, env check.Envelope) error {
+	if n.state == nil {
+		return n.send(ctx, env)
+	}
+	unannounced := n.state.unannounced(env)
+	if len(unannounced.Report.Updates) > 0 || len(unannounced.Report.Failures) > 0 {
+		if err := n.send(ctx, unannounced); err != nil {
+			return err
+		}
+	}
+	return n.state.record(env)
+}
+
+// send sends the report to the target. Chat and email targets are sent
+// nothing when the report is clean (no updates and no failures), but the JSON
+// report is always posted to a generic webhook.
+func (n notifier) send(ctx context.Context, env check.Envelope) error {
+	rep := env.Report
+	if n.target != notifyWebhook && len(rep.Updates) == 0 && len(rep.Failures) == 0 {
+		return nil
+	}
+	if n.template != nil {
+		return n.notifyTemplate(ctx, env)
+	}
+
+	switch n.target {
+	case notifyWebhook:
+		if err := n.post(ctx, env); err != nil {
+			return fmt.Errorf("posting report: %w", err)
+		}
+		return nil
+	case notifySlack:
+		msg := struct {
+			Text string `json:"text"`
+		}{Text: slackMessage(rep, env.GoModPath)}
+		if err := n.post(ctx, msg); err != nil {
+			return fmt.Errorf("notifying Slack: %w", err)
+		}
+		return nil
+	case notifyDiscord:
+		for _, msg := range discordMessages(rep, env.GoModPath) {
+			if err := n.post(ctx, msg); err != nil {
+				return fmt.Errorf("notifying Discord: %w", err)
+			}
+		}
+		return nil
+	case notifyTeams:
+		if err := n.post(ctx, teamsMessage(rep, env.GoModPath)); err != nil {
+			return fmt.Errorf("notifying Microsoft Teams: %w", err)
+		}
+		return nil
+	case notifyEmail:
+		parts, err := emailParts(env)
+		if err != nil {
+			return err
+		}
+		if err := n.sendEmail(ctx, env, parts); err != nil {
+			return fmt.Errorf("sending email: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown notification target %q", n.target)
+	}
+}
+
+// notifyTemplateFuncs are the functions available to -notify-template
+// templates in addition to text/template's.
+var notifyTemplateFuncs = template.FuncMap{
+	"age":    formatAge,
+	"plural": plural,
+	"join":   strings.Join,
+}
+
+// loadNotifyTemplate parses the -notify-template file at path.
+func loadNotifyTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading notification template: %w", err)
+	}
+	t, err := template.New(filepath.Base(path)).Funcs(notifyTemplateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing notification template: %w", err)
+	}
+	return t, nil
+}
+
+// notifyTemplate sends the report rendered with the notifier's template. Chat
+// targets get it as the text of a message, and email as a plain text body.
+// The generic webhook is sent it as is: as JSON if it is valid JSON, such as
+// a payload for another service, and otherwise as plain text.
+func (n notifier) notifyTemplate(ctx context.Context, env check.Envelope) error {
+	var b strings.Builder
+	if err := n.template.Execute(&b, env); err != nil {
+		return fmt.Errorf("rendering notification template: %w", err)
+	}
+	text := b.String()
+
+	var err error
+	switch n.target {
+	case notifySlack:
+		err = n.post(ctx, struct {
+			Text string `json:"text"`
+		}{Text: text})
+	case notifyDiscord:
+		err = n.post(ctx, discordMessage{Content: truncate(text, maxDiscordContent)})
+	case notifyTeams:
+		body := []adaptiveElement{{Type: "TextBlock", Text: text, Wrap: true}}
+		err = n.post(ctx, newTeamsCard(body))
+	case notifyWebhook:
+		contentType := "text/plain; charset=utf-8"
+		if json.Valid([]byte(text)) {
+			contentType = "application/json"
+		}
+		err = n.postBody(ctx, contentType, []byte(text))
+	case notifyEmail:
+		err = n.sendEmail(ctx, env, []emailPart{{"text/plain; charset=utf-8", text}})
+	default:
+		err = fmt.Errorf("unknown notification target %q", n.target)
+	}
+	if err != nil {
+		return fmt.Errorf("sending notification to %s: %w", n.target, err)
+	}
+	return nil
+}
+
+// post posts payload as JSON to the webhook.
+func (n notifier) post(ctx context.Context, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+	return n.postBody(ctx, "application/json", body)
+}
+
+// postBody posts body to the webhook, signing it if there is a secret.
+func (n notifier) postBody(ctx context.Context, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		n.webhookURL,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if n.secret != "" {
+		req.Header.Set(signatureHeader, signPayload(n.secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// The webhook URL is a secret, so leave it out of the error.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("posting to webhook: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookErrorSize))
+		if msg := strings.TrimSpace(string(data)); msg != "" {
+			return fmt.Errorf("webhook responded %s: %s", resp.Status, msg)
+		}
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// signPayload returns the signature header value for body: "sha256=" and
+// the hex-encoded HMAC-SHA256 of body keyed with secret.
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// slackEscaper escapes the characters that Slack's mrkdwn format uses for
+// links and mentions.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackMessage returns the report's updates and failures as a Slack message
+// in mrkdwn format.
+func slackMessage(rep check.Report, goModPath string) string {
+	var b strings.Builder
+	if len(rep.Updates) > 0 {
+		fmt.Fprintf(
+			&b,
+			"*%s available for pseudo-versioned dependencies in `%s`:*\n",
+			plural(len(rep.Updates), "update"),
+			slackEscaper.Replace(goModPath),
+		)
+		for _, u := range rep.Updates {
+			fmt.Fprintf(&b, "• `%s`: `%s` → `%s`", u.Module, u.Current, u.Latest)
+			if u.Age() > 0 {
+				fmt.Fprintf(&b, " (%s behind)", formatAge(u.CurrentTime, u.LatestTime))
+			}
+			if u.Severity.AtLeast(check.SeverityMedium) {
+				fmt.Fprintf(&b, " *%s*", u.Severity)
+			}
+			if u.CompareURL != "" {
+				fmt.Fprintf(&b, " <%s|compare>", slackEscaper.Replace(u.CompareURL))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(rep.Failures) > 0 {
+		if len(rep.Updates) > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("*Failed to check:*\n")
+		for _, f := range rep.Failures {
+			fmt.Fprintf(&b, "• `%s`: %s\n", f.Module, slackEscaper.Replace(f.Err.Error()))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Discord's limits on webhook messages.
+const (
+	maxDiscordContent          = 2000
+	maxDiscordEmbeds           = 10
+	maxDiscordTitle            = 256
+	maxDiscordEmbedDescription = 4096
+)
+
+// Colors of Discord embeds.
+const (
+	discordYellow = 0xfee75c
+	discordRed    = 0xed4245
+)
+
+// discordMessage is a Discord webhook message.
+type discordMessage struct {
+	Content string         `json:"content,omitempty"`
+	Embeds  []discordEmbed `json:"embeds,omitempty"`
+}
+
+// discordEmbed is a rich embed in a Discord message.
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	URL         string         `json:"url,omitempty"`
+	Description string         `json:"description"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+}
+
+// discordField is a field of a Discord embed.
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// discordMessages returns the report as Discord messages, with an embed per
+// update and one listing the failures. Discord allows only 10 embeds per
+// message, so large reports are split over several messages.
+func discordMessages(rep check.Report, goModPath string) []discordMessage {
+	var embeds []discordEmbed
+	for _, u := range rep.Updates {
+		embeds = append(embeds, discordUpdateEmbed(u))
+	}
+	if len(rep.Failures) > 0 {
+		var lines []string
+		for _, f := range rep.Failures {
+			lines = append(
+				lines,
+				fmt.Sprintf("- `%s`: %s", f.Module, markdownEscaper.Replace(f.Err.Error())),
+			)
+		}
+		embeds = append(embeds, discordEmbed{
+			Title:       "Failed to check " + plural(len(rep.Failures), "module"),
+			Description: truncate(strings.Join(lines, "\n"), maxDiscordEmbedDescription),
+			Color:       discordRed,
+		})
+	}
+
+	var content string
+	if len(rep.Updates) > 0 {
+		content = fmt.Sprintf(
+			"%s available for pseudo-versioned dependencies in `%s`:",
+			plural(len(rep.Updates), "update"),
+			goModPath,
+		)
+	}
+	var msgs []discordMessage
+	for batch := range slices.Chunk(embeds, maxDiscordEmbeds) {
+		msgs = append(msgs, discordMessage{Content: content, Embeds: batch})
+		content = ""
+	}
+	return msgs
+}
+
+// discordUpdateEmbed returns the embed describing an update. It is red if the
+// update needs attention beyond bumping the version.
+func discordUpdateEmbed(u check.Update) discordEmbed {
+	embed := discordEmbed{
+		Title:       truncate(u.Module, maxDiscordTitle),
+		URL:         u.CompareURL,
+		Description: fmt.Sprintf("`%s` → `%s`", u.Current, u.Latest),
+		Color:       discordYellow,
+	}
+	if u.Age() > 0 {
+		embed.Fields = append(embed.Fields, discordField{
+			Name:   "Behind by",
+			Value:  formatAge(u.CurrentTime, u.LatestTime),
+			Inline: true,
+		})
+	}
+	if u.Severity.AtLeast(check.SeverityMedium) {
+		if u.Severity.AtLeast(check.SeverityHigh) {
+			embed.Color = discordRed
+		}
+		embed.Fields = append(embed.Fields, discordField{
+			Name:   "Severity",
+			Value:  string(u.Severity),
+			Inline: true,
+		})
+	}
+	if u.CommitsBehind > 0 {
+		embed.Fields = append(embed.Fields, discordField{
+			Name:   "Commits",
+			Value:  strconv.Itoa(u.CommitsBehind),
+			Inline: true,
+		})
+	}
+	if u.Risk != check.RiskUnknown {
+		embed.Fields = append(embed.Fields, discordField{
+			Name:   "Risk",
+			Value:  string(u.Risk),
+			Inline: true,
+		})
+	}
+	if len(u.Vulnerabilities) > 0 {
+		embed.Color = discordRed
+		embed.Fields = append(embed.Fields, discordField{
+			Name:   "Vulnerabilities",
+			Value:  strconv.Itoa(len(u.Vulnerabilities)),
+			Inline: true,
+		})
+	}
+	if u.PinVanished {
+		embed.Color = discordRed
+		embed.Fields = append(embed.Fields, discordField{
+			Name:  "Pinned commit",
+			Value: "No longer exists upstream (history rewritten?): re-pin to the latest version",
+		})
+	}
+	return embed
+}
+
+// truncate shortens s to at most n bytes, ending it with an ellipsis if it
+// was shortened.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	const ellipsis = "…"
+	s = s[:n-len(ellipsis)]
+	// Do not cut a UTF-8 sequence in two.
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s + ellipsis
+}
+
+// teamsCard is a Microsoft Teams message with an Adaptive Card attachment.
+type teamsCard struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct { //nolint:tagliatelle // matches Adaptive Cards
+	ContentType string       `json:"contentType"`
+	Content     adaptiveCard `json:"content"`
+}
+
+// adaptiveCard is an Adaptive Card (https://adaptivecards.io), using the
+// subset of version 1.4 that Teams supports.
+type adaptiveCard struct { //nolint:tagliatelle // matches Adaptive Cards
+	Schema  string            `json:"$schema"`
+	Type    string            `json:"type"`
+	Version string            `json:"version"`
+	Body    []adaptiveElement `json:"body"`
+	MSTeams map[string]string `json:"msteams,omitempty"`
+}
+
+// adaptiveElement is an Adaptive Card element: a TextBlock, FactSet, or
+// Container.
+type adaptiveElement struct {
+	Type      string            `json:"type"`
+	Text      string            `json:"text,omitempty"`
+	Weight    string            `json:"weight,omitempty"`
+	Size      string            `json:"size,omitempty"`
+	Color     string            `json:"color,omitempty"`
+	Wrap      bool              `json:"wrap,omitempty"`
+	Separator bool              `json:"separator,omitempty"`
+	Facts     []adaptiveFact    `json:"facts,omitempty"`
+	Items     []adaptiveElement `json:"items,omitempty"`
+}
+
+type adaptiveFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// teamsMessage returns the report as a Microsoft Teams message with an
+// Adaptive Card listing each update's versions, and the failures.
+func teamsMessage(rep check.Report, goModPath string) teamsCard {
+	var body []adaptiveElement
+	if len(rep.Updates) > 0 {
+		body = append(body, adaptiveElement{
+			Type: "TextBlock",
+			Text: fmt.Sprintf(
+				"%s available for pseudo-versioned dependencies in %s",
+				plural(len(rep.Updates), "update"),
+				goModPath,
+			),
+			Weight: "Bolder",
+			Size:   "Medium",
+			Wrap:   true,
+		})
+	}
+	for _, u := range rep.Updates {
+		facts := []adaptiveFact{
+			{Title: "Current", Value: u.Current},
+			{Title: "Latest", Value: u.Latest},
+		}
+		if u.Age() > 0 {
+			facts = append(
+				facts,
+				adaptiveFact{Title: "Behind by", Value: formatAge(u.CurrentTime, u.LatestTime)},
+			)
+		}
+		if u.Severity.AtLeast(check.SeverityMedium) {
+			facts = append(facts, adaptiveFact{Title: "Severity", Value: string(u.Severity)})
+		}
+		if u.PinVanished {
+			facts = append(facts, adaptiveFact{
+				Title: "Pinned commit",
+				Value: "No longer exists upstream (history rewritten?)",
+			})
+		}
+		if u.CompareURL != "" {
+			facts = append(
+				facts,
+				adaptiveFact{Title: "Changes", Value: "[Compare](" + u.CompareURL + ")"},
+			)
+		}
+		body = append(body, adaptiveElement{
+			Type:      "Container",
+			Separator: true,
+			Items: []adaptiveElement{
+				{Type: "TextBlock", Text: u.Module, Weight: "Bolder", Wrap: true},
+				{Type: "FactSet", Facts: facts},
+			},
+		})
+	}
+
+	if len(rep.Failures) > 0 {
+		var lines []string
+		for _, f := range rep.Failures {
+			lines = append(lines, fmt.Sprintf("- %s: %s", f.Module, f.Err))
+		}
+		body = append(
+			body,
+			adaptiveElement{
+				Type:      "TextBlock",
+				Text:      "Failed to check:",
+				Weight:    "Bolder",
+				Color:     "Attention",
+				Separator: len(rep.Updates) > 0,
+				Wrap:      true,
+			},
+			adaptiveElement{Type: "TextBlock", Text: strings.Join(lines, "\n"), Wrap: true},
+		)
+	}
+
+	return newTeamsCard(body)
+}
+
+// newTeamsCard returns a Microsoft Teams message with an Adaptive Card with
+// the given body.
+func newTeamsCard(body []adaptiveElement) teamsCard {
+	return teamsCard{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: adaptiveCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body:    body,
+				// Use the full width of the channel.
+				MSTeams: map[string]string{"width": "Full"},
+			},
+		}},
+	}
+}
@@ -0,0 +1,526 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestNotifySlack(t *testing.T) {
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("got method %s, want POST", r.Method)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("got content type %q", got)
+		}
+		var msg struct {
+			Text string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+		if strings.Contains(msg.Text, "invalid") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, "invalid_payload")
+			return
+		}
+		posted = append(posted, msg.Text)
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	deps := []check.Dependency{
+		{Module: "go4.org/netipx", Version: "v0.0.0-20230719000000-aaaaaaaaaaaa"},
+		{Module: "github.com/example/module", Version: "v0.0.0-20231101000000-bbbbbbbbbbbb"},
+	}
+
+	tests := []struct {
+		name    string
+		rep     check.Report
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "clean",
+			rep:  check.Report{Dependencies: deps},
+		},
+		{
+			name: "updates and failures",
+			rep: check.Report{
+				Dependencies: deps,
+				Updates: []check.Update{
+					{
+						Module:      "go4.org/netipx",
+						Current:     "v0.0.0-20230719000000-aaaaaaaaaaaa",
+						Latest:      "v0.0.0-20231201000000-cccccccccccc",
+						CurrentTime: time.Date(2023, 7, 19, 0, 0, 0, 0, time.UTC),
+						LatestTime:  time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+						CompareURL: "https://github.com/inetaf/netipx/compare/" +
+							"aaaaaaaaaaaa...cccccccccccc",
+					},
+				},
+				Failures: []check.Failure{
+					{Module: "github.com/example/module", Err: errors.New("a < b & c")},
+				},
+			},
+			want: []string{
+				"*1 update available for pseudo-versioned dependencies in `go.mod`:*\n" +
+					"• `go4.org/netipx`: `v0.0.0-20230719000000-aaaaaaaaaaaa` → " +
+					"`v0.0.0-20231201000000-cccccccccccc` (4 months 12 days behind) " +
+					"<https://github.com/inetaf/netipx/compare/" +
+					"aaaaaaaaaaaa...cccccccccccc|compare>\n" +
+					"\n" +
+					"*Failed to check:*\n" +
+					"• `github.com/example/module`: a &lt; b &amp; c",
+			},
+		},
+		{
+			name: "webhook error",
+			rep: check.Report{
+				Dependencies: deps,
+				Failures: []check.Failure{
+					{Module: "github.com/example/module", Err: errors.New("invalid")},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posted = nil
+			n := notifier{target: notifySlack, webhookURL: server.URL, client: server.Client()}
+			err := n.notify(t.Context(), check.Envelope{GoModPath: "go.mod", Report: tt.rep})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "invalid_payload") {
+					t.Errorf("got error %v, want the webhook's response", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("notify: %v", err)
+			}
+			if strings.Join(posted, "\n---\n") != strings.Join(tt.want, "\n---\n") {
+				t.Errorf("got messages\n%q\nwant\n%q", posted, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifierPostHidesURL(t *testing.T) {
+	n := notifier{
+		target:     notifySlack,
+		webhookURL: "http://127.0.0.1:1/services/SECRET",
+		client:     http.DefaultClient,
+	}
+	err := n.post(t.Context(), struct{}{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if strings.Contains(err.Error(), "SECRET") {
+		t.Errorf("error %q contains the webhook URL", err)
+	}
+}
+
+func TestNotifyDiscord(t *testing.T) {
+	var posted []discordMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg discordMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+		posted = append(posted, msg)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	rep := check.Report{
+		Updates: []check.Update{
+			{
+				Module:        "go4.org/netipx",
+				Current:       "v0.0.0-20230719000000-aaaaaaaaaaaa",
+				Latest:        "v0.0.0-20231201000000-cccccccccccc",
+				CurrentTime:   time.Date(2023, 7, 19, 0, 0, 0, 0, time.UTC),
+				LatestTime:    time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+				CommitsBehind: 3,
+				Risk:          check.RiskFeature,
+				CompareURL:    "https://github.com/inetaf/netipx/compare/aaaa...cccc",
+			},
+			{
+				Module:      "github.com/example/module",
+				Current:     "v0.0.0-20231101000000-bbbbbbbbbbbb",
+				Latest:      "v0.0.0-20231101000000-dddddddddddd",
+				PinVanished: true,
+			},
+		},
+		Failures: []check.Failure{
+			{Module: "example.com/broken", Err: errors.New("server *error*")},
+		},
+	}
+	for i := range 9 {
+		rep.Updates = append(rep.Updates, check.Update{
+			Module:  fmt.Sprintf("example.com/m%d", i),
+			Current: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			Latest:  "v0.0.0-20231201000000-bbbbbbbbbbbb",
+		})
+	}
+
+	n := notifier{target: notifyDiscord, webhookURL: server.URL, client: server.Client()}
+	if err := n.notify(t.Context(), check.Envelope{GoModPath: "go.mod", Report: rep}); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	if len(posted) != 2 {
+		t.Fatalf("got %d messages, want 2", len(posted))
+	}
+	if len(posted[0].Embeds) != maxDiscordEmbeds || len(posted[1].Embeds) != 2 {
+		t.Errorf("got %d and %d embeds", len(posted[0].Embeds), len(posted[1].Embeds))
+	}
+	want := "11 updates available for pseudo-versioned dependencies in `go.mod`:"
+	if posted[0].Content != want {
+		t.Errorf("got content %q, want %q", posted[0].Content, want)
+	}
+	if posted[1].Content != "" {
+		t.Errorf("got content %q in second message, want none", posted[1].Content)
+	}
+
+	wantEmbeds := []discordEmbed{
+		{
+			Title: "go4.org/netipx",
+			URL:   "https://github.com/inetaf/netipx/compare/aaaa...cccc",
+			Description: "`v0.0.0-20230719000000-aaaaaaaaaaaa` → " +
+				"`v0.0.0-20231201000000-cccccccccccc`",
+			Color: discordYellow,
+			Fields: []discordField{
+				{Name: "Behind by", Value: "4 months 12 days", Inline: true},
+				{Name: "Commits", Value: "3", Inline: true},
+				{Name: "Risk", Value: "feature", Inline: true},
+			},
+		},
+		{
+			Title: "github.com/example/module",
+			Description: "`v0.0.0-20231101000000-bbbbbbbbbbbb` → " +
+				"`v0.0.0-20231101000000-dddddddddddd`",
+			Color: discordRed,
+			Fields: []discordField{
+				{
+					Name: "Pinned commit",
+					Value: "No longer exists upstream (history rewritten?): " +
+						"re-pin to the latest version",
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(posted[0].Embeds[:2], wantEmbeds) {
+		t.Errorf("got embeds\n%+v\nwant\n%+v", posted[0].Embeds[:2], wantEmbeds)
+	}
+	failures := discordEmbed{
+		Title:       "Failed to check 1 module",
+		Description: "- `example.com/broken`: server \\*error\\*",
+		Color:       discordRed,
+	}
+	if got := posted[1].Embeds[1]; !reflect.DeepEqual(got, failures) {
+		t.Errorf("got failures embed %+v, want %+v", got, failures)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{s: "short", n: 10, want: "short"},
+		{s: "exactly10!", n: 10, want: "exactly10!"},
+		{s: "much too long", n: 10, want: "much to…"},
+		{s: "ééééé", n: 8, want: "éé…"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestNotifyTeams(t *testing.T) {
+	var posted []teamsCard
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg teamsCard
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+		posted = append(posted, msg)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	rep := check.Report{
+		Updates: []check.Update{
+			{
+				Module:      "go4.org/netipx",
+				Current:     "v0.0.0-20230719000000-aaaaaaaaaaaa",
+				Latest:      "v0.0.0-20231201000000-cccccccccccc",
+				CurrentTime: time.Date(2023, 7, 19, 0, 0, 0, 0, time.UTC),
+				LatestTime:  time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+				CompareURL:  "https://github.com/inetaf/netipx/compare/aaaa...cccc",
+			},
+		},
+		Failures: []check.Failure{
+			{Module: "example.com/broken", Err: errors.New("server error")},
+		},
+	}
+	n := notifier{target: notifyTeams, webhookURL: server.URL, client: server.Client()}
+	if err := n.notify(t.Context(), check.Envelope{GoModPath: "go.mod", Report: rep}); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	want := teamsCard{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: adaptiveCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body: []adaptiveElement{
+					{
+						Type:   "TextBlock",
+						Text:   "1 update available for pseudo-versioned dependencies in go.mod",
+						Weight: "Bolder",
+						Size:   "Medium",
+						Wrap:   true,
+					},
+					{
+						Type:      "Container",
+						Separator: true,
+						Items: []adaptiveElement{
+							{
+								Type:   "TextBlock",
+								Text:   "go4.org/netipx",
+								Weight: "Bolder",
+								Wrap:   true,
+							},
+							{
+								Type: "FactSet",
+								Facts: []adaptiveFact{
+									{Title: "Current", Value: "v0.0.0-20230719000000-aaaaaaaaaaaa"},
+									{Title: "Latest", Value: "v0.0.0-20231201000000-cccccccccccc"},
+									{Title: "Behind by", Value: "4 months 12 days"},
+									{
+										Title: "Changes",
+										Value: "[Compare](https://github.com/inetaf/netipx/" +
+											"compare/aaaa...cccc)",
+									},
+								},
+							},
+						},
+					},
+					{
+						Type:      "TextBlock",
+						Text:      "Failed to check:",
+						Weight:    "Bolder",
+						Color:     "Attention",
+						Separator: true,
+						Wrap:      true,
+					},
+					{Type: "TextBlock", Text: "- example.com/broken: server error", Wrap: true},
+				},
+				MSTeams: map[string]string{"width": "Full"},
+			},
+		}},
+	}
+	if len(posted) != 1 || !reflect.DeepEqual(posted[0], want) {
+		t.Errorf("got messages\n%+v\nwant\n%+v", posted, want)
+	}
+}
+
+func TestNotifyWebhook(t *testing.T) {
+	const secret = "It's a Secret to Everybody"
+
+	var bodies [][]byte
+	var signatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading body: %v", err)
+		}
+		bodies = append(bodies, body)
+		signatures = append(signatures, r.Header.Get(signatureHeader))
+	}))
+	defer server.Close()
+
+	env := check.Envelope{
+		SchemaVersion: check.SchemaVersion,
+		ToolVersion:   "v1.2.0",
+		GeneratedAt:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		GoModPath:     "go.mod",
+		Report: check.Report{
+			Dependencies: []check.Dependency{
+				{Module: "go4.org/netipx", Version: "v0.0.0-20230719000000-aaaaaaaaaaaa"},
+			},
+		},
+	}
+
+	// A clean report is still posted, and unsigned without a secret.
+	for _, secret := range []string{"", secret} {
+		n := notifier{
+			target:     notifyWebhook,
+			webhookURL: server.URL,
+			secret:     secret,
+			client:     server.Client(),
+		}
+		if err := n.notify(t.Context(), env); err != nil {
+			t.Fatalf("notify: %v", err)
+		}
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("got %d requests, want 2", len(bodies))
+	}
+	want, err := json.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bodies[1]) != string(want) {
+		t.Errorf("got body\n%s\nwant\n%s", bodies[1], want)
+	}
+	if signatures[0] != "" {
+		t.Errorf("got signature %q without a secret", signatures[0])
+	}
+	// The example from GitHub's webhook documentation.
+	const helloSignature = "sha256=" +
+		"757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+	if got := signPayload(secret, []byte("Hello, World!")); got != helloSignature {
+		t.Errorf("got signature %q, want %q", got, helloSignature)
+	}
+	if got := signPayload(secret, bodies[1]); signatures[1] != got {
+		t.Errorf("got signature %q, want %q", signatures[1], got)
+	}
+}
+
+func TestNotifyTemplate(t *testing.T) {
+	type request struct {
+		contentType string
+		body        string
+	}
+	var posted []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading body: %v", err)
+		}
+		posted = append(posted, request{r.Header.Get("Content-Type"), string(body)})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	writeTemplate := func(name, text string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	env := check.Envelope{
+		GoModPath: "go.mod",
+		Report: check.Report{
+			Updates: []check.Update{
+				{
+					Module:      "go4.org/netipx",
+					Current:     "v0.0.0-20230719000000-aaaaaaaaaaaa",
+					Latest:      "v0.0.0-20231201000000-cccccccccccc",
+					CurrentTime: time.Date(2023, 7, 19, 0, 0, 0, 0, time.UTC),
+					LatestTime:  time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		target   string
+		template string
+		want     request
+	}{
+		{
+			name:   "slack",
+			target: notifySlack,
+			template: "<!here> {{plural (len .Report.Updates) \"update\"}} in {{.GoModPath}}:" +
+				"{{range .Report.Updates}} {{.Module}} ({{age .CurrentTime .LatestTime}})" +
+				"{{end}}",
+			want: request{
+				contentType: "application/json",
+				body: `{"text":"\u003c!here\u003e 1 update in go.mod: ` +
+					`go4.org/netipx (4 months 12 days)"}`,
+			},
+		},
+		{
+			name:     "webhook json",
+			target:   notifyWebhook,
+			template: `{"updates": {{len .Report.Updates}}}`,
+			want:     request{contentType: "application/json", body: `{"updates": 1}`},
+		},
+		{
+			name:     "webhook text",
+			target:   notifyWebhook,
+			template: `{{range .Report.Updates}}{{.Module}} {{.Latest}}{{end}}`,
+			want: request{
+				contentType: "text/plain; charset=utf-8",
+				body:        "go4.org/netipx v0.0.0-20231201000000-cccccccccccc",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posted = nil
+			tmpl, err := loadNotifyTemplate(writeTemplate("notify.tmpl", tt.template))
+			if err != nil {
+				t.Fatalf("loadNotifyTemplate: %v", err)
+			}
+			n := notifier{
+				target:     tt.target,
+				webhookURL: server.URL,
+				template:   tmpl,
+				client:     server.Client(),
+			}
+			if err := n.notify(t.Context(), env); err != nil {
+				t.Fatalf("notify: %v", err)
+			}
+			if len(posted) != 1 || posted[0] != tt.want {
+				t.Errorf("got requests %q, want %q", posted, tt.want)
+			}
+		})
+	}
+
+	if _, err := loadNotifyTemplate(writeTemplate("bad.tmpl", "{{.Report")); err == nil {
+		t.Error("expected error for invalid template, got nil")
+	}
+	tmpl, err := loadNotifyTemplate(writeTemplate("missing.tmpl", "{{.Nope}}"))
+	if err != nil {
+		t.Fatalf("loadNotifyTemplate: %v", err)
+	}
+	n := notifier{
+		target:     notifySlack,
+		webhookURL: server.URL,
+		template:   tmpl,
+		client:     server.Client(),
+	}
+	if err := n.notify(t.Context(), env); err == nil {
+		t.Error("expected error for template referring to a missing field, got nil")
+	}
+}
@@ -0,0 +1,606 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// Output formats.
+const (
+	formatText       = "text"
+	formatJSON       = "json"
+	formatMarkdown   = "markdown"
+	formatCycloneDX  = "cyclonedx"
+	formatSPDX       = "spdx"
+	formatRenovate   = "renovate"
+	formatDependabot = "dependabot"
+	formatRDJSON     = "rdjson"
+	formatGHAMatrix  = "gha-matrix"
+	formatDOT        = "dot"
+)
+
+// printJSON writes the report and its metadata to w as JSON.
+func printJSON(w io.Writer, env check.Envelope) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(env); err != nil {
+		return fmt.Errorf("writing JSON: %w", err)
+	}
+	return nil
+}
+
+// goModDependencies returns the dependencies in rep read from go.mod, by
+// module path, for the details go.mod gives, such as positions, which
+// failures do not record.
+func goModDependencies(rep check.Report) map[string]check.Dependency {
+	deps := map[string]check.Dependency{}
+	for _, dep := range rep.Dependencies {
+		if dep.Span != nil {
+			deps[dep.Module] = dep
+		}
+	}
+	return deps
+}
+
+// describeRequirer describes the version of u's dependency that r requires.
+func describeRequirer(u check.Update, r check.Requirer) string {
+	if r.Requires == u.Current {
+		return "requires the current version: ask its maintainers to update"
+	}
+	return "requires " + r.Requires
+}
+
+// plural returns n followed by noun, pluralized with "s" unless n is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
+
+// shortSHALength matches the length of the revision in pseudo-versions.
+const shortSHALength = 12
+
+// printCommits writes the update's commit subjects, if any.
+func printCommits(w io.Writer, u check.Update) {
+	for _, commit := range u.Commits {
+		sha := commit.SHA
+		if len(sha) > shortSHALength {
+			sha = sha[:shortSHALength]
+		}
+		fmt.Fprintf(w, "      %s %s\n", sha, commit.Subject)
+	}
+	if len(u.Commits) > 0 && u.CommitsBehind > len(u.Commits) {
+		fmt.Fprintf(w, "      ... and %d more\n", u.CommitsBehind-len(u.Commits))
+	}
+}
+
+// printSeverity writes the update's severity, unless it is low.
+func printSeverity(w io.Writer, severity check.Severity, colors colorizer) {
+	label := string(severity)
+	switch severity {
+	case check.SeverityMedium:
+		label = colors.yellow(label)
+	case check.SeverityHigh, check.SeverityCritical:
+		label = colors.red(label)
+	default:
+		return
+	}
+	fmt.Fprintf(w, "    severity: %s\n", label)
+}
+
+// printRisk writes the update's risk label, if it was classified.
+func printRisk(w io.Writer, risk check.Risk, colors colorizer) {
+	label := string(risk)
+	switch risk {
+	case check.RiskUnknown:
+		return
+	case check.RiskBreaking:
+		label = colors.red(label)
+	case check.RiskFeature:
+		label = colors.yellow(label)
+	case check.RiskPatch:
+		label = colors.green(label)
+	}
+	fmt.Fprintf(w, "    risk: %s\n", label)
+}
+
+// printAuthors writes the authors of an update's commits, if known, noting
+// when they are all bots.
+func printAuthors(w io.Writer, authors []string, colors colorizer) {
+	if len(authors) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "    authors: %s", strings.Join(authors, ", "))
+	if !slices.ContainsFunc(authors, func(a string) bool { return !check.IsBot(a) }) {
+		fmt.Fprintf(w, " (%s)", colors.yellow("bot commits only"))
+	}
+	fmt.Fprintln(w)
+}
+
+// maxDirectories limits how many changed directories are listed per update.
+const maxDirectories = 5
+
+// printChanges writes a summary of the changed files, if there is one.
+func printChanges(w io.Writer, changes *check.ChangeSummary, colors colorizer) {
+	if changes == nil {
+		return
+	}
+
+	dirs := changes.Directories
+	more := ""
+	if len(dirs) > maxDirectories {
+		more = fmt.Sprintf(" and %d more", len(dirs)-maxDirectories)
+		dirs = dirs[:maxDirectories]
+	}
+	fmt.Fprintf(
+		w,
+		"    changed %s (+%d -%d)",
+		plural(changes.Files, "file"),
+		changes.Additions,
+		changes.Deletions,
+	)
+	if len(dirs) > 0 {
+		fmt.Fprintf(w, " in %s%s", strings.Join(dirs, ", "), more)
+	}
+	fmt.Fprintln(w)
+
+	var touches []string
+	if changes.GoModChanged {
+		touches = append(touches, "go.mod/go.sum")
+	}
+	if changes.APIChanged {
+		touches = append(touches, "exported API files")
+	}
+	if len(touches) > 0 {
+		fmt.Fprintf(w, "    touches %s\n", colors.yellow(strings.Join(touches, " and ")))
+	}
+}
+
+// maxIncompatibleChanges limits how many incompatible API changes are listed
+// per update.
+const maxIncompatibleChanges = 20
+
+// printCompatibility writes the API compatibility assessment, if there is
+// one.
+func printCompatibility(w io.Writer, compat *check.Compatibility, colors colorizer) {
+	if compat == nil {
+		return
+	}
+	if compat.Compatible {
+		fmt.Fprintf(w, "    API: %s\n", colors.green("compatible"))
+		return
+	}
+
+	fmt.Fprintf(
+		w,
+		"    API: %s\n",
+		colors.red(plural(len(compat.Incompatible), "incompatible change")),
+	)
+	changes := compat.Incompatible
+	if len(changes) > maxIncompatibleChanges {
+		changes = changes[:maxIncompatibleChanges]
+	}
+	for _, change := range changes {
+		fmt.Fprintf(w, "      %s\n", change)
+	}
+	if len(compat.Incompatible) > len(changes) {
+		fmt.Fprintf(w, "      ... and %d more\n", len(compat.Incompatible)-len(changes))
+	}
+}
+
+// printLicenseChange writes how the update changes the module's license, if
+// it does.
+func printLicenseChange(w io.Writer, change *check.LicenseChange, colors colorizer) {
+	if change == nil {
+		return
+	}
+	if change.Current != change.Latest {
+		fmt.Fprintf(
+			w,
+			"    %s: %s -> %s\n",
+			colors.red("license changed"),
+			change.Current,
+			change.Latest,
+		)
+		return
+	}
+	fmt.Fprintf(w, "    %s (%s)\n", colors.yellow("license text changed"), change.Latest)
+}
+
+// maxReleaseNoteLines limits how many lines of release notes are shown per
+// update.
+const maxReleaseNoteLines = 15
+
+// printReleaseNotes writes an update's release notes, if any, indented.
+func printReleaseNotes(w io.Writer, notes string) {
+	if notes == "" {
+		return
+	}
+	fmt.Fprintln(w, "    release notes:")
+	lines := strings.Split(notes, "\n")
+	shown := lines[:min(len(lines), maxReleaseNoteLines)]
+	for _, line := range shown {
+		if line == "" {
+			fmt.Fprintln(w)
+			continue
+		}
+		fmt.Fprintf(w, "      %s\n", line)
+	}
+	if len(lines) > len(shown) {
+		fmt.Fprintf(w, "      ... and %s\n", plural(len(lines)-len(shown), "more line"))
+	}
+}
+
+// printVulnerabilities writes the known vulnerabilities affecting an
+// update's current version, if any.
+func printVulnerabilities(w io.Writer, vulns []check.Vulnerability, colors colorizer) {
+	if len(vulns) == 0 {
+		return
+	}
+	count := "1 known vulnerability"
+	if len(vulns) > 1 {
+		count = fmt.Sprintf("%d known vulnerabilities", len(vulns))
+	}
+	fmt.Fprintf(w, "    current version is affected by %s:\n", colors.red(count))
+	for _, v := range vulns {
+		status := colors.green("fixed by update")
+		if !v.FixedInLatest {
+			status = colors.red("not fixed by update")
+		}
+		fmt.Fprintf(w, "      %s", v.ID)
+		if v.Severity != "" {
+			fmt.Fprintf(w, " [%s]", v.Severity)
+		}
+		fmt.Fprintf(w, " (%s)", status)
+		if v.Summary != "" {
+			fmt.Fprintf(w, ": %s", v.Summary)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// describeAbandoned describes why a dependency's repository is considered
+// abandoned, e.g. "archived, last commit 2021-03-04".
+func describeAbandoned(a check.Abandoned, colors colorizer) string {
+	var parts []string
+	if a.Archived {
+		parts = append(parts, colors.red("archived"))
+	}
+	if !a.LastCommit.IsZero() {
+		lastCommit := a.LastCommit.UTC().Format(time.DateOnly)
+		parts = append(parts, "last commit "+colors.yellow(lastCommit))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeVendorMismatch describes how a dependency's vendored version
+// differs from the one go.mod requires.
+func describeVendorMismatch(m check.VendorMismatch, colors colorizer) string {
+	relation := "differs from"
+	if m.Older {
+		relation = "is older than"
+	}
+	return fmt.Sprintf(
+		"vendored %s %s required %s",
+		colors.red(m.Vendored),
+		relation,
+		colors.green(m.Required),
+	)
+}
+
+// printSignature writes the signature status of the update's latest commit,
+// if it was checked.
+func printSignature(w io.Writer, sig *check.Signature, colors colorizer) {
+	switch {
+	case sig == nil:
+	case sig.Verified:
+		fmt.Fprintf(w, "    signature: %s\n", colors.green("verified"))
+	case sig.Reason != "":
+		fmt.Fprintf(w, "    signature: %s (%s)\n", colors.yellow("not verified"), sig.Reason)
+	default:
+		fmt.Fprintf(w, "    signature: %s\n", colors.yellow("not verified"))
+	}
+}
+
+// printPinVanished writes a warning if the commit the dependency is pinned to
+// no longer exists upstream, suggesting re-pinning to the latest version.
+func printPinVanished(w io.Writer, u check.Update, colors colorizer) {
+	if !u.PinVanished {
+		return
+	}
+	fmt.Fprintf(
+		w,
+		"    %s (history rewritten?): re-pin with go get %s@%s\n",
+		colors.red("pinned commit no longer exists upstream"),
+		u.Module,
+		u.Latest,
+	)
+}
+
+// printPathChange writes whether the update's module path changed, in which
+// case importers should switch to the new path rather than update.
+func printPathChange(w io.Writer, u check.Update, colors colorizer) {
+	switch {
+	case u.DeclaredPath != "":
+		fmt.Fprintf(
+			w,
+			"    %s to %s (declared in latest go.mod): consider updating import path\n",
+			colors.red("module path changed"),
+			u.DeclaredPath,
+		)
+	case u.MovedTo != "":
+		fmt.Fprintf(
+			w,
+			"    %s, module path may now be %s: consider updating import path\n",
+			colors.yellow("repository moved"),
+			u.MovedTo,
+		)
+	}
+}
+
+// printTextUpdate writes an update in the human-readable report.
+func printTextUpdate(w io.Writer, u check.Update, colors colorizer) {
+	fmt.Fprintf(
+		w,
+		"  %s: %s -> %s\n",
+		colors.bold(u.Module),
+		colors.red(u.Current),
+		colors.green(u.Latest),
+	)
+	if u.Age() > 0 {
+		fmt.Fprintf(
+			w,
+			"    current commit is %s older than latest\n",
+			colors.yellow(formatAge(u.CurrentTime, u.LatestTime)),
+		)
+	}
+	if u.Source != "" {
+		fmt.Fprintf(w, "    pinned in %s\n", u.Source)
+	}
+	if u.Branch != "" {
+		fmt.Fprintf(w, "    on branch %s\n", u.Branch)
+	}
+	printSeverity(w, u.Severity, colors)
+	printPinVanished(w, u, colors)
+	printPathChange(w, u, colors)
+	printVulnerabilities(w, u.Vulnerabilities, colors)
+	switch u.SumDB {
+	case check.SumDBUnverified:
+		fmt.Fprintf(
+			w,
+			"    %s\n",
+			colors.red("latest version is not verified by the checksum database"),
+		)
+	case check.SumDBExempt:
+		fmt.Fprintln(
+			w,
+			"    checksum database: exempt (GONOSUMDB, GOPRIVATE, or GOSUMDB=off)",
+		)
+	}
+	printSignature(w, u.Signature, colors)
+	printRisk(w, u.Risk, colors)
+	if u.CommitsBehind > 0 {
+		fmt.Fprintf(
+			w,
+			"    behind by %s\n",
+			colors.yellow(plural(u.CommitsBehind, "commit")),
+		)
+	}
+	printCommits(w, u)
+	printAuthors(w, u.Authors, colors)
+	if u.TestOnly {
+		fmt.Fprintln(w, "    only needed by tests")
+	}
+	if len(u.ImportChain) > 0 {
+		fmt.Fprintf(w, "    imported via: %s\n", strings.Join(u.ImportChain, " -> "))
+	}
+	for _, r := range u.RequiredBy {
+		fmt.Fprintf(
+			w,
+			"    required by: %s@%s (%s)\n",
+			r.Module,
+			r.Version,
+			describeRequirer(u, r),
+		)
+	}
+	printChanges(w, u.Changes, colors)
+	printCompatibility(w, u.Compatibility, colors)
+	printLicenseChange(w, u.LicenseChange, colors)
+	printReleaseNotes(w, u.ReleaseNotes)
+	if u.CompareURL != "" {
+		fmt.Fprintf(w, "    compare: %s\n", u.CompareURL)
+	}
+}
+
+// describeBehind describes how many commits a fork is behind its upstream,
+// highlighted if it is behind at all.
+func describeBehind(f *check.ForkStatus, colors colorizer) string {
+	behind := strconv.Itoa(f.Behind)
+	if f.Behind > 0 {
+		return colors.yellow(behind)
+	}
+	return behind
+}
+
+// printTextFailures lists the dependencies that could not be checked.
+func printTextFailures(w io.Writer, failures []check.Failure, colors colorizer) {
+	fmt.Fprintln(w, "Failed to check:")
+	for _, f := range failures {
+		fmt.Fprintf(w, "  %s: %v\n", colors.bold(f.Module), f.Err)
+	}
+}
+
+// printTextTagged lists the newer releases of tagged requirements (-all),
+// preceded by a blank line, if there are any.
+func printTextTagged(w io.Writer, updates []check.TaggedUpdate, colors colorizer) {
+	if len(updates) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Newer releases of tagged dependencies:")
+	for _, u := range updates {
+		fmt.Fprintf(
+			w,
+			"  %s: %s -> %s\n",
+			colors.bold(u.Module),
+			colors.red(u.Current),
+			colors.green(u.Latest),
+		)
+	}
+}
+
+// printTextToolchain reports an outdated Go toolchain (-toolchain), preceded
+// by a blank line. Nothing is printed if it is current.
+func printTextToolchain(w io.Writer, s *check.ToolchainStatus, colors colorizer) {
+	if s == nil || !s.Outdated {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(
+		w,
+		"Go toolchain out of date: %s, latest %s",
+		colors.red(s.Current()),
+		colors.green(s.Latest),
+	)
+	if s.Unsupported {
+		fmt.Fprint(w, " (no longer supported)")
+	}
+	fmt.Fprintln(w)
+}
+
+// printText writes the human-readable report to w, with updates grouped by
+// the -group-by key, if any.
+func printText(w io.Writer, rep check.Report, groupBy string, colors colorizer) {
+	if len(rep.Dependencies) == 0 {
+		fmt.Fprintln(w, "No pseudo-versioned dependencies found in go.mod.")
+		// Tagged requirements and the toolchain are checked whether or not
+		// there are any pseudo-versioned dependencies.
+		if len(rep.Failures) > 0 {
+			fmt.Fprintln(w)
+			printTextFailures(w, rep.Failures, colors)
+		}
+		printTextTagged(w, rep.TaggedUpdates, colors)
+		printTextToolchain(w, rep.Toolchain, colors)
+		return
+	}
+
+	fmt.Fprintln(w, "Pseudo-versioned dependencies in go.mod:")
+	for _, dep := range rep.Dependencies {
+		fmt.Fprintf(w, "  %s", dep.Module)
+		if dep.Source != "" {
+			fmt.Fprintf(w, " (in %s)", dep.Source)
+		}
+		if dep.Replaces != "" {
+			fmt.Fprintf(w, " (replaces %s", dep.Replaces)
+			if f := dep.Fork; f != nil {
+				fmt.Fprintf(w, "; %d ahead, %s behind upstream", f.Ahead, describeBehind(f, colors))
+			}
+			fmt.Fprint(w, ")")
+		}
+		switch {
+		case dep.Tags == check.TagStatusNeverTagged:
+			fmt.Fprint(w, " (never tagged)")
+		case dep.BaseTagOutdated():
+			fmt.Fprintf(
+				w,
+				" (based on %s, %s now released)",
+				dep.BaseTag,
+				colors.yellow(dep.LatestTag),
+			)
+		case dep.Tags == check.TagStatusTagged:
+			fmt.Fprintf(w, " (has tags, latest %s)", colors.green(dep.LatestTag))
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+
+	if len(rep.Updates) > 0 {
+		for i, g := range groupUpdates(rep.Updates, groupBy) {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			if g.name == "" {
+				fmt.Fprintln(w, "Updates available:")
+			} else {
+				fmt.Fprintf(w, "Updates available on %s:\n", colors.bold(g.name))
+			}
+			for _, u := range g.updates {
+				printTextUpdate(w, u, colors)
+			}
+		}
+	} else if len(rep.Failures) == 0 {
+		fmt.Fprintln(w, "No updates found for pseudo-versioned dependencies.")
+	}
+
+	if len(rep.Abandoned) > 0 {
+		if len(rep.Updates) > 0 || len(rep.Failures) == 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, "Abandoned upstream (consider forking or replacing rather than updating):")
+		for _, a := range rep.Abandoned {
+			fmt.Fprintf(w, "  %s: %s\n", colors.bold(a.Module), describeAbandoned(a, colors))
+		}
+	}
+
+	if len(rep.Failures) > 0 {
+		if len(rep.Updates) > 0 || len(rep.Abandoned) > 0 {
+			fmt.Fprintln(w)
+		}
+		printTextFailures(w, rep.Failures, colors)
+	}
+
+	if len(rep.VendorMismatches) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Vendored copies out of date (run go mod vendor):")
+		for _, m := range rep.VendorMismatches {
+			fmt.Fprintf(w, "  %s: %s\n", colors.bold(m.Module), describeVendorMismatch(m, colors))
+		}
+	}
+
+	printTextTagged(w, rep.TaggedUpdates, colors)
+	printTextToolchain(w, rep.Toolchain, colors)
+
+	if s := rep.Summary; s != nil {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Summary:")
+		fmt.Fprintf(
+			w,
+			"  %d pseudo-versioned, %d stale, %d failed\n",
+			s.Pinned,
+			s.Stale,
+			s.Failed,
+		)
+		if s.Stale > 0 {
+			fmt.Fprintf(w, "  median staleness: %s\n", formatSeconds(s.MedianAgeSeconds))
+			fmt.Fprintf(
+				w,
+				"  most out of date: %s (%s)\n",
+				colors.bold(s.Stalest),
+				colors.yellow(formatSeconds(s.MaxAgeSeconds)),
+			)
+		}
+	}
+}
+
+// addSummary sets the report's freshness statistics.
+func addSummary(rep *check.Report) {
+	s := rep.Summarize()
+	rep.Summary = &s
+}
+
+// formatSeconds describes a staleness age in seconds in whole days.
+func formatSeconds(seconds int64) string {
+	days := int(seconds / (24 * 60 * 60))
+	if days == 0 {
+		return "less than a day"
+	}
+	return plural(days, "day")
+}
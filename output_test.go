@@ -0,0 +1,718 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestPrintText(t *testing.T) {
+	deps := []check.Dependency{
+		{Module: "go4.org/netipx", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+		{Module: "github.com/example/module", Version: "v0.0.0-20231101000000-bbbbbbbbbbbb"},
+	}
+
+	tests := []struct {
+		name      string
+		deps      []check.Dependency
+		updates   []check.Update
+		failures  []check.Failure
+		abandoned []check.Abandoned
+		vendor    []check.VendorMismatch
+		tagged    []check.TaggedUpdate
+		toolchain *check.ToolchainStatus
+		summary   bool
+		groupBy   string
+		colors    colorizer
+		want      string
+	}{
+		{
+			name: "no dependencies",
+			want: "No pseudo-versioned dependencies found in go.mod.\n",
+		},
+		{
+			name: "no updates",
+			deps: deps,
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"No updates found for pseudo-versioned dependencies.\n",
+		},
+		{
+			name: "updates with color",
+			deps: deps,
+			updates: []check.Update{
+				{
+					Module:  "go4.org/netipx",
+					Current: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:  "v0.0.0-20231201000000-cccccccccccc",
+				},
+			},
+			colors: colorizer{enabled: true},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"Updates available:\n" +
+				"  \x1b[1mgo4.org/netipx\x1b[0m: " +
+				"\x1b[31mv0.0.0-20231101000000-aaaaaaaaaaaa\x1b[0m -> " +
+				"\x1b[32mv0.0.0-20231201000000-cccccccccccc\x1b[0m\n",
+		},
+		{
+			name: "tagged updates without pseudo-versions",
+			tagged: []check.TaggedUpdate{
+				{Module: "github.com/example/tagged", Current: "v1.2.0", Latest: "v1.10.0"},
+			},
+			failures: []check.Failure{
+				{Module: "github.com/example/broken", Err: errors.New("proxy unavailable")},
+			},
+			want: "No pseudo-versioned dependencies found in go.mod.\n" +
+				"\n" +
+				"Failed to check:\n" +
+				"  github.com/example/broken: proxy unavailable\n" +
+				"\n" +
+				"Newer releases of tagged dependencies:\n" +
+				"  github.com/example/tagged: v1.2.0 -> v1.10.0\n",
+		},
+		{
+			name: "tagged updates",
+			deps: deps,
+			tagged: []check.TaggedUpdate{
+				{Module: "github.com/example/tagged", Current: "v1.2.0", Latest: "v1.10.0"},
+			},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"No updates found for pseudo-versioned dependencies.\n" +
+				"\n" +
+				"Newer releases of tagged dependencies:\n" +
+				"  github.com/example/tagged: v1.2.0 -> v1.10.0\n",
+		},
+		{
+			name: "outdated toolchain without pseudo-versions",
+			toolchain: &check.ToolchainStatus{
+				Go:          "1.20",
+				Toolchain:   "go1.21.5",
+				Latest:      "go1.23.2",
+				Outdated:    true,
+				Unsupported: true,
+			},
+			want: "No pseudo-versioned dependencies found in go.mod.\n" +
+				"\n" +
+				"Go toolchain out of date: go1.21.5, latest go1.23.2 (no longer supported)\n",
+		},
+		{
+			name:      "current toolchain",
+			deps:      deps,
+			toolchain: &check.ToolchainStatus{Go: "1.23.2", Latest: "go1.23.2"},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"No updates found for pseudo-versioned dependencies.\n",
+		},
+		{
+			name: "fork",
+			deps: []check.Dependency{
+				{
+					Module:   "github.com/ourorg/fork",
+					Version:  "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Replaces: "github.com/upstream/fork",
+					Fork:     &check.ForkStatus{Ahead: 3, Behind: 12},
+				},
+				{
+					Module:   "github.com/ourorg/other",
+					Version:  "v0.0.0-20231101000000-bbbbbbbbbbbb",
+					Replaces: "github.com/upstream/other",
+				},
+			},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  github.com/ourorg/fork (replaces github.com/upstream/fork; " +
+				"3 ahead, 12 behind upstream)\n" +
+				"  github.com/ourorg/other (replaces github.com/upstream/other)\n" +
+				"\n" +
+				"No updates found for pseudo-versioned dependencies.\n",
+		},
+		{
+			name: "release branch",
+			deps: deps[:1],
+			updates: []check.Update{
+				{
+					Module:  "go4.org/netipx",
+					Current: "v1.29.3-0.20231101000000-aaaaaaaaaaaa",
+					Latest:  "v1.29.5-0.20231201000000-cccccccccccc",
+					Branch:  "release-1.29",
+				},
+			},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"\n" +
+				"Updates available:\n" +
+				"  go4.org/netipx: v1.29.3-0.20231101000000-aaaaaaaaaaaa -> " +
+				"v1.29.5-0.20231201000000-cccccccccccc\n" +
+				"    on branch release-1.29\n",
+		},
+		{
+			name: "tool pin",
+			deps: []check.Dependency{
+				deps[0],
+				{
+					Module:  "golang.org/x/tools",
+					Version: "v0.0.0-20231101000000-dddddddddddd",
+					Source:  "Dockerfile:2",
+				},
+			},
+			updates: []check.Update{
+				{
+					Module:  "golang.org/x/tools",
+					Current: "v0.0.0-20231101000000-dddddddddddd",
+					Latest:  "v0.0.0-20231201000000-eeeeeeeeeeee",
+					Source:  "Dockerfile:2",
+				},
+			},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  golang.org/x/tools (in Dockerfile:2)\n" +
+				"\n" +
+				"Updates available:\n" +
+				"  golang.org/x/tools: v0.0.0-20231101000000-dddddddddddd -> " +
+				"v0.0.0-20231201000000-eeeeeeeeeeee\n" +
+				"    pinned in Dockerfile:2\n",
+		},
+		{
+			name: "vendor mismatches",
+			deps: deps,
+			vendor: []check.VendorMismatch{
+				{
+					Module:   "go4.org/netipx",
+					Required: "v0.0.0-20231201000000-cccccccccccc",
+					Vendored: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Older:    true,
+				},
+			},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"No updates found for pseudo-versioned dependencies.\n" +
+				"\n" +
+				"Vendored copies out of date (run go mod vendor):\n" +
+				"  go4.org/netipx: vendored v0.0.0-20231101000000-aaaaaaaaaaaa is older than " +
+				"required v0.0.0-20231201000000-cccccccccccc\n",
+		},
+		{
+			name: "dependencies with tags",
+			deps: []check.Dependency{
+				{
+					Module:    "go4.org/netipx",
+					Version:   "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Tags:      check.TagStatusTagged,
+					LatestTag: "v0.1.0",
+				},
+				{
+					Module:  "github.com/example/module",
+					Version: "v0.0.0-20231101000000-bbbbbbbbbbbb",
+					Tags:    check.TagStatusNeverTagged,
+				},
+				{
+					Module:    "github.com/example/based",
+					Version:   "v1.1.1-0.20231101000000-bbbbbbbbbbbb",
+					Tags:      check.TagStatusTagged,
+					LatestTag: "v1.4.0",
+					BaseTag:   "v1.1.0",
+				},
+			},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx (has tags, latest v0.1.0)\n" +
+				"  github.com/example/module (never tagged)\n" +
+				"  github.com/example/based (based on v1.1.0, v1.4.0 now released)\n" +
+				"\n" +
+				"No updates found for pseudo-versioned dependencies.\n",
+		},
+		{
+			name: "updates grouped by host",
+			deps: deps,
+			updates: []check.Update{
+				{
+					Module:  "go4.org/netipx",
+					Current: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:  "v0.0.0-20231201000000-cccccccccccc",
+				},
+				{
+					Module:  "github.com/example/module",
+					Current: "v0.0.0-20231101000000-bbbbbbbbbbbb",
+					Latest:  "v0.0.0-20231201000000-dddddddddddd",
+				},
+			},
+			groupBy: groupHost,
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"Updates available on go4.org:\n" +
+				"  go4.org/netipx: v0.0.0-20231101000000-aaaaaaaaaaaa -> " +
+				"v0.0.0-20231201000000-cccccccccccc\n" +
+				"\n" +
+				"Updates available on github.com:\n" +
+				"  github.com/example/module: v0.0.0-20231101000000-bbbbbbbbbbbb -> " +
+				"v0.0.0-20231201000000-dddddddddddd\n",
+		},
+		{
+			name: "updates with age and commits behind",
+			deps: deps,
+			updates: []check.Update{
+				{
+					Module:        "go4.org/netipx",
+					Current:       "v0.0.0-20230719000000-aaaaaaaaaaaa",
+					Latest:        "v0.0.0-20231201000000-cccccccccccc",
+					CurrentTime:   time.Date(2023, 7, 19, 0, 0, 0, 0, time.UTC),
+					LatestTime:    time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+					CommitsBehind: 37,
+				},
+			},
+			colors: colorizer{enabled: true},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"Updates available:\n" +
+				"  \x1b[1mgo4.org/netipx\x1b[0m: " +
+				"\x1b[31mv0.0.0-20230719000000-aaaaaaaaaaaa\x1b[0m -> " +
+				"\x1b[32mv0.0.0-20231201000000-cccccccccccc\x1b[0m\n" +
+				"    current commit is \x1b[33m4 months 12 days\x1b[0m older than latest\n" +
+				"    behind by \x1b[33m37 commits\x1b[0m\n",
+		},
+		{
+			name: "updates with commits, authors, changes, and compare URL",
+			deps: deps,
+			updates: []check.Update{
+				{
+					Module:        "go4.org/netipx",
+					Current:       "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:        "v0.0.0-20231101000000-cccccccccccc",
+					CommitsBehind: 3,
+					Commits: []check.Commit{
+						{SHA: "cccccccccccc1234567890", Subject: "Fix the bug"},
+						{SHA: "bbbbbbbbbbbb1234567890", Subject: "Add a feature"},
+					},
+					Risk:    check.RiskFeature,
+					Authors: []string{"alice", "dependabot[bot]"},
+					Changes: &check.ChangeSummary{
+						Files:        3,
+						Additions:    40,
+						Deletions:    2,
+						Directories:  []string{".", "internal/a"},
+						GoModChanged: true,
+						APIChanged:   true,
+					},
+					CompareURL: "https://github.com/inetaf/netipx/compare/" +
+						"aaaaaaaaaaaa...cccccccccccc",
+				},
+			},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"Updates available:\n" +
+				"  go4.org/netipx: v0.0.0-20231101000000-aaaaaaaaaaaa -> " +
+				"v0.0.0-20231101000000-cccccccccccc\n" +
+				"    risk: feature\n" +
+				"    behind by 3 commits\n" +
+				"      cccccccccccc Fix the bug\n" +
+				"      bbbbbbbbbbbb Add a feature\n" +
+				"      ... and 1 more\n" +
+				"    authors: alice, dependabot[bot]\n" +
+				"    changed 3 files (+40 -2) in ., internal/a\n" +
+				"    touches go.mod/go.sum and exported API files\n" +
+				"    compare: https://github.com/inetaf/netipx/compare/" +
+				"aaaaaaaaaaaa...cccccccccccc\n",
+		},
+		{
+			name: "updates with API compatibility and license changes",
+			deps: deps,
+			updates: []check.Update{
+				{
+					Module:        "go4.org/netipx",
+					Current:       "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:        "v0.0.0-20231101000000-cccccccccccc",
+					Compatibility: &check.Compatibility{Compatible: true},
+				},
+				{
+					Module:  "github.com/example/module",
+					Current: "v0.0.0-20231101000000-bbbbbbbbbbbb",
+					Latest:  "v0.0.0-20231101000000-dddddddddddd",
+					LicenseChange: &check.LicenseChange{
+						Current:     "MIT",
+						Latest:      "AGPL-3.0",
+						TextChanged: true,
+					},
+					Compatibility: &check.Compatibility{
+						Incompatible: []string{
+							"github.com/example/module.Func: removed",
+							"github.com/example/module.T.Name: changed from string to int",
+						},
+					},
+				},
+			},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"Updates available:\n" +
+				"  go4.org/netipx: v0.0.0-20231101000000-aaaaaaaaaaaa -> " +
+				"v0.0.0-20231101000000-cccccccccccc\n" +
+				"    API: compatible\n" +
+				"  github.com/example/module: v0.0.0-20231101000000-bbbbbbbbbbbb -> " +
+				"v0.0.0-20231101000000-dddddddddddd\n" +
+				"    API: 2 incompatible changes\n" +
+				"      github.com/example/module.Func: removed\n" +
+				"      github.com/example/module.T.Name: changed from string to int\n" +
+				"    license changed: MIT -> AGPL-3.0\n",
+		},
+		{
+			name: "updates with release notes",
+			deps: deps,
+			updates: []check.Update{
+				{
+					Module:       "go4.org/netipx",
+					Current:      "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:       "v0.0.0-20231101000000-cccccccccccc",
+					ReleaseNotes: "## v1.1.0\n\n* Add a feature.",
+				},
+			},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"Updates available:\n" +
+				"  go4.org/netipx: v0.0.0-20231101000000-aaaaaaaaaaaa -> " +
+				"v0.0.0-20231101000000-cccccccccccc\n" +
+				"    release notes:\n" +
+				"      ## v1.1.0\n" +
+				"\n" +
+				"      * Add a feature.\n",
+		},
+		{
+			name: "updates with vulnerabilities",
+			deps: deps,
+			updates: []check.Update{
+				{
+					Module:  "go4.org/netipx",
+					Current: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:  "v0.0.0-20231101000000-cccccccccccc",
+					Vulnerabilities: []check.Vulnerability{
+						{ID: "GO-2023-0001", Summary: "Panic on bad input", FixedInLatest: true},
+						{ID: "GO-2023-0002", Severity: "HIGH"},
+					},
+					SumDB:     check.SumDBUnverified,
+					Signature: &check.Signature{Reason: "unknown_key"},
+				},
+			},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"Updates available:\n" +
+				"  go4.org/netipx: v0.0.0-20231101000000-aaaaaaaaaaaa -> " +
+				"v0.0.0-20231101000000-cccccccccccc\n" +
+				"    current version is affected by 2 known vulnerabilities:\n" +
+				"      GO-2023-0001 (fixed by update): Panic on bad input\n" +
+				"      GO-2023-0002 [HIGH] (not fixed by update)\n" +
+				"    latest version is not verified by the checksum database\n" +
+				"    signature: not verified (unknown_key)\n",
+		},
+		{
+			name: "updates with module path changes",
+			deps: deps,
+			updates: []check.Update{
+				{
+					Module:       "go4.org/netipx",
+					Current:      "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:       "v0.0.0-20231101000000-cccccccccccc",
+					DeclaredPath: "example.com/netipx",
+				},
+				{
+					Module:  "github.com/example/module",
+					Current: "v0.0.0-20231101000000-bbbbbbbbbbbb",
+					Latest:  "v0.0.0-20231101000000-cccccccccccc",
+					MovedTo: "github.com/other/module",
+				},
+			},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"Updates available:\n" +
+				"  go4.org/netipx: v0.0.0-20231101000000-aaaaaaaaaaaa -> " +
+				"v0.0.0-20231101000000-cccccccccccc\n" +
+				"    module path changed to example.com/netipx (declared in latest go.mod): " +
+				"consider updating import path\n" +
+				"  github.com/example/module: v0.0.0-20231101000000-bbbbbbbbbbbb -> " +
+				"v0.0.0-20231101000000-cccccccccccc\n" +
+				"    repository moved, module path may now be github.com/other/module: " +
+				"consider updating import path\n",
+		},
+		{
+			name: "update with vanished pin",
+			deps: deps,
+			updates: []check.Update{
+				{
+					Module:      "github.com/example/module",
+					Current:     "v0.0.0-20231101000000-bbbbbbbbbbbb",
+					Latest:      "v0.0.0-20231101000000-cccccccccccc",
+					PinVanished: true,
+				},
+			},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"Updates available:\n" +
+				"  github.com/example/module: v0.0.0-20231101000000-bbbbbbbbbbbb -> " +
+				"v0.0.0-20231101000000-cccccccccccc\n" +
+				"    pinned commit no longer exists upstream (history rewritten?): re-pin with " +
+				"go get github.com/example/module@v0.0.0-20231101000000-cccccccccccc\n",
+		},
+		{
+			name: "failures",
+			deps: deps,
+			failures: []check.Failure{
+				{Module: "github.com/example/module", Err: errors.New("server error")},
+			},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"Failed to check:\n" +
+				"  github.com/example/module: server error\n",
+		},
+		{
+			name: "abandoned and failures",
+			deps: deps,
+			failures: []check.Failure{
+				{Module: "github.com/example/module", Err: errors.New("server error")},
+			},
+			abandoned: []check.Abandoned{
+				{
+					Module:     "go4.org/netipx",
+					Archived:   true,
+					LastCommit: time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+				},
+			},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"Abandoned upstream (consider forking or replacing rather than updating):\n" +
+				"  go4.org/netipx: archived, last commit 2021-03-04\n" +
+				"\n" +
+				"Failed to check:\n" +
+				"  github.com/example/module: server error\n",
+		},
+		{
+			name: "severity",
+			deps: deps,
+			updates: []check.Update{
+				{
+					Module:   "go4.org/netipx",
+					Current:  "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:   "v0.0.0-20231201000000-cccccccccccc",
+					Severity: check.SeverityCritical,
+				},
+				{
+					Module:   "github.com/example/module",
+					Current:  "v0.0.0-20231101000000-bbbbbbbbbbbb",
+					Latest:   "v0.0.0-20231201000000-dddddddddddd",
+					Severity: check.SeverityLow,
+				},
+			},
+			colors: colorizer{enabled: true},
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"Updates available:\n" +
+				"  \x1b[1mgo4.org/netipx\x1b[0m: " +
+				"\x1b[31mv0.0.0-20231101000000-aaaaaaaaaaaa\x1b[0m -> " +
+				"\x1b[32mv0.0.0-20231201000000-cccccccccccc\x1b[0m\n" +
+				"    severity: \x1b[31mcritical\x1b[0m\n" +
+				"  \x1b[1mgithub.com/example/module\x1b[0m: " +
+				"\x1b[31mv0.0.0-20231101000000-bbbbbbbbbbbb\x1b[0m -> " +
+				"\x1b[32mv0.0.0-20231201000000-dddddddddddd\x1b[0m\n",
+		},
+		{
+			name: "summary",
+			deps: deps,
+			updates: []check.Update{
+				{
+					Module:      "go4.org/netipx",
+					Current:     "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:      "v0.0.0-20231201000000-cccccccccccc",
+					CurrentTime: time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC),
+					LatestTime:  time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			failures: []check.Failure{
+				{Module: "github.com/example/module", Err: errors.New("server error")},
+			},
+			summary: true,
+			want: "Pseudo-versioned dependencies in go.mod:\n" +
+				"  go4.org/netipx\n" +
+				"  github.com/example/module\n" +
+				"\n" +
+				"Updates available:\n" +
+				"  go4.org/netipx: v0.0.0-20231101000000-aaaaaaaaaaaa -> " +
+				"v0.0.0-20231201000000-cccccccccccc\n" +
+				"    current commit is 1 month older than latest\n" +
+				"\n" +
+				"Failed to check:\n" +
+				"  github.com/example/module: server error\n" +
+				"\n" +
+				"Summary:\n" +
+				"  2 pseudo-versioned, 1 stale, 1 failed\n" +
+				"  median staleness: 30 days\n" +
+				"  most out of date: go4.org/netipx (30 days)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			rep := check.Report{
+				Dependencies:     tt.deps,
+				Updates:          tt.updates,
+				Failures:         tt.failures,
+				Abandoned:        tt.abandoned,
+				VendorMismatches: tt.vendor,
+				TaggedUpdates:    tt.tagged,
+				Toolchain:        tt.toolchain,
+			}
+			if tt.summary {
+				addSummary(&rep)
+			}
+			printText(&buf, rep, tt.groupBy, tt.colors)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintJSON(t *testing.T) {
+	rep := check.Report{
+		Dependencies: []check.Dependency{
+			{Module: "go4.org/netipx", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+			{Module: "example.com/gone", Version: "v0.0.0-20231101000000-bbbbbbbbbbbb"},
+		},
+		Updates: []check.Update{
+			{
+				Module:  "go4.org/netipx",
+				Current: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+				Latest:  "v0.0.0-20231201000000-cccccccccccc",
+			},
+		},
+		Failures: []check.Failure{
+			{
+				Module: "example.com/gone",
+				Err:    fmt.Errorf("example.com/gone@main: %w", check.ErrModuleNotFound),
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	env := check.Envelope{
+		SchemaVersion: check.SchemaVersion,
+		ToolVersion:   "v1.2.0",
+		GeneratedAt:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		GoModPath:     "go.mod",
+		Report:        rep,
+	}
+	if err := printJSON(&buf, env); err != nil {
+		t.Fatalf("printJSON: %v", err)
+	}
+
+	want := `{
+  "schema_version": 1,
+  "tool_version": "v1.2.0",
+  "generated_at": "2026-01-02T03:04:05Z",
+  "go_mod_path": "go.mod",
+  "dependencies": [
+    {
+      "module": "go4.org/netipx",
+      "version": "v0.0.0-20231101000000-aaaaaaaaaaaa"
+    },
+    {
+      "module": "example.com/gone",
+      "version": "v0.0.0-20231101000000-bbbbbbbbbbbb"
+    }
+  ],
+  "updates": [
+    {
+      "module": "go4.org/netipx",
+      "current": "v0.0.0-20231101000000-aaaaaaaaaaaa",
+      "latest": "v0.0.0-20231201000000-cccccccccccc"
+    }
+  ],
+  "failures": [
+    {
+      "module": "example.com/gone",
+      "error": "example.com/gone@main: module not found",
+      "code": "module_not_found"
+    }
+  ]
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrintAuthors(t *testing.T) {
+	tests := []struct {
+		authors []string
+		want    string
+	}{
+		{authors: nil, want: ""},
+		{authors: []string{"alice", "renovate[bot]"}, want: "    authors: alice, renovate[bot]\n"},
+		{
+			authors: []string{"dependabot[bot]", "renovate[bot]"},
+			want:    "    authors: dependabot[bot], renovate[bot] (bot commits only)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		printAuthors(&buf, tt.authors, colorizer{})
+		if got := buf.String(); got != tt.want {
+			t.Errorf("printAuthors(%q) = %q, want %q", tt.authors, got, tt.want)
+		}
+	}
+}
+
+// readGoMod writes gomod to a go.mod file and returns its path and the
+// pseudo-versioned dependencies read from it, by module path, with their
+// go.mod positions.
+func readGoMod(t *testing.T, gomod string) (string, map[string]check.Dependency) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "go.mod")
+	if err := os.WriteFile(path, []byte(gomod), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	found, err := check.FindPseudoVersionedDeps(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deps := map[string]check.Dependency{}
+	for _, dep := range found {
+		deps[dep.Module] = dep
+	}
+	return path, deps
+}
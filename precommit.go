@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// precommitCacheTTL is how long resolutions are cached with -precommit unless
+// -cache-ttl is given, so that most runs need no network access.
+const precommitCacheTTL = 7 * 24 * time.Hour
+
+// staleUpdates returns the report with only the updates whose current commit
+// is at least minAge older than the latest, and without failures.
+func staleUpdates(rep check.Report, minAge time.Duration) check.Report {
+	rep.Updates = slices.DeleteFunc(slices.Clone(rep.Updates), func(u check.Update) bool {
+		return u.Age() < minAge
+	})
+	rep.Failures = nil
+	return rep
+}
+
+// printPrecommit writes a line to w for each update in rep, which has been
+// filtered by staleUpdates, with the command that makes it, and a warning to
+// errOut for each failure in failures. Failures do not fail a pre-commit
+// hook, as they are usually caused by being offline.
+func printPrecommit(
+	w, errOut io.Writer,
+	rep check.Report,
+	failures []check.Failure,
+	goModPath string,
+	colors colorizer,
+) {
+	for _, u := range rep.Updates {
+		fmt.Fprintf(
+			w,
+			"%s: %s is pinned to a commit %s older than the latest; update with %s\n",
+			goModPath,
+			colors.bold(u.Module),
+			colors.yellow(formatAge(u.CurrentTime, u.LatestTime)),
+			updateCommand(u),
+		)
+	}
+	for _, f := range failures {
+		fmt.Fprintf(errOut, "%s: skipped %s: %v\n", goModPath, f.Module, f.Err)
+	}
+}
+
+// updateCommand returns the command that makes the update: go get, or for a
+// fork, which go get would not update, a go mod edit of its replace
+// directive.
+func updateCommand(u check.Update) string {
+	if u.Replaces != "" {
+		return fmt.Sprintf("go mod edit -replace=%s=%s@%s", u.Replaces, u.Module, u.Latest)
+	}
+	return fmt.Sprintf("go get %s@%s", u.Module, u.Latest)
+}
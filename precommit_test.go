@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestPrecommit(t *testing.T) {
+	pinned := time.Date(2023, 7, 19, 0, 0, 0, 0, time.UTC)
+	rep := check.Report{
+		Updates: []check.Update{
+			{
+				Module:      "go4.org/netipx",
+				Current:     "v0.0.0-20230719000000-aaaaaaaaaaaa",
+				Latest:      "v0.0.0-20231201000000-cccccccccccc",
+				CurrentTime: pinned,
+				LatestTime:  time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+			},
+			{
+				Module:      "github.com/fork/netipx",
+				Replaces:    "github.com/upstream/netipx",
+				Current:     "v0.0.0-20230719000000-aaaaaaaaaaaa",
+				Latest:      "v0.0.0-20231201000000-dddddddddddd",
+				CurrentTime: pinned,
+				LatestTime:  time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+			},
+			{
+				Module:      "example.com/recent",
+				Current:     "v0.0.0-20230719000000-aaaaaaaaaaaa",
+				Latest:      "v0.0.0-20230801000000-bbbbbbbbbbbb",
+				CurrentTime: pinned,
+				LatestTime:  time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		Failures: []check.Failure{
+			{Module: "example.com/offline", Err: errors.New("dial tcp: no such host")},
+		},
+	}
+
+	stale := staleUpdates(rep, 90*24*time.Hour)
+	if len(stale.Updates) != 2 {
+		t.Fatalf("got stale updates %+v, want go4.org/netipx and its fork", stale.Updates)
+	}
+	if len(stale.Failures) != 0 {
+		t.Errorf("got failures %+v, want none", stale.Failures)
+	}
+	if len(rep.Updates) != 3 {
+		t.Errorf("staleUpdates modified its argument")
+	}
+
+	var out, errOut strings.Builder
+	printPrecommit(&out, &errOut, stale, rep.Failures, "go.mod", colorizer{})
+	wantOut := "go.mod: go4.org/netipx is pinned to a commit 4 months 12 days older than the " +
+		"latest; update with go get go4.org/netipx@v0.0.0-20231201000000-cccccccccccc\n" +
+		"go.mod: github.com/fork/netipx is pinned to a commit 4 months 12 days older than " +
+		"the latest; update with go mod edit -replace=github.com/upstream/netipx=" +
+		"github.com/fork/netipx@v0.0.0-20231201000000-dddddddddddd\n"
+	if got := out.String(); got != wantOut {
+		t.Errorf("got output:\n%s\nwant:\n%s", got, wantOut)
+	}
+	wantErr := "go.mod: skipped example.com/offline: dial tcp: no such host\n"
+	if got := errOut.String(); got != wantErr {
+		t.Errorf("got errors:\n%s\nwant:\n%s", got, wantErr)
+	}
+
+	if code := exitCode(stale, false, failPolicy{}); code != exitUpdates {
+		t.Errorf("got exit code %d, want %d", code, exitUpdates)
+	}
+	if code := exitCode(staleUpdates(rep, 365*24*time.Hour), false, failPolicy{}); code != exitOK {
+		t.Errorf("got exit code %d without stale updates, want %d", code, exitOK)
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/mod/module"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// probeCommand is the subcommand checking modules given on the command line
+// rather than in a go.mod file.
+const probeCommand = "probe"
+
+// probeOptions holds the probe subcommand's command line options.
+type probeOptions struct {
+	branches []string
+	resolver string
+	format   string
+	verbose  bool
+	exitZero bool
+	deps     []check.Dependency
+}
+
+// parseProbeFlags parses the arguments of the probe subcommand: flags
+// followed by one or more module@pseudo-version arguments. An argument of -
+// reads "module pseudo-version" pairs from stdin instead (see
+// readModuleList).
+func parseProbeFlags(args []string, stdin io.Reader) (probeOptions, error) {
+	opts, fs, err := parseProbeFlagSet(
+		probeCommand,
+		"Usage: check-untagged-go-deps probe [flags] module@pseudo-version...\n"+
+			"       check-untagged-go-deps probe [flags] - < modules.txt\n\n"+
+			"Report whether newer commits exist for modules at pseudo-versions, without a "+
+			"go.mod file. With -, read \"module pseudo-version\" lines from stdin.\n\n",
+		args,
+	)
+	if err != nil {
+		return probeOptions{}, err
+	}
+
+	if fs.NArg() == 0 {
+		return probeOptions{}, &usageError{msg: "probe needs at least one module@pseudo-version"}
+	}
+	for _, arg := range fs.Args() {
+		if arg == "-" {
+			deps, err := readModuleList(stdin)
+			if err != nil {
+				return probeOptions{}, &usageError{msg: err.Error()}
+			}
+			opts.deps = append(opts.deps, deps...)
+			continue
+		}
+		dep, err := parseModuleVersion(arg)
+		if err != nil {
+			return probeOptions{}, &usageError{msg: err.Error()}
+		}
+		opts.deps = append(opts.deps, dep)
+	}
+	if len(opts.deps) == 0 {
+		return probeOptions{}, &usageError{msg: "no modules to probe"}
+	}
+	return opts, nil
+}
+
+// parseProbeFlagSet parses the flags shared by the subcommands that check
+// modules outside a go.mod file and returns the flag set, whose arguments
+// are left to the caller. usage is the text printed before the flags.
+func parseProbeFlagSet(
+	command string,
+	usage string,
+	args []string,
+) (probeOptions, *flag.FlagSet, error) {
+	fs := flag.NewFlagSet("check-untagged-go-deps "+command, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+
+	var opts probeOptions
+	branches := fs.String(
+		"branches",
+		strings.Join(check.DefaultBranches, ","),
+		"comma-separated branches to check; the most recent commit among them is used",
+	)
+	fs.StringVar(
+		&opts.resolver,
+		"resolver",
+		resolverGo,
+		"how to resolve versions: go (run go list) or proxy (query GOPROXY over HTTP)",
+	)
+	fs.StringVar(&opts.format, "format", formatText, "output format: text or json")
+	fs.BoolVar(&opts.verbose, "v", false, "log each resolution to stderr")
+	fs.BoolVar(
+		&opts.exitZero,
+		"exit-zero",
+		false,
+		"exit with code 0 even when updates are found (errors still exit with code 2)",
+	)
+	if err := fs.Parse(args); err != nil {
+		return probeOptions{}, nil, err
+	}
+
+	switch opts.resolver {
+	case resolverGo, resolverProxy:
+	default:
+		return probeOptions{}, nil, &usageError{
+			msg: fmt.Sprintf("invalid -resolver value %q: must be go or proxy", opts.resolver),
+		}
+	}
+	if opts.format != formatText && opts.format != formatJSON {
+		return probeOptions{}, nil, &usageError{
+			msg: fmt.Sprintf("invalid -format value %q: must be text or json", opts.format),
+		}
+	}
+	opts.branches = splitList(*branches)
+	if len(opts.branches) == 0 {
+		return probeOptions{}, nil, &usageError{msg: "-branches must list at least one branch"}
+	}
+	return opts, fs, nil
+}
+
+// readModuleList reads a plain list of modules, one "module pseudo-version"
+// pair per line, such as pins extracted from another ecosystem's manifest.
+// Blank lines and lines starting with # are skipped.
+func readModuleList(r io.Reader) ([]check.Dependency, error) {
+	var deps []check.Dependency
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("stdin:%d: want \"module pseudo-version\", got %q", line, text)
+		}
+		dep, err := parseModuleVersion(fields[0] + "@" + fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("stdin:%d: %w", line, err)
+		}
+		deps = append(deps, dep)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading stdin: %w", err)
+	}
+	return deps, nil
+}
+
+// parseModuleVersion parses a module@pseudo-version argument.
+func parseModuleVersion(s string) (check.Dependency, error) {
+	path, version, ok := strings.Cut(s, "@")
+	if !ok {
+		return check.Dependency{}, fmt.Errorf("%q is not of the form module@version", s)
+	}
+	if err := module.CheckPath(path); err != nil {
+		return check.Dependency{}, fmt.Errorf("invalid module path in %q: %w", s, err)
+	}
+	if !module.IsPseudoVersion(version) {
+		return check.Dependency{}, fmt.Errorf("%s in %q is not a pseudo-version", version, s)
+	}
+	return check.Dependency{Module: path, Version: version}, nil
+}
+
+// runProbe runs the probe subcommand with args and writes its report to w.
+// It returns the exit code.
+func runProbe(args []string, stdin io.Reader, w io.Writer) (int, error) {
+	opts, err := parseProbeFlags(args, stdin)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, nil
+		}
+		return exitUsage, err
+	}
+
+	// Stop in-flight queries on interrupt rather than waiting for them.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c, err := newProbeChecker(opts, len(opts.deps))
+	if err != nil {
+		return exitError, err
+	}
+	return probe(ctx, c, opts, w)
+}
+
+// newProbeChecker returns a checker configured by opts that checks
+// concurrency modules at a time.
+func newProbeChecker(opts probeOptions, concurrency int) (*check.Checker, error) {
+	logger := newLogger(os.Stderr, logFormatText, opts.verbose, false)
+	res, err := newResolver(opts.resolver, concurrency, logger)
+	if err != nil {
+		return nil, err
+	}
+	return check.NewChecker(
+		check.WithResolver(res),
+		check.WithLogger(logger),
+		check.WithConcurrency(concurrency),
+		check.WithBranches(opts.branches...),
+	), nil
+}
+
+// probe checks the modules in opts with c and writes the report to w. It
+// returns the exit code.
+func probe(ctx context.Context, c *check.Checker, opts probeOptions, w io.Writer) (int, error) {
+	return writeProbeReport(w, c.Check(ctx, opts.deps), opts, "")
+}
+
+// writeProbeReport writes rep to w in the format opts asks for and returns
+// the exit code. path is the file the modules came from, if any.
+func writeProbeReport(w io.Writer, rep check.Report, opts probeOptions, path string) (int, error) {
+	if opts.format == formatJSON {
+		if err := printJSON(w, check.NewEnvelope(rep, toolVersion(), path)); err != nil {
+			return exitError, err
+		}
+	} else {
+		printProbe(w, rep)
+	}
+	return exitCode(rep, opts.exitZero, failPolicy{}), nil
+}
+
+// printProbe writes one line per probed module, in the order given, saying
+// whether it has an update.
+func printProbe(w io.Writer, rep check.Report) {
+	updates := map[string]check.Update{}
+	for _, u := range rep.Updates {
+		updates[u.Module] = u
+	}
+	failures := map[string]error{}
+	for _, f := range rep.Failures {
+		failures[f.Module] = f.Err
+	}
+
+	for _, dep := range rep.Dependencies {
+		if err, ok := failures[dep.Module]; ok {
+			fmt.Fprintf(w, "%s@%s: failed: %v\n", dep.Module, dep.Version, err)
+			continue
+		}
+		u, ok := updates[dep.Module]
+		if !ok {
+			fmt.Fprintf(w, "%s@%s: up to date\n", dep.Module, dep.Version)
+			continue
+		}
+		fmt.Fprintf(w, "%s@%s: newer commit %s", dep.Module, dep.Version, u.Latest)
+		if u.Age() >= 24*time.Hour {
+			fmt.Fprintf(w, " (%s newer)", formatAge(u.CurrentTime, u.LatestTime))
+		}
+		fmt.Fprintln(w)
+	}
+}
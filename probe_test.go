@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+	"github.com/horgh/check-untagged-go-deps/check/checktest"
+)
+
+func TestParseProbeFlags(t *testing.T) {
+	stdin := strings.NewReader("# Pins from MODULE.bazel\n\n" +
+		"example.com/b v0.0.0-20231101000000-cccccccccccc\n")
+	opts, err := parseProbeFlags([]string{
+		"-branches", "trunk",
+		"go4.org/netipx@v0.0.0-20230719000000-aaaaaaaaaaaa",
+		"-",
+		"example.com/a@v1.2.1-0.20231101000000-bbbbbbbbbbbb",
+	}, stdin)
+	if err != nil {
+		t.Fatalf("parseProbeFlags: %v", err)
+	}
+	want := []check.Dependency{
+		{Module: "go4.org/netipx", Version: "v0.0.0-20230719000000-aaaaaaaaaaaa"},
+		{Module: "example.com/b", Version: "v0.0.0-20231101000000-cccccccccccc"},
+		{Module: "example.com/a", Version: "v1.2.1-0.20231101000000-bbbbbbbbbbbb"},
+	}
+	if !slices.Equal(opts.deps, want) {
+		t.Errorf("got dependencies %+v, want %+v", opts.deps, want)
+	}
+	if len(opts.branches) != 1 || opts.branches[0] != "trunk" {
+		t.Errorf("got branches %q, want [trunk]", opts.branches)
+	}
+
+	tests := []struct {
+		args  []string
+		stdin string
+	}{
+		{},
+		{args: []string{"go4.org/netipx"}},
+		{args: []string{"go4.org/netipx@v1.0.0"}},
+		{args: []string{"Not A Path@v0.0.0-20230719000000-aaaaaaaaaaaa"}},
+		{
+			args: []string{
+				"-format", "markdown",
+				"go4.org/netipx@v0.0.0-20230719000000-aaaaaaaaaaaa",
+			},
+		},
+		{args: []string{"-"}, stdin: "# Nothing pinned\n"},
+		{args: []string{"-"}, stdin: "go4.org/netipx v0.0.0-20230719000000-aaaaaaaaaaaa extra\n"},
+		{args: []string{"-"}, stdin: "go4.org/netipx v1.0.0\n"},
+	}
+	for _, tt := range tests {
+		var usageErr *usageError
+		_, err := parseProbeFlags(tt.args, strings.NewReader(tt.stdin))
+		if !errors.As(err, &usageErr) {
+			t.Errorf(
+				"parseProbeFlags(%q) with stdin %q: got error %v, want a usage error",
+				tt.args,
+				tt.stdin,
+				err,
+			)
+		}
+	}
+}
+
+func TestProbe(t *testing.T) {
+	resolver := &checktest.Resolver{
+		Versions: map[string]string{
+			"go4.org/netipx@main": "v0.0.0-20231201000000-cccccccccccc",
+			"example.com/a@main":  "v0.0.0-20231101000000-bbbbbbbbbbbb",
+		},
+	}
+	c := check.NewChecker(check.WithResolver(resolver), check.WithBranches("main"))
+	opts := probeOptions{
+		format: formatText,
+		deps: []check.Dependency{
+			{Module: "go4.org/netipx", Version: "v0.0.0-20230719000000-aaaaaaaaaaaa"},
+			{Module: "example.com/a", Version: "v0.0.0-20231101000000-bbbbbbbbbbbb"},
+			{Module: "example.com/gone", Version: "v0.0.0-20231101000000-bbbbbbbbbbbb"},
+		},
+	}
+
+	var buf bytes.Buffer
+	code, err := probe(t.Context(), c, opts, &buf)
+	if err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if code != exitUpdates {
+		t.Errorf("got exit code %d, want %d", code, exitUpdates)
+	}
+	want := "go4.org/netipx@v0.0.0-20230719000000-aaaaaaaaaaaa: newer commit " +
+		"v0.0.0-20231201000000-cccccccccccc (4 months 12 days newer)\n" +
+		"example.com/a@v0.0.0-20231101000000-bbbbbbbbbbbb: up to date\n" +
+		"example.com/gone@v0.0.0-20231101000000-bbbbbbbbbbbb: failed: " +
+		"none of the branches main found\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// rdjsonSourceURL links diagnostics to this tool.
+const rdjsonSourceURL = "https://github.com/horgh/check-untagged-go-deps"
+
+// Diagnostic codes in -format rdjson.
+const rdjsonCodeUpdate = "update_available"
+
+// rdjsonResult is a Reviewdog Diagnostic Format
+// (https://github.com/reviewdog/reviewdog/tree/master/proto/rdf) result.
+type rdjsonResult struct {
+	Source      rdjsonSource       `json:"source"`
+	Diagnostics []rdjsonDiagnostic `json:"diagnostics"`
+}
+
+type rdjsonSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type rdjsonDiagnostic struct {
+	Message     string             `json:"message"`
+	Location    rdjsonLocation     `json:"location"`
+	Severity    string             `json:"severity"`
+	Code        rdjsonCode         `json:"code"`
+	Suggestions []rdjsonSuggestion `json:"suggestions,omitempty"`
+}
+
+type rdjsonLocation struct {
+	Path  string       `json:"path"`
+	Range *rdjsonRange `json:"range,omitempty"`
+}
+
+// rdjsonRange is a range of a file. The start is inclusive and the end
+// exclusive.
+type rdjsonRange struct {
+	Start rdjsonPosition `json:"start"`
+	End   rdjsonPosition `json:"end"`
+}
+
+// rdjsonPosition is a position in a file. Lines and columns start at 1.
+type rdjsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column,omitempty"`
+}
+
+type rdjsonCode struct {
+	Value string `json:"value"`
+	URL   string `json:"url,omitempty"`
+}
+
+type rdjsonSuggestion struct {
+	Range rdjsonRange `json:"range"`
+	Text  string      `json:"text"`
+}
+
+// printRDJSON writes the report to w in the Reviewdog Diagnostic Format: a
+// warning for each update and an error for each failure, positioned at the
+// dependency's require directive in go.mod. Updates suggest replacing the
+// required version with the latest, so reviewdog can post them as suggested
+// changes.
+func printRDJSON(w io.Writer, env check.Envelope) error {
+	deps := goModDependencies(env.Report)
+	// requireRange returns the range of a dependency's require or replace
+	// directive, or nil if it is not known.
+	requireRange := func(span *check.Span) *rdjsonRange {
+		if span == nil {
+			return nil
+		}
+		return &rdjsonRange{
+			Start: rdjsonPosition{Line: span.Start.Line, Column: span.Start.LineRune},
+			End:   rdjsonPosition{Line: span.End.Line, Column: span.End.LineRune},
+		}
+	}
+	// versionRange returns the range of the version, which ends a
+	// dependency's directive, or nil if it is not known.
+	versionRange := func(span *check.Span, version string) *rdjsonRange {
+		if span == nil {
+			return nil
+		}
+		end := rdjsonPosition{Line: span.End.Line, Column: span.End.LineRune}
+		start := end
+		start.Column -= utf8.RuneCountInString(version)
+		return &rdjsonRange{Start: start, End: end}
+	}
+
+	result := rdjsonResult{
+		Source:      rdjsonSource{Name: toolName, URL: rdjsonSourceURL},
+		Diagnostics: []rdjsonDiagnostic{},
+	}
+	for _, u := range env.Report.Updates {
+		msg := fmt.Sprintf("Update available: %s -> %s", u.Current, u.Latest)
+		if u.Age() > 0 {
+			msg += fmt.Sprintf(
+				" (current commit is %s older than latest)",
+				formatAge(u.CurrentTime, u.LatestTime),
+			)
+		}
+		if u.CompareURL != "" {
+			msg += "\n" + u.CompareURL
+		}
+		d := rdjsonDiagnostic{
+			Message:  msg,
+			Location: rdjsonLocation{Path: env.GoModPath, Range: requireRange(u.Span)},
+			Severity: "WARNING",
+			Code:     rdjsonCode{Value: rdjsonCodeUpdate, URL: u.CompareURL},
+		}
+		if r := versionRange(u.Span, u.Current); r != nil {
+			d.Suggestions = []rdjsonSuggestion{{Range: *r, Text: u.Latest}}
+		}
+		result.Diagnostics = append(result.Diagnostics, d)
+	}
+	for _, f := range env.Report.Failures {
+		result.Diagnostics = append(result.Diagnostics, rdjsonDiagnostic{
+			Message:  fmt.Sprintf("Failed to check %s: %v", f.Module, f.Err),
+			Location: rdjsonLocation{Path: env.GoModPath, Range: requireRange(deps[f.Module].Span)},
+			Severity: "ERROR",
+			Code:     rdjsonCode{Value: check.ErrorCode(f.Err)},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(result); err != nil {
+		return fmt.Errorf("writing RDJSON: %w", err)
+	}
+	return nil
+}
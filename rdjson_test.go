@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestPrintRDJSON(t *testing.T) {
+	gomodPath, deps := readGoMod(t, "module example.com/app\n\ngo 1.25\n\n"+
+		"require go4.org/netipx v0.0.0-20230719000000-aaaaaaaaaaaa\n\nrequire (\n"+
+		"\tgithub.com/example/module v0.0.0-20231101000000-bbbbbbbbbbbb // indirect\n"+
+		"\tgithub.com/upstream/lib v1.2.0\n"+
+		")\n\n"+
+		"replace github.com/upstream/lib => "+
+		"github.com/fork/lib v0.0.0-20230801000000-eeeeeeeeeeee\n")
+
+	env := check.Envelope{
+		GoModPath: gomodPath,
+		Report: check.Report{
+			Dependencies: []check.Dependency{
+				deps["go4.org/netipx"],
+				deps["github.com/example/module"],
+				deps["github.com/fork/lib"],
+			},
+			Updates: []check.Update{
+				{
+					Module:  "go4.org/netipx",
+					Current: "v0.0.0-20230719000000-aaaaaaaaaaaa",
+					Latest:  "v0.0.0-20231201000000-cccccccccccc",
+					Span:    deps["go4.org/netipx"].Span,
+				},
+				{
+					Module:   "github.com/fork/lib",
+					Replaces: "github.com/upstream/lib",
+					Current:  "v0.0.0-20230801000000-eeeeeeeeeeee",
+					Latest:   "v0.0.0-20230901000000-ffffffffffff",
+					Span:     deps["github.com/fork/lib"].Span,
+				},
+			},
+			Failures: []check.Failure{
+				{
+					Module: "github.com/example/module",
+					Err:    errors.Join(check.ErrAuth, errors.New("terminal prompts disabled")),
+				},
+				{Module: "example.com/unknown", Err: errors.New("x")},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := printRDJSON(&buf, env); err != nil {
+		t.Fatalf("printRDJSON: %v", err)
+	}
+
+	want := `{
+  "source": {
+    "name": "check-untagged-go-deps",
+    "url": "https://github.com/horgh/check-untagged-go-deps"
+  },
+  "diagnostics": [
+    {
+      "message": "Update available: v0.0.0-20230719000000-aaaaaaaaaaaa -> ` +
+		`v0.0.0-20231201000000-cccccccccccc",
+      "location": {
+        "path": "GOMOD",
+        "range": {
+          "start": {
+            "line": 5,
+            "column": 1
+          },
+          "end": {
+            "line": 5,
+            "column": 58
+          }
+        }
+      },
+      "severity": "WARNING",
+      "code": {
+        "value": "update_available"
+      },
+      "suggestions": [
+        {
+          "range": {
+            "start": {
+              "line": 5,
+              "column": 24
+            },
+            "end": {
+              "line": 5,
+              "column": 58
+            }
+          },
+          "text": "v0.0.0-20231201000000-cccccccccccc"
+        }
+      ]
+    },
+    {
+      "message": "Update available: v0.0.0-20230801000000-eeeeeeeeeeee -> ` +
+		`v0.0.0-20230901000000-ffffffffffff",
+      "location": {
+        "path": "GOMOD",
+        "range": {
+          "start": {
+            "line": 12,
+            "column": 1
+          },
+          "end": {
+            "line": 12,
+            "column": 90
+          }
+        }
+      },
+      "severity": "WARNING",
+      "code": {
+        "value": "update_available"
+      },
+      "suggestions": [
+        {
+          "range": {
+            "start": {
+              "line": 12,
+              "column": 56
+            },
+            "end": {
+              "line": 12,
+              "column": 90
+            }
+          },
+          "text": "v0.0.0-20230901000000-ffffffffffff"
+        }
+      ]
+    },
+    {
+      "message": "Failed to check github.com/example/module: ` +
+		`authentication required or denied\nterminal prompts disabled",
+      "location": {
+        "path": "GOMOD",
+        "range": {
+          "start": {
+            "line": 8,
+            "column": 2
+          },
+          "end": {
+            "line": 8,
+            "column": 62
+          }
+        }
+      },
+      "severity": "ERROR",
+      "code": {
+        "value": "auth"
+      }
+    },
+    {
+      "message": "Failed to check example.com/unknown: x",
+      "location": {
+        "path": "GOMOD"
+      },
+      "severity": "ERROR",
+      "code": {
+        "value": "unknown"
+      }
+    }
+  ]
+}
+`
+	got := strings.ReplaceAll(buf.String(), gomodPath, "GOMOD")
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+	"golang.org/x/mod/module"
+)
+
+// renovateReport is the -format renovate output: the pseudo-versioned
+// dependencies in a shape that a Renovate custom datasource can transform
+// into its releases format with a JSONata template.
+type renovateReport struct {
+	Dependencies []renovateDependency `json:"dependencies"`
+}
+
+// renovateDependency is a pseudo-versioned dependency with its current and
+// newest commits. If there is no update, the new fields equal the current
+// ones.
+type renovateDependency struct { //nolint:tagliatelle // matches Renovate
+	Module           string `json:"module"`
+	CurrentValue     string `json:"currentValue"`
+	CurrentDigest    string `json:"currentDigest"`
+	CurrentTimestamp string `json:"currentTimestamp,omitempty"`
+	NewValue         string `json:"newValue"`
+	NewDigest        string `json:"newDigest"`
+	NewTimestamp     string `json:"newTimestamp,omitempty"`
+	UpdateAvailable  bool   `json:"updateAvailable"`
+	// Releases are the current and new versions in Renovate's datasource
+	// format, oldest first.
+	Releases []renovateRelease `json:"releases"`
+}
+
+// renovateRelease is a release in Renovate's custom datasource format.
+type renovateRelease struct { //nolint:tagliatelle // matches Renovate
+	Version          string `json:"version"`
+	Digest           string `json:"digest,omitempty"`
+	ReleaseTimestamp string `json:"releaseTimestamp,omitempty"`
+}
+
+// printRenovate writes the pseudo-versioned dependencies in the report in the
+// -format renovate structure to w. Dependencies that failed to be checked are
+// omitted.
+func printRenovate(w io.Writer, rep check.Report) error {
+	updates := map[string]check.Update{}
+	for _, u := range rep.Updates {
+		updates[u.Module] = u
+	}
+	failed := map[string]bool{}
+	for _, f := range rep.Failures {
+		failed[f.Module] = true
+	}
+
+	out := renovateReport{Dependencies: []renovateDependency{}}
+	for _, dep := range rep.Dependencies {
+		if failed[dep.Module] {
+			continue
+		}
+		current := renovateRelease{Version: dep.Version}
+		current.Digest, _ = module.PseudoVersionRev(dep.Version)
+		if t, err := module.PseudoVersionTime(dep.Version); err == nil {
+			current.ReleaseTimestamp = t.UTC().Format(time.RFC3339)
+		}
+
+		d := renovateDependency{
+			Module:           dep.Module,
+			CurrentValue:     current.Version,
+			CurrentDigest:    current.Digest,
+			CurrentTimestamp: current.ReleaseTimestamp,
+			Releases:         []renovateRelease{current},
+		}
+		latest := current
+		if u, ok := updates[dep.Module]; ok {
+			d.UpdateAvailable = true
+			latest = renovateRelease{Version: u.Latest}
+			latest.Digest, _ = module.PseudoVersionRev(u.Latest)
+			if !u.LatestTime.IsZero() {
+				latest.ReleaseTimestamp = u.LatestTime.UTC().Format(time.RFC3339)
+			}
+			d.Releases = append(d.Releases, latest)
+		}
+		d.NewValue = latest.Version
+		d.NewDigest = latest.Digest
+		d.NewTimestamp = latest.ReleaseTimestamp
+		out.Dependencies = append(out.Dependencies, d)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("writing Renovate output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestPrintRenovate(t *testing.T) {
+	rep := check.Report{
+		Dependencies: []check.Dependency{
+			{Module: "go4.org/netipx", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+			{Module: "example.com/current", Version: "v0.0.0-20231102000000-bbbbbbbbbbbb"},
+			{Module: "example.com/gone", Version: "v0.0.0-20231103000000-dddddddddddd"},
+		},
+		Updates: []check.Update{
+			{
+				Module:     "go4.org/netipx",
+				Current:    "v0.0.0-20231101000000-aaaaaaaaaaaa",
+				Latest:     "v0.0.0-20231201000000-cccccccccccc",
+				LatestTime: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		Failures: []check.Failure{{Module: "example.com/gone", Err: errors.New("not found")}},
+	}
+
+	var buf bytes.Buffer
+	if err := printRenovate(&buf, rep); err != nil {
+		t.Fatalf("printRenovate: %v", err)
+	}
+
+	want := `{
+  "dependencies": [
+    {
+      "module": "go4.org/netipx",
+      "currentValue": "v0.0.0-20231101000000-aaaaaaaaaaaa",
+      "currentDigest": "aaaaaaaaaaaa",
+      "currentTimestamp": "2023-11-01T00:00:00Z",
+      "newValue": "v0.0.0-20231201000000-cccccccccccc",
+      "newDigest": "cccccccccccc",
+      "newTimestamp": "2023-12-01T00:00:00Z",
+      "updateAvailable": true,
+      "releases": [
+        {
+          "version": "v0.0.0-20231101000000-aaaaaaaaaaaa",
+          "digest": "aaaaaaaaaaaa",
+          "releaseTimestamp": "2023-11-01T00:00:00Z"
+        },
+        {
+          "version": "v0.0.0-20231201000000-cccccccccccc",
+          "digest": "cccccccccccc",
+          "releaseTimestamp": "2023-12-01T00:00:00Z"
+        }
+      ]
+    },
+    {
+      "module": "example.com/current",
+      "currentValue": "v0.0.0-20231102000000-bbbbbbbbbbbb",
+      "currentDigest": "bbbbbbbbbbbb",
+      "currentTimestamp": "2023-11-02T00:00:00Z",
+      "newValue": "v0.0.0-20231102000000-bbbbbbbbbbbb",
+      "newDigest": "bbbbbbbbbbbb",
+      "newTimestamp": "2023-11-02T00:00:00Z",
+      "updateAvailable": false,
+      "releases": [
+        {
+          "version": "v0.0.0-20231102000000-bbbbbbbbbbbb",
+          "digest": "bbbbbbbbbbbb",
+          "releaseTimestamp": "2023-11-02T00:00:00Z"
+        }
+      ]
+    }
+  ]
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
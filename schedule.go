@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedule is a cron schedule: the minutes, hours, days of the month,
+// months, and days of the week at which to run, as bit sets.
+type schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record whether the day of the month or week field
+	// started with "*", such as "*" or "*/2". If neither did, a day matches
+	// if either field matches, as in cron.
+	domStar, dowStar bool
+}
+
+// scheduleAliases are the supported cron shorthands.
+var scheduleAliases = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+// scheduleField describes the range of a cron field.
+type scheduleField struct {
+	name     string
+	min, max int
+}
+
+var scheduleFields = [...]scheduleField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	// 7 is also Sunday.
+	{name: "day of week", min: 0, max: 7},
+}
+
+// parseSchedule parses a cron expression with five fields (minute, hour, day
+// of month, month, and day of week), or a shorthand such as "@daily". Fields
+// may be "*", numbers, ranges ("1-5"), steps ("*/15", "0-30/10"), or lists of
+// these ("1,15"). Names of months and days are not supported.
+func parseSchedule(spec string) (*schedule, error) {
+	if alias, ok := scheduleAliases[spec]; ok {
+		spec = alias
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != len(scheduleFields) {
+		return nil, fmt.Errorf(
+			"invalid schedule %q: want %d fields (minute hour day-of-month month day-of-week)",
+			spec,
+			len(scheduleFields),
+		)
+	}
+
+	var bits [len(scheduleFields)]uint64
+	for i, field := range fields {
+		var err error
+		if bits[i], err = parseScheduleField(field, scheduleFields[i]); err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+	}
+	s := &schedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}
+	// Treat 7 as Sunday.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseScheduleField parses one field of a cron expression into a bit set.
+func parseScheduleField(field string, f scheduleField) (uint64, error) {
+	var bits uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepPart, f.name)
+			}
+		}
+
+		low, high := f.min, f.max
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseScheduleValue(lowPart, f); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = parseScheduleValue(highPart, f); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// "5/15" means from 5 to the end in steps of 15.
+				high = f.max
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q in %s field", rangePart, f.name)
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// parseScheduleValue parses a number in a cron field.
+func parseScheduleValue(s string, f scheduleField) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf(
+			"invalid value %q in %s field: must be %d-%d",
+			s,
+			f.name,
+			f.min,
+			f.max,
+		)
+	}
+	return v, nil
+}
+
+// maxScheduleSearch bounds the search for the next run, for schedules that
+// can never run, such as February 30th.
+const maxScheduleSearch = 5 * 366 * 24 * time.Hour
+
+// next returns the first time after t, to the minute, matched by the
+// schedule in t's location, or the zero time if there is none within five
+// years.
+func (s *schedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxScheduleSearch)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the schedule runs on t's day.
+func (s *schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// window is when updates are announced and made (-schedule-days): some days
+// of the week in a time zone.
+type window struct {
+	// days is a bit set of the days of the week, with Sunday as 0.
+	days uint8
+	loc  *time.Location
+}
+
+// parseWindow parses a comma-separated list of days of the week, as names
+// such as "monday" or abbreviations such as "mon", into a window in loc.
+func parseWindow(days string, loc *time.Location) (*window, error) {
+	w := &window{loc: loc}
+	for day := range strings.SplitSeq(days, ",") {
+		day = strings.ToLower(strings.TrimSpace(day))
+		found := false
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			name := strings.ToLower(d.String())
+			if day == name || day == name[:3] {
+				w.days |= 1 << uint(d)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid day of the week %q", day)
+		}
+	}
+	return w, nil
+}
+
+// contains reports whether t falls on one of the window's days.
+func (w *window) contains(t time.Time) bool {
+	return w.days&(1<<uint(t.In(w.loc).Weekday())) != 0
+}
+
+// String returns the window's days and time zone, e.g. "Monday, Thursday
+// (UTC)".
+func (w *window) String() string {
+	var days []string
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if w.days&(1<<uint(d)) != 0 {
+			days = append(days, d.String())
+		}
+	}
+	return strings.Join(days, ", ") + " (" + w.loc.String() + ")"
+}
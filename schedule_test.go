@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"30-10 * * * *",
+		"a * * * *",
+		"@fortnightly",
+	} {
+		if _, err := parseSchedule(spec); err == nil {
+			t.Errorf("parseSchedule(%q): expected error", spec)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// 2026-03-04 is a Wednesday.
+	from := time.Date(2026, 3, 4, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{spec: "* * * * *", want: time.Date(2026, 3, 4, 10, 31, 0, 0, time.UTC)},
+		{spec: "@hourly", want: time.Date(2026, 3, 4, 11, 0, 0, 0, time.UTC)},
+		{spec: "@daily", want: time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 14 * * *", want: time.Date(2026, 3, 4, 14, 0, 0, 0, time.UTC)},
+		{spec: "*/20 * * * *", want: time.Date(2026, 3, 4, 10, 40, 0, 0, time.UTC)},
+		{spec: "5/20 * * * *", want: time.Date(2026, 3, 4, 10, 45, 0, 0, time.UTC)},
+		{spec: "0 9 * * 1-5", want: time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC)},
+		{spec: "@weekly", want: time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 * * 7", want: time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{spec: "@monthly", want: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "@yearly", want: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// With both day fields restricted, either may match.
+		{spec: "0 0 15 * 5", want: time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+		// With a day field starting with "*", both must match, as in cron.
+		{spec: "0 0 */2 * 5", want: time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 6 * */2", want: time.Date(2026, 6, 6, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 31 * *", want: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 29 2 *", want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 30 2 *", want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := parseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("parseSchedule: %v", err)
+			}
+			if got := s.next(from); !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	loc := time.FixedZone("UTC-8", -8*60*60)
+	w, err := parseWindow("Monday, thu", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := w.String(), "Monday, Thursday (UTC-8)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		t    time.Time
+		want bool
+	}{
+		// 2026-03-09 is a Monday.
+		{t: time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC), want: true},
+		// Still Sunday in the window's time zone.
+		{t: time.Date(2026, 3, 9, 7, 0, 0, 0, time.UTC), want: false},
+		{t: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC), want: false},
+		{t: time.Date(2026, 3, 12, 12, 0, 0, 0, time.UTC), want: true},
+	}
+	for _, tt := range tests {
+		if got := w.contains(tt.t); got != tt.want {
+			t.Errorf("contains(%v) = %t, want %t", tt.t, got, tt.want)
+		}
+	}
+
+	for _, days := range []string{"", "mo", "monday,funday"} {
+		if _, err := parseWindow(days, loc); err == nil {
+			t.Errorf("parseWindow(%q): expected error", days)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// serve serves the daemon's HTTP API on ln until ctx is done.
+func (d *daemon) serve(ctx context.Context, ln net.Listener) {
+	server := &http.Server{Handler: d.handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	if err := server.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		d.logError(fmt.Errorf("serving HTTP: %w", err))
+	}
+}
+
+// handler returns the daemon's HTTP API (see -listen):
+//
+//   - GET /report returns the latest JSON report of the go.mod file given by
+//     the gomod query parameter, or of the first go.mod file.
+//   - POST /check requests a check of every go.mod file, responding without
+//     waiting for it to finish.
+//   - GET /metrics returns Prometheus metrics.
+func (d *daemon) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /report", d.serveReport)
+	mux.HandleFunc("POST /check", d.serveCheck)
+	mux.HandleFunc("GET /metrics", d.serveMetrics)
+	return mux
+}
+
+// serveReport serves the latest report of a go.mod file as JSON.
+func (d *daemon) serveReport(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("gomod")
+	if path == "" && len(d.gomodPaths) > 0 {
+		path = d.gomodPaths[0]
+	}
+
+	d.mu.Lock()
+	env, ok := d.reports[path]
+	d.mu.Unlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("no report for %q yet", path), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := printJSON(w, env); err != nil {
+		d.logError(fmt.Errorf("serving report: %w", err))
+	}
+}
+
+// serveCheck requests a check. It responds 202 Accepted; the new reports are
+// available from /report once the check finishes.
+func (d *daemon) serveCheck(w http.ResponseWriter, _ *http.Request) {
+	d.requestCheck()
+	w.WriteHeader(http.StatusAccepted)
+}
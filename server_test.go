@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+	"github.com/horgh/check-untagged-go-deps/check/checktest"
+)
+
+func TestDaemonHandler(t *testing.T) {
+	d := &daemon{
+		gomodPaths: []string{"go.mod", "sub/go.mod"},
+		trigger:    make(chan struct{}, 1),
+		reports: map[string]check.Envelope{
+			"go.mod": {GoModPath: "go.mod"},
+			"sub/go.mod": {
+				GoModPath: "sub/go.mod",
+				Report: check.Report{
+					Updates: []check.Update{{Module: "go4.org/netipx"}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		// wantPath is the go.mod path of the report returned, if any.
+		wantPath string
+	}{
+		{
+			name:       "first report",
+			method:     http.MethodGet,
+			target:     "/report",
+			wantStatus: http.StatusOK,
+			wantPath:   "go.mod",
+		},
+		{
+			name:       "report by path",
+			method:     http.MethodGet,
+			target:     "/report?gomod=sub/go.mod",
+			wantStatus: http.StatusOK,
+			wantPath:   "sub/go.mod",
+		},
+		{
+			name:       "unknown report",
+			method:     http.MethodGet,
+			target:     "/report?gomod=other/go.mod",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "check",
+			method:     http.MethodPost,
+			target:     "/check",
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "check with GET",
+			method:     http.MethodGet,
+			target:     "/check",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			d.handler().ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantPath == "" {
+				return
+			}
+			var env check.Envelope
+			if err := json.NewDecoder(w.Body).Decode(&env); err != nil {
+				t.Fatalf("decoding report: %v", err)
+			}
+			if env.GoModPath != tt.wantPath {
+				t.Errorf("got report for %q, want %q", env.GoModPath, tt.wantPath)
+			}
+		})
+	}
+
+	select {
+	case <-d.trigger:
+	default:
+		t.Error("POST /check did not request a check")
+	}
+}
+
+func TestDaemonRunTriggered(t *testing.T) {
+	gomodPath := filepath.Join(t.TempDir(), "go.mod")
+	gomod := "module example.com/app\n\ngo 1.25\n\n" +
+		"require go4.org/netipx v0.0.0-20230719000000-aaaaaaaaaaaa\n"
+	if err := os.WriteFile(gomodPath, []byte(gomod), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	d := &daemon{
+		checker: check.NewChecker(
+			check.WithResolver(&checktest.Resolver{}),
+			check.WithBranches("main"),
+		),
+		trigger:    make(chan struct{}, 1),
+		gomodPaths: []string{gomodPath},
+		format:     formatText,
+		out:        &out,
+		errOut:     io.Discard,
+	}
+	checks := func() int {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if stats, ok := d.stats[gomodPath]; ok {
+			return stats.checks
+		}
+		return 0
+	}
+	waitForChecks := func(n int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for checks() < n {
+			if time.Now().After(deadline) {
+				t.Fatalf("got %d checks, want %d", checks(), n)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.run(ctx)
+		close(done)
+	}()
+
+	// Without a schedule, a check runs at startup and then only on request.
+	waitForChecks(1)
+	d.requestCheck()
+	waitForChecks(2)
+
+	cancel()
+	<-done
+	if got := checks(); got != 2 {
+		t.Errorf("got %d checks, want 2", got)
+	}
+}
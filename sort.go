@@ -0,0 +1,82 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// -sort values.
+const (
+	sortGoMod    = "gomod"
+	sortAge      = "age"
+	sortName     = "name"
+	sortHost     = "host"
+	sortSeverity = "severity"
+)
+
+// sortReport orders the report's dependencies, updates, failures, and
+// abandoned dependencies by the given -sort key: age (most stale first),
+// name, host (then name), or severity (most urgent first, then most stale).
+// Dependencies without updates sort after those with them by age and
+// severity. Ties, and sortGoMod, keep go.mod order.
+func sortReport(rep *check.Report, by string) {
+	if by == sortGoMod || by == "" {
+		return
+	}
+
+	updates := map[string]check.Update{}
+	for _, u := range rep.Updates {
+		updates[u.Module] = u
+	}
+	compare := func(a, b string) int {
+		switch by {
+		case sortAge:
+			return cmp.Compare(updates[b].Age(), updates[a].Age())
+		case sortName:
+			return cmp.Compare(a, b)
+		case sortHost:
+			return cmp.Or(cmp.Compare(check.ModuleHost(a), check.ModuleHost(b)), cmp.Compare(a, b))
+		case sortSeverity:
+			return cmp.Or(
+				compareUrgency(updates[a], updates[b]),
+				cmp.Compare(updates[b].Age(), updates[a].Age()),
+			)
+		default:
+			return 0
+		}
+	}
+
+	slices.SortStableFunc(rep.Dependencies, func(a, b check.Dependency) int {
+		return compare(a.Module, b.Module)
+	})
+	slices.SortStableFunc(rep.Updates, func(a, b check.Update) int {
+		return compare(a.Module, b.Module)
+	})
+	slices.SortStableFunc(rep.Failures, func(a, b check.Failure) int {
+		return compare(a.Module, b.Module)
+	})
+	slices.SortStableFunc(rep.Abandoned, func(a, b check.Abandoned) int {
+		return compare(a.Module, b.Module)
+	})
+}
+
+// compareUrgency orders update a before b if its severity is more urgent.
+// The zero Update, for a dependency without an update, is the least urgent.
+func compareUrgency(a, b check.Update) int {
+	switch {
+	case a.Module == "" && b.Module == "":
+		return 0
+	case a.Module == "":
+		return 1
+	case b.Module == "":
+		return -1
+	case !b.Severity.AtLeast(a.Severity):
+		return -1
+	case !a.Severity.AtLeast(b.Severity):
+		return 1
+	default:
+		return 0
+	}
+}
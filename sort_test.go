@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestSortReport(t *testing.T) {
+	latest := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	update := func(module string, ageDays int, severity check.Severity) check.Update {
+		return check.Update{
+			Module:      module,
+			CurrentTime: latest.AddDate(0, 0, -ageDays),
+			LatestTime:  latest,
+			Severity:    severity,
+		}
+	}
+	newReport := func() check.Report {
+		return check.Report{
+			Dependencies: []check.Dependency{
+				{Module: "gitlab.com/a/b"},
+				{Module: "github.com/z/y"},
+				{Module: "example.com/fresh"},
+				{Module: "github.com/c/d"},
+				{Module: "example.com/failed"},
+			},
+			Updates: []check.Update{
+				update("gitlab.com/a/b", 10, check.SeverityLow),
+				update("github.com/z/y", 100, check.SeverityCritical),
+				update("github.com/c/d", 40, check.SeverityHigh),
+			},
+			Failures: []check.Failure{{Module: "example.com/failed", Err: errors.New("x")}},
+		}
+	}
+
+	tests := []struct {
+		by          string
+		wantDeps    []string
+		wantUpdates []string
+	}{
+		{
+			by: sortGoMod,
+			wantDeps: []string{
+				"gitlab.com/a/b",
+				"github.com/z/y",
+				"example.com/fresh",
+				"github.com/c/d",
+				"example.com/failed",
+			},
+			wantUpdates: []string{"gitlab.com/a/b", "github.com/z/y", "github.com/c/d"},
+		},
+		{
+			by: sortAge,
+			wantDeps: []string{
+				"github.com/z/y",
+				"github.com/c/d",
+				"gitlab.com/a/b",
+				"example.com/fresh",
+				"example.com/failed",
+			},
+			wantUpdates: []string{"github.com/z/y", "github.com/c/d", "gitlab.com/a/b"},
+		},
+		{
+			by: sortName,
+			wantDeps: []string{
+				"example.com/failed",
+				"example.com/fresh",
+				"github.com/c/d",
+				"github.com/z/y",
+				"gitlab.com/a/b",
+			},
+			wantUpdates: []string{"github.com/c/d", "github.com/z/y", "gitlab.com/a/b"},
+		},
+		{
+			by: sortHost,
+			wantDeps: []string{
+				"example.com/failed",
+				"example.com/fresh",
+				"github.com/c/d",
+				"github.com/z/y",
+				"gitlab.com/a/b",
+			},
+			wantUpdates: []string{"github.com/c/d", "github.com/z/y", "gitlab.com/a/b"},
+		},
+		{
+			by: sortSeverity,
+			wantDeps: []string{
+				"github.com/z/y",
+				"github.com/c/d",
+				"gitlab.com/a/b",
+				"example.com/fresh",
+				"example.com/failed",
+			},
+			wantUpdates: []string{"github.com/z/y", "github.com/c/d", "gitlab.com/a/b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			rep := newReport()
+			sortReport(&rep, tt.by)
+
+			var deps, updates []string
+			for _, d := range rep.Dependencies {
+				deps = append(deps, d.Module)
+			}
+			for _, u := range rep.Updates {
+				updates = append(updates, u.Module)
+			}
+			if !slices.Equal(deps, tt.wantDeps) {
+				t.Errorf("got dependencies %q, want %q", deps, tt.wantDeps)
+			}
+			if !slices.Equal(updates, tt.wantUpdates) {
+				t.Errorf("got updates %q, want %q", updates, tt.wantUpdates)
+			}
+		})
+	}
+}
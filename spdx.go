@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+	"golang.org/x/mod/module"
+)
+
+// spdxNoAssertion is the SPDX value for information that was not determined.
+const spdxNoAssertion = "NOASSERTION"
+
+// spdxDocument is an SPDX 2.3 (https://spdx.dev) document in its JSON
+// serialization. Only the fields that are used are included.
+type spdxDocument struct { //nolint:tagliatelle // matches SPDX
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct { //nolint:tagliatelle // matches SPDX
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	SourceInfo            string            `json:"sourceInfo,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs"`
+	Annotations           []spdxAnnotation  `json:"annotations,omitempty"`
+}
+
+type spdxExternalRef struct { //nolint:tagliatelle // matches SPDX
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxAnnotation struct { //nolint:tagliatelle // matches SPDX
+	AnnotationDate string `json:"annotationDate"`
+	AnnotationType string `json:"annotationType"`
+	Annotator      string `json:"annotator"`
+	Comment        string `json:"comment"`
+}
+
+type spdxRelationship struct { //nolint:tagliatelle // matches SPDX
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// printSPDX writes the pseudo-versioned dependencies in the report as an SPDX
+// document to w. As with printCycloneDX, each package's version is its
+// pseudo-version. The pinned commit is recorded as the package's source
+// information, and an available update as an annotation.
+func printSPDX(w io.Writer, env check.Envelope) error {
+	created := env.GeneratedAt.UTC().Format(time.RFC3339)
+	creator := "Tool: " + toolName
+	if env.ToolVersion != "" {
+		creator += "-" + env.ToolVersion
+	}
+
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              "Pseudo-versioned dependencies in " + env.GoModPath,
+		DocumentNamespace: "https://spdx.org/spdxdocs/" + toolName + "-" + newUUID(),
+		CreationInfo: spdxCreationInfo{
+			Created:  created,
+			Creators: []string{creator},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+
+	latest := map[string]string{}
+	for _, u := range env.Report.Updates {
+		latest[u.Module] = u.Latest
+	}
+
+	for _, dep := range env.Report.Dependencies {
+		p := spdxPackage{
+			Name:                  dep.Module,
+			SPDXID:                spdxPackageID(dep.Module),
+			VersionInfo:           dep.Version,
+			DownloadLocation:      spdxNoAssertion,
+			PrimaryPackagePurpose: "LIBRARY",
+			ExternalRefs: []spdxExternalRef{
+				{
+					ReferenceCategory: "PACKAGE-MANAGER",
+					ReferenceType:     "purl",
+					ReferenceLocator:  goPURL(dep.Module, dep.Version),
+				},
+			},
+		}
+		if rev, err := module.PseudoVersionRev(dep.Version); err == nil {
+			p.SourceInfo = "pinned to commit " + rev
+			if t, err := module.PseudoVersionTime(dep.Version); err == nil {
+				p.SourceInfo += " (" + t.UTC().Format(time.RFC3339) + ")"
+			}
+		}
+		if v, ok := latest[dep.Module]; ok {
+			p.Annotations = []spdxAnnotation{
+				{
+					AnnotationDate: created,
+					AnnotationType: "OTHER",
+					Annotator:      creator,
+					Comment:        "latest version: " + v,
+				},
+			}
+		}
+		doc.Packages = append(doc.Packages, p)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      doc.SPDXID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: p.SPDXID,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("writing SPDX: %w", err)
+	}
+	return nil
+}
+
+// spdxPackageID returns the SPDX identifier of a module's package. Identifiers
+// may only contain letters, numbers, '.', and '-'.
+func spdxPackageID(modulePath string) string {
+	id := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return '-'
+		}
+	}, modulePath)
+	return "SPDXRef-Package-" + id
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestPrintSPDX(t *testing.T) {
+	env := check.Envelope{
+		ToolVersion: "v1.2.0",
+		GeneratedAt: time.Date(2026, 1, 2, 3, 4, 5, 600, time.UTC),
+		GoModPath:   "go.mod",
+		Report: check.Report{
+			Dependencies: []check.Dependency{
+				{Module: "go4.org/netipx", Version: "v0.0.0-20231101000000-aaaaaaaaaaaa"},
+				{Module: "example.com/gone", Version: "v0.0.0-20231101000000-bbbbbbbbbbbb"},
+			},
+			Updates: []check.Update{
+				{
+					Module:  "go4.org/netipx",
+					Current: "v0.0.0-20231101000000-aaaaaaaaaaaa",
+					Latest:  "v0.0.0-20231201000000-cccccccccccc",
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := printSPDX(&buf, env); err != nil {
+		t.Fatalf("printSPDX: %v", err)
+	}
+
+	namespace := regexp.MustCompile(`check-untagged-go-deps-` + uuidPattern + `"`)
+	got := namespace.ReplaceAllString(buf.String(), `check-untagged-go-deps-UUID"`)
+
+	want := `{
+  "spdxVersion": "SPDX-2.3",
+  "dataLicense": "CC0-1.0",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "name": "Pseudo-versioned dependencies in go.mod",
+  "documentNamespace": "https://spdx.org/spdxdocs/check-untagged-go-deps-UUID",
+  "creationInfo": {
+    "created": "2026-01-02T03:04:05Z",
+    "creators": [
+      "Tool: check-untagged-go-deps-v1.2.0"
+    ]
+  },
+  "packages": [
+    {
+      "name": "go4.org/netipx",
+      "SPDXID": "SPDXRef-Package-go4.org-netipx",
+      "versionInfo": "v0.0.0-20231101000000-aaaaaaaaaaaa",
+      "downloadLocation": "NOASSERTION",
+      "filesAnalyzed": false,
+      "sourceInfo": "pinned to commit aaaaaaaaaaaa (2023-11-01T00:00:00Z)",
+      "primaryPackagePurpose": "LIBRARY",
+      "externalRefs": [
+        {
+          "referenceCategory": "PACKAGE-MANAGER",
+          "referenceType": "purl",
+          "referenceLocator": "pkg:golang/go4.org/netipx@v0.0.0-20231101000000-aaaaaaaaaaaa"
+        }
+      ],
+      "annotations": [
+        {
+          "annotationDate": "2026-01-02T03:04:05Z",
+          "annotationType": "OTHER",
+          "annotator": "Tool: check-untagged-go-deps-v1.2.0",
+          "comment": "latest version: v0.0.0-20231201000000-cccccccccccc"
+        }
+      ]
+    },
+    {
+      "name": "example.com/gone",
+      "SPDXID": "SPDXRef-Package-example.com-gone",
+      "versionInfo": "v0.0.0-20231101000000-bbbbbbbbbbbb",
+      "downloadLocation": "NOASSERTION",
+      "filesAnalyzed": false,
+      "sourceInfo": "pinned to commit bbbbbbbbbbbb (2023-11-01T00:00:00Z)",
+      "primaryPackagePurpose": "LIBRARY",
+      "externalRefs": [
+        {
+          "referenceCategory": "PACKAGE-MANAGER",
+          "referenceType": "purl",
+          "referenceLocator": "pkg:golang/example.com/gone@v0.0.0-20231101000000-bbbbbbbbbbbb"
+        }
+      ]
+    }
+  ],
+  "relationships": [
+    {
+      "spdxElementId": "SPDXRef-DOCUMENT",
+      "relationshipType": "DESCRIBES",
+      "relatedSpdxElement": "SPDXRef-Package-go4.org-netipx"
+    },
+    {
+      "spdxElementId": "SPDXRef-DOCUMENT",
+      "relationshipType": "DESCRIBES",
+      "relatedSpdxElement": "SPDXRef-Package-example.com-gone"
+    }
+  ]
+}
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// notifyState records which updates and failures have been announced by
+// -notify, persisted to a file (-notify-state) so that each is announced
+// once, across runs, rather than every time it is found.
+type notifyState struct {
+	path string
+
+	mu sync.Mutex
+	// announced maps go.mod paths to what has been announced for them.
+	announced map[string]announcement
+}
+
+// announcement is what has been announced for a go.mod file: updates, by
+// module and latest version, and modules that failed to be checked.
+type announcement struct {
+	Updates  map[string]string `json:"updates,omitempty"`
+	Failures []string          `json:"failures,omitempty"`
+}
+
+// notifyStateFile is the format of the -notify-state file.
+type notifyStateFile struct {
+	Announced map[string]announcement `json:"announced"`
+}
+
+// loadNotifyState reads the state file at path. It is not an error for the
+// file not to exist yet.
+func loadNotifyState(path string) (*notifyState, error) {
+	s := &notifyState{path: path, announced: map[string]announcement{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading notify state: %w", err)
+	}
+	var file notifyStateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing notify state %s: %w", path, err)
+	}
+	if file.Announced != nil {
+		s.announced = file.Announced
+	}
+	return s, nil
+}
+
+// stateKey returns the key of a go.mod file in the state, its absolute path
+// if it can be determined, so that the state does not depend on the working
+// directory.
+func stateKey(gomodPath string) string {
+	if abs, err := filepath.Abs(gomodPath); err == nil {
+		return abs
+	}
+	return gomodPath
+}
+
+// unannounced returns the envelope with only the updates and failures that
+// have not been announced for its go.mod file.
+func (s *notifyState) unannounced(env check.Envelope) check.Envelope {
+	s.mu.Lock()
+	previous := s.announced[stateKey(env.GoModPath)]
+	s.mu.Unlock()
+
+	rep := env.Report
+	rep.Updates = slices.DeleteFunc(slices.Clone(rep.Updates), func(u check.Update) bool {
+		return previous.Updates[u.Module] == u.Latest
+	})
+	rep.Failures = slices.DeleteFunc(slices.Clone(rep.Failures), func(f check.Failure) bool {
+		return slices.Contains(previous.Failures, f.Module)
+	})
+	env.Report = rep
+	return env
+}
+
+// record replaces what has been announced for the envelope's go.mod file with
+// its updates and failures, and writes the state file. Updates that have been
+// applied and failures that have cleared are forgotten, so they are announced
+// again if they recur.
+func (s *notifyState) record(env check.Envelope) error {
+	a := announcement{Updates: map[string]string{}}
+	for _, u := range env.Report.Updates {
+		a.Updates[u.Module] = u.Latest
+	}
+	for _, f := range env.Report.Failures {
+		a.Failures = append(a.Failures, f.Module)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.announced[stateKey(env.GoModPath)] = a
+	return s.save()
+}
+
+// save writes the state file. s.mu must be held.
+func (s *notifyState) save() error {
+	return writeStateFile(s.path, "notify state", notifyStateFile{Announced: s.announced})
+}
+
+// writeStateFile writes v as JSON to the state file at path. name describes
+// the state in errors.
+func writeStateFile(path, name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", name, err)
+	}
+
+	// Write to a temporary file and rename so that an interrupted write does
+	// not lose the state.
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating %s file: %w", name, err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writing %s file: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing %s file: %w", name, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("renaming %s file: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestNotifyState(t *testing.T) {
+	var posted []check.Envelope
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var env check.Envelope
+		if err := json.NewDecoder(r.Body).Decode(&env); err != nil {
+			t.Errorf("decoding report: %v", err)
+		}
+		posted = append(posted, env)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	newNotifier := func() notifier {
+		state, err := loadNotifyState(path)
+		if err != nil {
+			t.Fatalf("loadNotifyState: %v", err)
+		}
+		return notifier{
+			target:     notifyWebhook,
+			webhookURL: server.URL,
+			state:      state,
+			client:     server.Client(),
+		}
+	}
+
+	netipx := check.Update{Module: "go4.org/netipx", Latest: "v0.0.0-20231201000000-cccccccccccc"}
+	newer := check.Update{Module: "go4.org/netipx", Latest: "v0.0.0-20240101000000-dddddddddddd"}
+	other := check.Update{Module: "example.com/a", Latest: "v0.0.0-20240101000000-eeeeeeeeeeee"}
+	failure := check.Failure{Module: "example.com/b", Err: errors.New("auth")}
+
+	steps := []struct {
+		name string
+		rep  check.Report
+		// reload loads the state from the file first, as a new run would.
+		reload bool
+		// want is the modules posted, if anything is.
+		want []string
+	}{
+		{
+			name: "first",
+			rep:  check.Report{Updates: []check.Update{netipx}},
+			want: []string{netipx.Module},
+		},
+		{name: "already announced", rep: check.Report{Updates: []check.Update{netipx}}},
+		{
+			name:   "already announced in previous run",
+			rep:    check.Report{Updates: []check.Update{netipx}},
+			reload: true,
+		},
+		{
+			name: "new update and failure",
+			rep: check.Report{
+				Updates:  []check.Update{netipx, other},
+				Failures: []check.Failure{failure},
+			},
+			want: []string{other.Module, failure.Module},
+		},
+		{
+			name: "newer latest",
+			rep: check.Report{
+				Updates:  []check.Update{newer, other},
+				Failures: []check.Failure{failure},
+			},
+			want: []string{newer.Module},
+		},
+		{name: "applied", rep: check.Report{}, reload: true},
+		{
+			name: "recurring",
+			rep:  check.Report{Updates: []check.Update{newer}},
+			want: []string{newer.Module},
+		},
+	}
+
+	n := newNotifier()
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			if step.reload {
+				n = newNotifier()
+			}
+			posted = nil
+			env := check.Envelope{GoModPath: "go.mod", Report: step.rep}
+			if err := n.notify(context.Background(), env); err != nil {
+				t.Fatalf("notify: %v", err)
+			}
+
+			if len(step.want) == 0 {
+				if len(posted) != 0 {
+					t.Fatalf("got %d posts, want none", len(posted))
+				}
+				return
+			}
+			if len(posted) != 1 {
+				t.Fatalf("got %d posts, want 1", len(posted))
+			}
+			var got []string
+			for _, u := range posted[0].Report.Updates {
+				got = append(got, u.Module)
+			}
+			for _, f := range posted[0].Report.Failures {
+				got = append(got, f.Module)
+			}
+			if !slices.Equal(got, step.want) {
+				t.Errorf("got modules %q, want %q", got, step.want)
+			}
+		})
+	}
+}
+
+func TestLoadNotifyStateInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadNotifyState(path); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,510 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+// goModEdit is a change -update makes to go.mod: moving a pseudo-versioned
+// requirement, or the fork replacing one, to a newer version.
+type goModEdit struct {
+	// module is the module whose version changes: the required module, or
+	// the fork replacing it.
+	module string
+	// replaces is the required module that module replaces, if it is a fork.
+	replaces string
+	from, to string
+	// tag is set if to is a tag chosen by -prefer-tags rather than the latest
+	// version on the branch.
+	tag bool
+}
+
+// required returns the path of the module in the build list that e updates:
+// the module it replaces, if it is a fork.
+func (e goModEdit) required() string {
+	if e.replaces != "" {
+		return e.replaces
+	}
+	return e.module
+}
+
+// runUpdate makes the edits -update plans for rep to the go.mod file at
+// gomodPath, re-vendoring after them with -update-vendor, and returns those
+// made and those held back.
+func runUpdate(
+	ctx context.Context,
+	opts options,
+	rep check.Report,
+	gomodPath string,
+) ([]goModEdit, []heldEdit, error) {
+	if !opts.updateVendor {
+		return editGoMod(ctx, opts, rep, gomodPath)
+	}
+	return vendorEdits(ctx, gomodPath, func() ([]goModEdit, []heldEdit, error) {
+		return editGoMod(ctx, opts, rep, gomodPath)
+	}, goModVendor)
+}
+
+// editGoMod makes the edits -update plans for rep to the go.mod file at
+// gomodPath, and returns those made and those held back.
+func editGoMod(
+	ctx context.Context,
+	opts options,
+	rep check.Report,
+	gomodPath string,
+) ([]goModEdit, []heldEdit, error) {
+	planned, held := planUpdates(rep, opts.preferTags, opts.allowBreaking)
+
+	var state *cooldownState
+	now := time.Now()
+	if opts.cooldownState != "" {
+		var err error
+		if state, err = loadCooldownState(opts.cooldownState); err != nil {
+			return nil, nil, err
+		}
+		var cooling []heldEdit
+		planned, cooling = state.cooling(gomodPath, planned, now, opts.cooldown)
+		held = append(held, cooling...)
+	}
+
+	planned, deferred := limitEdits(planned, opts.maxUpdates)
+	held = append(held, deferred...)
+
+	if opts.verify == "" {
+		edits, err := applyGoModEdits(gomodPath, planned)
+		return edits, held, err
+	}
+	edits, failed, err := verifyEdits(ctx, gomodPath, planned, shellVerifier(opts.verify))
+	if err != nil {
+		return nil, nil, err
+	}
+	held = append(held, failed...)
+	if state != nil {
+		if err := state.record(gomodPath, failed, now, opts.cooldown); err != nil {
+			return nil, nil, err
+		}
+	}
+	return edits, held, nil
+}
+
+// planUpdates returns the edits -update makes to go.mod for rep, in the
+// order of rep.Dependencies: each dependency with an update moves to the
+// latest version. With preferTags, a dependency whose module has a tagged
+// release newer than its current version (see check.WithTagCheck) moves to
+// that tag instead, whether or not there are new commits, so it can be
+// kept up to date by tools such as Dependabot from then on. Dependencies
+// found outside go.mod (-tool-pins) are left alone.
+//
+// The edits are ordered so that dependencies come before the modules that
+// require them (see orderEdits).
+//
+// Unless allowBreaking is set, dependencies whose updates were found to be
+// breaking (see breakingReason) are held back and returned separately, so
+// unattended update jobs do not land incompatible changes.
+func planUpdates(rep check.Report, preferTags, allowBreaking bool) ([]goModEdit, []heldEdit) {
+	updates := map[string]check.Update{}
+	for _, u := range rep.Updates {
+		updates[u.Module] = u
+	}
+	var (
+		edits []goModEdit
+		held  []heldEdit
+	)
+	for _, dep := range rep.Dependencies {
+		if dep.Source != "" {
+			continue
+		}
+		edit := goModEdit{module: dep.Module, replaces: dep.Replaces, from: dep.Version}
+		switch {
+		case preferTags && dep.LatestTag != "" && semver.Compare(dep.LatestTag, dep.Version) > 0:
+			edit.to, edit.tag = dep.LatestTag, true
+		case updates[dep.Module].Latest != "":
+			edit.to = updates[dep.Module].Latest
+		default:
+			continue
+		}
+		if u, ok := updates[dep.Module]; ok && !allowBreaking {
+			if reason := breakingReason(u); reason != "" {
+				held = append(held, heldEdit{
+					goModEdit: edit,
+					reason:    reason,
+					hint:      "use -allow-breaking to update it anyway",
+				})
+				continue
+			}
+		}
+		edits = append(edits, edit)
+	}
+	return orderEdits(edits, updates), held
+}
+
+// orderEdits orders edits so that each comes before the edits of modules
+// that require it, according to the updates' requirers (see
+// check.WithRequirers), so that when they are applied or reviewed one at a
+// time, a module never moves to a version needing a newer requirement than
+// go.mod has yet. Otherwise, and within cycles, the order is kept.
+func orderEdits(edits []goModEdit, updates map[string]check.Update) []goModEdit {
+	index := map[string]int{}
+	for i, e := range edits {
+		index[e.required()] = i
+	}
+	// pending counts the edits that must come before each edit, and after
+	// lists the edits that must come after it.
+	pending := make([]int, len(edits))
+	after := make([][]int, len(edits))
+	for i, e := range edits {
+		for _, r := range updates[e.module].RequiredBy {
+			if j, ok := index[r.Module]; ok && j != i {
+				after[i] = append(after[i], j)
+				pending[j]++
+			}
+		}
+	}
+
+	ordered := make([]goModEdit, 0, len(edits))
+	done := make([]bool, len(edits))
+	for len(ordered) < len(edits) {
+		next := -1
+		for i := range edits {
+			if !done[i] && pending[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			// Every remaining edit waits on another, so they are in a cycle.
+			next = slices.Index(done, false)
+		}
+		done[next] = true
+		ordered = append(ordered, edits[next])
+		for _, j := range after[next] {
+			pending[j]--
+		}
+	}
+	return ordered
+}
+
+// heldEdit is an edit -update did not make: because the update is breaking,
+// was deferred by -max-updates, or failed -verify.
+type heldEdit struct {
+	goModEdit
+	// reason is why it was not made, and hint what happens to it or what
+	// to do about it.
+	reason, hint string
+}
+
+// limitEdits returns the first limit edits, and the rest as deferred (see
+// -max-updates). A limit of 0 means no limit. Since edits are in the order of
+// orderEdits, the dependencies of those made come first.
+func limitEdits(edits []goModEdit, limit int) ([]goModEdit, []heldEdit) {
+	if limit == 0 || len(edits) <= limit {
+		return edits, nil
+	}
+	var deferred []heldEdit
+	for _, e := range edits[limit:] {
+		deferred = append(deferred, heldEdit{
+			goModEdit: e,
+			reason:    fmt.Sprintf("deferred by -max-updates %d", limit),
+			hint:      "it is left for a later run",
+		})
+	}
+	return edits[:limit], deferred
+}
+
+// breakingReason returns why the update is breaking, or "" if it was not
+// found to be: its commits are marked as breaking changes (-risk), or it
+// changes the exported API incompatibly (-api-diff).
+func breakingReason(u check.Update) string {
+	if c := u.Compatibility; c != nil && !c.Compatible {
+		reason := "incompatible API change"
+		if len(c.Incompatible) > 0 {
+			reason += ": " + c.Incompatible[0]
+			if n := len(c.Incompatible) - 1; n > 0 {
+				reason += fmt.Sprintf(" (and %d more)", n)
+			}
+		}
+		return reason
+	}
+	if u.Risk == check.RiskBreaking {
+		return "commits marked as breaking changes"
+	}
+	return ""
+}
+
+// applyGoModEdits makes edits to the go.mod file at gomodPath, keeping its
+// comments and layout, and returns the edits made. A requirement whose
+// version changed since it was checked is left alone.
+func applyGoModEdits(gomodPath string, edits []goModEdit) ([]goModEdit, error) {
+	if len(edits) == 0 {
+		return nil, nil
+	}
+	data, err := os.ReadFile(gomodPath) //nolint:gosec // the user chooses the go.mod file
+	if err != nil {
+		return nil, fmt.Errorf("reading go.mod: %w", err)
+	}
+	f, err := modfile.Parse(gomodPath, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parsing go.mod: %w", err)
+	}
+
+	var made []goModEdit
+	for _, e := range edits {
+		ok, err := applyGoModEdit(f, e)
+		if err != nil {
+			return nil, fmt.Errorf("updating %s: %w", e.module, err)
+		}
+		if ok {
+			made = append(made, e)
+		}
+	}
+	if len(made) == 0 {
+		return nil, nil
+	}
+
+	f.Cleanup()
+	out, err := f.Format()
+	if err != nil {
+		return nil, fmt.Errorf("formatting go.mod: %w", err)
+	}
+	fi, err := os.Stat(gomodPath)
+	if err != nil {
+		return nil, fmt.Errorf("writing go.mod: %w", err)
+	}
+	if err := os.WriteFile(gomodPath, out, fi.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("writing go.mod: %w", err)
+	}
+	return made, nil
+}
+
+// verifyEdits makes edits to the go.mod file at gomodPath one at a time, in
+// order, running verify in its directory after each (see -verify). An edit
+// verify fails for is undone, along with any change to go.sum, so the edits
+// after it are verified without it. It returns the edits made and those
+// that failed.
+func verifyEdits(
+	ctx context.Context,
+	gomodPath string,
+	edits []goModEdit,
+	verify func(ctx context.Context, dir string) error,
+) ([]goModEdit, []heldEdit, error) {
+	dir := filepath.Dir(gomodPath)
+	var (
+		made   []goModEdit
+		failed []heldEdit
+	)
+	for _, e := range edits {
+		saved, err := snapshotFiles(gomodPath, filepath.Join(dir, "go.sum"))
+		if err != nil {
+			return nil, nil, err
+		}
+		applied, err := applyGoModEdits(gomodPath, []goModEdit{e})
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(applied) == 0 {
+			continue
+		}
+		verifyErr := verify(ctx, dir)
+		if verifyErr == nil {
+			made = append(made, e)
+			continue
+		}
+		if err := saved.restore(); err != nil {
+			return nil, nil, err
+		}
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		failed = append(failed, heldEdit{
+			goModEdit: e,
+			reason:    "-verify failed: " + verifyErr.Error(),
+			hint:      "the change was undone",
+		})
+	}
+	return made, failed, nil
+}
+
+// vendorEdits makes edits to the go.mod file at gomodPath with edit, then, if
+// any were made and the module has a vendor directory, runs vendor in its
+// directory (see -update-vendor). If vendor fails, go.mod and go.sum are
+// restored as they were before the edits, which are returned as held.
+func vendorEdits(
+	ctx context.Context,
+	gomodPath string,
+	edit func() ([]goModEdit, []heldEdit, error),
+	vendor func(ctx context.Context, dir string) error,
+) ([]goModEdit, []heldEdit, error) {
+	dir := filepath.Dir(gomodPath)
+	saved, err := snapshotFiles(gomodPath, filepath.Join(dir, "go.sum"))
+	if err != nil {
+		return nil, nil, err
+	}
+	made, held, err := edit()
+	if err != nil || len(made) == 0 {
+		return made, held, err
+	}
+	if _, err := os.Stat(filepath.Join(dir, "vendor", "modules.txt")); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return made, held, nil
+		}
+		return nil, nil, err
+	}
+
+	vendorErr := vendor(ctx, dir)
+	if vendorErr == nil {
+		return made, held, nil
+	}
+	if err := saved.restore(); err != nil {
+		return nil, nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+	for _, e := range made {
+		held = append(held, heldEdit{
+			goModEdit: e,
+			reason:    "go mod vendor failed: " + vendorErr.Error(),
+			hint:      "the change was undone",
+		})
+	}
+	return nil, held, nil
+}
+
+// goModVendor runs go mod vendor in dir, with its output on stderr.
+func goModVendor(ctx context.Context, dir string) error {
+	cmd := exec.CommandContext(ctx, "go", "mod", "vendor")
+	cmd.Dir = dir
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// editedDependencies returns the dependencies as edits leave them, to compare
+// with their vendored copies.
+func editedDependencies(edits []goModEdit) []check.Dependency {
+	deps := make([]check.Dependency, 0, len(edits))
+	for _, e := range edits {
+		deps = append(deps, check.Dependency{Module: e.module, Replaces: e.replaces, Version: e.to})
+	}
+	return deps
+}
+
+// shellVerifier returns a verify function for verifyEdits running command
+// with sh, with its output on stderr.
+func shellVerifier(command string) func(ctx context.Context, dir string) error {
+	return func(ctx context.Context, dir string) error {
+		cmd := exec.CommandContext(ctx, "sh", "-c", command) //nolint:gosec // the user's command
+		cmd.Dir = dir
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	}
+}
+
+// fileSnapshot holds the contents of files, keyed by path, to restore them.
+// A nil content means the file did not exist.
+type fileSnapshot map[string][]byte
+
+// snapshotFiles reads the files at paths.
+func snapshotFiles(paths ...string) (fileSnapshot, error) {
+	s := fileSnapshot{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path) //nolint:gosec // files next to the user's go.mod
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		s[path] = data
+	}
+	return s, nil
+}
+
+// restore writes the files back as they were, removing those that did not
+// exist.
+func (s fileSnapshot) restore() error {
+	for path, data := range s {
+		if data == nil {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // like go.mod
+			return err
+		}
+	}
+	return nil
+}
+
+// applyGoModEdit makes an edit to f, if the requirement or replacement it
+// changes is still at the version it was checked at.
+func applyGoModEdit(f *modfile.File, e goModEdit) (bool, error) {
+	if e.replaces != "" {
+		for _, r := range f.Replace {
+			if r.Old.Path == e.replaces && r.New.Path == e.module && r.New.Version == e.from {
+				return true, f.AddReplace(r.Old.Path, r.Old.Version, e.module, e.to)
+			}
+		}
+		return false, nil
+	}
+	for _, r := range f.Require {
+		if r.Mod.Path == e.module && r.Mod.Version == e.from {
+			return true, f.AddRequire(e.module, e.to)
+		}
+	}
+	return false, nil
+}
+
+// printGoModEdits writes a line to w for each edit made to the go.mod file
+// named goModPath and each held back, and if any were made, a reminder to
+// update go.sum, and one to re-vendor for each edited dependency whose
+// vendored copy is stale.
+func printGoModEdits(
+	w io.Writer,
+	goModPath string,
+	edits []goModEdit,
+	held []heldEdit,
+	stale []check.VendorMismatch,
+) {
+	for _, h := range held {
+		fmt.Fprintf(
+			w,
+			"%s: not updating %s to %s: %s; %s\n",
+			goModPath, h.module, h.to, h.reason, h.hint,
+		)
+	}
+	if len(edits) == 0 {
+		return
+	}
+	for _, e := range edits {
+		how := ""
+		if e.tag {
+			how = " (tagged release)"
+		}
+		fmt.Fprintf(w, "%s: updated %s %s => %s%s\n", goModPath, e.module, e.from, e.to, how)
+	}
+	fmt.Fprintln(w, "Run 'go mod tidy' to update go.sum.")
+	if len(stale) == 0 {
+		return
+	}
+	for _, m := range stale {
+		fmt.Fprintf(
+			w,
+			"%s: vendored %s is %s, not %s\n",
+			goModPath, m.Module, m.Vendored, m.Required,
+		)
+	}
+	fmt.Fprintln(w, "Run 'go mod vendor' to update the vendor directory.")
+}
@@ -0,0 +1,518 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/horgh/check-untagged-go-deps/check"
+)
+
+func TestPlanUpdates(t *testing.T) {
+	const (
+		current = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+		latest  = "v0.0.0-20231201000000-bbbbbbbbbbbb"
+	)
+	rep := check.Report{
+		Dependencies: []check.Dependency{
+			{Module: "example.com/stale", Version: current},
+			{Module: "example.com/tagged", Version: current, LatestTag: "v1.2.0"},
+			// Tagged at the commit already required: no new commits.
+			{Module: "example.com/released", Version: current, LatestTag: "v0.1.0"},
+			// The only tag is older than the pin.
+			{
+				Module:    "example.com/old-tag",
+				Version:   "v1.2.1-0.20231101000000-aaaaaaaaaaaa",
+				LatestTag: "v1.2.0",
+			},
+			{Module: "example.com/fork", Version: current, Replaces: "example.com/upstream"},
+			{Module: "example.com/tool", Version: current, Source: "Dockerfile:2"},
+			{Module: "example.com/current", Version: current},
+		},
+		Updates: []check.Update{
+			{Module: "example.com/stale", Current: current, Latest: latest},
+			{Module: "example.com/tagged", Current: current, Latest: latest},
+			{
+				Module:  "example.com/old-tag",
+				Current: "v1.2.1-0.20231101000000-aaaaaaaaaaaa",
+				Latest:  "v1.2.1-0.20231201000000-bbbbbbbbbbbb",
+			},
+			{Module: "example.com/fork", Current: current, Latest: latest},
+			{Module: "example.com/tool", Current: current, Latest: latest, Source: "Dockerfile:2"},
+		},
+	}
+
+	want := []goModEdit{
+		{module: "example.com/stale", from: current, to: latest},
+		{module: "example.com/tagged", from: current, to: latest},
+		{
+			module: "example.com/old-tag",
+			from:   "v1.2.1-0.20231101000000-aaaaaaaaaaaa",
+			to:     "v1.2.1-0.20231201000000-bbbbbbbbbbbb",
+		},
+		{module: "example.com/fork", replaces: "example.com/upstream", from: current, to: latest},
+	}
+	if got, _ := planUpdates(rep, false, false); !reflect.DeepEqual(got, want) {
+		t.Errorf("without -prefer-tags got %+v, want %+v", got, want)
+	}
+
+	want[1] = goModEdit{module: "example.com/tagged", from: current, to: "v1.2.0", tag: true}
+	want = append(want[:2], append(
+		[]goModEdit{{module: "example.com/released", from: current, to: "v0.1.0", tag: true}},
+		want[2:]...,
+	)...)
+	if got, _ := planUpdates(rep, true, false); !reflect.DeepEqual(got, want) {
+		t.Errorf("with -prefer-tags got %+v, want %+v", got, want)
+	}
+}
+
+func TestPlanUpdatesBreaking(t *testing.T) {
+	const (
+		current = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+		latest  = "v0.0.0-20231201000000-bbbbbbbbbbbb"
+	)
+	rep := check.Report{
+		Dependencies: []check.Dependency{
+			{Module: "example.com/risky", Version: current},
+			{Module: "example.com/api", Version: current},
+			{Module: "example.com/safe", Version: current},
+		},
+		Updates: []check.Update{
+			{
+				Module:  "example.com/risky",
+				Current: current,
+				Latest:  latest,
+				Risk:    check.RiskBreaking,
+			},
+			{
+				Module:  "example.com/api",
+				Current: current,
+				Latest:  latest,
+				Compatibility: &check.Compatibility{
+					Incompatible: []string{
+						"example.com/api.Func: removed",
+						"example.com/api.T: removed",
+					},
+				},
+			},
+			{
+				Module:        "example.com/safe",
+				Current:       current,
+				Latest:        latest,
+				Risk:          check.RiskFeature,
+				Compatibility: &check.Compatibility{Compatible: true},
+			},
+		},
+	}
+
+	edits, held := planUpdates(rep, false, false)
+	want := []goModEdit{{module: "example.com/safe", from: current, to: latest}}
+	if !reflect.DeepEqual(edits, want) {
+		t.Errorf("got edits %+v, want %+v", edits, want)
+	}
+	wantHeld := []heldEdit{
+		{
+			goModEdit: goModEdit{module: "example.com/risky", from: current, to: latest},
+			reason:    "commits marked as breaking changes",
+			hint:      "use -allow-breaking to update it anyway",
+		},
+		{
+			goModEdit: goModEdit{module: "example.com/api", from: current, to: latest},
+			reason:    "incompatible API change: example.com/api.Func: removed (and 1 more)",
+			hint:      "use -allow-breaking to update it anyway",
+		},
+	}
+	if !reflect.DeepEqual(held, wantHeld) {
+		t.Errorf("got held %+v, want %+v", held, wantHeld)
+	}
+
+	var out bytes.Buffer
+	printGoModEdits(&out, "go.mod", nil, held[:1], nil)
+	wantOut := "go.mod: not updating example.com/risky to " + latest +
+		": commits marked as breaking changes; use -allow-breaking to update it anyway\n"
+	if out.String() != wantOut {
+		t.Errorf("got output %q, want %q", out.String(), wantOut)
+	}
+
+	if edits, held := planUpdates(rep, false, true); len(edits) != 3 || len(held) != 0 {
+		t.Errorf("with -allow-breaking got edits %+v, held %+v, want 3 edits", edits, held)
+	}
+}
+
+func TestApplyGoModEdits(t *testing.T) {
+	gomod := `module test
+
+go 1.25
+
+require (
+	example.com/a v0.0.0-20231101000000-aaaaaaaaaaaa // pinned for a fix
+	example.com/b v0.0.0-20231101000000-aaaaaaaaaaaa
+	example.com/upstream v1.0.0
+)
+
+replace example.com/upstream => example.com/fork v0.0.0-20231101000000-aaaaaaaaaaaa
+`
+	gomodPath := filepath.Join(t.TempDir(), "go.mod")
+	if err := os.WriteFile(gomodPath, []byte(gomod), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	edits := []goModEdit{
+		{
+			module: "example.com/a",
+			from:   "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			to:     "v1.2.0",
+			tag:    true,
+		},
+		// go.mod has changed since it was checked.
+		{
+			module: "example.com/b",
+			from:   "v0.0.0-20231001000000-cccccccccccc",
+			to:     "v0.0.0-20231201000000-bbbbbbbbbbbb",
+		},
+		{
+			module:   "example.com/fork",
+			replaces: "example.com/upstream",
+			from:     "v0.0.0-20231101000000-aaaaaaaaaaaa",
+			to:       "v0.0.0-20231201000000-bbbbbbbbbbbb",
+		},
+	}
+	made, err := applyGoModEdits(gomodPath, edits)
+	if err != nil {
+		t.Fatalf("applyGoModEdits: %v", err)
+	}
+	if want := []goModEdit{edits[0], edits[2]}; !reflect.DeepEqual(made, want) {
+		t.Errorf("got edits %+v, want %+v", made, want)
+	}
+
+	got, err := os.ReadFile(gomodPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `module test
+
+go 1.25
+
+require (
+	example.com/a v1.2.0 // pinned for a fix
+	example.com/b v0.0.0-20231101000000-aaaaaaaaaaaa
+	example.com/upstream v1.0.0
+)
+
+replace example.com/upstream => example.com/fork v0.0.0-20231201000000-bbbbbbbbbbbb
+`
+	if string(got) != want {
+		t.Errorf("got go.mod:\n%s\nwant:\n%s", got, want)
+	}
+
+	var out bytes.Buffer
+	printGoModEdits(&out, "go.mod", made[:1], nil, nil)
+	wantOut := "go.mod: updated example.com/a v0.0.0-20231101000000-aaaaaaaaaaaa => v1.2.0 " +
+		"(tagged release)\nRun 'go mod tidy' to update go.sum.\n"
+	if out.String() != wantOut {
+		t.Errorf("got output %q, want %q", out.String(), wantOut)
+	}
+}
+
+func TestOrderEdits(t *testing.T) {
+	edits := []goModEdit{
+		{module: "example.com/app-lib"},
+		{module: "example.com/fork", replaces: "example.com/base"},
+		{module: "example.com/leaf"},
+		{module: "example.com/cycle-a"},
+		{module: "example.com/cycle-b"},
+	}
+	updates := map[string]check.Update{
+		"example.com/leaf": {
+			RequiredBy: []check.Requirer{
+				{Module: "example.com/app-lib"},
+				{Module: "example.com/base"},
+				{Module: "example.com/unchanged"},
+			},
+		},
+		"example.com/fork": {
+			RequiredBy: []check.Requirer{{Module: "example.com/app-lib"}},
+		},
+		"example.com/cycle-a": {
+			RequiredBy: []check.Requirer{{Module: "example.com/cycle-b"}},
+		},
+		"example.com/cycle-b": {
+			RequiredBy: []check.Requirer{{Module: "example.com/cycle-a"}},
+		},
+	}
+
+	var got []string
+	for _, e := range orderEdits(edits, updates) {
+		got = append(got, e.module)
+	}
+	want := []string{
+		"example.com/leaf",
+		"example.com/fork",
+		"example.com/app-lib",
+		"example.com/cycle-a",
+		"example.com/cycle-b",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got order %q, want %q", got, want)
+	}
+}
+
+func TestLimitEdits(t *testing.T) {
+	edits := []goModEdit{
+		{module: "example.com/a", to: "v0.0.0-20231201000000-aaaaaaaaaaaa"},
+		{module: "example.com/b", to: "v0.0.0-20231201000000-bbbbbbbbbbbb"},
+		{module: "example.com/c", to: "v0.0.0-20231201000000-cccccccccccc"},
+	}
+
+	if got, deferred := limitEdits(edits, 0); len(got) != 3 || len(deferred) != 0 {
+		t.Errorf("with no limit got %+v, deferred %+v", got, deferred)
+	}
+	if got, deferred := limitEdits(edits, 3); len(got) != 3 || len(deferred) != 0 {
+		t.Errorf("with a limit of 3 got %+v, deferred %+v", got, deferred)
+	}
+
+	got, deferred := limitEdits(edits, 1)
+	if !reflect.DeepEqual(got, edits[:1]) {
+		t.Errorf("got %+v, want %+v", got, edits[:1])
+	}
+	var out bytes.Buffer
+	printGoModEdits(&out, "go.mod", nil, deferred, nil)
+	want := "go.mod: not updating example.com/b to v0.0.0-20231201000000-bbbbbbbbbbbb: " +
+		"deferred by -max-updates 1; it is left for a later run\n" +
+		"go.mod: not updating example.com/c to v0.0.0-20231201000000-cccccccccccc: " +
+		"deferred by -max-updates 1; it is left for a later run\n"
+	if out.String() != want {
+		t.Errorf("got output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestVerifyEdits(t *testing.T) {
+	const (
+		current = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+		latest  = "v0.0.0-20231201000000-bbbbbbbbbbbb"
+	)
+	dir := t.TempDir()
+	gomodPath := filepath.Join(dir, "go.mod")
+	gomod := "module test\n\ngo 1.25\n\nrequire (\n" +
+		"\texample.com/a " + current + "\n" +
+		"\texample.com/b " + current + "\n" +
+		")\n"
+	if err := os.WriteFile(gomodPath, []byte(gomod), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	gosumPath := filepath.Join(dir, "go.sum")
+
+	edits := []goModEdit{
+		{module: "example.com/a", from: current, to: latest},
+		{module: "example.com/b", from: current, to: latest},
+	}
+	var verified []string
+	verify := func(_ context.Context, gotDir string) error {
+		if gotDir != dir {
+			t.Errorf("got verify directory %s, want %s", gotDir, dir)
+		}
+		data, err := os.ReadFile(gomodPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		verified = append(verified, string(data))
+		// Like go mod tidy.
+		if err := os.WriteFile(gosumPath, []byte("sums\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "example.com/b "+latest) {
+			return errors.New("exit status 1")
+		}
+		return nil
+	}
+
+	made, failed, err := verifyEdits(t.Context(), gomodPath, edits, verify)
+	if err != nil {
+		t.Fatalf("verifyEdits: %v", err)
+	}
+	if !reflect.DeepEqual(made, edits[:1]) {
+		t.Errorf("got edits made %+v, want %+v", made, edits[:1])
+	}
+	wantFailed := []heldEdit{{
+		goModEdit: edits[1],
+		reason:    "-verify failed: exit status 1",
+		hint:      "the change was undone",
+	}}
+	if !reflect.DeepEqual(failed, wantFailed) {
+		t.Errorf("got failed %+v, want %+v", failed, wantFailed)
+	}
+	if len(verified) != 2 {
+		t.Fatalf("verified %d times, want 2", len(verified))
+	}
+	// Each edit is verified on its own, after the edits before it.
+	if !strings.Contains(verified[0], "example.com/b "+current) ||
+		!strings.Contains(verified[1], "example.com/a "+latest) {
+		t.Errorf("verified go.mod files:\n%s", strings.Join(verified, "\n"))
+	}
+
+	data, err := os.ReadFile(gomodPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "example.com/a "+latest) ||
+		!strings.Contains(string(data), "example.com/b "+current) {
+		t.Errorf("got go.mod:\n%s", data)
+	}
+	// go.sum is as it was after the first edit's verification.
+	if data, err := os.ReadFile(gosumPath); err != nil || string(data) != "sums\n" {
+		t.Errorf("got go.sum %q, %v", data, err)
+	}
+}
+
+func TestVendorEdits(t *testing.T) {
+	const (
+		current = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+		latest  = "v0.0.0-20231201000000-bbbbbbbbbbbb"
+	)
+	gomod := "module test\n\ngo 1.25\n\nrequire (\n" +
+		"\texample.com/a " + current + "\n" +
+		"\texample.com/upstream v1.0.0\n" +
+		")\n\n" +
+		"replace example.com/upstream => example.com/fork " + current + "\n"
+	modulesTxt := "# example.com/a " + current + "\n## explicit\n" +
+		"# example.com/upstream v1.0.0 => example.com/fork " + current + "\n## explicit\n"
+	const vendorFailed = "go mod vendor failed: exit status 1"
+	edits := []goModEdit{
+		{module: "example.com/a", from: current, to: latest},
+		{module: "example.com/fork", replaces: "example.com/upstream", from: current, to: latest},
+	}
+
+	tests := []struct {
+		name      string
+		vendored  bool
+		vendorErr error
+		wantMade  []goModEdit
+		wantHeld  []heldEdit
+		// wantVendor is whether vendor is run, and wantGoMod whether
+		// go.mod keeps the edits.
+		wantVendor, wantGoMod bool
+	}{
+		{
+			name:       "vendored",
+			vendored:   true,
+			wantMade:   edits,
+			wantVendor: true,
+			wantGoMod:  true,
+		},
+		{
+			name:      "vendor fails",
+			vendored:  true,
+			vendorErr: errors.New("exit status 1"),
+			wantHeld: []heldEdit{
+				{goModEdit: edits[0], reason: vendorFailed, hint: "the change was undone"},
+				{goModEdit: edits[1], reason: vendorFailed, hint: "the change was undone"},
+			},
+			wantVendor: true,
+		},
+		{
+			name:      "not vendored",
+			wantMade:  edits,
+			wantGoMod: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			gomodPath := filepath.Join(dir, "go.mod")
+			if err := os.WriteFile(gomodPath, []byte(gomod), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if tt.vendored {
+				if err := os.Mkdir(filepath.Join(dir, "vendor"), 0o700); err != nil {
+					t.Fatal(err)
+				}
+				path := filepath.Join(dir, "vendor", "modules.txt")
+				if err := os.WriteFile(path, []byte(modulesTxt), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			vendored := false
+			made, held, err := vendorEdits(
+				t.Context(),
+				gomodPath,
+				func() ([]goModEdit, []heldEdit, error) {
+					made, err := applyGoModEdits(gomodPath, edits)
+					return made, nil, err
+				},
+				func(_ context.Context, gotDir string) error {
+					if gotDir != dir {
+						t.Errorf("got vendor directory %s, want %s", gotDir, dir)
+					}
+					vendored = true
+					return tt.vendorErr
+				},
+			)
+			if err != nil {
+				t.Fatalf("vendorEdits: %v", err)
+			}
+			if !reflect.DeepEqual(made, tt.wantMade) {
+				t.Errorf("got edits made %+v, want %+v", made, tt.wantMade)
+			}
+			if !reflect.DeepEqual(held, tt.wantHeld) {
+				t.Errorf("got held %+v, want %+v", held, tt.wantHeld)
+			}
+			if vendored != tt.wantVendor {
+				t.Errorf("vendor run: %t, want %t", vendored, tt.wantVendor)
+			}
+			data, err := os.ReadFile(gomodPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(data) != gomod; got != tt.wantGoMod {
+				t.Errorf("got go.mod:\n%s", data)
+			}
+		})
+	}
+}
+
+func TestPrintGoModEditsStaleVendor(t *testing.T) {
+	const (
+		current = "v0.0.0-20231101000000-aaaaaaaaaaaa"
+		latest  = "v0.0.0-20231201000000-bbbbbbbbbbbb"
+	)
+	dir := t.TempDir()
+	gomodPath := filepath.Join(dir, "go.mod")
+	if err := os.Mkdir(filepath.Join(dir, "vendor"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	modulesTxt := "# example.com/a " + latest + "\n## explicit\n" +
+		"# example.com/upstream v1.0.0 => example.com/fork " + current + "\n## explicit\n"
+	path := filepath.Join(dir, "vendor", "modules.txt")
+	if err := os.WriteFile(path, []byte(modulesTxt), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The vendored fork is stale, as go mod vendor was not run, but a
+	// vendored copy already at the update is not.
+	edits := []goModEdit{
+		{module: "example.com/a", from: current, to: latest},
+		{module: "example.com/fork", replaces: "example.com/upstream", from: current, to: latest},
+	}
+	stale, err := check.FindVendorMismatches(gomodPath, editedDependencies(edits))
+	if err != nil {
+		t.Fatalf("FindVendorMismatches: %v", err)
+	}
+
+	var out bytes.Buffer
+	printGoModEdits(&out, "go.mod", edits, nil, stale)
+	want := "go.mod: updated example.com/a " + current + " => " + latest + "\n" +
+		"go.mod: updated example.com/fork " + current + " => " + latest + "\n" +
+		"Run 'go mod tidy' to update go.sum.\n" +
+		"go.mod: vendored example.com/fork is " + current + ", not " + latest + "\n" +
+		"Run 'go mod vendor' to update the vendor directory.\n"
+	if out.String() != want {
+		t.Errorf("got output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
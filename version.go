@@ -0,0 +1,61 @@
+package main
+
+import "runtime/debug"
+
+// versionString returns the tool's version, VCS revision, and build date as
+// recorded in the binary's build information.
+func versionString() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "check-untagged-go-deps (unknown version)"
+	}
+	return formatVersion(info)
+}
+
+// toolVersion returns just the tool's module version, e.g. "v1.2.0", for
+// recording in reports.
+func toolVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	return mainVersion(info)
+}
+
+func mainVersion(info *debug.BuildInfo) string {
+	if info.Main.Version == "" {
+		return "(devel)"
+	}
+	return info.Main.Version
+}
+
+func formatVersion(info *debug.BuildInfo) string {
+	version := mainVersion(info)
+
+	var revision, buildTime string
+	modified := false
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.time":
+			buildTime = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+
+	out := "check-untagged-go-deps " + version
+	if revision != "" {
+		out += "\ncommit: " + revision
+		if modified {
+			out += " (modified)"
+		}
+	}
+	if buildTime != "" {
+		out += "\ndate: " + buildTime
+	}
+	out += "\ngo: " + info.GoVersion
+
+	return out
+}
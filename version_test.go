@@ -0,0 +1,52 @@
+package main
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestFormatVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		info *debug.BuildInfo
+		want string
+	}{
+		{
+			name: "release build with vcs info",
+			info: &debug.BuildInfo{
+				GoVersion: "go1.25.0",
+				Main:      debug.Module{Version: "v1.2.0"},
+				Settings: []debug.BuildSetting{
+					{Key: "vcs.revision", Value: "0123456789abcdef"},
+					{Key: "vcs.time", Value: "2026-01-06T00:00:00Z"},
+					{Key: "vcs.modified", Value: "false"},
+				},
+			},
+			want: "check-untagged-go-deps v1.2.0\n" +
+				"commit: 0123456789abcdef\n" +
+				"date: 2026-01-06T00:00:00Z\n" +
+				"go: go1.25.0",
+		},
+		{
+			name: "modified development build",
+			info: &debug.BuildInfo{
+				GoVersion: "go1.25.0",
+				Settings: []debug.BuildSetting{
+					{Key: "vcs.revision", Value: "0123456789abcdef"},
+					{Key: "vcs.modified", Value: "true"},
+				},
+			},
+			want: "check-untagged-go-deps (devel)\n" +
+				"commit: 0123456789abcdef (modified)\n" +
+				"go: go1.25.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatVersion(tt.info); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}